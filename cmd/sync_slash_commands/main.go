@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/server/api/slash_command"
+	"log"
+	"os"
+	"time"
+)
+
+// Syncs the slash commands with discord.
+// Run with -dry-run in CI to see the changes a deploy will make.
+func main() {
+	var dryRun bool
+	var jsonOutput bool
+	var envFile string
+	flag.BoolVar(&dryRun, "dry-run", false, "show the changes without making them")
+	flag.BoolVar(&jsonOutput, "json", false, "write the report as json")
+	flag.StringVar(&envFile, "env-file", "", "file with environment variables")
+	flag.Parse()
+
+	if envFile != "" {
+		config.ProcessEnvFile(envFile)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	report, err := slash_command.Sync(ctx, dryRun)
+	if jsonOutput {
+		reportJSON, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(reportJSON))
+	} else {
+		fmt.Println(report.String())
+	}
+	if err != nil {
+		log.Println("sync failed:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/cloudflare"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron/deadline_reminders"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron/sheets_sync"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron/submission_digest"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron/which_time"
+	"github.com/virtual-vgo/vvgo/pkg/server/member_directory"
+	"time"
+)
+
+const CloudflarePurgeJob = "cloudflare_purge"
+
+func registerJobs() error {
+	timezonesChannelId := func() string {
+		if config.Config().Development {
+			return config.Config().Discord.SandboxChannelID
+		}
+		return config.Config().Discord.TimezonesChannelID
+	}
+
+	var sheetsSyncSchedule string
+	if interval := config.Config().Sheets.SyncInterval; interval > 0 {
+		sheetsSyncSchedule = "@every " + interval.String()
+	}
+
+	var memberSyncSchedule string
+	if interval := config.Config().Discord.MemberSyncInterval; interval > 0 {
+		memberSyncSchedule = "@every " + interval.String()
+	}
+
+	for _, job := range []cron.Job{
+		{
+			Name:        CloudflarePurgeJob,
+			Description: "Purge the cloudflare cache. This runs after each deployment.",
+			Run:         func(context.Context) error { cloudflare.PurgeCache(); return nil },
+		},
+		{
+			Name:        "deadline_reminders",
+			Description: "Post reminders for upcoming submission deadlines.",
+			Schedule:    "* * * * *",
+			Run: func(ctx context.Context) error {
+				deadline_reminders.SendReminders(ctx, time.Now())
+				return nil
+			},
+		},
+		{
+			Name:        "member_directory_sync",
+			Description: "Page through the discord guild and update the member directory.",
+			Schedule:    memberSyncSchedule,
+			Run: func(ctx context.Context) error {
+				_, err := member_directory.Sync(ctx)
+				return err
+			},
+		},
+		{
+			Name:        "sheets_sync",
+			Description: "Pull the configured google sheets into redis.",
+			Schedule:    sheetsSyncSchedule,
+			Run: func(ctx context.Context) error {
+				var failed int
+				for _, sync := range sheets_sync.PullAll(ctx) {
+					if sync.Error != "" {
+						failed++
+					}
+				}
+				if failed != 0 {
+					return fmt.Errorf("%d sheets failed to sync", failed)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "submission_digest",
+			Description: "Post the daily submission counts for open projects to the production channel.",
+			Schedule:    "@daily",
+			Run: func(ctx context.Context) error {
+				return submission_digest.SendDigests(ctx, time.Now())
+			},
+		},
+		{
+			Name:        "which_time",
+			Description: "Update the timezones message.",
+			Schedule:    "@every 30s",
+			Timeout:     30 * time.Second,
+			Run: func(ctx context.Context) error {
+				which_time.WhichTime(ctx, timezonesChannelId())
+				return nil
+			},
+		},
+	} {
+		if err := cron.Register(job); err != nil {
+			return fmt.Errorf("cron.Register() failed: %w", err)
+		}
+	}
+	return nil
+}
@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
-	"github.com/virtual-vgo/vvgo/pkg/clients/cloudflare"
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/dispatcher"
 	"github.com/virtual-vgo/vvgo/pkg/config"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
 	"github.com/virtual-vgo/vvgo/pkg/server"
-	"github.com/virtual-vgo/vvgo/pkg/server/cron/which_time"
+	"github.com/virtual-vgo/vvgo/pkg/server/api/slash_command"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron"
+	"github.com/virtual-vgo/vvgo/pkg/server/member_directory"
 	"github.com/virtual-vgo/vvgo/pkg/version"
 	"math/rand"
 	"net/http"
@@ -43,7 +46,7 @@ func main() {
 		fmt.Println(version.String())
 		os.Exit(0)
 	case showEnvUsage:
-		_ = envconfig.Usage("", &config.Config)
+		_ = envconfig.Usage("", &config.Configuration{})
 		os.Exit(0)
 	case envFile != "":
 		config.ProcessEnvFile(envFile)
@@ -52,18 +55,39 @@ func main() {
 	}
 
 	if showRuntimeConfig {
-		configJSON, _ := json.MarshalIndent(config.Config, "", "  ")
+		configJSON, _ := json.MarshalIndent(config.Config(), "", "  ")
 		fmt.Println(string(configJSON))
 		os.Exit(0)
 	}
 
-	apiServer := server.NewServer(config.Config.VVGO.ListenAddress)
-	logger.Println("http server: listening on " + config.Config.VVGO.ListenAddress)
+	apiServer := server.NewServer(config.Config().VVGO.ListenAddress)
+	logger.Println("http server: listening on " + config.Config().VVGO.ListenAddress)
 
-	if !config.Config.Development {
-		go cloudflare.PurgeCache()
+	if err := registerJobs(); err != nil {
+		logger.WithError(err).Fatal("registerJobs() failed")
+	}
+	go cron.Run(ctx)
+
+	if migrated, skipped, err := models.MigrateLegacyArrangementsBallots(ctx); err != nil {
+		logger.MethodFailure(ctx, "models.MigrateLegacyArrangementsBallots", err)
+	} else if migrated > 0 || skipped > 0 {
+		logger.Printf("http server: migrated %d %s arrangements ballots, skipped %d invalid ballots",
+			migrated, models.LegacyArrangementsSeason, skipped)
+	}
+
+	if config.Config().Discord.EnableGateway {
+		gateway := discord.NewGateway(discord.GatewayIntentGuilds | discord.GatewayIntentGuildMembers |
+			discord.GatewayIntentGuildMessages | discord.GatewayIntentGuildMessageReactions)
+		gateway.Subscribe(member_directory.HandleGatewayEvent)
+		go gateway.Run(ctx)
+	}
+
+	if !config.Config().Development {
+		if err := cron.Trigger(ctx, CloudflarePurgeJob); err != nil {
+			logger.MethodFailure(ctx, "cron.Trigger", err)
+		}
 
-		_, err := discord.CreateMessage(ctx, discord.VVGOChannelWebDevelopers, discord.CreateMessageParams{
+		_, err := discord.CreateMessage(ctx, discord.Snowflake(config.Config().Discord.DeploymentsChannelID), discord.CreateMessageParams{
 			Embed: &discord.Embed{
 				Title:       "🍏 Fresh VVGO Deployment",
 				Description: fmt.Sprintf("**Build Time:** %s\n**Git Sha:** `%s`", version.BuildTime(), version.Get().GitSha),
@@ -75,14 +99,14 @@ func main() {
 	}
 
 	go func() {
-		channelId := discord.VVGOChannelJacksonsSandbox
-		if !config.Config.Development {
-			channelId = discord.VVGOChannelTimezones
-		}
-
-		which_time.WhichTime(ctx, channelId)
-		for range time.Tick(30 * time.Second) {
-			which_time.WhichTime(ctx, channelId)
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGHUP)
+		for range sigCh {
+			if err := config.Reload(envFile); err != nil {
+				logger.WithError(err).Error("config.Reload() failed")
+				continue
+			}
+			logger.Println("http server: reloaded configuration")
 		}
 	}()
 
@@ -101,5 +125,15 @@ func main() {
 		}
 	}
 	logger.Println("http server: closed")
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	// Deferred slash command responses are sent through the dispatcher, so they finish first.
+	if err := slash_command.WaitDeferred(shutdownCtx); err != nil {
+		logger.MethodFailure(ctx, "slash_command.WaitDeferred", err)
+	}
+	if err := dispatcher.Shutdown(shutdownCtx); err != nil {
+		logger.MethodFailure(ctx, "dispatcher.Shutdown", err)
+	}
 	os.Exit(0)
 }
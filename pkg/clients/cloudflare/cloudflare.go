@@ -3,24 +3,24 @@ package cloudflare
 import (
 	"bytes"
 	log "github.com/sirupsen/logrus"
+	"github.com/virtual-vgo/vvgo/pkg/clients/dispatcher"
 	"github.com/virtual-vgo/vvgo/pkg/config"
-	"github.com/virtual-vgo/vvgo/pkg/http_wrappers"
 	"net/http"
 	"strings"
 )
 
 func PurgeCache() {
-	if config.Config.Cloudflare.ZoneId != "" && config.Config.Cloudflare.ApiKey != "" {
+	if config.Config().Cloudflare.ZoneId != "" && config.Config().Cloudflare.ApiKey != "" {
 		req, err := http.NewRequest(http.MethodPost,
-			"https://api.cloudflare.com/client/v4/zones/"+config.Config.Cloudflare.ZoneId+"/purge_cache",
+			"https://api.cloudflare.com/client/v4/zones/"+config.Config().Cloudflare.ZoneId+"/purge_cache",
 			strings.NewReader(`{"purge_everything":true}`))
 		if err != nil {
 			log.WithError(err).Error("http.NewRequest() failed")
 			log.Error("cloudflare cache purge failed")
 			return
 		}
-		req.Header.Add("Authorization", "Bearer "+config.Config.Cloudflare.ApiKey)
-		resp, err := http_wrappers.DoRequest(req)
+		req.Header.Add("Authorization", "Bearer "+config.Config().Cloudflare.ApiKey)
+		resp, err := dispatcher.DoPriority(req, dispatcher.PriorityLow)
 		if err != nil {
 			log.WithError(err).Error("http.Do() failed")
 			log.Error("cloudflare cache purge failed")
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/virtual-vgo/vvgo/pkg/config"
-	"github.com/virtual-vgo/vvgo/pkg/http_wrappers"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"io"
 	"net/http"
@@ -22,25 +21,16 @@ var ErrInvalidOAuthCode = errors.New("invalid oauth code")
 const ClientPublicKey = "a56a084a21829d02f272e4e3f4b67a846a831281849f6740f7bbf873840c4076"
 const ApplicationID = "700963768787795998"
 const OAuthClientID = ApplicationID
-const VVGOGuildID = "690626216637497425" // The VVGO discord server
-const VVGOVerifiedMemberRoleID = "690636730281230396"
-const VVGOProductionTeamRoleID = "746434659252174971"
-const VVGOExecutiveDirectorRoleID = "690626333062987866"
-const VVGOProductionDirectorRoleID = "805504313072943155"
-const VVGOChannelJacksonsSandbox = "700792848253059142"
-const VVGOChannelWebDevelopers = "692441475740467250"
-const VVGOChannelTimezones = "983552216226992159"
-
-var IgnoreChannelIds = []string{
-	"690626217594060892",
-	"817084492635701298",
-	"817084789139308544",
-}
+
+// GuildID returns the id of the discord server we serve.
+func GuildID() Snowflake { return Snowflake(config.Config().Discord.GuildID) }
+
+func guildPath() string { return "/guilds/" + GuildID().String() }
 
 func LoginURL(state string) string {
 	query := make(url.Values)
 	query.Set("client_id", OAuthClientID)
-	query.Set("redirect_uri", config.Config.VVGO.ServerUrl+"/login/discord")
+	query.Set("redirect_uri", config.Config().VVGO.ServerUrl+"/login/discord")
 	query.Set("response_type", "code")
 	query.Set("state", state)
 	query.Set("scope", "identify")
@@ -71,10 +61,10 @@ func newOAuthRequest(ctx context.Context, code string) (*http.Request, error) {
 	// build the authorization request
 	form := make(url.Values)
 	form.Add("client_id", OAuthClientID)
-	form.Add("client_secret", config.Config.Discord.OAuthClientSecret)
+	form.Add("client_secret", config.Config().Discord.OAuthClientSecret)
 	form.Add("grant_type", "authorization_code")
 	form.Add("code", code)
-	form.Add("redirect_uri", config.Config.VVGO.ServerUrl+"/login/discord")
+	form.Add("redirect_uri", config.Config().VVGO.ServerUrl+"/login/discord")
 	form.Add("scope", "identify")
 
 	req, err := newRequest(ctx, http.MethodPost, "/oauth2/token", strings.NewReader(form.Encode()))
@@ -116,7 +106,7 @@ func newTokenRequest(ctx context.Context, oauthToken *OAuthToken, path string) (
 // Here we use the server's own auth token.
 // https://discordapp.com/developers/docs/resources/guild#get-guild-member
 func GetGuildMember(ctx context.Context, userID Snowflake) (*GuildMember, error) {
-	req, err := newBotRequest(ctx, http.MethodGet, "/guilds/"+VVGOGuildID+"/members/"+userID.String(), nil)
+	req, err := newBotRequest(ctx, http.MethodGet, guildPath()+"/members/"+userID.String(), nil)
 	if err != nil {
 		logger.NewRequestFailure(ctx, err)
 		return nil, err
@@ -135,7 +125,7 @@ func SearchGuildMembers(ctx context.Context, query string, limit int) ([]GuildMe
 	params.Set("query", query)
 	params.Set("limit", strconv.Itoa(limit))
 
-	req, err := newBotRequest(ctx, http.MethodGet, "/guilds/"+VVGOGuildID+"/members", nil)
+	req, err := newBotRequest(ctx, http.MethodGet, guildPath()+"/members/search?"+params.Encode(), nil)
 	if err != nil {
 		logger.NewRequestFailure(ctx, err)
 		return nil, err
@@ -149,16 +139,18 @@ func SearchGuildMembers(ctx context.Context, query string, limit int) ([]GuildMe
 	return guildMembers, nil
 }
 
-func ListGuildMembers(ctx context.Context, limit int, after int) ([]GuildMember, error) {
+// ListGuildMembers Query discord for a page of guild members with ids after the given id.
+// https://discord.com/developers/docs/resources/guild#list-guild-members
+func ListGuildMembers(ctx context.Context, limit int, after Snowflake) ([]GuildMember, error) {
 	params := make(url.Values)
 	if limit >= 1 {
 		params.Set("limit", strconv.Itoa(limit))
 	}
-	if after >= 0 {
-		params.Set("after", strconv.Itoa(after))
+	if after != "" {
+		params.Set("after", after.String())
 	}
 
-	req, err := newBotRequest(ctx, http.MethodGet, "/guilds/"+VVGOGuildID+"/members?"+params.Encode(), nil)
+	req, err := newBotRequest(ctx, http.MethodGet, guildPath()+"/members?"+params.Encode(), nil)
 	if err != nil {
 		logger.NewRequestFailure(ctx, err)
 		return nil, err
@@ -173,7 +165,7 @@ func ListGuildMembers(ctx context.Context, limit int, after int) ([]GuildMember,
 }
 
 func GetGuildChannels(ctx context.Context) ([]Channel, error) {
-	const path = "/guilds/" + VVGOGuildID + "/channels"
+	path := guildPath() + "/channels"
 	var dest []Channel
 	err := doDiscordBotRequestWithJsonParams(ctx, http.MethodGet, path, nil, &dest)
 	if err != nil {
@@ -181,7 +173,7 @@ func GetGuildChannels(ctx context.Context) ([]Channel, error) {
 	}
 
 	shouldSkip := func(id string) bool {
-		for _, ignored := range IgnoreChannelIds {
+		for _, ignored := range config.Config().Discord.IgnoreChannelIDs {
 			if id == ignored {
 				return false
 			}
@@ -200,7 +192,7 @@ func GetGuildChannels(ctx context.Context) ([]Channel, error) {
 }
 
 func CreateGuildChannel(ctx context.Context, params CreateGuildChannelParams) error {
-	const path = "/guilds/" + VVGOGuildID + "/channels"
+	path := guildPath() + "/channels"
 	return doDiscordBotRequestWithJsonParams(ctx, http.MethodPost, path, params, nil)
 }
 
@@ -235,9 +227,19 @@ func CreateApplicationCommand(ctx context.Context, params CreateApplicationComma
 	return &command, nil
 }
 
+func EditApplicationCommand(ctx context.Context, id Snowflake, params EditApplicationCommandParams) (*ApplicationCommand, error) {
+	path := slashCommandsPath() + "/" + id.String()
+
+	var command ApplicationCommand
+	err := doDiscordBotRequestWithJsonParams(ctx, http.MethodPatch, path, &params, &command)
+	if err != nil {
+		return nil, err
+	}
+	return &command, nil
+}
+
 func DeleteApplicationCommand(ctx context.Context, id Snowflake) error {
-	path := "/applications/" + ApplicationID + "/guilds/" + VVGOGuildID + "/commands/" + id.String()
-	req, err := newBotRequest(ctx, http.MethodPost, path, nil)
+	req, err := newBotRequest(ctx, http.MethodDelete, slashCommandsPath()+"/"+id.String(), nil)
 	if err != nil {
 		return err
 	}
@@ -245,6 +247,24 @@ func DeleteApplicationCommand(ctx context.Context, id Snowflake) error {
 	return err
 }
 
+// GetApplicationCommandPermissions returns the permissions of each of our commands that has permissions.
+func GetApplicationCommandPermissions(ctx context.Context) ([]GuildApplicationCommandPermissions, error) {
+	req, err := newBotRequest(ctx, http.MethodGet, slashCommandsPath()+"/permissions", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []GuildApplicationCommandPermissions
+	_, err = doDiscordRequest(req, &permissions)
+	return permissions, err
+}
+
+// EditApplicationCommandPermissions replaces the permissions of a command.
+func EditApplicationCommandPermissions(ctx context.Context, id Snowflake, params EditApplicationCommandPermissionsParams) error {
+	path := slashCommandsPath() + "/" + id.String() + "/permissions"
+	return doDiscordBotRequestWithJsonParams(ctx, http.MethodPut, path, &params, nil)
+}
+
 func CreateMessage(ctx context.Context, channelId Snowflake, params CreateMessageParams) (*Message, error) {
 	path := "/channels/" + channelId.String() + "/messages"
 
@@ -266,9 +286,31 @@ func BulkDeleteMessages(ctx context.Context, channelId Snowflake, params BulkDel
 	return doDiscordBotRequestWithJsonParams(ctx, http.MethodPost, path, &params, nil)
 }
 
+// EditOriginalInteractionResponse replaces the response to an interaction.
+// Interaction tokens are valid for 15 minutes.
+// https://discord.com/developers/docs/interactions/receiving-and-responding#edit-original-interaction-response
+func EditOriginalInteractionResponse(ctx context.Context, token string, params InteractionApplicationCommandCallbackData) (*Message, error) {
+	path := "/webhooks/" + ApplicationID + "/" + token + "/messages/@original"
+
+	var message Message
+	err := doDiscordWebhookRequestWithJsonParams(ctx, http.MethodPatch, path, &params, &message)
+	return &message, err
+}
+
+// CreateFollowupMessage sends another message in response to an interaction.
+// https://discord.com/developers/docs/interactions/receiving-and-responding#create-followup-message
+func CreateFollowupMessage(ctx context.Context, token string, params InteractionApplicationCommandCallbackData) (*Message, error) {
+	path := "/webhooks/" + ApplicationID + "/" + token
+
+	var message Message
+	err := doDiscordWebhookRequestWithJsonParams(ctx, http.MethodPost, path, &params, &message)
+	return &message, err
+}
+
+func slashCommandsPath() string { return "/applications/" + ApplicationID + guildPath() + "/commands" }
+
 func newSlashCommandRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
-	path := "/applications/" + ApplicationID + "/guilds/" + VVGOGuildID + "/commands"
-	return newBotRequest(ctx, method, path, body)
+	return newBotRequest(ctx, method, slashCommandsPath(), body)
 }
 
 func doDiscordBotRequestWithJsonParams(ctx context.Context, method, path string, params interface{}, dest interface{}) error {
@@ -287,18 +329,35 @@ func doDiscordBotRequestWithJsonParams(ctx context.Context, method, path string,
 	return err
 }
 
+// webhook requests are authenticated by the token in the path
+func doDiscordWebhookRequestWithJsonParams(ctx context.Context, method, path string, params interface{}, dest interface{}) error {
+	var paramsBytes bytes.Buffer
+	if err := json.NewEncoder(&paramsBytes).Encode(params); err != nil {
+		return fmt.Errorf("json.Encode() failed: %w", err)
+	}
+
+	req, err := newRequest(ctx, method, path, &paramsBytes)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = doDiscordRequest(req, dest)
+	return err
+}
+
 // returns a request using a bot token for authentication
 func newBotRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	req, err := newRequest(ctx, method, path, body)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Authorization", "Bot "+config.Config.Discord.BotAuthenticationToken)
+	req.Header.Add("Authorization", "Bot "+config.Config().Discord.BotAuthenticationToken)
 	return req, err
 }
 
 func newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, method, config.Config.Discord.Endpoint+path, body)
+	req, err := http.NewRequestWithContext(ctx, method, config.Config().Discord.Endpoint+path, body)
 	if err != nil {
 		return nil, fmt.Errorf("http.NewRequestWithContext() failed: %w", err)
 	}
@@ -307,7 +366,7 @@ func newRequest(ctx context.Context, method string, path string, body io.Reader)
 
 // performs the http request and logs results
 func doDiscordRequest(req *http.Request, dest interface{}) (*http.Response, error) {
-	resp, err := http_wrappers.DoRequest(req)
+	resp, err := rateLimits.do(req)
 	if err != nil {
 		logger.HttpDoFailure(req.Context(), err)
 		return nil, err
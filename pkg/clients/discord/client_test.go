@@ -14,8 +14,10 @@ import (
 
 func TestClient_QueryOAuth(t *testing.T) {
 	ctx := context.Background()
-	config.Config.Discord.BotAuthenticationToken = "test-bot-auth-token"
-	config.Config.Discord.OAuthClientSecret = "test-oauth-client-secret"
+	config.Update(func(x *config.Configuration) {
+		x.Discord.BotAuthenticationToken = "test-bot-auth-token"
+		x.Discord.OAuthClientSecret = "test-oauth-client-secret"
+	})
 
 	var gotRequest *http.Request
 	var gotForm string
@@ -37,7 +39,7 @@ func TestClient_QueryOAuth(t *testing.T) {
 		}`))
 	}))
 	defer ts.Close()
-	config.Config.Discord.Endpoint = ts.URL
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
 	gotToken, gotError := GetOAuthToken(ctx, "test-code")
 	require.NoError(t, gotError)
 	assert.Equal(t, http.MethodPost, gotRequest.Method)
@@ -65,7 +67,7 @@ func TestClient_QueryOAuth(t *testing.T) {
 
 func TestClient_QueryIdentity(t *testing.T) {
 	ctx := context.Background()
-	config.Config.Discord.BotAuthenticationToken = "test-bot-auth-token"
+	config.Update(func(x *config.Configuration) { x.Discord.BotAuthenticationToken = "test-bot-auth-token" })
 	token := &OAuthToken{
 		AccessToken:  "6qrZcUqja7812RVdnEKjpzOL4CvHBFG",
 		TokenType:    "Bearer",
@@ -91,7 +93,7 @@ func TestClient_QueryIdentity(t *testing.T) {
 		}`))
 	}))
 	defer ts.Close()
-	config.Config.Discord.Endpoint = ts.URL
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
 	gotUser, gotError := GetIdentity(ctx, token)
 	require.NoError(t, gotError)
 	assert.Equal(t, http.MethodGet, gotRequest.Method)
@@ -103,7 +105,7 @@ func TestClient_QueryIdentity(t *testing.T) {
 
 func TestClient_QueryGuildMember(t *testing.T) {
 	ctx := context.Background()
-	config.Config.Discord.BotAuthenticationToken = "test-bot-auth-token"
+	config.Update(func(x *config.Configuration) { x.Discord.BotAuthenticationToken = "test-bot-auth-token" })
 
 	var gotRequest *http.Request
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -119,7 +121,7 @@ func TestClient_QueryGuildMember(t *testing.T) {
 		}`))
 	}))
 	defer ts.Close()
-	config.Config.Discord.Endpoint = ts.URL
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
 	gotMember, gotError := GetGuildMember(ctx, "test-user-id")
 	require.NoError(t, gotError)
 	assert.Equal(t, http.MethodGet, gotRequest.Method)
@@ -127,3 +129,46 @@ func TestClient_QueryGuildMember(t *testing.T) {
 	assert.Equal(t, []string{"Bot test-bot-auth-token"}, gotRequest.Header["Authorization"])
 	assert.Equal(t, &GuildMember{Nick: "NOT API SUPPORT", Roles: []string{"jelly", "donut"}}, gotMember)
 }
+
+func TestClient_EditOriginalInteractionResponse(t *testing.T) {
+	ctx := context.Background()
+	config.Update(func(x *config.Configuration) { x.Discord.BotAuthenticationToken = "test-bot-auth-token" })
+
+	var gotRequest *http.Request
+	var gotBody string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequest = r
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r.Body)
+		gotBody = buf.String()
+		_, _ = w.Write([]byte(`{"id": "1234", "content": "boop"}`))
+	}))
+	defer ts.Close()
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
+
+	gotMessage, gotError := EditOriginalInteractionResponse(ctx, "test-token", InteractionApplicationCommandCallbackData{Content: "boop"})
+	require.NoError(t, gotError)
+	assert.Equal(t, http.MethodPatch, gotRequest.Method)
+	assert.Equal(t, "/webhooks/"+ApplicationID+"/test-token/messages/@original", gotRequest.URL.String())
+	assert.Empty(t, gotRequest.Header["Authorization"], "webhooks are authorized by the token")
+	assert.JSONEq(t, `{"tts": false, "content": "boop"}`, gotBody)
+	assert.Equal(t, &Message{Id: "1234", Content: "boop"}, gotMessage)
+}
+
+func TestClient_DeleteApplicationCommand(t *testing.T) {
+	ctx := context.Background()
+	config.Update(func(x *config.Configuration) { x.Discord.BotAuthenticationToken = "test-bot-auth-token" })
+
+	var gotRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequest = r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
+
+	require.NoError(t, DeleteApplicationCommand(ctx, "1234"))
+	assert.Equal(t, http.MethodDelete, gotRequest.Method)
+	assert.Equal(t, "/applications/"+ApplicationID+"/guilds/690626216637497425/commands/1234", gotRequest.URL.String())
+	assert.Equal(t, []string{"Bot test-bot-auth-token"}, gotRequest.Header["Authorization"])
+}
@@ -0,0 +1,157 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/net/websocket"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrFakeGatewayNotConnected = errors.New("no client is connected")
+
+// FakeGatewaySessionID is the session id sent in READY by the fake gateway.
+const FakeGatewaySessionID = "fake-session"
+
+// FakeGateway is a local gateway server for tests.
+// It says hello, acknowledges heartbeats, answers identify with READY and resume with RESUMED,
+// and dispatches the events it is given to the connected client.
+type FakeGateway struct {
+	HeartbeatInterval time.Duration
+	// SkipHeartbeatAcks stops the gateway from acknowledging heartbeats.
+	SkipHeartbeatAcks bool
+
+	server   *httptest.Server
+	lock     sync.Mutex
+	conn     *websocket.Conn
+	sequence int64
+	received []GatewayPayload
+	sessions chan GatewayOpcode
+}
+
+func NewFakeGateway() *FakeGateway {
+	x := &FakeGateway{HeartbeatInterval: time.Minute, sessions: make(chan GatewayOpcode, 16)}
+	x.server = httptest.NewServer(websocket.Handler(x.serve))
+	return x
+}
+
+// Endpoint is the websocket url of the gateway.
+func (x *FakeGateway) Endpoint() string { return "ws" + strings.TrimPrefix(x.server.URL, "http") }
+
+func (x *FakeGateway) Close() {
+	x.Disconnect()
+	x.server.Close()
+}
+
+func (x *FakeGateway) serve(conn *websocket.Conn) {
+	x.lock.Lock()
+	x.conn = conn
+	err := x.send(GatewayOpcodeHello, "", GatewayHello{HeartbeatInterval: x.HeartbeatInterval.Milliseconds()})
+	x.lock.Unlock()
+	if err != nil {
+		return
+	}
+
+	for {
+		var payload GatewayPayload
+		if err := websocket.JSON.Receive(conn, &payload); err != nil {
+			return
+		}
+
+		x.lock.Lock()
+		x.received = append(x.received, payload)
+		switch payload.Op {
+		case GatewayOpcodeIdentify:
+			err = x.send(GatewayOpcodeDispatch, ReadyEvent{}.EventName(), ReadyEvent{SessionID: FakeGatewaySessionID})
+		case GatewayOpcodeResume:
+			err = x.send(GatewayOpcodeDispatch, ResumedEvent{}.EventName(), ResumedEvent{})
+		case GatewayOpcodeHeartbeat:
+			if !x.SkipHeartbeatAcks {
+				err = x.send(GatewayOpcodeHeartbeatAck, "", nil)
+			}
+		}
+		x.lock.Unlock()
+		if err != nil {
+			return
+		}
+
+		switch payload.Op {
+		case GatewayOpcodeIdentify, GatewayOpcodeResume:
+			select {
+			case x.sessions <- payload.Op:
+			default:
+			}
+		}
+	}
+}
+
+// send writes a payload to the connection. The caller must hold the lock.
+func (x *FakeGateway) send(op GatewayOpcode, eventName string, data interface{}) error {
+	if x.conn == nil {
+		return ErrFakeGatewayNotConnected
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	payload := GatewayPayload{Op: op, Data: dataJSON, Type: eventName}
+	if op == GatewayOpcodeDispatch {
+		x.sequence++
+		payload.Sequence = x.sequence
+	}
+	return websocket.JSON.Send(x.conn, payload)
+}
+
+// WaitForSession waits for the client to identify or resume, and returns which one it did.
+func (x *FakeGateway) WaitForSession(timeout time.Duration) (GatewayOpcode, error) {
+	select {
+	case op := <-x.sessions:
+		return op, nil
+	case <-time.After(timeout):
+		return 0, fmt.Errorf("no session after %s", timeout)
+	}
+}
+
+// Dispatch sends an event to the connected client.
+func (x *FakeGateway) Dispatch(event GatewayEvent) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	if unknown, ok := event.(UnknownEvent); ok {
+		return x.send(GatewayOpcodeDispatch, unknown.Name, unknown.Data)
+	}
+	return x.send(GatewayOpcodeDispatch, event.EventName(), event)
+}
+
+// Reconnect asks the client to reconnect.
+func (x *FakeGateway) Reconnect() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	return x.send(GatewayOpcodeReconnect, "", nil)
+}
+
+// InvalidSession tells the client its session is invalid.
+func (x *FakeGateway) InvalidSession(resumable bool) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	return x.send(GatewayOpcodeInvalidSession, "", resumable)
+}
+
+// Disconnect drops the connection to the client.
+func (x *FakeGateway) Disconnect() {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	if x.conn != nil {
+		x.conn.Close()
+		x.conn = nil
+	}
+}
+
+// Received returns the payloads sent by clients.
+func (x *FakeGateway) Received() []GatewayPayload {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	return append([]GatewayPayload(nil), x.received...)
+}
@@ -0,0 +1,328 @@
+package discord
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"golang.org/x/net/websocket"
+	"math/rand"
+	"net"
+	"net/url"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var ErrGatewayReconnect = errors.New("gateway requested a reconnect")
+var ErrGatewayInvalidSession = errors.New("gateway session is invalid")
+var ErrGatewayZombie = errors.New("gateway did not acknowledge the last heartbeat")
+
+const (
+	// GatewayMinBackoff is the wait before the first reconnect attempt.
+	GatewayMinBackoff = time.Second
+	// GatewayMaxBackoff is the longest wait between reconnect attempts.
+	GatewayMaxBackoff = 2 * time.Minute
+	// gatewayEventBuffer is how many events can wait for the subscribers before the connection blocks.
+	gatewayEventBuffer = 256
+)
+
+// GatewayEventHandler receives events from the gateway.
+// Handlers run one event at a time in the order the events arrive, so they should return quickly.
+type GatewayEventHandler func(ctx context.Context, event GatewayEvent)
+
+// Gateway is a client for the discord websocket gateway.
+// https://discord.com/developers/docs/topics/gateway
+type Gateway struct {
+	// Endpoint is the websocket url of the gateway.
+	Endpoint string
+	Token    string
+	Intents  GatewayIntent
+	// MinBackoff and MaxBackoff bound the wait between reconnect attempts.
+	// They default to GatewayMinBackoff and GatewayMaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	handlersLock sync.Mutex
+	handlers     map[int]GatewayEventHandler
+	nextHandler  int
+
+	// The session is kept between connections so we can resume.
+	sessionID string
+	sequence  int64
+}
+
+// NewGateway returns a gateway client for the bot in the config.
+func NewGateway(intents GatewayIntent) *Gateway {
+	return &Gateway{
+		Endpoint: config.Config().Discord.GatewayEndpoint,
+		Token:    config.Config().Discord.BotAuthenticationToken,
+		Intents:  intents,
+	}
+}
+
+// Subscribe adds a handler for gateway events and returns a func that removes it.
+func (x *Gateway) Subscribe(handler GatewayEventHandler) (unsubscribe func()) {
+	x.handlersLock.Lock()
+	defer x.handlersLock.Unlock()
+	if x.handlers == nil {
+		x.handlers = make(map[int]GatewayEventHandler)
+	}
+	id := x.nextHandler
+	x.nextHandler++
+	x.handlers[id] = handler
+	return func() {
+		x.handlersLock.Lock()
+		defer x.handlersLock.Unlock()
+		delete(x.handlers, id)
+	}
+}
+
+func (x *Gateway) publish(ctx context.Context, event GatewayEvent) {
+	x.handlersLock.Lock()
+	handlers := make([]GatewayEventHandler, 0, len(x.handlers))
+	for _, handler := range x.handlers {
+		handlers = append(handlers, handler)
+	}
+	x.handlersLock.Unlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
+
+// Run connects to the gateway and publishes events until ctx is done.
+// Dropped connections are resumed, waiting longer after each failed attempt.
+func (x *Gateway) Run(ctx context.Context) {
+	events := make(chan GatewayEvent, gatewayEventBuffer)
+	defer close(events)
+	go func() {
+		for event := range events {
+			x.publish(ctx, event)
+		}
+	}()
+
+	minBackoff, maxBackoff := x.MinBackoff, x.MaxBackoff
+	if minBackoff == 0 {
+		minBackoff = GatewayMinBackoff
+	}
+	if maxBackoff == 0 {
+		maxBackoff = GatewayMaxBackoff
+	}
+
+	backoff := minBackoff
+	for {
+		connected, err := x.connect(ctx, events)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = minBackoff
+		}
+
+		logger.WithError(err).WithField("backoff", backoff).Warn("discord gateway: disconnected")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connect runs one connection to the gateway.
+// It reports whether the session was established, and the reason the connection ended.
+func (x *Gateway) connect(ctx context.Context, events chan<- GatewayEvent) (bool, error) {
+	conn, err := dialGateway(ctx, x.Endpoint)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() { <-ctx.Done(); conn.Close() }()
+
+	var hello GatewayHello
+	if err := x.receiveHello(conn, &hello); err != nil {
+		return false, err
+	}
+
+	payloads := make(chan GatewayPayload)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			var payload GatewayPayload
+			if err := websocket.JSON.Receive(conn, &payload); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case payloads <- payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := x.sendIdentifyOrResume(conn); err != nil {
+		return false, err
+	}
+
+	interval := time.Duration(hello.HeartbeatInterval) * time.Millisecond
+	heartbeat := time.NewTimer(time.Duration(rand.Float64() * float64(interval)))
+	defer heartbeat.Stop()
+	acked, connected := true, false
+	for {
+		select {
+		case <-ctx.Done():
+			return connected, ctx.Err()
+
+		case err := <-readErr:
+			return connected, err
+
+		case <-heartbeat.C:
+			if !acked {
+				return connected, ErrGatewayZombie
+			}
+			if err := x.sendHeartbeat(conn); err != nil {
+				return connected, err
+			}
+			acked = false
+			heartbeat.Reset(interval)
+
+		case payload := <-payloads:
+			switch payload.Op {
+			case GatewayOpcodeDispatch:
+				x.sequence = payload.Sequence
+				event, err := decodeGatewayEvent(payload)
+				if err != nil {
+					logger.WithError(err).Warn("discord gateway: invalid event")
+					continue
+				}
+				switch event := event.(type) {
+				case ReadyEvent:
+					x.sessionID = event.SessionID
+					connected = true
+				case ResumedEvent:
+					connected = true
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+				}
+
+			case GatewayOpcodeHeartbeat:
+				if err := x.sendHeartbeat(conn); err != nil {
+					return connected, err
+				}
+
+			case GatewayOpcodeHeartbeatAck:
+				acked = true
+
+			case GatewayOpcodeReconnect:
+				return connected, ErrGatewayReconnect
+
+			case GatewayOpcodeInvalidSession:
+				var resumable bool
+				_ = json.Unmarshal(payload.Data, &resumable)
+				if !resumable {
+					x.sessionID, x.sequence = "", 0
+				}
+				return connected, ErrGatewayInvalidSession
+			}
+		}
+	}
+}
+
+func dialGateway(ctx context.Context, endpoint string) (*websocket.Conn, error) {
+	wsConfig, err := websocket.NewConfig(endpoint, "https://discord.com")
+	if err != nil {
+		return nil, fmt.Errorf("websocket.NewConfig() failed: %w", err)
+	}
+
+	address := wsConfig.Location.Host
+	if wsConfig.Location.Port() == "" {
+		address = net.JoinHostPort(wsConfig.Location.Hostname(), defaultPort(wsConfig.Location))
+	}
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("net.Dial() failed: %w", err)
+	}
+	if wsConfig.Location.Scheme == "wss" {
+		tlsConn := tls.Client(netConn, &tls.Config{ServerName: wsConfig.Location.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("tls.Handshake() failed: %w", err)
+		}
+		netConn = tlsConn
+	}
+
+	conn, err := websocket.NewClient(wsConfig, netConn)
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("websocket.NewClient() failed: %w", err)
+	}
+	return conn, nil
+}
+
+func defaultPort(location *url.URL) string {
+	if location.Scheme == "wss" {
+		return "443"
+	}
+	return "80"
+}
+
+func (x *Gateway) receiveHello(conn *websocket.Conn, hello *GatewayHello) error {
+	var payload GatewayPayload
+	if err := websocket.JSON.Receive(conn, &payload); err != nil {
+		return fmt.Errorf("websocket.Receive() failed: %w", err)
+	}
+	if payload.Op != GatewayOpcodeHello {
+		return fmt.Errorf("expected hello, got opcode %d", payload.Op)
+	}
+	if err := json.Unmarshal(payload.Data, hello); err != nil {
+		return fmt.Errorf("json.Unmarshal() failed: %w", err)
+	}
+	if hello.HeartbeatInterval <= 0 {
+		return fmt.Errorf("invalid heartbeat interval %d", hello.HeartbeatInterval)
+	}
+	return nil
+}
+
+func (x *Gateway) sendIdentifyOrResume(conn *websocket.Conn) error {
+	if x.sessionID != "" {
+		return sendGatewayPayload(conn, GatewayOpcodeResume, GatewayResume{
+			Token: x.Token, SessionID: x.sessionID, Sequence: x.sequence,
+		})
+	}
+	return sendGatewayPayload(conn, GatewayOpcodeIdentify, GatewayIdentify{
+		Token:   x.Token,
+		Intents: x.Intents,
+		Properties: GatewayIdentifyProperties{
+			OS: runtime.GOOS, Browser: "vvgo", Device: "vvgo",
+		},
+	})
+}
+
+func (x *Gateway) sendHeartbeat(conn *websocket.Conn) error {
+	if x.sequence == 0 {
+		return sendGatewayPayload(conn, GatewayOpcodeHeartbeat, nil)
+	}
+	return sendGatewayPayload(conn, GatewayOpcodeHeartbeat, x.sequence)
+}
+
+func sendGatewayPayload(conn *websocket.Conn, op GatewayOpcode, data interface{}) error {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("json.Marshal() failed: %w", err)
+	}
+	if err := websocket.JSON.Send(conn, GatewayPayload{Op: op, Data: dataJSON}); err != nil {
+		return fmt.Errorf("websocket.Send() failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// https://discord.com/developers/docs/topics/opcodes-and-status-codes#gateway-gateway-opcodes
+type GatewayOpcode int
+
+const (
+	GatewayOpcodeDispatch       GatewayOpcode = 0
+	GatewayOpcodeHeartbeat      GatewayOpcode = 1
+	GatewayOpcodeIdentify       GatewayOpcode = 2
+	GatewayOpcodeResume         GatewayOpcode = 6
+	GatewayOpcodeReconnect      GatewayOpcode = 7
+	GatewayOpcodeInvalidSession GatewayOpcode = 9
+	GatewayOpcodeHello          GatewayOpcode = 10
+	GatewayOpcodeHeartbeatAck   GatewayOpcode = 11
+)
+
+// https://discord.com/developers/docs/topics/gateway#gateway-intents
+type GatewayIntent int
+
+const (
+	GatewayIntentGuilds                GatewayIntent = 1 << 0
+	GatewayIntentGuildMembers          GatewayIntent = 1 << 1
+	GatewayIntentGuildMessages         GatewayIntent = 1 << 9
+	GatewayIntentGuildMessageReactions GatewayIntent = 1 << 10
+	GatewayIntentDirectMessages        GatewayIntent = 1 << 12
+)
+
+// https://discord.com/developers/docs/topics/gateway#payloads-gateway-payload-structure
+type GatewayPayload struct {
+	Op       GatewayOpcode   `json:"op"`
+	Data     json.RawMessage `json:"d,omitempty"`
+	Sequence int64           `json:"s,omitempty"`
+	Type     string          `json:"t,omitempty"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#hello-hello-structure
+type GatewayHello struct {
+	HeartbeatInterval int64 `json:"heartbeat_interval"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#identify-identify-structure
+type GatewayIdentify struct {
+	Token      string                    `json:"token"`
+	Intents    GatewayIntent             `json:"intents"`
+	Properties GatewayIdentifyProperties `json:"properties"`
+}
+
+type GatewayIdentifyProperties struct {
+	OS      string `json:"$os"`
+	Browser string `json:"$browser"`
+	Device  string `json:"$device"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#resume-resume-structure
+type GatewayResume struct {
+	Token     string `json:"token"`
+	SessionID string `json:"session_id"`
+	Sequence  int64  `json:"seq"`
+}
+
+// GatewayEvent is an event dispatched by the gateway.
+type GatewayEvent interface {
+	EventName() string
+}
+
+// https://discord.com/developers/docs/topics/gateway#ready
+type ReadyEvent struct {
+	SessionID string `json:"session_id"`
+	User      User   `json:"user"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#resumed
+type ResumedEvent struct{}
+
+// https://discord.com/developers/docs/topics/gateway#guild-member-add
+type GuildMemberAddEvent struct {
+	GuildMember
+	GuildID Snowflake `json:"guild_id"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#guild-member-remove
+type GuildMemberRemoveEvent struct {
+	GuildID Snowflake `json:"guild_id"`
+	User    User      `json:"user"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#guild-member-update
+type GuildMemberUpdateEvent struct {
+	GuildMember
+	GuildID Snowflake `json:"guild_id"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#message-create
+type MessageCreateEvent struct {
+	Message
+	GuildID Snowflake    `json:"guild_id"`
+	Author  User         `json:"author"`
+	Member  *GuildMember `json:"member,omitempty"`
+}
+
+// https://discord.com/developers/docs/topics/gateway#message-reaction-add
+type MessageReactionAddEvent struct {
+	UserID    Snowflake    `json:"user_id"`
+	ChannelID Snowflake    `json:"channel_id"`
+	MessageID Snowflake    `json:"message_id"`
+	GuildID   Snowflake    `json:"guild_id"`
+	Member    *GuildMember `json:"member,omitempty"`
+	Emoji     Emoji        `json:"emoji"`
+}
+
+// https://discord.com/developers/docs/resources/emoji#emoji-object
+type Emoji struct {
+	ID   Snowflake `json:"id"`
+	Name string    `json:"name"`
+}
+
+// UnknownEvent is an event without a type in this package.
+type UnknownEvent struct {
+	Name string
+	Data json.RawMessage
+}
+
+func (ReadyEvent) EventName() string              { return "READY" }
+func (ResumedEvent) EventName() string            { return "RESUMED" }
+func (GuildMemberAddEvent) EventName() string     { return "GUILD_MEMBER_ADD" }
+func (GuildMemberRemoveEvent) EventName() string  { return "GUILD_MEMBER_REMOVE" }
+func (GuildMemberUpdateEvent) EventName() string  { return "GUILD_MEMBER_UPDATE" }
+func (MessageCreateEvent) EventName() string      { return "MESSAGE_CREATE" }
+func (MessageReactionAddEvent) EventName() string { return "MESSAGE_REACTION_ADD" }
+func (x UnknownEvent) EventName() string          { return x.Name }
+
+// decodeGatewayEvent returns the typed event for a dispatch payload.
+func decodeGatewayEvent(payload GatewayPayload) (GatewayEvent, error) {
+	var event GatewayEvent
+	var err error
+	switch payload.Type {
+	case ReadyEvent{}.EventName():
+		var data ReadyEvent
+		err = json.Unmarshal(payload.Data, &data)
+		event = data
+	case ResumedEvent{}.EventName():
+		event = ResumedEvent{}
+	case GuildMemberAddEvent{}.EventName():
+		var data GuildMemberAddEvent
+		err = json.Unmarshal(payload.Data, &data)
+		event = data
+	case GuildMemberRemoveEvent{}.EventName():
+		var data GuildMemberRemoveEvent
+		err = json.Unmarshal(payload.Data, &data)
+		event = data
+	case GuildMemberUpdateEvent{}.EventName():
+		var data GuildMemberUpdateEvent
+		err = json.Unmarshal(payload.Data, &data)
+		event = data
+	case MessageCreateEvent{}.EventName():
+		var data MessageCreateEvent
+		err = json.Unmarshal(payload.Data, &data)
+		event = data
+	case MessageReactionAddEvent{}.EventName():
+		var data MessageReactionAddEvent
+		err = json.Unmarshal(payload.Data, &data)
+		event = data
+	default:
+		event = UnknownEvent{Name: payload.Type, Data: payload.Data}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s event: %w", payload.Type, err)
+	}
+	return event, nil
+}
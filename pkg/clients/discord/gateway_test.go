@@ -0,0 +1,132 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGateway(t *testing.T) {
+	fake := NewFakeGateway()
+	defer fake.Close()
+
+	gateway := &Gateway{
+		Endpoint:   fake.Endpoint(),
+		Token:      "bot-token",
+		Intents:    GatewayIntentGuildMembers | GatewayIntentGuildMessages,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	}
+
+	var eventsLock sync.Mutex
+	var events []GatewayEvent
+	gateway.Subscribe(func(ctx context.Context, event GatewayEvent) {
+		eventsLock.Lock()
+		defer eventsLock.Unlock()
+		events = append(events, event)
+	})
+	waitForEvents := func(t *testing.T, n int) []GatewayEvent {
+		assert.Eventually(t, func() bool {
+			eventsLock.Lock()
+			defer eventsLock.Unlock()
+			return len(events) >= n
+		}, time.Second, time.Millisecond)
+		eventsLock.Lock()
+		defer eventsLock.Unlock()
+		return append([]GatewayEvent(nil), events...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go gateway.Run(ctx)
+
+	op, err := fake.WaitForSession(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, GatewayOpcodeIdentify, op)
+
+	t.Run("identify", func(t *testing.T) {
+		received := fake.Received()
+		require.NotEmpty(t, received)
+		var identify GatewayIdentify
+		require.NoError(t, json.Unmarshal(received[len(received)-1].Data, &identify))
+		assert.Equal(t, "bot-token", identify.Token)
+		assert.Equal(t, GatewayIntentGuildMembers|GatewayIntentGuildMessages, identify.Intents)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		member := GuildMember{User: User{ID: "42", Username: "chester"}, Roles: []string{"role"}}
+		require.NoError(t, fake.Dispatch(GuildMemberAddEvent{GuildMember: member, GuildID: "guild"}))
+		require.NoError(t, fake.Dispatch(MessageReactionAddEvent{UserID: "42", MessageID: "7", Emoji: Emoji{Name: "🧀"}}))
+		require.NoError(t, fake.Dispatch(UnknownEvent{Name: "TYPING_START", Data: json.RawMessage(`{}`)}))
+
+		got := waitForEvents(t, 4)
+		assert.Equal(t, []GatewayEvent{
+			ReadyEvent{SessionID: FakeGatewaySessionID},
+			GuildMemberAddEvent{GuildMember: member, GuildID: "guild"},
+			MessageReactionAddEvent{UserID: "42", MessageID: "7", Emoji: Emoji{Name: "🧀"}},
+			UnknownEvent{Name: "TYPING_START", Data: json.RawMessage(`{}`)},
+		}, got)
+	})
+
+	t.Run("reconnect resumes", func(t *testing.T) {
+		require.NoError(t, fake.Reconnect())
+		op, err := fake.WaitForSession(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, GatewayOpcodeResume, op)
+
+		received := fake.Received()
+		var resume GatewayResume
+		require.NoError(t, json.Unmarshal(received[len(received)-1].Data, &resume))
+		assert.Equal(t, GatewayResume{Token: "bot-token", SessionID: FakeGatewaySessionID, Sequence: 4}, resume)
+		assert.Equal(t, ResumedEvent{}, waitForEvents(t, 5)[4])
+	})
+
+	t.Run("dropped connection resumes", func(t *testing.T) {
+		fake.Disconnect()
+		op, err := fake.WaitForSession(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, GatewayOpcodeResume, op)
+	})
+
+	t.Run("invalid session identifies", func(t *testing.T) {
+		require.NoError(t, fake.InvalidSession(false))
+		op, err := fake.WaitForSession(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, GatewayOpcodeIdentify, op)
+	})
+}
+
+func TestGateway_Heartbeat(t *testing.T) {
+	fake := NewFakeGateway()
+	defer fake.Close()
+	fake.HeartbeatInterval = 10 * time.Millisecond
+
+	gateway := &Gateway{Endpoint: fake.Endpoint(), MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go gateway.Run(ctx)
+
+	_, err := fake.WaitForSession(time.Second)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		for _, payload := range fake.Received() {
+			if payload.Op == GatewayOpcodeHeartbeat && string(payload.Data) == "1" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond, "heartbeat with the last sequence")
+
+	t.Run("zombie connection resumes", func(t *testing.T) {
+		fake.lock.Lock()
+		fake.SkipHeartbeatAcks = true
+		fake.lock.Unlock()
+		op, err := fake.WaitForSession(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, GatewayOpcodeResume, op)
+	})
+}
@@ -1,5 +1,11 @@
 package discord
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 // https://discordapp.com/developers/docs/topics/oauth2
 type OAuthToken struct {
 	AccessToken  string `json:"access_token"`
@@ -14,6 +20,14 @@ type Snowflake string
 
 func (x Snowflake) String() string { return string(x) }
 
+// Less reports whether x is a smaller id than y.
+func (x Snowflake) Less(y Snowflake) bool {
+	if len(x) != len(y) {
+		return len(x) < len(y)
+	}
+	return x < y
+}
+
 // https://discordapp.com/developers/docs/resources/user#user-object
 type User struct {
 	ID       Snowflake `json:"id"`
@@ -40,8 +54,13 @@ type CreateApplicationCommandParams struct {
 	Name        string                     `json:"name"`
 	Description string                     `json:"description"`
 	Options     []ApplicationCommandOption `json:"options,omitempty"`
+	// DefaultPermission is false for commands that only members given permission can use.
+	DefaultPermission bool `json:"default_permission"`
 }
 
+// https://discord.com/developers/docs/interactions/slash-commands#edit-guild-application-command
+type EditApplicationCommandParams CreateApplicationCommandParams
+
 // https://discord.com/developers/docs/interactions/slash-commands#applicationcommand
 type ApplicationCommand struct {
 	ID            string                     `json:"id"`
@@ -49,6 +68,36 @@ type ApplicationCommand struct {
 	Name          string                     `json:"name"`
 	Description   string                     `json:"description"`
 	Options       []ApplicationCommandOption `json:"options,omitempty"`
+	// DefaultPermission is true when it is missing.
+	DefaultPermission *bool `json:"default_permission,omitempty"`
+}
+
+// https://discord.com/developers/docs/interactions/slash-commands#guildapplicationcommandpermissions
+type GuildApplicationCommandPermissions struct {
+	ID            string                         `json:"id"`
+	ApplicationID string                         `json:"application_id"`
+	GuildID       string                         `json:"guild_id"`
+	Permissions   []ApplicationCommandPermission `json:"permissions"`
+}
+
+// https://discord.com/developers/docs/interactions/slash-commands#applicationcommandpermissions
+type ApplicationCommandPermission struct {
+	ID         string                           `json:"id"`
+	Type       ApplicationCommandPermissionType `json:"type"`
+	Permission bool                             `json:"permission"`
+}
+
+// https://discord.com/developers/docs/interactions/slash-commands#applicationcommandpermissiontype
+type ApplicationCommandPermissionType int
+
+const (
+	ApplicationCommandPermissionTypeRole ApplicationCommandPermissionType = 1
+	ApplicationCommandPermissionTypeUser ApplicationCommandPermissionType = 2
+)
+
+// https://discord.com/developers/docs/interactions/slash-commands#edit-application-command-permissions
+type EditApplicationCommandPermissionsParams struct {
+	Permissions []ApplicationCommandPermission `json:"permissions"`
 }
 
 // https://discord.com/developers/docs/interactions/slash-commands#applicationcommandoption
@@ -59,6 +108,8 @@ type ApplicationCommandOption struct {
 	Required    bool                             `json:"required"`
 	Choices     []ApplicationCommandOptionChoice `json:"choices,omitempty"`
 	Options     []ApplicationCommandOption       `json:"options,omitempty"`
+	// Autocomplete asks for suggestions while the user types. Options with choices cannot autocomplete.
+	Autocomplete bool `json:"autocomplete,omitempty"`
 }
 
 // https://discord.com/developers/docs/interactions/slash-commands#applicationcommandoptiontype
@@ -73,6 +124,8 @@ const (
 	ApplicationCommandOptionTypeUser            ApplicationCommandOptionType = 6
 	ApplicationCommandOptionTypeChannel         ApplicationCommandOptionType = 7
 	ApplicationCommandOptionTypeRole            ApplicationCommandOptionType = 8
+	ApplicationCommandOptionTypeMentionable     ApplicationCommandOptionType = 9
+	ApplicationCommandOptionTypeNumber          ApplicationCommandOptionType = 10
 )
 
 // https://discord.com/developers/docs/interactions/slash-commands#applicationcommandoptionchoice
@@ -90,14 +143,18 @@ type Interaction struct {
 	ChannelID string                             `json:"channel_id"`
 	Member    GuildMember                        `json:"member"`
 	Token     string                             `json:"token"`
+	// Message is the message with the component in component interactions.
+	Message *Message `json:"message,omitempty"`
 }
 
 // https://discord.com/developers/docs/interactions/slash-commands#interaction-interactiontype
 type InteractionType int
 
 const (
-	InteractionTypePing               InteractionType = 1
-	InteractionTypeApplicationCommand InteractionType = 2
+	InteractionTypePing                           InteractionType = 1
+	InteractionTypeApplicationCommand             InteractionType = 2
+	InteractionTypeMessageComponent               InteractionType = 3
+	InteractionTypeApplicationCommandAutocomplete InteractionType = 4
 )
 
 // https://discord.com/developers/docs/interactions/slash-commands#interaction-applicationcommandinteractiondataoption
@@ -105,13 +162,38 @@ type ApplicationCommandInteractionData struct {
 	ID      string                                    `json:"id"`
 	Name    string                                    `json:"name"`
 	Options []ApplicationCommandInteractionDataOption `json:"options,omitempty"`
+
+	// CustomID and ComponentType are sent for component interactions.
+	CustomID      string        `json:"custom_id,omitempty"`
+	ComponentType ComponentType `json:"component_type,omitempty"`
+	// Values are the options picked in a select menu.
+	Values []string `json:"values,omitempty"`
 }
 
 // https://discord.com/developers/docs/interactions/slash-commands#interaction-applicationcommandinteractiondataoption
 type ApplicationCommandInteractionDataOption struct {
 	Name    string                                    `json:"name"`
-	Value   string                                    `json:"value"`
+	Type    ApplicationCommandOptionType              `json:"type,omitempty"`
+	Value   OptionValue                               `json:"value,omitempty"`
 	Options []ApplicationCommandInteractionDataOption `json:"options,omitempty"`
+	// Focused is set on the option the user is typing in autocomplete interactions.
+	Focused bool `json:"focused,omitempty"`
+}
+
+// OptionValue is the value of an option as text.
+// Discord sends strings, numbers, and booleans depending on the option type.
+type OptionValue string
+
+func (x OptionValue) String() string { return string(x) }
+
+func (x *OptionValue) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*x = OptionValue(text)
+		return nil
+	}
+	*x = OptionValue(data)
+	return nil
 }
 
 // https://discord.com/developers/docs/interactions/slash-commands#interaction-response
@@ -124,9 +206,12 @@ type InteractionResponse struct {
 type InteractionCallbackType int
 
 const (
-	InteractionCallbackTypePong                     InteractionCallbackType = 1
-	InteractionCallbackTypeChannelMessageWithSource InteractionCallbackType = 4
-	InteractionCallbackTypeAcknowledgeWithSource    InteractionCallbackType = 5
+	InteractionCallbackTypePong                                 InteractionCallbackType = 1
+	InteractionCallbackTypeChannelMessageWithSource             InteractionCallbackType = 4
+	InteractionCallbackTypeAcknowledgeWithSource                InteractionCallbackType = 5
+	InteractionCallbackTypeDeferredUpdateMessage                InteractionCallbackType = 6
+	InteractionCallbackTypeUpdateMessage                        InteractionCallbackType = 7
+	InteractionCallbackTypeApplicationCommandAutocompleteResult InteractionCallbackType = 8
 )
 
 // https://discord.com/developers/docs/interactions/slash-commands#interaction-response-interactionapplicationcommandcallbackdata
@@ -135,10 +220,59 @@ type InteractionApplicationCommandCallbackData struct {
 	Content string  `json:"content"`
 	Embeds  []Embed `json:"embeds,omitempty"`
 	Flags   int     `json:"flags,omitempty"`
+	// Components are the buttons and select menus on the message.
+	Components []Component `json:"components,omitempty"`
+	// Choices are the suggestions of an autocomplete result.
+	Choices []ApplicationCommandOptionChoice `json:"choices,omitempty"`
 }
 
 const InteractionApplicationCommandCallbackDataFlagEphemeral = 1 << 6
 
+// https://discord.com/developers/docs/interactions/message-components#component-object
+type Component struct {
+	Type ComponentType `json:"type"`
+	// CustomID is sent back in the interaction when a user uses the component.
+	CustomID    string         `json:"custom_id,omitempty"`
+	Label       string         `json:"label,omitempty"`
+	Style       ButtonStyle    `json:"style,omitempty"`
+	Url         string         `json:"url,omitempty"`
+	Disabled    bool           `json:"disabled,omitempty"`
+	Options     []SelectOption `json:"options,omitempty"`
+	Placeholder string         `json:"placeholder,omitempty"`
+	MinValues   int            `json:"min_values,omitempty"`
+	MaxValues   int            `json:"max_values,omitempty"`
+	// Components are the children of an action row.
+	Components []Component `json:"components,omitempty"`
+}
+
+// https://discord.com/developers/docs/interactions/message-components#component-object-component-types
+type ComponentType int
+
+const (
+	ComponentTypeActionRow  ComponentType = 1
+	ComponentTypeButton     ComponentType = 2
+	ComponentTypeSelectMenu ComponentType = 3
+)
+
+// https://discord.com/developers/docs/interactions/message-components#button-object-button-styles
+type ButtonStyle int
+
+const (
+	ButtonStylePrimary   ButtonStyle = 1
+	ButtonStyleSecondary ButtonStyle = 2
+	ButtonStyleSuccess   ButtonStyle = 3
+	ButtonStyleDanger    ButtonStyle = 4
+	ButtonStyleLink      ButtonStyle = 5
+)
+
+// https://discord.com/developers/docs/interactions/message-components#select-menu-object-select-option-structure
+type SelectOption struct {
+	Label       string `json:"label"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+}
+
 // https://discord.com/developers/docs/resources/webhook#execute-webhook-jsonform-params
 type ExecuteWebhookParams struct {
 	Content   string  `json:"content,omitempty"`
@@ -164,6 +298,25 @@ type Embed struct {
 	Fields      []EmbedField    `json:"fields,omitempty"`
 }
 
+// https://discord.com/developers/docs/reference#message-formatting-timestamp-styles
+type TimestampStyle string
+
+const (
+	TimestampStyleShortTime     TimestampStyle = "t"
+	TimestampStyleLongTime      TimestampStyle = "T"
+	TimestampStyleShortDate     TimestampStyle = "d"
+	TimestampStyleLongDate      TimestampStyle = "D"
+	TimestampStyleShortDateTime TimestampStyle = "f"
+	TimestampStyleLongDateTime  TimestampStyle = "F"
+	TimestampStyleRelative      TimestampStyle = "R"
+)
+
+// FormatTimestamp formats t as a message timestamp.
+// Discord shows timestamps in the timezone of the reader.
+func FormatTimestamp(t time.Time, style TimestampStyle) string {
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style)
+}
+
 // https://discord.com/developers/docs/resources/channel#embed-object-embed-types
 type EmbedType string
 
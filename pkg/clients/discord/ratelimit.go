@@ -0,0 +1,307 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/dispatcher"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxRateLimitRetries is how many times a request is retried after a 429 response.
+const MaxRateLimitRetries = 3
+
+// RateLimitMetrics counts the time spent waiting on one route template.
+type RateLimitMetrics struct {
+	Route string
+	// Bucket is the rate limit bucket discord assigned to the route, if it is known.
+	Bucket string `json:"Bucket,omitempty"`
+	// Requests is the number of requests sent, including retries.
+	Requests int64
+	// Waits is the number of requests that waited for the bucket or the global limit.
+	Waits       int64
+	WaitTime    time.Duration
+	LongestWait time.Duration
+	// TooManyRequests is the number of 429 responses.
+	TooManyRequests int64
+	GlobalLimits    int64
+}
+
+// https://discord.com/developers/docs/topics/rate-limits
+type rateLimitBucket struct {
+	limit     int
+	remaining int
+	resetAt   time.Time
+}
+
+type rateLimiter struct {
+	lock sync.Mutex
+	// routeBuckets maps route templates to discord's bucket ids.
+	routeBuckets map[string]string
+	// buckets are keyed by bucket id and major parameter.
+	buckets       map[string]*rateLimitBucket
+	globalResetAt time.Time
+	metrics       map[string]*RateLimitMetrics
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		routeBuckets: make(map[string]string),
+		buckets:      make(map[string]*rateLimitBucket),
+		metrics:      make(map[string]*RateLimitMetrics),
+	}
+}
+
+var rateLimits = newRateLimiter()
+
+// RateLimitStats returns the rate limit metrics of each route, sorted by route.
+func RateLimitStats() []RateLimitMetrics { return rateLimits.stats() }
+
+// rateLimitRoute identifies the requests that share a rate limit.
+// Discord assigns buckets to route templates, and scopes each bucket by the route's major parameter,
+// so requests to two channels with the same bucket are still limited separately.
+type rateLimitRoute struct {
+	// Template is the method and path, with ids and webhook tokens dropped.
+	Template string
+	// Major is the channel, guild, or webhook the request is for, if there is one.
+	Major string
+}
+
+// newRateLimitRoute returns the route of a request for rate limiting.
+func newRateLimitRoute(method string, path string) rateLimitRoute {
+	var major string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if i >= 2 && segments[i-2] == "webhooks" && !isSnowflake(segment) {
+			// Interaction tokens are secret, and there is a new one for each interaction.
+			segments[i] = ":token"
+			continue
+		}
+		if i == 0 || !isSnowflake(segment) {
+			continue
+		}
+		switch segments[i-1] {
+		case "channels", "guilds", "webhooks":
+			if major == "" {
+				major = segments[i-1] + "/" + segment
+			}
+			segments[i] = ":" + strings.TrimSuffix(segments[i-1], "s") + "_id"
+		default:
+			segments[i] = ":id"
+		}
+	}
+	return rateLimitRoute{Template: method + " " + strings.Join(segments, "/"), Major: major}
+}
+
+// bucketKey returns the key of the route's bucket in the rate limiter.
+func (x rateLimitRoute) bucketKey(bucketID string) string {
+	if x.Major == "" {
+		return bucketID
+	}
+	return bucketID + " " + x.Major
+}
+
+func isSnowflake(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(segment, 10, 64)
+	return err == nil
+}
+
+// do sends the request, waiting for the rate limits, and retries 429 responses.
+// The request body must be rewindable with GetBody if it has one.
+func (x *rateLimiter) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	route := newRateLimitRoute(req.Method, req.URL.Path)
+	for attempt := 0; ; attempt++ {
+		if err := x.wait(ctx, route); err != nil {
+			return nil, err
+		}
+
+		resp, err := dispatcher.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			x.update(route, resp.Header, nil)
+			return resp, nil
+		}
+
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(&body)
+		var rateLimit RateLimitResponse
+		if err := json.Unmarshal(body.Bytes(), &rateLimit); err != nil {
+			logger.JsonDecodeFailure(ctx, err)
+		}
+		x.update(route, resp.Header, &rateLimit)
+
+		if attempt == MaxRateLimitRetries || req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("req.GetBody() failed: %w", err)
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// wait blocks until the route can send a request, and reserves the request in its bucket.
+func (x *rateLimiter) wait(ctx context.Context, route rateLimitRoute) error {
+	var waited time.Duration
+	for {
+		delay := x.reserve(route)
+		if delay <= 0 {
+			x.recordRequest(route, waited)
+			return nil
+		}
+
+		logger.WithField("route", route.Template).WithField("major", route.Major).WithField("delay", delay).Info("discord client: waiting for rate limit")
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			x.recordRequest(route, waited)
+			return ctx.Err()
+		case <-timer.C:
+			waited += delay
+		}
+	}
+}
+
+// reserve takes a request from the route's bucket, or returns how long to wait before trying again.
+func (x *rateLimiter) reserve(route rateLimitRoute) time.Duration {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	now := time.Now()
+	if x.globalResetAt.After(now) {
+		return x.globalResetAt.Sub(now)
+	}
+
+	bucketID, ok := x.routeBuckets[route.Template]
+	if !ok {
+		return 0
+	}
+	bucket, ok := x.buckets[route.bucketKey(bucketID)]
+	switch {
+	case !ok:
+		return 0
+	case !bucket.resetAt.After(now):
+		bucket.remaining = bucket.limit
+		bucket.resetAt = time.Time{}
+	case bucket.remaining <= 0:
+		return bucket.resetAt.Sub(now)
+	}
+	if bucket.remaining > 0 {
+		bucket.remaining--
+	}
+	return 0
+}
+
+// update reads the rate limit headers of a response, and the body of a 429 response.
+func (x *rateLimiter) update(route rateLimitRoute, header http.Header, tooManyRequests *RateLimitResponse) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	now := time.Now()
+	metrics := x.routeMetrics(route)
+	if tooManyRequests != nil {
+		metrics.TooManyRequests++
+		retryAfter := time.Duration(tooManyRequests.RetryAfter * float64(time.Second))
+		if tooManyRequests.Global || header.Get("X-RateLimit-Global") == "true" {
+			metrics.GlobalLimits++
+			x.globalResetAt = now.Add(retryAfter)
+			return
+		}
+		if header.Get("X-RateLimit-Reset-After") == "" {
+			header = header.Clone()
+			header.Set("X-RateLimit-Reset-After", strconv.FormatFloat(tooManyRequests.RetryAfter, 'f', -1, 64))
+			header.Set("X-RateLimit-Remaining", "0")
+		}
+	}
+
+	bucketID := header.Get("X-RateLimit-Bucket")
+	if bucketID == "" {
+		bucketID = x.routeBuckets[route.Template]
+	}
+	if bucketID == "" {
+		if tooManyRequests == nil {
+			return
+		}
+		// Without a bucket header the route template gets its own bucket.
+		bucketID = route.Template
+	}
+	x.routeBuckets[route.Template] = bucketID
+	metrics.Bucket = bucketID
+
+	bucket, ok := x.buckets[route.bucketKey(bucketID)]
+	if !ok {
+		bucket = new(rateLimitBucket)
+		x.buckets[route.bucketKey(bucketID)] = bucket
+	}
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		bucket.limit = limit
+	}
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		bucket.remaining = remaining
+	}
+	if resetAfter, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset-After"), 64); err == nil {
+		bucket.resetAt = now.Add(time.Duration(resetAfter * float64(time.Second)))
+	}
+}
+
+func (x *rateLimiter) routeMetrics(route rateLimitRoute) *RateLimitMetrics {
+	metrics, ok := x.metrics[route.Template]
+	if !ok {
+		metrics = &RateLimitMetrics{Route: route.Template}
+		x.metrics[route.Template] = metrics
+	}
+	return metrics
+}
+
+func (x *rateLimiter) recordRequest(route rateLimitRoute, waited time.Duration) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	metrics := x.routeMetrics(route)
+	metrics.Requests++
+	if waited > 0 {
+		metrics.Waits++
+		metrics.WaitTime += waited
+		if waited > metrics.LongestWait {
+			metrics.LongestWait = waited
+		}
+	}
+}
+
+func (x *rateLimiter) stats() []RateLimitMetrics {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	stats := make([]RateLimitMetrics, 0, len(x.metrics))
+	for _, metrics := range x.metrics {
+		stats = append(stats, *metrics)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Route < stats[j].Route })
+	return stats
+}
@@ -0,0 +1,171 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimitRoute(t *testing.T) {
+	for _, tt := range []struct {
+		method, path string
+		want         rateLimitRoute
+	}{
+		{http.MethodGet, "/api/v8/guilds/123/members/456", rateLimitRoute{"GET /api/v8/guilds/:guild_id/members/:id", "guilds/123"}},
+		{http.MethodPost, "/api/v8/channels/123/messages", rateLimitRoute{"POST /api/v8/channels/:channel_id/messages", "channels/123"}},
+		{http.MethodDelete, "/api/v8/channels/123/messages/456", rateLimitRoute{"DELETE /api/v8/channels/:channel_id/messages/:id", "channels/123"}},
+		{http.MethodPost, "/api/v8/webhooks/123/token/messages/456", rateLimitRoute{"POST /api/v8/webhooks/:webhook_id/:token/messages/:id", "webhooks/123"}},
+		{http.MethodPatch, "/api/v8/webhooks/123/aW50ZXJhY3Rpb24/messages/@original", rateLimitRoute{"PATCH /api/v8/webhooks/:webhook_id/:token/messages/@original", "webhooks/123"}},
+		{http.MethodGet, "/api/v8/guilds/123/channels", rateLimitRoute{"GET /api/v8/guilds/:guild_id/channels", "guilds/123"}},
+		{http.MethodPost, "/oauth2/token", rateLimitRoute{"POST /oauth2/token", ""}},
+	} {
+		assert.Equal(t, tt.want, newRateLimitRoute(tt.method, tt.path), tt.path)
+	}
+}
+
+func TestRateLimiter_Do(t *testing.T) {
+	t.Run("retries 429", func(t *testing.T) {
+		var bodies []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				w.Header().Set("X-RateLimit-Bucket", "bucket")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+
+		limiter := newRateLimiter()
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/channels/123/messages", strings.NewReader("cheese"))
+		require.NoError(t, err)
+		resp, err := limiter.do(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"cheese", "cheese"}, bodies)
+
+		stats := limiter.stats()
+		require.Len(t, stats, 1)
+		assert.Equal(t, "bucket", stats[0].Bucket)
+		assert.Equal(t, int64(2), stats[0].Requests)
+		assert.Equal(t, int64(1), stats[0].Waits)
+		assert.Equal(t, int64(1), stats[0].TooManyRequests)
+		assert.True(t, stats[0].WaitTime > 0)
+	})
+
+	t.Run("gives up after retries", func(t *testing.T) {
+		var requests int
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"retry_after": 0.001, "global": true}`))
+		}))
+		defer ts.Close()
+
+		limiter := newRateLimiter()
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/guilds/123", nil)
+		require.NoError(t, err)
+		resp, err := limiter.do(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(resp.Body)
+		assert.Equal(t, `{"retry_after": 0.001, "global": true}`, body.String())
+		assert.Equal(t, MaxRateLimitRetries+1, requests)
+		assert.Equal(t, int64(MaxRateLimitRetries+1), limiter.stats()[0].GlobalLimits)
+	})
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	ctx := context.Background()
+	route := newRateLimitRoute(http.MethodPost, "/channels/123/messages")
+	patchRoute := newRateLimitRoute(http.MethodPatch, "/channels/123/messages/456")
+	header := make(http.Header)
+	header.Set("X-RateLimit-Bucket", "bucket")
+	header.Set("X-RateLimit-Limit", "2")
+	header.Set("X-RateLimit-Remaining", "1")
+	header.Set("X-RateLimit-Reset-After", "0.05")
+
+	t.Run("shares buckets", func(t *testing.T) {
+		limiter := newRateLimiter()
+		limiter.update(route, header, nil)
+		limiter.update(patchRoute, header, nil)
+
+		require.NoError(t, limiter.wait(ctx, route))
+		start := time.Now()
+		require.NoError(t, limiter.wait(ctx, patchRoute))
+		assert.True(t, time.Since(start) >= 40*time.Millisecond, "waits for the bucket to reset")
+	})
+
+	t.Run("scopes buckets by major parameter", func(t *testing.T) {
+		limiter := newRateLimiter()
+		exhausted := header.Clone()
+		exhausted.Set("X-RateLimit-Remaining", "0")
+		exhausted.Set("X-RateLimit-Reset-After", "60")
+		limiter.update(route, exhausted, nil)
+
+		otherChannel := newRateLimitRoute(http.MethodPost, "/channels/456/messages")
+		assert.Equal(t, time.Duration(0), limiter.reserve(otherChannel), "other channel")
+		assert.True(t, limiter.reserve(route) > 0, "same channel")
+	})
+
+	t.Run("unknown routes", func(t *testing.T) {
+		limiter := newRateLimiter()
+		limiter.update(route, make(http.Header), &RateLimitResponse{RetryAfter: 60})
+
+		assert.True(t, limiter.reserve(route) > 0, "same channel")
+		assert.Equal(t, time.Duration(0), limiter.reserve(newRateLimitRoute(http.MethodPost, "/channels/456/messages")), "other channel")
+		assert.Equal(t, time.Duration(0), limiter.reserve(patchRoute), "other route")
+		assert.Equal(t, "POST /channels/:channel_id/messages", limiter.stats()[0].Bucket)
+	})
+
+	t.Run("concurrent requests", func(t *testing.T) {
+		limiter := newRateLimiter()
+		limiter.update(route, header, nil)
+
+		var wg sync.WaitGroup
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, limiter.wait(ctx, route))
+			}()
+		}
+		wg.Wait()
+		assert.True(t, time.Since(start) >= 40*time.Millisecond)
+		assert.Equal(t, int64(3), limiter.stats()[0].Requests)
+		assert.Equal(t, int64(2), limiter.stats()[0].Waits)
+	})
+
+	t.Run("global", func(t *testing.T) {
+		limiter := newRateLimiter()
+		limiter.update(route, make(http.Header), &RateLimitResponse{RetryAfter: 60, Global: true})
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, limiter.wait(ctx, newRateLimitRoute(http.MethodGet, "/guilds/456")), context.DeadlineExceeded)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		limiter := newRateLimiter()
+		header := header.Clone()
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset-After", "60")
+		limiter.update(route, header, nil)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, limiter.wait(ctx, route), context.Canceled)
+	})
+}
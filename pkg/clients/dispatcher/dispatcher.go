@@ -1,25 +1,86 @@
 package dispatcher
 
 import (
+	"context"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/http_wrappers"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"io"
 	"net/http"
 	"sync"
 	"time"
 )
 
-type Dispatcher struct {
-	startOnce sync.Once
-	queue     chan taskData
-	opts      Opts
+var ErrClosed = errors.New("dispatcher is closed")
+var ErrQueueFull = errors.New("dispatcher queue is full")
+
+// Priority picks the lane of a request. Requests in higher lanes are sent first.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	priorityLanes
+)
+
+// Doer sends http requests. *http.Client is a Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc turns a func into a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (x DoerFunc) Do(req *http.Request) (*http.Response, error) { return x(req) }
+
+// Limit is the rate of requests to a host.
+type Limit struct {
+	// Rate is how many requests are sent per second. Zero means no limit.
+	Rate float64
+	// Burst is how many requests can be sent at once after the host was idle.
+	Burst int
 }
 
 type Opts struct {
+	// Concurrency is how many requests are sent at once. Defaults to 1.
+	Concurrency int
+	// QueueSize is how many requests can wait to be sent. Zero means no limit.
 	QueueSize int
-	MinWait   time.Duration
+	// HostLimit is the rate limit of each host.
+	HostLimit Limit
+	// HostLimits overrides HostLimit for some hosts.
+	HostLimits map[string]Limit
+	Retry      RetryPolicy
+	// Client sends the requests. Defaults to http_wrappers.DoRequest.
+	Client Doer
 }
 
-type taskData struct {
-	req        *http.Request
-	resultChan chan<- taskResult
+// Dispatcher queues outbound http requests and sends them from a pool of workers.
+// Requests wait for a free worker and for their host's rate limit, and failed requests are retried with the retry policy.
+type Dispatcher struct {
+	opts Opts
+
+	lock    sync.Mutex
+	lanes   [priorityLanes][]*task
+	queued  int
+	running int
+	buckets map[string]*tokenBucket
+	closed  bool
+	// stopped is set when shutdown gives up waiting, and stops retries.
+	stopped bool
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+type task struct {
+	req       *http.Request
+	priority  Priority
+	attempt   int
+	notBefore time.Time
+	result    chan taskResult
 }
 
 type taskResult struct {
@@ -27,29 +88,300 @@ type taskResult struct {
 	err  error
 }
 
-func NewDispatcher(opts Opts) Dispatcher {
-	return Dispatcher{queue: make(chan taskData, opts.QueueSize), opts: opts}
+func NewDispatcher(opts Opts) *Dispatcher {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Client == nil {
+		opts.Client = DoerFunc(http_wrappers.DoRequest)
+	}
+	x := &Dispatcher{
+		opts:    opts,
+		buckets: make(map[string]*tokenBucket),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go x.loop()
+	return x
 }
 
+// Do sends the request in the normal lane.
 func (x *Dispatcher) Do(req *http.Request) (*http.Response, error) {
-	x.start()
-	resultChan := make(chan taskResult, 1)
-	x.queue <- taskData{req, resultChan}
-	result := <-resultChan
-	return result.resp, result.err
-}
-
-func (x *Dispatcher) start() {
-	x.startOnce.Do(func() {
-		ticker := time.NewTicker(x.opts.MinWait)
-		defer ticker.Stop()
-		go func() {
-			for range ticker.C {
-				task := <-x.queue
-				resp, err := http.DefaultClient.Do(task.req)
-				task.resultChan <- taskResult{resp, err}
-				close(task.resultChan)
+	return x.DoPriority(req, PriorityNormal)
+}
+
+// DoPriority queues the request in a lane and waits for the response.
+// If the request context is done while the request is queued, the request is dropped and the context error is returned.
+func (x *Dispatcher) DoPriority(req *http.Request, priority Priority) (*http.Response, error) {
+	if priority < PriorityLow || priority >= priorityLanes {
+		priority = PriorityNormal
+	}
+	t := &task{req: req, priority: priority, result: make(chan taskResult, 1)}
+	if err := x.enqueue(t); err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-t.result:
+		return result.resp, result.err
+	case <-req.Context().Done():
+		if x.remove(t) {
+			return nil, req.Context().Err()
+		}
+		// The request is being sent, and fails with the context.
+		result := <-t.result
+		return result.resp, result.err
+	}
+}
+
+// Shutdown stops accepting requests and waits for the queued requests to finish.
+// If ctx is done first, the requests that are still queued fail with ErrClosed.
+func (x *Dispatcher) Shutdown(ctx context.Context) error {
+	x.lock.Lock()
+	x.closed = true
+	x.lock.Unlock()
+	x.signal()
+
+	select {
+	case <-x.done:
+		return nil
+	case <-ctx.Done():
+		x.lock.Lock()
+		x.stopped = true
+		for priority := range x.lanes {
+			for _, t := range x.lanes[priority] {
+				t.result <- taskResult{err: ErrClosed}
 			}
-		}()
+			x.lanes[priority] = nil
+		}
+		x.queued = 0
+		x.lock.Unlock()
+		x.signal()
+		return ctx.Err()
+	}
+}
+
+func (x *Dispatcher) enqueue(t *task) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	switch {
+	case x.closed:
+		return ErrClosed
+	case x.opts.QueueSize > 0 && x.queued >= x.opts.QueueSize:
+		return ErrQueueFull
+	}
+	x.push(t)
+	return nil
+}
+
+// push adds a task to the end of its lane. The caller must hold the lock.
+func (x *Dispatcher) push(t *task) {
+	x.lanes[t.priority] = append(x.lanes[t.priority], t)
+	x.queued++
+	x.signal()
+}
+
+// remove drops a queued task, and reports whether it was still queued.
+func (x *Dispatcher) remove(t *task) bool {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	lane := x.lanes[t.priority]
+	for i := range lane {
+		if lane[i] == t {
+			x.lanes[t.priority] = append(lane[:i:i], lane[i+1:]...)
+			x.queued--
+			x.signal()
+			return true
+		}
+	}
+	return false
+}
+
+func (x *Dispatcher) signal() {
+	select {
+	case x.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (x *Dispatcher) loop() {
+	defer close(x.done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		x.lock.Lock()
+		wait := x.startReady(time.Now())
+		finished := x.closed && x.queued == 0 && x.running == 0
+		x.lock.Unlock()
+		if finished {
+			return
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+		select {
+		case <-x.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// startReady starts queued tasks while there are free workers.
+// It returns how long until the next waiting task is ready, or zero if no task is waiting on time.
+// The caller must hold the lock.
+func (x *Dispatcher) startReady(now time.Time) time.Duration {
+	for x.running < x.opts.Concurrency {
+		t, wait := x.next(now)
+		if t == nil {
+			return wait
+		}
+		x.running++
+		go x.run(t)
+	}
+	return 0
+}
+
+// next removes the first task that can be sent now, looking at the higher lanes first.
+// If no task can be sent, it returns how long until one can.
+func (x *Dispatcher) next(now time.Time) (*task, time.Duration) {
+	var wait time.Duration
+	waitFor := func(d time.Duration) {
+		if wait == 0 || d < wait {
+			wait = d
+		}
+	}
+
+	limited := make(map[string]bool)
+	for priority := priorityLanes - 1; priority >= PriorityLow; priority-- {
+		for i, t := range x.lanes[priority] {
+			if t.notBefore.After(now) {
+				waitFor(t.notBefore.Sub(now))
+				continue
+			}
+			host := t.req.URL.Host
+			if limited[host] {
+				continue
+			}
+			if d := x.bucket(host).take(now); d > 0 {
+				limited[host] = true
+				waitFor(d)
+				continue
+			}
+			x.lanes[priority] = append(x.lanes[priority][:i:i], x.lanes[priority][i+1:]...)
+			x.queued--
+			return t, 0
+		}
+	}
+	return nil, wait
+}
+
+func (x *Dispatcher) bucket(host string) *tokenBucket {
+	bucket, ok := x.buckets[host]
+	if !ok {
+		limit, ok := x.opts.HostLimits[host]
+		if !ok {
+			limit = x.opts.HostLimit
+		}
+		bucket = newTokenBucket(limit)
+		x.buckets[host] = bucket
+	}
+	return bucket
+}
+
+// run sends the request, and queues it again if it should be retried.
+func (x *Dispatcher) run(t *task) {
+	resp, err := x.opts.Client.Do(t.req)
+	t.attempt++
+
+	if x.retry(t, resp, err) {
+		logger.WithField("host", t.req.URL.Host).
+			WithField("attempt", t.attempt).
+			WithField("backoff", time.Until(t.notBefore).String()).
+			WithError(err).
+			Warn("dispatcher: retrying request")
+		x.lock.Lock()
+		x.running--
+		x.push(t)
+		x.lock.Unlock()
+		return
+	}
+
+	t.result <- taskResult{resp, err}
+	x.lock.Lock()
+	x.running--
+	x.lock.Unlock()
+	x.signal()
+}
+
+// retry prepares the task for the next attempt, and reports whether the request should be sent again.
+func (x *Dispatcher) retry(t *task, resp *http.Response, err error) bool {
+	x.lock.Lock()
+	stopped := x.stopped
+	x.lock.Unlock()
+
+	policy := x.opts.Retry
+	switch {
+	case stopped, t.attempt >= policy.MaxAttempts, t.req.Context().Err() != nil:
+		return false
+	case !policy.shouldRetry(t.req, resp, err):
+		return false
+	case t.req.Body != nil && t.req.GetBody == nil:
+		return false
+	}
+
+	req := t.req.Clone(t.req.Context())
+	if t.req.GetBody != nil {
+		body, err := t.req.GetBody()
+		if err != nil {
+			return false
+		}
+		req.Body = body
+	}
+
+	t.notBefore = time.Now().Add(policy.backoff(t.attempt, resp))
+	t.req = req
+	if resp != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	return true
+}
+
+var defaultDispatcher struct {
+	once       sync.Once
+	dispatcher *Dispatcher
+}
+
+// Default is the dispatcher shared by the api clients.
+func Default() *Dispatcher {
+	defaultDispatcher.once.Do(func() {
+		defaultDispatcher.dispatcher = NewDispatcher(Opts{
+			Concurrency: config.Config().Dispatcher.Concurrency,
+			QueueSize:   config.Config().Dispatcher.QueueSize,
+			HostLimit: Limit{
+				Rate:  config.Config().Dispatcher.HostRate,
+				Burst: config.Config().Dispatcher.HostBurst,
+			},
+			Retry: DefaultRetryPolicy,
+		})
 	})
+	return defaultDispatcher.dispatcher
 }
+
+// Do sends the request with the default dispatcher in the normal lane.
+func Do(req *http.Request) (*http.Response, error) { return Default().Do(req) }
+
+// DoPriority sends the request with the default dispatcher.
+func DoPriority(req *http.Request, priority Priority) (*http.Response, error) {
+	return Default().DoPriority(req, priority)
+}
+
+// Shutdown shuts down the default dispatcher.
+func Shutdown(ctx context.Context) error { return Default().Shutdown(ctx) }
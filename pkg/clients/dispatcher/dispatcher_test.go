@@ -0,0 +1,249 @@
+package dispatcher
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClient struct {
+	lock     sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	release  chan struct{}
+	statuses []int
+}
+
+func (x *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	x.lock.Lock()
+	x.requests = append(x.requests, req)
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		x.bodies = append(x.bodies, string(body))
+	}
+	status := http.StatusOK
+	if len(x.statuses) > 0 {
+		status, x.statuses = x.statuses[0], x.statuses[1:]
+	}
+	x.lock.Unlock()
+
+	if x.release != nil {
+		select {
+		case <-x.release:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+}
+
+func (x *fakeClient) paths() []string {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	var paths []string
+	for _, req := range x.requests {
+		paths = append(paths, req.URL.Path)
+	}
+	return paths
+}
+
+func newTestRequest(t *testing.T, ctx context.Context, method, url string, body io.Reader) *http.Request {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	require.NoError(t, err)
+	return req
+}
+
+func TestDispatcher_Do(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("concurrency", func(t *testing.T) {
+		client := &fakeClient{release: make(chan struct{})}
+		dispatcher := NewDispatcher(Opts{Concurrency: 2, Client: client})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/", nil))
+				assert.NoError(t, err)
+			}()
+		}
+		assert.Eventually(t, func() bool { return len(client.paths()) == 2 }, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		assert.Len(t, client.paths(), 2, "third request waits for a worker")
+
+		close(client.release)
+		wg.Wait()
+		assert.Len(t, client.paths(), 3)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		client := &fakeClient{release: make(chan struct{})}
+		dispatcher := NewDispatcher(Opts{Concurrency: 1, Client: client})
+
+		var wg sync.WaitGroup
+		send := func(path string, priority Priority) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := dispatcher.DoPriority(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test"+path, nil), priority)
+				assert.NoError(t, err)
+			}()
+		}
+		send("/first", PriorityNormal)
+		assert.Eventually(t, func() bool { return len(client.paths()) == 1 }, time.Second, time.Millisecond)
+		send("/low", PriorityLow)
+		send("/normal", PriorityNormal)
+		send("/high", PriorityHigh)
+		assert.Eventually(t, func() bool {
+			dispatcher.lock.Lock()
+			defer dispatcher.lock.Unlock()
+			return dispatcher.queued == 3
+		}, time.Second, time.Millisecond)
+
+		close(client.release)
+		wg.Wait()
+		assert.Equal(t, []string{"/first", "/high", "/normal", "/low"}, client.paths())
+	})
+
+	t.Run("host limit", func(t *testing.T) {
+		client := &fakeClient{}
+		dispatcher := NewDispatcher(Opts{
+			Concurrency: 4,
+			Client:      client,
+			HostLimits:  map[string]Limit{"slow.test": {Rate: 20, Burst: 1}},
+		})
+
+		start := time.Now()
+		for _, url := range []string{"http://slow.test/1", "http://fast.test/1", "http://slow.test/2", "http://fast.test/2"} {
+			_, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, url, nil))
+			require.NoError(t, err)
+		}
+		assert.True(t, time.Since(start) >= 40*time.Millisecond, "second slow request waits for a token")
+	})
+
+	t.Run("canceled while queued", func(t *testing.T) {
+		client := &fakeClient{release: make(chan struct{})}
+		defer close(client.release)
+		dispatcher := NewDispatcher(Opts{Concurrency: 1, Client: client})
+		go dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/busy", nil))
+		assert.Eventually(t, func() bool { return len(client.paths()) == 1 }, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/queued", nil))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		dispatcher.lock.Lock()
+		assert.Equal(t, 0, dispatcher.queued)
+		dispatcher.lock.Unlock()
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		client := &fakeClient{release: make(chan struct{})}
+		defer close(client.release)
+		dispatcher := NewDispatcher(Opts{Concurrency: 1, QueueSize: 1, Client: client})
+		go dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/busy", nil))
+		assert.Eventually(t, func() bool { return len(client.paths()) == 1 }, time.Second, time.Millisecond)
+		go dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/queued", nil))
+		assert.Eventually(t, func() bool {
+			dispatcher.lock.Lock()
+			defer dispatcher.lock.Unlock()
+			return dispatcher.queued == 1
+		}, time.Second, time.Millisecond)
+
+		_, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/full", nil))
+		assert.ErrorIs(t, err, ErrQueueFull)
+	})
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	t.Run("retries temporary failures", func(t *testing.T) {
+		client := &fakeClient{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}}
+		dispatcher := NewDispatcher(Opts{Client: client, Retry: policy})
+		resp, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodPut, "http://cheese.test/", strings.NewReader("brie")))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"brie", "brie", "brie"}, client.bodies)
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		client := &fakeClient{statuses: []int{503, 503, 503, 503}}
+		dispatcher := NewDispatcher(Opts{Client: client, Retry: policy})
+		resp, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, client.paths(), 3)
+	})
+
+	t.Run("does not retry post", func(t *testing.T) {
+		client := &fakeClient{statuses: []int{503, 200}}
+		dispatcher := NewDispatcher(Opts{Client: client, Retry: policy})
+		resp, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodPost, "http://cheese.test/", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, client.paths(), 1)
+	})
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: 4 * time.Second} {
+		got := policy.backoff(attempt, nil)
+		assert.True(t, got >= want/2 && got <= want, "attempt %d: %s", attempt, got)
+	}
+
+	resp := &http.Response{Header: make(http.Header)}
+	resp.Header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, policy.backoff(1, resp))
+	resp.Header.Set("Retry-After", "60")
+	assert.Equal(t, 4*time.Second, policy.backoff(1, resp))
+}
+
+func TestDispatcher_Shutdown(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("drains the queue", func(t *testing.T) {
+		client := &fakeClient{}
+		dispatcher := NewDispatcher(Opts{Client: client})
+		_, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/", nil))
+		require.NoError(t, err)
+
+		require.NoError(t, dispatcher.Shutdown(ctx))
+		_, err = dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/", nil))
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+
+	t.Run("timeout fails queued requests", func(t *testing.T) {
+		client := &fakeClient{release: make(chan struct{})}
+		defer close(client.release)
+		dispatcher := NewDispatcher(Opts{Concurrency: 1, Client: client})
+		go dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/busy", nil))
+		assert.Eventually(t, func() bool { return len(client.paths()) == 1 }, time.Second, time.Millisecond)
+
+		queued := make(chan error)
+		go func() {
+			_, err := dispatcher.Do(newTestRequest(t, ctx, http.MethodGet, "http://cheese.test/queued", nil))
+			queued <- err
+		}()
+		assert.Eventually(t, func() bool {
+			dispatcher.lock.Lock()
+			defer dispatcher.lock.Unlock()
+			return dispatcher.queued == 1
+		}, time.Second, time.Millisecond)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, dispatcher.Shutdown(shutdownCtx), context.DeadlineExceeded)
+		assert.ErrorIs(t, <-queued, ErrClosed)
+	})
+}
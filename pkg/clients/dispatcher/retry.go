@@ -0,0 +1,79 @@
+package dispatcher
+
+import (
+	"context"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryPolicy retries temporary failures of idempotent requests twice.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// RetryPolicy decides when a failed request is sent again.
+type RetryPolicy struct {
+	// MaxAttempts is the most times a request is sent. Zero or one means requests are not retried.
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry. It doubles with each attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// ShouldRetry reports whether a request should be sent again. Defaults to RetryTemporary.
+	ShouldRetry func(req *http.Request, resp *http.Response, err error) bool
+}
+
+// RetryTemporary retries idempotent requests that failed to connect or got a 502, 503, or 504 response.
+func RetryTemporary(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (x RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if x.ShouldRetry == nil {
+		return RetryTemporary(req, resp, err)
+	}
+	return x.ShouldRetry(req, resp, err)
+}
+
+// backoff returns the wait before the next attempt.
+// The wait is jittered, and a Retry-After header in seconds is honored up to MaxBackoff.
+func (x RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	backoff := x.MinBackoff
+	for i := 1; i < attempt && (x.MaxBackoff == 0 || backoff < x.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if x.MaxBackoff > 0 && backoff > x.MaxBackoff {
+		backoff = x.MaxBackoff
+	}
+	backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter := time.Duration(seconds) * time.Second
+			if x.MaxBackoff > 0 && retryAfter > x.MaxBackoff {
+				retryAfter = x.MaxBackoff
+			}
+			if retryAfter > backoff {
+				backoff = retryAfter
+			}
+		}
+	}
+	return backoff
+}
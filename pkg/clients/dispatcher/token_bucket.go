@@ -0,0 +1,35 @@
+package dispatcher
+
+import (
+	"math"
+	"time"
+)
+
+// tokenBucket refills at a steady rate, and each request takes a token.
+type tokenBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	updated time.Time
+}
+
+func newTokenBucket(limit Limit) *tokenBucket {
+	burst := math.Max(float64(limit.Burst), 1)
+	return &tokenBucket{rate: limit.Rate, burst: burst, tokens: burst}
+}
+
+// take takes a token, or returns how long until there is one.
+func (x *tokenBucket) take(now time.Time) time.Duration {
+	if x.rate <= 0 {
+		return 0
+	}
+	if !x.updated.IsZero() {
+		x.tokens = math.Min(x.burst, x.tokens+now.Sub(x.updated).Seconds()*x.rate)
+	}
+	x.updated = now
+	if x.tokens >= 1 {
+		x.tokens--
+		return 0
+	}
+	return time.Duration((1 - x.tokens) / x.rate * float64(time.Second))
+}
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"os"
+	"time"
+)
+
+// LockTTL is how long a lock is held if its holder never releases it.
+const LockTTL = 30 * time.Second
+
+// LockWait is how long WithLock waits for another holder to release the lock.
+const LockWait = 10 * time.Second
+
+const lockRetryInterval = 50 * time.Millisecond
+
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// WithLock runs f while holding the lock at key, so read-modify-write updates from other requests and servers do not interleave.
+// The lock is released when f returns, or expires after LockTTL.
+func WithLock(ctx context.Context, key string, f func() error) error {
+	hostname, _ := os.Hostname()
+	token := fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), time.Now().UnixNano())
+
+	deadline := time.Now().Add(LockWait)
+	for {
+		ok, err := SetNX(ctx, key, LockTTL, token)
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s: %w", key, ErrLockTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	defer func() {
+		if _, err := DelIfEqual(ctx, key, token); err != nil {
+			logger.RedisFailure(ctx, err)
+		}
+	}()
+	return f()
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWithLock(t *testing.T) {
+	ctx := context.Background()
+	UseStore(NewMemoryStore())
+
+	// Without the lock, concurrent increments through Get and Set would lose updates.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, WithLock(ctx, "counter:lock", func() error {
+				value, err := Get(ctx, "counter")
+				if err != nil {
+					return err
+				}
+				count, _ := strconv.Atoi(value)
+				return Set(ctx, "counter", strconv.Itoa(count+1))
+			}))
+		}()
+	}
+	wg.Wait()
+
+	got, err := Get(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, "10", got)
+
+	ok, err := SetNX(ctx, "counter:lock", LockTTL, "token")
+	require.NoError(t, err)
+	assert.True(t, ok, "the lock is released")
+}
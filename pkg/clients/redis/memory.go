@@ -0,0 +1,410 @@
+package redis
+
+import (
+	"context"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWrongType is returned by the memory store when a key holds a different kind of value.
+var ErrWrongType = errors.New("operation against a key holding the wrong kind of value")
+
+type memoryEntry struct {
+	value     interface{} // string, map[string]string, []string, or []zMember
+	expiresAt time.Time
+}
+
+type zMember struct {
+	score  float64
+	member string
+}
+
+// MemoryStore is a Store that keeps everything in process memory.
+// It is meant for development and tests; nothing survives a restart.
+type MemoryStore struct {
+	lock sync.Mutex
+	data map[string]*memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string]*memoryEntry)}
+}
+
+// entry returns the live entry for key, dropping it if it has expired.
+func (x *MemoryStore) entry(key string) *memoryEntry {
+	entry, ok := x.data[key]
+	if !ok {
+		return nil
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(x.data, key)
+		return nil
+	}
+	return entry
+}
+
+func (x *MemoryStore) getString(key string) (string, error) {
+	entry := x.entry(key)
+	if entry == nil {
+		return "", nil
+	}
+	value, ok := entry.value.(string)
+	if !ok {
+		return "", ErrWrongType
+	}
+	return value, nil
+}
+
+func (x *MemoryStore) getHash(key string, create bool) (map[string]string, error) {
+	entry := x.entry(key)
+	if entry == nil {
+		if !create {
+			return nil, nil
+		}
+		hash := make(map[string]string)
+		x.data[key] = &memoryEntry{value: hash}
+		return hash, nil
+	}
+	hash, ok := entry.value.(map[string]string)
+	if !ok {
+		return nil, ErrWrongType
+	}
+	return hash, nil
+}
+
+func (x *MemoryStore) Get(_ context.Context, key string) (string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	return x.getString(key)
+}
+
+func (x *MemoryStore) MGet(_ context.Context, keys ...string) ([]string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		// MGET returns nil for anything that is not a string.
+		values[i], _ = x.getString(key)
+	}
+	return values, nil
+}
+
+func (x *MemoryStore) Set(_ context.Context, key string, value string, ttl time.Duration) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	entry := &memoryEntry{value: value}
+	if ttl != 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	x.data[key] = entry
+	return nil
+}
+
+func (x *MemoryStore) Del(_ context.Context, keys ...string) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	for _, key := range keys {
+		delete(x.data, key)
+	}
+	return nil
+}
+
+func (x *MemoryStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	var keys []string
+	for key := range x.data {
+		if strings.HasPrefix(key, prefix) && x.entry(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (x *MemoryStore) SetNX(_ context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	if x.entry(key) != nil {
+		return false, nil
+	}
+	entry := &memoryEntry{value: value}
+	if ttl != 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	x.data[key] = entry
+	return true, nil
+}
+
+func (x *MemoryStore) DelIfEqual(_ context.Context, key string, value string) (bool, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	entry := x.entry(key)
+	if entry == nil || entry.value != value {
+		return false, nil
+	}
+	delete(x.data, key)
+	return true, nil
+}
+
+func (x *MemoryStore) Incr(_ context.Context, key string) (int64, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	return x.incr(key)
+}
+
+func (x *MemoryStore) incr(key string) (int64, error) {
+	valueString, err := x.getString(key)
+	if err != nil {
+		return 0, err
+	}
+	var value int64
+	if valueString != "" {
+		value, err = strconv.ParseInt(valueString, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+	}
+	value++
+	entry := x.entry(key)
+	if entry == nil {
+		entry = new(memoryEntry)
+		x.data[key] = entry
+	}
+	entry.value = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
+func (x *MemoryStore) SaveVersion(_ context.Context, counterKey, versionsKey, valueKey string, version, value string, keep int64) (int64, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	// Check the types first, so that nothing is changed if one of the keys holds the wrong kind of value.
+	if _, err := x.getString(counterKey); err != nil {
+		return 0, err
+	}
+	versions, err := x.getHash(versionsKey, true)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := x.incr(counterKey)
+	if err != nil {
+		return 0, err
+	}
+	versions[strconv.FormatInt(id, 10)] = version
+	x.data[valueKey] = &memoryEntry{value: value}
+	if expired := id - keep; expired > 0 {
+		delete(versions, strconv.FormatInt(expired, 10))
+	}
+	return id, nil
+}
+
+func (x *MemoryStore) HGet(_ context.Context, key string, field string) (string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	hash, err := x.getHash(key, false)
+	if err != nil {
+		return "", err
+	}
+	return hash[field], nil
+}
+
+func (x *MemoryStore) HMGet(_ context.Context, key string, fields ...string) ([]string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	hash, err := x.getHash(key, false)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = hash[field]
+	}
+	return values, nil
+}
+
+func (x *MemoryStore) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	hash, err := x.getHash(key, false)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(hash))
+	for field, value := range hash {
+		values[field] = value
+	}
+	return values, nil
+}
+
+func (x *MemoryStore) HSet(_ context.Context, key string, fields map[string]string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	hash, err := x.getHash(key, true)
+	if err != nil {
+		return err
+	}
+	for field, value := range fields {
+		hash[field] = value
+	}
+	return nil
+}
+
+func (x *MemoryStore) HDel(_ context.Context, key string, fields ...string) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	hash, err := x.getHash(key, false)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		delete(hash, field)
+	}
+	if hash != nil && len(hash) == 0 {
+		delete(x.data, key)
+	}
+	return nil
+}
+
+func (x *MemoryStore) RPush(_ context.Context, key string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	entry := x.entry(key)
+	if entry == nil {
+		entry = &memoryEntry{value: []string(nil)}
+		x.data[key] = entry
+	}
+	list, ok := entry.value.([]string)
+	if !ok {
+		return ErrWrongType
+	}
+	entry.value = append(list, values...)
+	return nil
+}
+
+func (x *MemoryStore) LRange(_ context.Context, key string, start, stop int) ([]string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	entry := x.entry(key)
+	if entry == nil {
+		return nil, nil
+	}
+	list, ok := entry.value.([]string)
+	if !ok {
+		return nil, ErrWrongType
+	}
+
+	start, stop = listRange(len(list), start, stop)
+	if start > stop {
+		return []string{}, nil
+	}
+	return append([]string(nil), list[start:stop+1]...), nil
+}
+
+func (x *MemoryStore) LTrim(_ context.Context, key string, start, stop int) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	entry := x.entry(key)
+	if entry == nil {
+		return nil
+	}
+	list, ok := entry.value.([]string)
+	if !ok {
+		return ErrWrongType
+	}
+
+	start, stop = listRange(len(list), start, stop)
+	if start > stop {
+		delete(x.data, key)
+		return nil
+	}
+	entry.value = append([]string(nil), list[start:stop+1]...)
+	return nil
+}
+
+// listRange resolves negative indexes and clamps start and stop to a list of length n, like redis does.
+func listRange(n, start, stop int) (int, int) {
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	return start, stop
+}
+
+func (x *MemoryStore) ZAdd(_ context.Context, key string, score float64, member string) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	entry := x.entry(key)
+	if entry == nil {
+		entry = &memoryEntry{value: []zMember(nil)}
+		x.data[key] = entry
+	}
+	set, ok := entry.value.([]zMember)
+	if !ok {
+		return ErrWrongType
+	}
+
+	for i := range set {
+		if set[i].member == member {
+			set = append(set[:i], set[i+1:]...)
+			break
+		}
+	}
+	set = append(set, zMember{score: score, member: member})
+	sort.Slice(set, func(i, j int) bool {
+		if set[i].score == set[j].score {
+			return set[i].member < set[j].member
+		}
+		return set[i].score < set[j].score
+	})
+	entry.value = set
+	return nil
+}
+
+func (x *MemoryStore) ZRangeByScore(_ context.Context, key string, min, max float64) ([]string, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	entry := x.entry(key)
+	if entry == nil {
+		return []string{}, nil
+	}
+	set, ok := entry.value.([]zMember)
+	if !ok {
+		return nil, ErrWrongType
+	}
+
+	reverse := max < min
+	if reverse {
+		min, max = max, min
+	}
+	members := make([]string, 0, len(set))
+	for _, z := range set {
+		if z.score >= min && z.score <= max {
+			members = append(members, z.member)
+		}
+	}
+	if reverse {
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+	}
+	return members, nil
+}
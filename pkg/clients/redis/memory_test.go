@@ -0,0 +1,182 @@
+package redis
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Strings(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	require.NoError(t, store.Set(ctx, "sessions:a", "alice", 0))
+	require.NoError(t, store.Set(ctx, "sessions:b", "bob", 0))
+	require.NoError(t, store.Set(ctx, "other", "cheese", 0))
+
+	got, err := store.Get(ctx, "sessions:a")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got)
+
+	got, err = store.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	keys, err := store.Keys(ctx, "sessions:")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sessions:a", "sessions:b"}, keys)
+
+	values, err := store.MGet(ctx, "sessions:b", "missing", "sessions:a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "", "alice"}, values)
+
+	require.NoError(t, store.Del(ctx, "sessions:a"))
+	got, err = store.Get(ctx, "sessions:a")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+}
+
+func TestMemoryStore_Expires(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	require.NoError(t, store.Set(ctx, "short", "value", time.Nanosecond))
+	require.NoError(t, store.Set(ctx, "long", "value", time.Hour))
+	time.Sleep(time.Millisecond)
+
+	got, err := store.Get(ctx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	got, err = store.Get(ctx, "long")
+	require.NoError(t, err)
+	assert.Equal(t, "value", got)
+}
+
+func TestMemoryStore_SetNX(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	ok, err := store.SetNX(ctx, "lock", "alice", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok, "first SetNX")
+
+	ok, err = store.SetNX(ctx, "lock", "bob", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, ok, "second SetNX")
+
+	ok, err = store.DelIfEqual(ctx, "lock", "bob")
+	require.NoError(t, err)
+	assert.False(t, ok, "DelIfEqual with another value")
+
+	ok, err = store.DelIfEqual(ctx, "lock", "alice")
+	require.NoError(t, err)
+	assert.True(t, ok, "DelIfEqual")
+
+	got, err := store.Get(ctx, "lock")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+}
+
+func TestMemoryStore_Incr(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := store.Incr(ctx, "counter")
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	require.NoError(t, store.HSet(ctx, "hash", map[string]string{"a": "1"}))
+	_, err := store.Incr(ctx, "hash")
+	assert.Equal(t, ErrWrongType, err)
+}
+
+func TestMemoryStore_Hashes(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	require.NoError(t, store.HSet(ctx, "hash", map[string]string{"a": "1", "b": "2"}))
+	require.NoError(t, store.HSet(ctx, "hash", map[string]string{"b": "3"}))
+
+	got, err := store.HGet(ctx, "hash", "b")
+	require.NoError(t, err)
+	assert.Equal(t, "3", got)
+
+	values, err := store.HMGet(ctx, "hash", "b", "missing", "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "", "1"}, values)
+
+	all, err := store.HGetAll(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, all)
+
+	require.NoError(t, store.HDel(ctx, "hash", "a"))
+	all, err = store.HGetAll(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"b": "3"}, all)
+
+	all, err = store.HGetAll(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestMemoryStore_LRange(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	require.NoError(t, store.RPush(ctx, "list", "a", "b", "c", "d"))
+
+	for _, tt := range []struct {
+		name        string
+		start, stop int
+		want        []string
+	}{
+		{name: "all", start: 0, stop: -1, want: []string{"a", "b", "c", "d"}},
+		{name: "middle", start: 1, stop: 2, want: []string{"b", "c"}},
+		{name: "tail", start: -2, stop: -1, want: []string{"c", "d"}},
+		{name: "past end", start: 2, stop: 10, want: []string{"c", "d"}},
+		{name: "empty", start: 3, stop: 1, want: []string{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.LRange(ctx, "list", tt.start, tt.stop)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMemoryStore_LTrim(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	require.NoError(t, store.RPush(ctx, "list", "a", "b", "c", "d"))
+
+	require.NoError(t, store.LTrim(ctx, "list", -3, -1))
+	got, err := store.LRange(ctx, "list", 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d"}, got)
+
+	require.NoError(t, store.LTrim(ctx, "list", 2, 1))
+	got, err = store.LRange(ctx, "list", 0, -1)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestMemoryStore_ZRangeByScore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	require.NoError(t, store.ZAdd(ctx, "zset", 3, "c"))
+	require.NoError(t, store.ZAdd(ctx, "zset", 1, "a"))
+	require.NoError(t, store.ZAdd(ctx, "zset", 2, "b"))
+	require.NoError(t, store.ZAdd(ctx, "zset", 4, "d"))
+
+	got, err := store.ZRangeByScore(ctx, "zset", 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, got)
+
+	got, err = store.ZRangeByScore(ctx, "zset", 4, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "c", "b", "a"}, got)
+}
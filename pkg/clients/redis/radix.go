@@ -0,0 +1,222 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/mediocregopher/radix/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const Network = "tcp"
+
+const NewClientRetryWaitTime = 1 * time.Second
+
+// radixStore is a Store backed by a redis server.
+type radixStore struct {
+	initOnce sync.Once
+	pool     *radix.Pool
+}
+
+func newRadixStore() *radixStore { return new(radixStore) }
+
+func (x *radixStore) client() *radix.Pool {
+	x.initOnce.Do(func() {
+		ticker := time.NewTicker(NewClientRetryWaitTime)
+		defer ticker.Stop()
+		for attempt := 0; ; attempt++ {
+			var err error
+			x.pool, err = radix.NewPool(Network,
+				config.Config().Redis.Address,
+				config.Config().Redis.PoolSize,
+				radix.PoolConnFunc(func(network, addr string) (radix.Conn, error) {
+					var dialOpts []radix.DialOpt
+
+					if config.Config().Redis.Pass != "" {
+						dialOpts = append(dialOpts,
+							radix.DialAuthUser(config.Config().Redis.User, config.Config().Redis.Pass),
+							radix.DialSelectDB(config.Config().Redis.UseDB))
+
+					}
+
+					if config.Config().Redis.UseTLS {
+						dialOpts = append(dialOpts, radix.DialUseTLS(&tls.Config{InsecureSkipVerify: true}))
+					}
+					return radix.Dial("tcp", config.Config().Redis.Address, dialOpts...)
+				}))
+			if err != nil {
+				log.WithField("attempt", attempt).WithError(err).Warnf("radix.Dial() failed")
+				log.WithField("attempt", attempt).Warnf("retry after %v", NewClientRetryWaitTime)
+				<-ticker.C
+				continue
+			}
+			break
+		}
+	})
+	return x.pool
+}
+
+func (x *radixStore) do(rcv interface{}, cmd string, args ...string) error {
+	return x.client().Do(radix.Cmd(rcv, cmd, args...))
+}
+
+func (x *radixStore) Get(_ context.Context, key string) (string, error) {
+	var value string
+	err := x.do(&value, GET, key)
+	return value, err
+}
+
+func (x *radixStore) MGet(_ context.Context, keys ...string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	values := make([]string, 0, len(keys))
+	err := x.do(&values, MGET, keys...)
+	return values, err
+}
+
+func (x *radixStore) Set(_ context.Context, key string, value string, ttl time.Duration) error {
+	if ttl == 0 {
+		return x.do(nil, SET, key, value)
+	}
+	return x.do(nil, PSETEX, key, ttlMilliseconds(ttl), value)
+}
+
+func (x *radixStore) Del(_ context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return x.do(nil, DEL, keys...)
+}
+
+func (x *radixStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := x.do(&keys, KEYS, prefix+"*")
+	return keys, err
+}
+
+func (x *radixStore) SetNX(_ context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	args := []string{key, value, "NX"}
+	if ttl != 0 {
+		args = append(args, "PX", ttlMilliseconds(ttl))
+	}
+	var reply string
+	maybeNil := radix.MaybeNil{Rcv: &reply}
+	if err := x.do(&maybeNil, SET, args...); err != nil {
+		return false, err
+	}
+	return !maybeNil.Nil, nil
+}
+
+// delIfEqualScript deletes a key only if it holds the expected value.
+var delIfEqualScript = radix.NewEvalScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func (x *radixStore) DelIfEqual(_ context.Context, key string, value string) (bool, error) {
+	var deleted int
+	err := x.client().Do(delIfEqualScript.Cmd(&deleted, key, value))
+	return deleted == 1, err
+}
+
+func (x *radixStore) Incr(_ context.Context, key string) (int64, error) {
+	var value int64
+	err := x.do(&value, INCR, key)
+	return value, err
+}
+
+// saveVersionScript saves a new version and the current value together,
+// so the counter, the versions, and the value cannot get out of step.
+var saveVersionScript = radix.NewEvalScript(3, `
+local id = redis.call("INCR", KEYS[1])
+redis.call("HSET", KEYS[2], id, ARGV[1])
+redis.call("SET", KEYS[3], ARGV[2])
+local expired = id - tonumber(ARGV[3])
+if expired > 0 then
+	redis.call("HDEL", KEYS[2], expired)
+end
+return id`)
+
+func (x *radixStore) SaveVersion(_ context.Context, counterKey, versionsKey, valueKey string, version, value string, keep int64) (int64, error) {
+	var id int64
+	err := x.client().Do(saveVersionScript.Cmd(&id, counterKey, versionsKey, valueKey,
+		version, value, strconv.FormatInt(keep, 10)))
+	return id, err
+}
+
+func (x *radixStore) HGet(_ context.Context, key string, field string) (string, error) {
+	var value string
+	err := x.do(&value, HGET, key, field)
+	return value, err
+}
+
+func (x *radixStore) HMGet(_ context.Context, key string, fields ...string) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	values := make([]string, 0, len(fields))
+	err := x.do(&values, HMGET, append([]string{key}, fields...)...)
+	return values, err
+}
+
+func (x *radixStore) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	values := make(map[string]string)
+	err := x.do(&values, HGETALL, key)
+	return values, err
+}
+
+func (x *radixStore) HSet(_ context.Context, key string, fields map[string]string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	args := make([]string, 0, 1+2*len(fields))
+	args = append(args, key)
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+	return x.do(nil, HSET, args...)
+}
+
+func (x *radixStore) HDel(_ context.Context, key string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return x.do(nil, HDEL, append([]string{key}, fields...)...)
+}
+
+func (x *radixStore) RPush(_ context.Context, key string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return x.do(nil, RPUSH, append([]string{key}, values...)...)
+}
+
+func (x *radixStore) LRange(_ context.Context, key string, start, stop int) ([]string, error) {
+	var values []string
+	err := x.do(&values, LRANGE, key, strconv.Itoa(start), strconv.Itoa(stop))
+	return values, err
+}
+
+func (x *radixStore) LTrim(_ context.Context, key string, start, stop int) error {
+	return x.do(nil, LTRIM, key, strconv.Itoa(start), strconv.Itoa(stop))
+}
+
+func (x *radixStore) ZAdd(_ context.Context, key string, score float64, member string) error {
+	return x.do(nil, ZADD, key, fmt.Sprintf("%f", score), member)
+}
+
+func (x *radixStore) ZRangeByScore(_ context.Context, key string, min, max float64) ([]string, error) {
+	cmd := ZRANGEBYSCORE
+	if max < min {
+		cmd = ZREVRANGEBYSCORE
+	}
+	var members []string
+	err := x.do(&members, cmd, key, fmt.Sprintf("%f", min), fmt.Sprintf("%f", max))
+	return members, err
+}
@@ -3,33 +3,37 @@ package redis
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"github.com/mediocregopher/radix/v3"
 	log "github.com/sirupsen/logrus"
 	"github.com/virtual-vgo/vvgo/pkg/config"
 	"github.com/virtual-vgo/vvgo/pkg/errors"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"github.com/virtual-vgo/vvgo/pkg/models/mixtape"
 	"github.com/virtual-vgo/vvgo/pkg/models/traces"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
-const Network = "tcp"
-
-const NewClientRetryWaitTime = 1 * time.Second
-
 const (
+	DEL              = "DEL"
+	EVAL             = "EVAL"
 	GET              = "GET"
 	HDEL             = "HDEL"
 	HGET             = "HGET"
 	HGETALL          = "HGETALL"
+	HMGET            = "HMGET"
 	HSET             = "HSET"
 	INCR             = "INCR"
+	KEYS             = "KEYS"
+	LRANGE           = "LRANGE"
+	LTRIM            = "LTRIM"
+	MGET             = "MGET"
+	PSETEX           = "PSETEX"
+	RPUSH            = "RPUSH"
 	SET              = "SET"
 	ZADD             = "ZADD"
 	ZRANGEBYSCORE    = "ZRANGEBYSCORE"
@@ -41,99 +45,36 @@ type ObjectId uint64
 func StringToObjectId(str string) uint64 { id, _ := strconv.ParseUint(str, 10, 64); return id }
 func (id ObjectId) String() string       { return strconv.FormatUint(uint64(id), 10) }
 
-type Action struct {
-	Rcv  interface{}
-	Cmd  string
-	Args []string
-}
-
-func Cmd(rcv interface{}, cmd string, args ...string) Action {
-	return Action{
-		Rcv:  rcv,
-		Cmd:  cmd,
-		Args: args,
-	}
-}
-
-var client *radix.Pool
+var store Store
+var storeLock sync.Mutex
 
-func getClient() *radix.Pool {
-	initClient()
-	return client
+// UseStore replaces the storage backend.
+func UseStore(s Store) {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	store = s
 }
 
-var initClientOnce = sync.Once{}
-
-func initClient() {
-	initClientOnce.Do(func() {
-		ticker := time.NewTicker(NewClientRetryWaitTime)
-		defer ticker.Stop()
-		for attempt := 0; ; attempt++ {
-			var err error
-			client, err = radix.NewPool(Network,
-				config.Config.Redis.Address,
-				config.Config.Redis.PoolSize,
-				radix.PoolConnFunc(func(network, addr string) (radix.Conn, error) {
-					var dialOpts []radix.DialOpt
-
-					if config.Config.Redis.Pass != "" {
-						dialOpts = append(dialOpts,
-							radix.DialAuthUser(config.Config.Redis.User, config.Config.Redis.Pass),
-							radix.DialSelectDB(config.Config.Redis.UseDB))
-
-					}
-
-					if config.Config.Redis.UseTLS {
-						dialOpts = append(dialOpts, radix.DialUseTLS(&tls.Config{InsecureSkipVerify: true}))
-					}
-					return radix.Dial("tcp", config.Config.Redis.Address, dialOpts...)
-				}))
-			if err != nil {
-				log.WithField("attempt", attempt).WithError(err).Warnf("radix.Dial() failed")
-				log.WithField("attempt", attempt).Warnf("retry after %v", NewClientRetryWaitTime)
-				<-ticker.C
-				continue
-			}
-			break
+func getStore() Store {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	if store == nil {
+		switch config.Config().Redis.Backend {
+		case BackendMemory:
+			store = NewMemoryStore()
+		case BackendRedis, "":
+			store = newRadixStore()
+		default:
+			log.WithField("backend", config.Config().Redis.Backend).Fatal("unknown redis backend")
 		}
-	})
-}
-
-func ReadSheet(ctx context.Context, spreadsheetName string, name string) ([][]interface{}, error) {
-	var buf bytes.Buffer
-	key := "sheets:" + spreadsheetName + ":" + name
-	if err := Do(ctx, Cmd(&buf, GET, key)); err != nil {
-		return nil, errors.RedisFailure(err)
-	}
-
-	if buf.Len() == 0 {
-		return nil, nil
-	}
-
-	var values [][]interface{}
-	if err := json.NewDecoder(&buf).Decode(&values); err != nil {
-		return nil, errors.JsonDecodeFailure(err)
 	}
-	return values, nil
+	return store
 }
 
-func WriteSheet(ctx context.Context, spreadsheetName, name string, values [][]interface{}) error {
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(&values); err != nil {
-		return errors.JsonEncodeFailure(err)
-	}
-
-	key := "sheets:" + spreadsheetName + ":" + name
-	if err := Do(ctx, Cmd(nil, SET, key, buf.String())); err != nil {
-		return errors.RedisFailure(err)
-	}
-	return nil
-}
-
-func Do(ctx context.Context, a Action) error {
-	initClient()
+// do runs query against the store and records the query metrics.
+func do(ctx context.Context, cmd string, args []string, query func(Store) error) error {
 	var err error
-	metrics := traces.NewRedisQueryMetrics(a.Cmd, a.Args)
+	metrics := traces.NewRedisQueryMetrics(cmd, args)
 	span, ok := traces.NewSpanFromContext(ctx, "redis query")
 	if !ok {
 		logger.Warn("redis client: invalid trace context")
@@ -141,7 +82,7 @@ func Do(ctx context.Context, a Action) error {
 		defer func() { WriteSpan(span.WithRedisQuery(metrics).WithError(err)) }()
 	}
 
-	if err = getClient().Do(radix.Cmd(a.Rcv, a.Cmd, a.Args...)); err != nil {
+	if err = query(getStore()); err != nil {
 		logger.
 			WithFields(metrics.Fields()).
 			WithError(err).
@@ -154,10 +95,168 @@ func Do(ctx context.Context, a Action) error {
 	return err
 }
 
+func Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := do(ctx, GET, []string{key}, func(s Store) (err error) {
+		value, err = s.Get(ctx, key)
+		return
+	})
+	return value, err
+}
+
+func MGet(ctx context.Context, keys ...string) ([]string, error) {
+	var values []string
+	err := do(ctx, MGET, keys, func(s Store) (err error) {
+		values, err = s.MGet(ctx, keys...)
+		return
+	})
+	return values, err
+}
+
+func Set(ctx context.Context, key string, value string) error {
+	return do(ctx, SET, []string{key, value}, func(s Store) error {
+		return s.Set(ctx, key, value, 0)
+	})
+}
+
+func SetEx(ctx context.Context, key string, ttl time.Duration, value string) error {
+	return do(ctx, PSETEX, []string{key, ttlMilliseconds(ttl), value}, func(s Store) error {
+		return s.Set(ctx, key, value, ttl)
+	})
+}
+
+// ttlMilliseconds formats a ttl for PX and PSETEX.
+// Redis rejects a ttl of zero, so ttls under a millisecond are rounded up.
+func ttlMilliseconds(ttl time.Duration) string {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+func Del(ctx context.Context, keys ...string) error {
+	return do(ctx, DEL, keys, func(s Store) error {
+		return s.Del(ctx, keys...)
+	})
+}
+
+// SetNX sets key to value if key does not exist, and reports whether it was set.
+// A ttl of zero means the key never expires.
+func SetNX(ctx context.Context, key string, ttl time.Duration, value string) (bool, error) {
+	var ok bool
+	err := do(ctx, SET, []string{key, value, "NX", "PX", ttlMilliseconds(ttl)}, func(s Store) (err error) {
+		ok, err = s.SetNX(ctx, key, value, ttl)
+		return
+	})
+	return ok, err
+}
+
+// DelIfEqual deletes key if it holds value, and reports whether it was deleted.
+func DelIfEqual(ctx context.Context, key string, value string) (bool, error) {
+	var ok bool
+	err := do(ctx, EVAL, []string{key, value}, func(s Store) (err error) {
+		ok, err = s.DelIfEqual(ctx, key, value)
+		return
+	})
+	return ok, err
+}
+
+func Keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := do(ctx, KEYS, []string{prefix + "*"}, func(s Store) (err error) {
+		keys, err = s.Keys(ctx, prefix)
+		return
+	})
+	return keys, err
+}
+
+func Incr(ctx context.Context, key string) (int64, error) {
+	var value int64
+	err := do(ctx, INCR, []string{key}, func(s Store) (err error) {
+		value, err = s.Incr(ctx, key)
+		return
+	})
+	return value, err
+}
+
+// SaveVersion increments the counter at counterKey, saves version in the hash at versionsKey under the new count,
+// sets valueKey to value, and deletes the version that is keep versions older, all in one step.
+func SaveVersion(ctx context.Context, counterKey, versionsKey, valueKey string, version, value string, keep int64) (int64, error) {
+	var id int64
+	err := do(ctx, EVAL, []string{counterKey, versionsKey, valueKey}, func(s Store) (err error) {
+		id, err = s.SaveVersion(ctx, counterKey, versionsKey, valueKey, version, value, keep)
+		return
+	})
+	return id, err
+}
+
+func HGet(ctx context.Context, key string, field string) (string, error) {
+	var value string
+	err := do(ctx, HGET, []string{key, field}, func(s Store) (err error) {
+		value, err = s.HGet(ctx, key, field)
+		return
+	})
+	return value, err
+}
+
+func HMGet(ctx context.Context, key string, fields ...string) ([]string, error) {
+	var values []string
+	err := do(ctx, HMGET, append([]string{key}, fields...), func(s Store) (err error) {
+		values, err = s.HMGet(ctx, key, fields...)
+		return
+	})
+	return values, err
+}
+
+func HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	var values map[string]string
+	err := do(ctx, HGETALL, []string{key}, func(s Store) (err error) {
+		values, err = s.HGetAll(ctx, key)
+		return
+	})
+	return values, err
+}
+
+func HSet(ctx context.Context, key string, fields map[string]string) error {
+	args := []string{key}
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+	return do(ctx, HSET, args, func(s Store) error {
+		return s.HSet(ctx, key, fields)
+	})
+}
+
+func HDel(ctx context.Context, key string, fields ...string) error {
+	return do(ctx, HDEL, append([]string{key}, fields...), func(s Store) error {
+		return s.HDel(ctx, key, fields...)
+	})
+}
+
+func RPush(ctx context.Context, key string, values ...string) error {
+	return do(ctx, RPUSH, append([]string{key}, values...), func(s Store) error {
+		return s.RPush(ctx, key, values...)
+	})
+}
+
+func LRange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	var values []string
+	err := do(ctx, LRANGE, []string{key, strconv.Itoa(start), strconv.Itoa(stop)}, func(s Store) (err error) {
+		values, err = s.LRange(ctx, key, start, stop)
+		return
+	})
+	return values, err
+}
+
+func LTrim(ctx context.Context, key string, start, stop int) error {
+	return do(ctx, LTRIM, []string{key, strconv.Itoa(start), strconv.Itoa(stop)}, func(s Store) error {
+		return s.LTrim(ctx, key, start, stop)
+	})
+}
+
 func NewTrace(ctx context.Context, name string) (*traces.Span, error) {
-	initClient()
-	var traceId int64
-	err := getClient().Do(radix.Cmd(&traceId, INCR, traces.NextTraceIdRedisKey))
+	traceId, err := getStore().Incr(ctx, traces.NextTraceIdRedisKey)
 	if err != nil {
 		return nil, err
 	}
@@ -166,26 +265,24 @@ func NewTrace(ctx context.Context, name string) (*traces.Span, error) {
 }
 
 func WriteSpan(span traces.Span) {
-	initClient()
 	if span.Duration == 0 {
 		span = span.Finish()
 	}
-	timestamp := fmt.Sprintf("%f", time.Duration(span.StartTime.UnixNano()).Seconds())
+	timestamp := time.Duration(span.StartTime.UnixNano()).Seconds()
 	var data bytes.Buffer
 	if err := json.NewEncoder(&data).Encode(span); err != nil {
 		log.WithError(err).Error("json.Encode() failed")
 		return
 	}
-	if err := getClient().Do(radix.Cmd(nil, ZADD, traces.SpansRedisKey, timestamp, data.String())); err != nil {
+	if err := getStore().ZAdd(context.Background(), traces.SpansRedisKey, timestamp, data.String()); err != nil {
 		logger.RedisFailure(context.Background(), err)
 		return
 	}
 }
 
 func ListSpans(ctx context.Context, start, end time.Time) ([]traces.Span, error) {
-	initClient()
-	startString := fmt.Sprintf("%f", time.Duration(start.UnixNano()).Seconds())
-	endString := fmt.Sprintf("%f", time.Duration(end.UnixNano()).Seconds())
+	startScore := time.Duration(start.UnixNano()).Seconds()
+	endScore := time.Duration(end.UnixNano()).Seconds()
 
 	cmd := ZRANGEBYSCORE
 	if end.Before(start) {
@@ -193,7 +290,11 @@ func ListSpans(ctx context.Context, start, end time.Time) ([]traces.Span, error)
 	}
 
 	var entriesJSON []string
-	if err := Do(ctx, Cmd(&entriesJSON, cmd, traces.SpansRedisKey, startString, endString)); err != nil {
+	args := []string{traces.SpansRedisKey, fmt.Sprintf("%f", startScore), fmt.Sprintf("%f", endScore)}
+	if err := do(ctx, cmd, args, func(s Store) (err error) {
+		entriesJSON, err = s.ZRangeByScore(ctx, traces.SpansRedisKey, startScore, endScore)
+		return
+	}); err != nil {
 		return nil, err
 	}
 	spans := make([]traces.Span, 0, len(entriesJSON))
@@ -208,13 +309,13 @@ func ListSpans(ctx context.Context, start, end time.Time) ([]traces.Span, error)
 }
 
 func ListMixtapeProjects(ctx context.Context) ([]mixtape.Project, error) {
-	var projectsJSON []string
-	if err := Do(ctx, Cmd(&projectsJSON, "HVALS", mixtape.ProjectsRedisKey)); err != nil {
+	projectsMap, err := HGetAll(ctx, mixtape.ProjectsRedisKey)
+	if err != nil {
 		return nil, errors.RedisFailure(err)
 	}
 
-	projects := make([]mixtape.Project, 0, len(projectsJSON))
-	for _, projectJSON := range projectsJSON {
+	projects := make([]mixtape.Project, 0, len(projectsMap))
+	for _, projectJSON := range projectsMap {
 		var project mixtape.Project
 		if err := json.NewDecoder(strings.NewReader(projectJSON)).Decode(&project); err != nil {
 			logger.JsonDecodeFailure(ctx, err)
@@ -222,7 +323,7 @@ func ListMixtapeProjects(ctx context.Context) ([]mixtape.Project, error) {
 		}
 		projects = append(projects, project)
 	}
-
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Id < projects[j].Id })
 	return projects, nil
 }
 
@@ -236,11 +337,11 @@ func SaveMixtapeProject(ctx context.Context, project mixtape.Project) error {
 		return err
 	}
 
-	return Do(ctx, Cmd(nil, HSET,
-		mixtape.ProjectsRedisKey, strconv.FormatUint(project.Id, 16), projectJSON.String()))
+	return HSet(ctx, mixtape.ProjectsRedisKey, map[string]string{
+		strconv.FormatUint(project.Id, 16): projectJSON.String(),
+	})
 }
 
 func DeleteMixtapeProject(ctx context.Context, id uint64) error {
-	return Do(ctx, Cmd(nil, HDEL,
-		mixtape.ProjectsRedisKey, strconv.FormatUint(id, 16)))
+	return HDel(ctx, mixtape.ProjectsRedisKey, strconv.FormatUint(id, 16))
 }
@@ -1 +1,12 @@
 package redis
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTtlMilliseconds(t *testing.T) {
+	assert.Equal(t, "1500", ttlMilliseconds(1500*time.Millisecond))
+	assert.Equal(t, "1", ttlMilliseconds(time.Microsecond), "sub-millisecond ttls are rounded up")
+}
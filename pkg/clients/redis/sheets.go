@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxSheetVersions is the number of versions kept for each sheet.
+const MaxSheetVersions = 25
+
+var ErrSheetVersionNotFound = errors.New("sheet version not found")
+
+// SheetVersion is a copy of a sheet kept after every write.
+// The latest version holds the current values of the sheet.
+type SheetVersion struct {
+	// Version is the hash field the version is saved under, so it is not part of the saved JSON.
+	Version   int64 `json:"Version,omitempty"`
+	Author    string
+	CreatedAt time.Time
+	// RollbackOf is the version restored by a rollback, or zero for regular writes.
+	RollbackOf int64 `json:"RollbackOf,omitempty"`
+	Rows       int
+	Values     [][]interface{} `json:"Values,omitempty"`
+}
+
+func sheetKey(spreadsheetName, name string) string { return "sheets:" + spreadsheetName + ":" + name }
+func sheetVersionsKey(spreadsheetName, name string) string {
+	return "sheet_versions:" + spreadsheetName + ":" + name
+}
+func sheetVersionCounterKey(spreadsheetName, name string) string {
+	return "sheet_versions:" + spreadsheetName + ":" + name + ":next"
+}
+
+func ReadSheet(ctx context.Context, spreadsheetName string, name string) ([][]interface{}, error) {
+	sheetJSON, err := Get(ctx, sheetKey(spreadsheetName, name))
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	if sheetJSON == "" {
+		return nil, nil
+	}
+
+	var values [][]interface{}
+	if err := json.NewDecoder(strings.NewReader(sheetJSON)).Decode(&values); err != nil {
+		return nil, errors.JsonDecodeFailure(err)
+	}
+	return values, nil
+}
+
+// WriteSheet replaces the sheet and saves the new values as the latest version.
+// Author identifies who made the change.
+func WriteSheet(ctx context.Context, spreadsheetName, name string, author string, values [][]interface{}) error {
+	_, err := writeSheetVersion(ctx, spreadsheetName, name, SheetVersion{Author: author, Values: values})
+	return err
+}
+
+// RollbackSheet replaces the sheet with the values of a previous version.
+// The rollback is saved as a new version, so it can be undone the same way.
+func RollbackSheet(ctx context.Context, spreadsheetName, name string, author string, version int64) (SheetVersion, error) {
+	previous, err := GetSheetVersion(ctx, spreadsheetName, name, version)
+	if err != nil {
+		return SheetVersion{}, err
+	}
+	return writeSheetVersion(ctx, spreadsheetName, name, SheetVersion{
+		Author:     author,
+		RollbackOf: previous.Version,
+		Values:     previous.Values,
+	})
+}
+
+// writeSheetVersion saves the version and replaces the sheet in one step,
+// so the version counter, the saved versions, and the sheet always agree, even across servers.
+// Readers see either the old or the new values, never a mix.
+func writeSheetVersion(ctx context.Context, spreadsheetName, name string, version SheetVersion) (SheetVersion, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(&version.Values); err != nil {
+		return SheetVersion{}, errors.JsonEncodeFailure(err)
+	}
+	sheetJSON := buf.String()
+
+	version.CreatedAt = time.Now()
+	version.Rows = len(version.Values)
+	versionJSON, err := json.Marshal(version)
+	if err != nil {
+		return SheetVersion{}, errors.JsonEncodeFailure(err)
+	}
+
+	id, err := SaveVersion(ctx, sheetVersionCounterKey(spreadsheetName, name), sheetVersionsKey(spreadsheetName, name),
+		sheetKey(spreadsheetName, name), string(versionJSON), sheetJSON, MaxSheetVersions)
+	if err != nil {
+		return SheetVersion{}, errors.RedisFailure(err)
+	}
+	version.Version = id
+	return version, nil
+}
+
+// ListSheetVersions returns the saved versions of a sheet without their values, newest first.
+func ListSheetVersions(ctx context.Context, spreadsheetName, name string) ([]SheetVersion, error) {
+	versionsMap, err := HGetAll(ctx, sheetVersionsKey(spreadsheetName, name))
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	versions := make([]SheetVersion, 0, len(versionsMap))
+	for id, versionJSON := range versionsMap {
+		var version SheetVersion
+		if err := json.Unmarshal([]byte(versionJSON), &version); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		version.Version, _ = strconv.ParseInt(id, 10, 64)
+		version.Values = nil
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
+
+// GetSheetVersion returns a saved version of a sheet with its values.
+func GetSheetVersion(ctx context.Context, spreadsheetName, name string, version int64) (SheetVersion, error) {
+	versionJSON, err := HGet(ctx, sheetVersionsKey(spreadsheetName, name), strconv.FormatInt(version, 10))
+	if err != nil {
+		return SheetVersion{}, errors.RedisFailure(err)
+	}
+	if versionJSON == "" {
+		return SheetVersion{}, fmt.Errorf("%s:%s version %d: %w", spreadsheetName, name, version, ErrSheetVersionNotFound)
+	}
+
+	var dest SheetVersion
+	if err := json.Unmarshal([]byte(versionJSON), &dest); err != nil {
+		return SheetVersion{}, errors.JsonDecodeFailure(err)
+	}
+	dest.Version = version
+	return dest, nil
+}
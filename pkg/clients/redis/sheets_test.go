@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestSheetVersions(t *testing.T) {
+	ctx := context.Background()
+	UseStore(NewMemoryStore())
+
+	v1 := [][]interface{}{{"Name"}, {"01-snake-eater"}}
+	v2 := [][]interface{}{{"Name"}, {"02-proof-of-a-hero"}}
+	require.NoError(t, WriteSheet(ctx, "website_data", "Projects", "alice", v1))
+	require.NoError(t, WriteSheet(ctx, "website_data", "Projects", "bob", v2))
+
+	versions, err := ListSheetVersions(ctx, "website_data", "Projects")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, int64(2), versions[0].Version)
+	assert.Equal(t, "bob", versions[0].Author)
+	assert.Equal(t, 2, versions[0].Rows)
+	assert.Nil(t, versions[0].Values)
+	assert.Equal(t, "alice", versions[1].Author)
+
+	t.Run("rollback", func(t *testing.T) {
+		version, err := RollbackSheet(ctx, "website_data", "Projects", "carol", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), version.Version)
+		assert.Equal(t, int64(1), version.RollbackOf)
+
+		got, err := ReadSheet(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, v1, got)
+	})
+
+	t.Run("rollback/not found", func(t *testing.T) {
+		_, err := RollbackSheet(ctx, "website_data", "Projects", "carol", 42)
+		assert.True(t, errors.Is(err, ErrSheetVersionNotFound), "errors.Is(err, ErrSheetVersionNotFound)")
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		for i := 0; i < MaxSheetVersions+5; i++ {
+			require.NoError(t, WriteSheet(ctx, "website_data", "Parts", "alice", v1))
+		}
+		versions, err := ListSheetVersions(ctx, "website_data", "Parts")
+		require.NoError(t, err)
+		assert.Len(t, versions, MaxSheetVersions)
+		assert.Equal(t, int64(MaxSheetVersions+5), versions[0].Version)
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() { defer wg.Done(); assert.NoError(t, WriteSheet(ctx, "website_data", "Credits", "alice", v1)) }()
+		}
+		wg.Wait()
+
+		versions, err := ListSheetVersions(ctx, "website_data", "Credits")
+		require.NoError(t, err)
+		require.Len(t, versions, 10)
+		for i, version := range versions {
+			assert.Equal(t, int64(10-i), version.Version)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// Store is the storage backend behind this package.
+// Missing keys and fields read as empty values rather than errors, matching redis.
+type Store interface {
+	// Get returns the value of key.
+	Get(ctx context.Context, key string) (string, error)
+	// MGet returns the values of keys in order.
+	MGet(ctx context.Context, keys ...string) ([]string, error)
+	// Set sets key to value. A ttl of zero means the key never expires.
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	// Del deletes keys.
+	Del(ctx context.Context, keys ...string) error
+	// Keys returns all keys beginning with prefix.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+	// SetNX sets key to value if key does not exist, and reports whether it was set.
+	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+	// DelIfEqual deletes key if it holds value, and reports whether it was deleted.
+	DelIfEqual(ctx context.Context, key string, value string) (bool, error)
+
+	// Incr increments the counter at key and returns the new value.
+	Incr(ctx context.Context, key string) (int64, error)
+	// SaveVersion increments the counter at counterKey, saves version in the hash at versionsKey under the new count,
+	// sets valueKey to value, and deletes the version that is keep versions older, all in one step.
+	// It returns the new count.
+	SaveVersion(ctx context.Context, counterKey, versionsKey, valueKey string, version, value string, keep int64) (int64, error)
+
+	// HGet returns the value of field in the hash at key.
+	HGet(ctx context.Context, key string, field string) (string, error)
+	// HMGet returns the values of fields in the hash at key in order. Missing fields are empty.
+	HMGet(ctx context.Context, key string, fields ...string) ([]string, error)
+	// HGetAll returns all fields and values of the hash at key.
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// HSet sets fields in the hash at key.
+	HSet(ctx context.Context, key string, fields map[string]string) error
+	// HDel deletes fields from the hash at key.
+	HDel(ctx context.Context, key string, fields ...string) error
+
+	// RPush appends values to the list at key.
+	RPush(ctx context.Context, key string, values ...string) error
+	// LRange returns the elements of the list at key between start and stop inclusive.
+	// Negative indexes count from the end of the list.
+	LRange(ctx context.Context, key string, start, stop int) ([]string, error)
+	// LTrim keeps only the elements of the list at key between start and stop inclusive.
+	// Negative indexes count from the end of the list.
+	LTrim(ctx context.Context, key string, start, stop int) error
+
+	// ZAdd adds member to the sorted set at key with the given score.
+	ZAdd(ctx context.Context, key string, score float64, member string) error
+	// ZRangeByScore returns the members of the sorted set at key with scores between min and max,
+	// ordered by score. If max is less than min, the members are returned in reverse order.
+	ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error)
+}
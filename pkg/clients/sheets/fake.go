@@ -0,0 +1,73 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"sync"
+)
+
+// ErrRangeNotFound is returned by the fake client when a range was never written.
+var ErrRangeNotFound = errors.New("range not found")
+
+// FakeClient is a Client that keeps spreadsheets in process memory.
+// It is meant for development and tests.
+type FakeClient struct {
+	lock   sync.Mutex
+	ranges map[string][][]interface{}
+	Reads  int
+	Writes int
+}
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{ranges: make(map[string][][]interface{})}
+}
+
+func fakeKey(spreadsheetId, sheetRange string) string { return spreadsheetId + "!" + sheetRange }
+
+func (x *FakeClient) ReadSheet(_ context.Context, spreadsheetId string, readRange string) ([][]interface{}, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	x.Reads++
+	values, ok := x.ranges[fakeKey(spreadsheetId, readRange)]
+	if !ok {
+		return nil, fmt.Errorf("%s!%s: %w", spreadsheetId, readRange, ErrRangeNotFound)
+	}
+	return copyValues(values), nil
+}
+
+// WriteSheet replaces the values in writeRange.
+// Like google, blank cells and rows at the end are not read back.
+func (x *FakeClient) WriteSheet(_ context.Context, spreadsheetId string, writeRange string, values [][]interface{}) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	x.Writes++
+	x.ranges[fakeKey(spreadsheetId, writeRange)] = trimValues(copyValues(values))
+	return nil
+}
+
+func copyValues(values [][]interface{}) [][]interface{} {
+	if values == nil {
+		return nil
+	}
+	dest := make([][]interface{}, len(values))
+	for i := range values {
+		dest[i] = append([]interface{}(nil), values[i]...)
+	}
+	return dest
+}
+
+func trimValues(values [][]interface{}) [][]interface{} {
+	for i, row := range values {
+		for len(row) != 0 && isBlank(row[len(row)-1]) {
+			row = row[:len(row)-1]
+		}
+		values[i] = row
+	}
+	for len(values) != 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+	return values
+}
+
+func isBlank(cell interface{}) bool { return cell == nil || cell == "" }
@@ -7,11 +7,49 @@ import (
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
+	"sync"
 )
 
+const CredentialsRedisKey = "google_api_credentials"
+
+// Client reads and writes ranges of google spreadsheets.
+type Client interface {
+	// ReadSheet returns the values in readRange of the spreadsheet.
+	ReadSheet(ctx context.Context, spreadsheetId string, readRange string) ([][]interface{}, error)
+	// WriteSheet replaces the values in writeRange of the spreadsheet.
+	// Cells outside of values are left alone, and blank cells in values are cleared.
+	WriteSheet(ctx context.Context, spreadsheetId string, writeRange string, values [][]interface{}) error
+}
+
+var client Client = googleClient{}
+var clientLock sync.Mutex
+
+// UseClient replaces the client used by ReadSheet and WriteSheet.
+func UseClient(c Client) {
+	clientLock.Lock()
+	defer clientLock.Unlock()
+	client = c
+}
+
+func getClient() Client {
+	clientLock.Lock()
+	defer clientLock.Unlock()
+	return client
+}
+
 func ReadSheet(ctx context.Context, spreadsheetName string, sheetName string) ([][]interface{}, error) {
-	var credentialsJSON string
-	err := redis.Do(ctx, redis.Cmd(&credentialsJSON, "GET", "google_api_credentials"))
+	return getClient().ReadSheet(ctx, spreadsheetName, sheetName)
+}
+
+func WriteSheet(ctx context.Context, spreadsheetName string, name string, values [][]interface{}) error {
+	return getClient().WriteSheet(ctx, spreadsheetName, name, values)
+}
+
+// googleClient talks to the google sheets api with the credentials stored in redis.
+type googleClient struct{}
+
+func (googleClient) service(ctx context.Context) (*sheets.Service, error) {
+	credentialsJSON, err := redis.Get(ctx, CredentialsRedisKey)
 	if err != nil {
 		logger.RedisFailure(ctx, err)
 	}
@@ -20,22 +58,32 @@ func ReadSheet(ctx context.Context, spreadsheetName string, sheetName string) ([
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve Sheets client: %w", err)
 	}
+	return srv, nil
+}
+
+func (x googleClient) ReadSheet(ctx context.Context, spreadsheetId string, readRange string) ([][]interface{}, error) {
+	srv, err := x.service(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetName, sheetName).Do()
+	resp, err := srv.Spreadsheets.Values.Get(spreadsheetId, readRange).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve data from sheet: %w", err)
 	}
 	return resp.Values, nil
 }
 
-func WriteSheet(ctx context.Context, spreadsheetName string, name string, values [][]interface{}) error {
-	srv, err := sheets.NewService(ctx)
+func (x googleClient) WriteSheet(ctx context.Context, spreadsheetId string, writeRange string, values [][]interface{}) error {
+	srv, err := x.service(ctx)
 	if err != nil {
-		return fmt.Errorf("sheets.NewService(): %w", err)
+		return err
 	}
+
 	_, err = srv.Spreadsheets.Values.
-		Update(spreadsheetName, name, &sheets.ValueRange{Values: values, MajorDimension: "ROWS"}).
+		Update(spreadsheetId, writeRange, &sheets.ValueRange{Values: values, MajorDimension: "ROWS"}).
 		ValueInputOption("USER_ENTERED").
+		Context(ctx).
 		Do()
 	if err != nil {
 		return fmt.Errorf("sheets.Values.Update() failed: %w", err)
@@ -63,7 +63,7 @@ func NewRequest(method, url string, body interface{}) (*http.Request, error) {
 	if err != nil {
 		return nil, errors.NewRequestFailure(err)
 	}
-	req.Header.Set("Authorization", "Bearer "+config.Config.VVGO.ClientToken)
+	req.Header.Set("Authorization", "Bearer "+config.Config().VVGO.ClientToken)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "vvgo-client")
 	return req, nil
@@ -2,7 +2,9 @@ package when2meet
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/dispatcher"
 	"github.com/virtual-vgo/vvgo/pkg/errors"
 	"net/http"
 	"net/url"
@@ -14,20 +16,21 @@ import (
 const TimeZone = "America/Los_Angeles"
 
 var Endpoint = "https://www.when2meet.com"
+
+var ErrInvalidDate = errors.New("invalid date")
 var locationReg = regexp.MustCompile(`<body onload="window\.location='(\/\?.*)'">`)
 
 func rangeDates(startDate, endDate string) ([]string, error) {
 	layout := "2006-01-02"
 	start, err := time.Parse(layout, startDate)
 	if err != nil {
-		return nil, fmt.Errorf("time.Parse() failed: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDate, err)
 	}
 	end, err := time.Parse(layout, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("time.Parse() failed: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDate, err)
 	}
 
-	fmt.Println(start, end)
 	var dates []string
 	for ; start.Before(end); start = start.Add(24 * 3600 * time.Second) {
 		dates = append(dates, start.Format(layout))
@@ -36,7 +39,7 @@ func rangeDates(startDate, endDate string) ([]string, error) {
 	return dates, nil
 }
 
-func CreateEvent(name, startDate, endDate string) (string, error) {
+func CreateEvent(ctx context.Context, name, startDate, endDate string) (string, error) {
 	dates, err := rangeDates(startDate, endDate)
 	if err != nil {
 		return "", err
@@ -49,10 +52,17 @@ func CreateEvent(name, startDate, endDate string) (string, error) {
 	data.Set("TimeZone", TimeZone)
 	data.Set("NoEarlierThan", "14")
 	data.Set("NoLaterThan", "0")
-	resp, err := http.PostForm(Endpoint+"/SaveNewEvent.php", data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, Endpoint+"/SaveNewEvent.php", strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("http.NewRequestWithContext() failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := dispatcher.Do(req)
 	if err != nil {
 		return "", errors.HttpDoFailure(err)
-	} else if resp.StatusCode != http.StatusOK {
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
 		return "", errors.Non200StatusCode()
 	}
 
@@ -1,6 +1,7 @@
 package when2meet
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -12,7 +13,7 @@ func TestRangeDates(t *testing.T) {
 }
 
 func TestGetWhen2MeetURL(t *testing.T) {
-	url, err := CreateEvent("cheesus", "2030-02-09", "2030-02-11")
+	url, err := CreateEvent(context.Background(), "cheesus", "2030-02-09", "2030-02-11")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, url)
 }
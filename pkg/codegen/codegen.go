@@ -20,6 +20,7 @@ var MethodFailures = []struct {
 	{Method: `ListPartsFailure`, Failed: `models.ListParts`},
 	{Method: `ListProjectsFailure`, Failed: `models.ListProjects`},
 	{Method: `ListSubmissionsFailure`, Failed: `models.ListSubmissions`},
+	{Method: `ListSubmissionUploadsFailure`, Failed: `models.ListSubmissionUploads`},
 	{Method: `NewCookieFailure`, Failed: `login.NewCookie`},
 	{Method: `NewRequestFailure`, Failed: `http.NewRequest`},
 }
@@ -2,19 +2,71 @@ package config
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-var Config struct {
+// current holds the Configuration. It is replaced as a whole when the configuration is reloaded.
+var current atomic.Value
+
+// validators check a configuration read from the environment before it replaces the current one.
+var validators []func(config Configuration) error
+var validatorsLock sync.Mutex
+
+// AddValidator adds a check to the configuration read from the environment.
+// Packages that give meaning to configuration values, like the vvgo role names in RoleMap, add checks for them.
+func AddValidator(validate func(config Configuration) error) {
+	validatorsLock.Lock()
+	defer validatorsLock.Unlock()
+	validators = append(validators, validate)
+}
+
+func validate(config Configuration) error {
+	validatorsLock.Lock()
+	defer validatorsLock.Unlock()
+	for _, validate := range validators {
+		if err := validate(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateLock serializes updates, so that concurrent updates do not lose each other's changes.
+var updateLock sync.Mutex
+
+// Config returns the current configuration.
+// It is safe to call while the configuration is reloaded.
+func Config() Configuration {
+	config, _ := current.Load().(Configuration)
+	return config
+}
+
+// Update changes a copy of the current configuration and then replaces the current configuration with it.
+func Update(change func(config *Configuration)) {
+	updateLock.Lock()
+	defer updateLock.Unlock()
+	config := Config()
+	change(&config)
+	current.Store(config)
+}
+
+type Configuration struct {
 	Development bool
 
 	VVGO struct {
 		ListenAddress      string `json:"listen_address" envconfig:"listen_address" default:"0.0.0.0:8080"`
 		ServerUrl          string `json:"server_url" envconfig:"server_url" default:"https://vvgo.org"`
 		DistroBucket       string `json:"distro_bucket" envconfig:"distro_bucket" default:"vvgo-distro"`
+		SubmissionsBucket  string `json:"submissions_bucket" envconfig:"submissions_bucket" default:"vvgo-submissions"`
+		ArrangementsBucket string `json:"arrangements_bucket" envconfig:"arrangements_bucket" default:"vvgo-arrangements"`
 		MemberPasswordHash string `json:"member_password_hash" envconfig:"member_password_hash"`
 		ClientToken        string `json:"vvgo_client_token" envconfig:"vvgo_client_token"`
 	} `json:"vvgo" envconfig:"vvgo"`
@@ -31,6 +83,13 @@ var Config struct {
 		// This should only be overwritten for testing.
 		Endpoint string `json:"endpoint" envconfig:"endpoint" default:"https://discord.com/api/v8"`
 
+		// GatewayEndpoint is the websocket gateway to connect to.
+		// This should only be overwritten for testing.
+		GatewayEndpoint string `json:"gateway_endpoint" envconfig:"gateway_endpoint" default:"wss://gateway.discord.gg/?v=8&encoding=json"`
+
+		// EnableGateway connects the bot to the gateway to receive guild events.
+		EnableGateway bool `json:"enable_gateway" envconfig:"enable_gateway" default:"false"`
+
 		// BotAuthenticationToken is used for making queries about our discord guild.
 		// This is found in the bot tab for the discord app.
 		BotAuthenticationToken string `json:"bot_authentication_token" envconfig:"bot_authentication_token"`
@@ -38,9 +97,38 @@ var Config struct {
 		// OAuthClientSecret is the secret used in oauth requests.
 		// This is found in the oauth2 tab for the discord app.
 		OAuthClientSecret string `json:"oauth_client_secret" envconfig:"oauth_client_secret"`
+
+		// GuildID is the discord server we serve.
+		GuildID string `json:"guild_id" envconfig:"guild_id" default:"690626216637497425"`
+
+		// RoleMap maps discord role ids in the guild to vvgo roles.
+		// The format is `role_id:vvgo_role,role_id:vvgo_role`.
+		RoleMap map[string]string `json:"role_map" envconfig:"role_map" default:"690626333062987866:vvgo-leader,746434659252174971:vvgo-teams,690636730281230396:vvgo-member"`
+
+		// DeploymentsChannelID is the channel for deployment announcements.
+		DeploymentsChannelID string `json:"deployments_channel_id" envconfig:"deployments_channel_id" default:"692441475740467250"`
+
+		// TimezonesChannelID is the channel for the timezone trumpet.
+		TimezonesChannelID string `json:"timezones_channel_id" envconfig:"timezones_channel_id" default:"983552216226992159"`
+
+		// ProductionChannelID is the production team channel for the daily submissions digest.
+		// An empty id disables the digest.
+		ProductionChannelID string `json:"production_channel_id" envconfig:"production_channel_id"`
+
+		// SandboxChannelID replaces the other channels in development.
+		SandboxChannelID string `json:"sandbox_channel_id" envconfig:"sandbox_channel_id" default:"700792848253059142"`
+
+		// MemberSyncInterval is the time between syncs of the guild member directory. Zero disables the scheduled sync.
+		MemberSyncInterval time.Duration `json:"member_sync_interval" envconfig:"member_sync_interval" default:"6h"`
+
+		// IgnoreChannelIDs are hidden from the channels api.
+		IgnoreChannelIDs []string `json:"ignore_channel_ids" envconfig:"ignore_channel_ids" default:"690626217594060892,817084492635701298,817084789139308544"`
 	} `json:"discord" envconfig:"discord"`
 
 	Redis struct {
+		// Backend selects the storage backend, either "redis" or "memory".
+		// The memory backend does not persist anything and is meant for development and testing.
+		Backend  string `json:"backend" envconfig:"BACKEND" default:"redis"`
 		Address  string `json:"address" envconfig:"address" default:"localhost:6379"`
 		UseDB    int    `json:"use_db" envconfig:"USE_DB" default:"0"`
 		User     string `json:"user" envconfig:"USER" default:"default"`
@@ -49,6 +137,43 @@ var Config struct {
 		PoolSize int    `json:"pool_size" envconfig:"POOL_SIZE" default:"10"`
 	} `json:"redis" envconfig:"redis"`
 
+	Sheets struct {
+		// SpreadsheetIDs maps spreadsheet names in redis to google spreadsheet ids.
+		// The format is `name:spreadsheet_id,name:spreadsheet_id`.
+		SpreadsheetIDs map[string]string `json:"spreadsheet_ids" envconfig:"spreadsheet_ids"`
+
+		// SyncSheets are the sheets that are pulled from google.
+		// The format is `spreadsheet_name:sheet_name,spreadsheet_name:sheet_name`.
+		SyncSheets []string `json:"sync_sheets" envconfig:"sync_sheets" default:"website_data:Credits,website_data:Projects,website_data:Parts,website_data:Leaders,website_data:Highlights,website_data:Roster"`
+
+		// SyncInterval is the time between pulls. Zero disables the scheduled pull.
+		SyncInterval time.Duration `json:"sync_interval" envconfig:"sync_interval" default:"0"`
+	} `json:"sheets" envconfig:"sheets"`
+
+	Deadlines struct {
+		// Timezone is used for submission deadlines that do not name a timezone.
+		Timezone string `json:"timezone" envconfig:"timezone" default:"Pacific/Honolulu"`
+
+		// ReminderChannelIDs are the discord channels where deadline reminders are posted.
+		// Reminders are posted to the sandbox channel in development.
+		ReminderChannelIDs []string `json:"reminder_channel_ids" envconfig:"reminder_channel_ids"`
+
+		// Reminders are how long before a deadline reminders are posted.
+		Reminders []time.Duration `json:"reminders" envconfig:"reminders" default:"168h,24h,1h"`
+	} `json:"deadlines" envconfig:"deadlines"`
+
+	Dispatcher struct {
+		// Concurrency is how many outbound requests are sent at once.
+		Concurrency int `json:"concurrency" envconfig:"concurrency" default:"8"`
+
+		// QueueSize is how many outbound requests can wait to be sent.
+		QueueSize int `json:"queue_size" envconfig:"queue_size" default:"1024"`
+
+		// HostRate and HostBurst limit the requests per second to each host.
+		HostRate  float64 `json:"host_rate" envconfig:"host_rate" default:"20"`
+		HostBurst int     `json:"host_burst" envconfig:"host_burst" default:"20"`
+	} `json:"dispatcher" envconfig:"dispatcher"`
+
 	Cloudflare struct {
 		ApiKey string `json:"api_key" envconfig:"API_KEY"`
 		ZoneId string `json:"zone_id" envconfig:"ZONE_ID"`
@@ -57,23 +182,53 @@ var Config struct {
 
 func init() { ProcessEnv() }
 
-func ProcessEnv() { envconfig.MustProcess("", &Config) }
+func ProcessEnv() {
+	var config Configuration
+	envconfig.MustProcess("", &config)
+	if err := validate(config); err != nil {
+		logrus.WithError(err).Fatal("invalid configuration")
+	}
+	Update(func(current *Configuration) { *current = config })
+}
 
 func ProcessEnvFile(envFile string) {
 	defer ProcessEnv()
+	if err := setEnvFromFile(envFile); err != nil {
+		logrus.WithField("file_name", envFile).WithError(err).Error("setEnvFromFile() failed")
+		logrus.Fatal("cannot read environment file")
+	}
+}
+
+// Reload reads the configuration again from the environment and envFile, if it is not empty.
+// The current configuration is kept if the new one cannot be read.
+func Reload(envFile string) error {
+	if envFile != "" {
+		if err := setEnvFromFile(envFile); err != nil {
+			return err
+		}
+	}
 
+	var newConfig Configuration
+	if err := envconfig.Process("", &newConfig); err != nil {
+		return fmt.Errorf("envconfig.Process() failed: %w", err)
+	}
+	if err := validate(newConfig); err != nil {
+		return err
+	}
+	Update(func(config *Configuration) { *config = newConfig })
+	return nil
+}
+
+func setEnvFromFile(envFile string) error {
 	file, err := os.Open(envFile)
 	if err != nil {
-		logrus.WithField("file_name", envFile).WithError(err).Error("os.Open() failed")
-		logrus.Fatal("cannot read environment file")
-		return
+		return fmt.Errorf("os.Open() failed: %w", err)
 	}
+	defer file.Close()
 
 	var buf bytes.Buffer
 	if _, err = buf.ReadFrom(file); err != nil {
-		logrus.WithField("file_name", envFile).WithError(err).Error("file.Read() failed")
-		logrus.Fatal("cannot read environment file")
-		return
+		return fmt.Errorf("file.Read() failed: %w", err)
 	}
 
 	for _, line := range strings.Split(buf.String(), "\n") {
@@ -84,14 +239,13 @@ func ProcessEnvFile(envFile string) {
 
 		fields := strings.SplitN(line, "=", 2)
 		if len(fields) != 2 {
-			logrus.Fatal("cannot parse environment file")
-			return
+			return errors.New("cannot parse environment file")
 		}
 
 		key, val := fields[0], fields[1]
 		if err = os.Setenv(key, val); err != nil {
-			logrus.WithField("file_name", envFile).WithError(err).Error("os.Setenv() failed")
-			return
+			return fmt.Errorf("os.Setenv() failed: %w", err)
 		}
 	}
+	return nil
 }
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	defer func(config Configuration) { Update(func(x *Configuration) { *x = config }) }(Config())
+	require.NoError(t, os.Setenv("VVGO_SERVER_URL", "https://example.com"))
+	defer os.Unsetenv("VVGO_SERVER_URL")
+
+	// Readers and reloads run at once, as they do when the server gets a SIGHUP.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); _ = Config().VVGO.ServerUrl }()
+		go func() { defer wg.Done(); assert.NoError(t, Reload("")) }()
+	}
+	wg.Wait()
+	assert.Equal(t, "https://example.com", Config().VVGO.ServerUrl)
+}
+
+func TestReload_Invalid(t *testing.T) {
+	defer func(config Configuration) { Update(func(x *Configuration) { *x = config }) }(Config())
+	defer func(want []func(Configuration) error) { validators = want }(validators)
+	require.NoError(t, os.Setenv("VVGO_SERVER_URL", "https://example.com"))
+	defer os.Unsetenv("VVGO_SERVER_URL")
+
+	before := Config().VVGO.ServerUrl
+	AddValidator(func(config Configuration) error { return errors.New("invalid") })
+	assert.EqualError(t, Reload(""), "invalid")
+	assert.Equal(t, before, Config().VVGO.ServerUrl, "the current configuration is kept")
+}
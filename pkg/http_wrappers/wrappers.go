@@ -95,6 +95,13 @@ func DoRequest(r *http.Request) (*http.Response, error) {
 
 	resp, respErr = http.DefaultClient.Do(r)
 	requestMetrics := traces.NewHttpRequestMetrics(r)
+	if respErr != nil {
+		if spanOk {
+			redis.WriteSpan(span.Finish().WithHttpRequestMetrics(requestMetrics).WithError(respErr))
+		}
+		logger.WithFields(requestMetrics.Fields()).WithError(respErr).Warn("http client: request failed")
+		return nil, respErr
+	}
 	responseMetrics := traces.NewHttpResponseMetrics(resp.StatusCode, resp.ContentLength)
 	if spanOk {
 		redis.WriteSpan(
@@ -3,9 +3,11 @@ package models
 import (
 	"encoding/json"
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
 	"github.com/virtual-vgo/vvgo/pkg/models/mixtape"
 	"github.com/virtual-vgo/vvgo/pkg/models/traces"
 	"github.com/virtual-vgo/vvgo/pkg/version"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
 )
 
 type ApiResponseStatus string
@@ -15,28 +17,55 @@ const StatusFound ApiResponseStatus = "found"
 const StatusError ApiResponseStatus = "error"
 
 type ApiResponse struct {
-	Status          ApiResponseStatus
-	Location        string                `json:"Location,omitempty"`
-	Version         *version.Version      `json:"Version,omitempty"`
-	Error           *ApiError             `json:"Error,omitempty"`
-	Projects        []Project             `json:"Projects,omitempty"`
-	Parts           []Part                `json:"Parts,omitempty"`
-	Sessions        []Identity            `json:"Sessions,omitempty"`
-	Spreadsheet     *Spreadsheet          `json:"Spreadsheet,omitempty"`
-	Credits         []Credit              `json:"credits,omitempty"`
-	Dataset         []map[string]string   `json:"Dataset,omitempty"`
-	Identity        *Identity             `json:"Identity,omitempty"`
-	GuildMembers    []discord.GuildMember `json:"GuildMembers,omitempty"`
-	Channels        []discord.Channel     `json:"channels,omitempty"`
-	MixtapeProjects []mixtape.Project     `json:"MixtapeProjects,omitempty"`
-	MixtapeProject  *mixtape.Project      `json:"MixtapeProject,omitempty"`
-	WorkflowResult  []WorkflowTaskResult  `json:"WorkflowResult,omitempty"`
-	CreditsTable    CreditsTable          `json:"CreditsTable,omitempty"`
-	Ballot          ArrangementsBallot    `json:"Ballot,omitempty"`
-	OAuthRedirect   *OAuthRedirect        `json:"OAuthRedirect,omitempty"`
-	CreditsPasta    *CreditsPasta         `json:"CreditsPasta,omitempty"`
-	Spans           []traces.Span         `json:"Spans,omitempty"`
-	Waterfalls      []traces.Waterfall    `json:"Waterfalls,omitempty"`
+	Status                   ApiResponseStatus
+	Location                 string                     `json:"Location,omitempty"`
+	Version                  *version.Version           `json:"Version,omitempty"`
+	Error                    *ApiError                  `json:"Error,omitempty"`
+	Projects                 []Project                  `json:"Projects,omitempty"`
+	Parts                    []Part                     `json:"Parts,omitempty"`
+	Sessions                 []Identity                 `json:"Sessions,omitempty"`
+	Spreadsheet              *Spreadsheet               `json:"Spreadsheet,omitempty"`
+	Credits                  []Credit                   `json:"credits,omitempty"`
+	Dataset                  []map[string]string        `json:"Dataset,omitempty"`
+	Identity                 *Identity                  `json:"Identity,omitempty"`
+	GuildMembers             []discord.GuildMember      `json:"GuildMembers,omitempty"`
+	Channels                 []discord.Channel          `json:"channels,omitempty"`
+	MixtapeProjects          []mixtape.Project          `json:"MixtapeProjects,omitempty"`
+	MixtapeProject           *mixtape.Project           `json:"MixtapeProject,omitempty"`
+	WorkflowResult           []WorkflowTaskResult       `json:"WorkflowResult,omitempty"`
+	CreditsTable             CreditsTable               `json:"CreditsTable,omitempty"`
+	Ballot                   ArrangementsBallot         `json:"Ballot,omitempty"`
+	OAuthRedirect            *OAuthRedirect             `json:"OAuthRedirect,omitempty"`
+	CreditsPasta             *CreditsPasta              `json:"CreditsPasta,omitempty"`
+	CreditsDraft             *CreditsDraft              `json:"CreditsDraft,omitempty"`
+	Spans                    []traces.Span              `json:"Spans,omitempty"`
+	Waterfalls               []traces.Waterfall         `json:"Waterfalls,omitempty"`
+	Submissions              []SubmissionUpload         `json:"Submissions,omitempty"`
+	Submission               *SubmissionUpload          `json:"Submission,omitempty"`
+	UploadUrl                string                     `json:"UploadUrl,omitempty"`
+	SheetSyncs               []SheetSync                `json:"SheetSyncs,omitempty"`
+	SheetVersions            []redis.SheetVersion       `json:"SheetVersions,omitempty"`
+	SheetVersion             *redis.SheetVersion        `json:"SheetVersion,omitempty"`
+	SheetDiff                SheetDiff                  `json:"SheetDiff,omitempty"`
+	SheetValidations         SheetValidations           `json:"SheetValidations,omitempty"`
+	ProjectLifecycle         *ProjectLifecycle          `json:"ProjectLifecycle,omitempty"`
+	CronJobs                 []CronJob                  `json:"CronJobs,omitempty"`
+	DiscordRateLimits        []discord.RateLimitMetrics `json:"DiscordRateLimits,omitempty"`
+	GuildMemberRoleChanges   []GuildMemberRoleChange    `json:"GuildMemberRoleChanges,omitempty"`
+	GuildMemberSync          *GuildMemberSync           `json:"GuildMemberSync,omitempty"`
+	AuditEntries             []AuditEntry               `json:"AuditEntries,omitempty"`
+	NameCorrections          []NameCorrection           `json:"NameCorrections,omitempty"`
+	NameCorrection           *NameCorrection            `json:"NameCorrection,omitempty"`
+	Elections                []voting.Election          `json:"Elections,omitempty"`
+	Election                 *voting.Election           `json:"Election,omitempty"`
+	ElectionResult           *voting.Result             `json:"ElectionResult,omitempty"`
+	ElectionBallots          []ElectionBallot           `json:"ElectionBallots,omitempty"`
+	Arrangements             []ArrangementSubmission    `json:"Arrangements,omitempty"`
+	Arrangement              *ArrangementSubmission     `json:"Arrangement,omitempty"`
+	UploadUrls               map[string]string          `json:"UploadUrls,omitempty"`
+	InstrumentationTemplates []InstrumentationTemplate  `json:"InstrumentationTemplates,omitempty"`
+	InstrumentationReport    *InstrumentationReport     `json:"InstrumentationReport,omitempty"`
+	SubmissionTracking       *SubmissionTracking        `json:"SubmissionTracking,omitempty"`
 }
 
 type ApiError struct {
@@ -53,6 +82,13 @@ type CreditsPasta struct {
 
 type ArrangementsBallot []string
 
+// ElectionBallot is a ballot with the nick of the member who cast it.
+type ElectionBallot struct {
+	VoterID string
+	Nick    string
+	Ballot  voting.Ballot
+}
+
 type OAuthRedirect struct {
 	DiscordURL string
 	State      string
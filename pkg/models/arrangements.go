@@ -0,0 +1,503 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ArrangementSubmissionsRedisKey = "arrangements:submissions"
+
+// arrangementsLockKey serializes changes to submissions and the season ballots they are on.
+const arrangementsLockKey = ArrangementSubmissionsRedisKey + ":lock"
+
+// LegacyArrangementsSeason is the last season voted on before arrangements ballots were elections.
+// Its choices and ballots are stored under arrangements:season2.
+const LegacyArrangementsSeason = "season2"
+
+var ErrArrangementNotFound = errors.New("arrangement not found")
+var ErrArrangementDeclined = errors.New("arrangement was declined")
+
+// ArrangementScale is the kind of ensemble an arrangement is written for.
+// The score orders for each scale are described in scoreOrder.md.
+type ArrangementScale string
+
+const (
+	ArrangementScaleFull             ArrangementScale = "full"
+	ArrangementScaleBigBand          ArrangementScale = "big-band"
+	ArrangementScaleWindEnsemble     ArrangementScale = "wind-ensemble"
+	ArrangementScaleEverythingWeHave ArrangementScale = "everything-we-have"
+	ArrangementScaleOther            ArrangementScale = "other"
+)
+
+var ArrangementScales = []ArrangementScale{ArrangementScaleFull, ArrangementScaleBigBand,
+	ArrangementScaleWindEnsemble, ArrangementScaleEverythingWeHave, ArrangementScaleOther}
+
+// ParseArrangementScale reads a scale written by hand, so that "Big Band" is ArrangementScaleBigBand.
+func ParseArrangementScale(value string) ArrangementScale {
+	return ArrangementScale(strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(value, "_", " "))), "-"))
+}
+
+func (x ArrangementScale) IsValid() bool {
+	for _, scale := range ArrangementScales {
+		if x == scale {
+			return true
+		}
+	}
+	return false
+}
+
+// ArrangementFileKind is what an uploaded file is for.
+type ArrangementFileKind string
+
+const (
+	ArrangementFileMockup   ArrangementFileKind = "mockup"
+	ArrangementFileScore    ArrangementFileKind = "score"
+	ArrangementFileNotation ArrangementFileKind = "notation"
+)
+
+var ArrangementFileKinds = []ArrangementFileKind{ArrangementFileMockup, ArrangementFileScore, ArrangementFileNotation}
+
+func (x ArrangementFileKind) IsValid() bool {
+	for _, kind := range ArrangementFileKinds {
+		if x == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type ArrangementStatus string
+
+const (
+	// ArrangementStatusSubmitted arrangements are waiting for review, or kept in consideration for a later season.
+	ArrangementStatusSubmitted ArrangementStatus = "submitted"
+	ArrangementStatusOnBallot  ArrangementStatus = "on-ballot"
+	ArrangementStatusDeclined  ArrangementStatus = "declined"
+)
+
+// ArrangementSubmission is an arrangement submitted for consideration as vvgo repertoire.
+type ArrangementSubmission struct {
+	ID                string
+	Title             string
+	Composer          string
+	Arranger          string
+	ArrangerDiscordID string
+	Scale             ArrangementScale
+	// Instrumentation lists the instruments the arrangement is written for.
+	Instrumentation string
+	Notes           string `json:"Notes,omitempty"`
+	Files           []ArrangementFile
+	Reviews         []ArrangementReview `json:"Reviews,omitempty"`
+	Status          ArrangementStatus
+	// Season is the season whose ballot the arrangement is on.
+	Season      string `json:"Season,omitempty"`
+	SubmittedAt time.Time
+}
+
+// ArrangementFile is a file uploaded to the arrangements bucket.
+type ArrangementFile struct {
+	Kind      ArrangementFileKind
+	ObjectKey string
+	FileName  string
+	FileSize  int64
+	Checksum  string // hex encoded sha256 of the file, as reported by the uploader
+	// Uploaded is set once the uploaded object was checked against FileSize and Checksum.
+	Uploaded bool `json:"Uploaded,omitempty"`
+}
+
+// VerifyObject reads the uploaded object and reports how it differs from the size and checksum the arranger reported.
+// It returns an empty string if the object matches.
+func (x ArrangementFile) VerifyObject(size int64, object io.Reader) (string, error) {
+	return verifyObject(x.FileSize, x.Checksum, size, object)
+}
+
+// ArrangementReview is a Rep-Prep member's notes on an arrangement.
+type ArrangementReview struct {
+	ReviewerDiscordID string
+	Notes             string
+	// Feasibility rates how technically and logistically feasible the arrangement is for vvgo, from 1 to 5.
+	Feasibility int
+	At          time.Time
+}
+
+const MinFeasibility, MaxFeasibility = 1, 5
+
+type ArrangementSubmissions []ArrangementSubmission
+
+// NewArrangementSubmission returns a new submission with a unique id.
+func NewArrangementSubmission(title, composer, arranger, arrangerDiscordID string, scale ArrangementScale, instrumentation string) ArrangementSubmission {
+	now := time.Now().UTC()
+	return ArrangementSubmission{
+		ID:                objectKeySafe(title) + "-" + strconv.FormatInt(now.UnixNano(), 36),
+		Title:             title,
+		Composer:          composer,
+		Arranger:          arranger,
+		ArrangerDiscordID: arrangerDiscordID,
+		Scale:             scale,
+		Instrumentation:   instrumentation,
+		Status:            ArrangementStatusSubmitted,
+		SubmittedAt:       now,
+	}
+}
+
+// AddFile adds a file with a unique object key to the submission.
+func (x *ArrangementSubmission) AddFile(kind ArrangementFileKind, fileName string, fileSize int64, checksum string) ArrangementFile {
+	file := ArrangementFile{
+		Kind: kind,
+		ObjectKey: fmt.Sprintf("%s/%s-%d-%s",
+			x.ID, kind, time.Now().UnixNano(), objectKeySafe(fileName)),
+		FileName: fileName,
+		FileSize: fileSize,
+		Checksum: strings.ToLower(checksum),
+	}
+	x.Files = append(x.Files, file)
+	return file
+}
+
+// AddReview adds the review, replacing any review by the same reviewer.
+func (x *ArrangementSubmission) AddReview(review ArrangementReview) {
+	for i := range x.Reviews {
+		if x.Reviews[i].ReviewerDiscordID == review.ReviewerDiscordID {
+			x.Reviews[i] = review
+			return
+		}
+	}
+	x.Reviews = append(x.Reviews, review)
+}
+
+// Feasibility is the average feasibility rating of the reviews, or zero if there are none.
+func (x ArrangementSubmission) Feasibility() float64 {
+	if len(x.Reviews) == 0 {
+		return 0
+	}
+	var total int
+	for _, review := range x.Reviews {
+		total += review.Feasibility
+	}
+	return float64(total) / float64(len(x.Reviews))
+}
+
+// BallotChoice is the arrangement's choice on a ballot.
+// Choices are submission ids, so arrangements with the same title and arranger are different choices.
+func (x ArrangementSubmission) BallotChoice() string { return x.ID }
+
+// BallotLabel is how the arrangement is shown on a ballot.
+func (x ArrangementSubmission) BallotLabel() string {
+	return fmt.Sprintf("%s (arr. %s)", x.Title, x.Arranger)
+}
+
+// GetFile returns the submission's file with the object key.
+func (x ArrangementSubmission) GetFile(objectKey string) (ArrangementFile, bool) {
+	for _, file := range x.Files {
+		if file.ObjectKey == objectKey {
+			return file, true
+		}
+	}
+	return ArrangementFile{}, false
+}
+
+// HasObject is true if the object key is one of the submission's files.
+func (x ArrangementSubmission) HasObject(objectKey string) bool {
+	for _, file := range x.Files {
+		if file.ObjectKey == objectKey {
+			return true
+		}
+	}
+	return false
+}
+
+// ArrangementsElectionID is the id of the election for a season's arrangements ballot.
+func ArrangementsElectionID(season string) string { return "arrangements-" + season }
+
+func newArrangementsElection(season string) voting.Election {
+	return voting.Election{
+		ID:        ArrangementsElectionID(season),
+		Title:     "Arrangements for " + season,
+		Method:    voting.MethodInstantRunoff,
+		VoterRole: RoleVVGOExecutiveDirector.String(),
+	}
+}
+
+// MigrateLegacyArrangementsBallots copies the legacy season's ballots into the season's election.
+// The election is created with the legacy choices if it does not exist yet.
+// Ballots already in the election are kept, so it is safe to run on every start.
+// Ballots that cannot be read or are invalid are logged and skipped.
+// It returns how many ballots were copied and how many were skipped.
+func MigrateLegacyArrangementsBallots(ctx context.Context) (int, int, error) {
+	legacyKey := "arrangements:" + LegacyArrangementsSeason
+	ballotsMap, err := redis.HGetAll(ctx, legacyKey+":ballots")
+	if err != nil {
+		return 0, 0, errors.RedisFailure(err)
+	}
+	if len(ballotsMap) == 0 {
+		return 0, 0, nil
+	}
+	choices, err := redis.LRange(ctx, legacyKey+":submissions", 0, -1)
+	if err != nil {
+		return 0, 0, errors.RedisFailure(err)
+	}
+
+	skipped := make(map[string]error)
+	ballots := make(map[string]voting.Ballot, len(ballotsMap))
+	for voterID, ballotJSON := range ballotsMap {
+		var ballot voting.Ballot
+		if err := json.Unmarshal([]byte(ballotJSON), &ballot); err != nil {
+			skipped[voterID] = errors.JsonDecodeFailure(err)
+			continue
+		}
+		ballots[voterID] = ballot
+	}
+
+	election, err := voting.GetElection(ctx, ArrangementsElectionID(LegacyArrangementsSeason))
+	switch {
+	case errors.Is(err, voting.ErrElectionNotFound):
+		election = newArrangementsElection(LegacyArrangementsSeason)
+	case err != nil:
+		return 0, 0, err
+	}
+	onBallot := make(map[string]bool, len(election.Choices))
+	for _, choice := range election.Choices {
+		onBallot[choice] = true
+	}
+	addChoice := func(choice string) {
+		if !onBallot[choice] {
+			election.Choices = append(election.Choices, choice)
+			onBallot[choice] = true
+		}
+	}
+	for _, choice := range choices {
+		addChoice(choice)
+	}
+	for _, ballot := range ballots {
+		for _, choice := range ballot {
+			addChoice(choice)
+		}
+	}
+	if err := voting.SaveElection(ctx, election); err != nil {
+		return 0, 0, err
+	}
+	migrated, invalid, err := voting.ImportBallots(ctx, election, ballots)
+	if err != nil {
+		return 0, 0, err
+	}
+	for voterID, err := range invalid {
+		skipped[voterID] = err
+	}
+	for voterID, err := range skipped {
+		logger.WithError(err).WithField("voter_id", voterID).
+			Warn("models.MigrateLegacyArrangementsBallots(): skipped ballot")
+	}
+	return migrated, len(skipped), nil
+}
+
+// ListArrangementSubmissions returns every submission, oldest first.
+func ListArrangementSubmissions(ctx context.Context) (ArrangementSubmissions, error) {
+	submissionsMap, err := redis.HGetAll(ctx, ArrangementSubmissionsRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	submissions := make(ArrangementSubmissions, 0, len(submissionsMap))
+	for _, submissionJSON := range submissionsMap {
+		var submission ArrangementSubmission
+		if err := json.Unmarshal([]byte(submissionJSON), &submission); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		submissions = append(submissions, submission)
+	}
+	return submissions.Sort(), nil
+}
+
+func GetArrangementSubmission(ctx context.Context, id string) (ArrangementSubmission, error) {
+	submissionJSON, err := redis.HGet(ctx, ArrangementSubmissionsRedisKey, id)
+	switch {
+	case err != nil:
+		return ArrangementSubmission{}, errors.RedisFailure(err)
+	case submissionJSON == "":
+		return ArrangementSubmission{}, fmt.Errorf("%w: %s", ErrArrangementNotFound, id)
+	}
+
+	var submission ArrangementSubmission
+	if err := json.Unmarshal([]byte(submissionJSON), &submission); err != nil {
+		return ArrangementSubmission{}, errors.JsonDecodeFailure(err)
+	}
+	return submission, nil
+}
+
+func SaveArrangementSubmission(ctx context.Context, submission ArrangementSubmission) error {
+	submissionJSON, err := json.Marshal(submission)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.HSet(ctx, ArrangementSubmissionsRedisKey, map[string]string{submission.ID: string(submissionJSON)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// UpdateArrangementSubmission applies the change to the submission and saves it.
+// Changes to the same submission from other requests are applied one at a time.
+func UpdateArrangementSubmission(ctx context.Context, id string, change func(submission *ArrangementSubmission) error) (ArrangementSubmission, error) {
+	var submission ArrangementSubmission
+	err := redis.WithLock(ctx, arrangementsLockKey, func() error {
+		var err error
+		if submission, err = GetArrangementSubmission(ctx, id); err != nil {
+			return err
+		}
+		if err := change(&submission); err != nil {
+			return err
+		}
+		return SaveArrangementSubmission(ctx, submission)
+	})
+	if err != nil {
+		return ArrangementSubmission{}, err
+	}
+	return submission, nil
+}
+
+// PromoteArrangements puts the arrangements on the ballot for the season.
+// The season's election is created if it does not exist, and executive directors vote in it.
+// Arrangements on the ballot of another season are taken off that ballot.
+func PromoteArrangements(ctx context.Context, season string, ids ...string) (voting.Election, error) {
+	var election voting.Election
+	err := redis.WithLock(ctx, arrangementsLockKey, func() error {
+		var err error
+		election, err = promoteArrangements(ctx, season, ids)
+		return err
+	})
+	return election, err
+}
+
+func promoteArrangements(ctx context.Context, season string, ids []string) (voting.Election, error) {
+	promoted := make(ArrangementSubmissions, 0, len(ids))
+	for _, id := range ids {
+		submission, err := GetArrangementSubmission(ctx, id)
+		if err != nil {
+			return voting.Election{}, err
+		}
+		if submission.Status == ArrangementStatusDeclined {
+			return voting.Election{}, fmt.Errorf("%w: %s", ErrArrangementDeclined, id)
+		}
+		promoted = append(promoted, submission)
+	}
+
+	election, err := voting.GetElection(ctx, ArrangementsElectionID(season))
+	switch {
+	case errors.Is(err, voting.ErrElectionNotFound):
+		election = newArrangementsElection(season)
+	case err != nil:
+		return voting.Election{}, err
+	}
+
+	onBallot := make(map[string]bool, len(election.Choices))
+	for _, choice := range election.Choices {
+		onBallot[choice] = true
+	}
+	if election.Labels == nil {
+		election.Labels = make(map[string]string, len(promoted))
+	}
+	for _, submission := range promoted {
+		if !onBallot[submission.BallotChoice()] {
+			election.Choices = append(election.Choices, submission.BallotChoice())
+			onBallot[submission.BallotChoice()] = true
+		}
+		election.Labels[submission.BallotChoice()] = submission.BallotLabel()
+	}
+
+	// Save the election first, so that an invalid election leaves the arrangements unchanged.
+	if err := voting.SaveElection(ctx, election); err != nil {
+		return voting.Election{}, err
+	}
+	for _, submission := range promoted {
+		if submission.Status == ArrangementStatusOnBallot && submission.Season != season {
+			if err := removeFromBallot(ctx, submission); err != nil {
+				return voting.Election{}, err
+			}
+		}
+		submission.Status = ArrangementStatusOnBallot
+		submission.Season = season
+		if err := SaveArrangementSubmission(ctx, submission); err != nil {
+			return voting.Election{}, err
+		}
+	}
+	return election, nil
+}
+
+// DeclineArrangement removes the arrangement from consideration, and from the ballot it is on.
+func DeclineArrangement(ctx context.Context, id string) (ArrangementSubmission, error) {
+	var submission ArrangementSubmission
+	err := redis.WithLock(ctx, arrangementsLockKey, func() error {
+		var err error
+		if submission, err = GetArrangementSubmission(ctx, id); err != nil {
+			return err
+		}
+		if submission.Status == ArrangementStatusOnBallot {
+			if err := removeFromBallot(ctx, submission); err != nil {
+				return err
+			}
+		}
+		submission.Status = ArrangementStatusDeclined
+		return SaveArrangementSubmission(ctx, submission)
+	})
+	if err != nil {
+		return ArrangementSubmission{}, err
+	}
+	return submission, nil
+}
+
+// removeFromBallot takes the submission off the ballot of the season it was promoted to.
+// Ballots that ranked it are kept, and the choice is ignored when they are counted.
+func removeFromBallot(ctx context.Context, submission ArrangementSubmission) error {
+	election, err := voting.GetElection(ctx, ArrangementsElectionID(submission.Season))
+	switch {
+	case errors.Is(err, voting.ErrElectionNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	choices := make([]string, 0, len(election.Choices))
+	for _, choice := range election.Choices {
+		if choice != submission.BallotChoice() {
+			choices = append(choices, choice)
+		}
+	}
+	if len(choices) == len(election.Choices) {
+		return nil
+	}
+	election.Choices = choices
+	delete(election.Labels, submission.BallotChoice())
+	return voting.SaveElection(ctx, election)
+}
+
+func (x ArrangementSubmissions) ForDiscordID(discordID string) ArrangementSubmissions {
+	var want ArrangementSubmissions
+	for _, submission := range x {
+		if submission.ArrangerDiscordID == discordID {
+			want = append(want, submission)
+		}
+	}
+	return want
+}
+
+// Sorting
+
+func (x ArrangementSubmissions) Len() int      { return len(x) }
+func (x ArrangementSubmissions) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x ArrangementSubmissions) Less(i, j int) bool {
+	if x[i].SubmittedAt.Equal(x[j].SubmittedAt) {
+		return x[i].ID < x[j].ID
+	}
+	return x[i].SubmittedAt.Before(x[j].SubmittedAt)
+}
+func (x ArrangementSubmissions) Sort() ArrangementSubmissions { sort.Sort(x); return x }
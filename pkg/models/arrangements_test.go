@@ -0,0 +1,172 @@
+package models
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArrangementSubmission_AddReview(t *testing.T) {
+	var submission ArrangementSubmission
+	assert.Equal(t, 0.0, submission.Feasibility())
+
+	submission.AddReview(ArrangementReview{ReviewerDiscordID: "1", Feasibility: 2})
+	submission.AddReview(ArrangementReview{ReviewerDiscordID: "2", Feasibility: 5})
+	submission.AddReview(ArrangementReview{ReviewerDiscordID: "1", Feasibility: 4})
+	assert.Len(t, submission.Reviews, 2, "reviews are replaced")
+	assert.Equal(t, 4.5, submission.Feasibility())
+}
+
+func TestPromoteArrangements(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+
+	snakeEater := NewArrangementSubmission("Snake Eater", "Norihiko Hibino", "Jackson", "1", ArrangementScaleFull, "orchestra")
+	snakeEater.AddFile(ArrangementFileScore, "Snake Eater.pdf", 1024, "AB")
+	aurene := NewArrangementSubmission("Aurene", "Maclaine Diemer", "Brandon", "2", ArrangementScaleBigBand, "5444")
+	declined := NewArrangementSubmission("Cheese", "Cheese", "Cheese", "3", ArrangementScaleOther, "cheese")
+	declined.Status = ArrangementStatusDeclined
+	for _, submission := range []ArrangementSubmission{snakeEater, aurene, declined} {
+		require.NoError(t, SaveArrangementSubmission(ctx, submission))
+	}
+	assert.Equal(t, "ab", snakeEater.Files[0].Checksum)
+	assert.True(t, snakeEater.HasObject(snakeEater.Files[0].ObjectKey))
+	file, ok := snakeEater.GetFile(snakeEater.Files[0].ObjectKey)
+	assert.True(t, ok)
+	assert.False(t, file.Uploaded, "files are uploaded after they are submitted")
+
+	_, err := PromoteArrangements(ctx, "season3", snakeEater.ID, declined.ID)
+	assert.ErrorIs(t, err, ErrArrangementDeclined)
+	_, err = PromoteArrangements(ctx, "season3", "cheese")
+	assert.ErrorIs(t, err, ErrArrangementNotFound)
+	_, err = PromoteArrangements(ctx, "Season 3", snakeEater.ID)
+	assert.ErrorIs(t, err, voting.ErrInvalidElection)
+
+	election, err := PromoteArrangements(ctx, "season3", snakeEater.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{snakeEater.ID}, election.Choices, "a ballot can start with one arrangement")
+
+	election, err = PromoteArrangements(ctx, "season3", snakeEater.ID, aurene.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "arrangements-season3", election.ID)
+	assert.Equal(t, RoleVVGOExecutiveDirector.String(), election.VoterRole)
+	assert.Equal(t, []string{snakeEater.ID, aurene.ID}, election.Choices)
+	assert.Equal(t, "Snake Eater (arr. Jackson)", election.Label(snakeEater.ID))
+	assert.Equal(t, "Aurene (arr. Brandon)", election.Label(aurene.ID))
+
+	election, err = PromoteArrangements(ctx, "season3", aurene.ID)
+	require.NoError(t, err)
+	assert.Len(t, election.Choices, 2, "arrangements are only on the ballot once")
+
+	submissions, err := ListArrangementSubmissions(ctx)
+	require.NoError(t, err)
+	for _, submission := range submissions[:2] {
+		assert.Equal(t, ArrangementStatusOnBallot, submission.Status)
+		assert.Equal(t, "season3", submission.Season)
+	}
+	assert.Equal(t, ArrangementSubmissions{aurene.withStatus(ArrangementStatusOnBallot, "season3")}, submissions.ForDiscordID("2"))
+
+	t.Run("another season", func(t *testing.T) {
+		election, err := PromoteArrangements(ctx, "season4", aurene.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{aurene.ID}, election.Choices)
+
+		season3, err := voting.GetElection(ctx, ArrangementsElectionID("season3"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{snakeEater.ID}, season3.Choices, "leaves the old ballot")
+		assert.Equal(t, map[string]string{snakeEater.ID: "Snake Eater (arr. Jackson)"}, season3.Labels)
+	})
+
+	t.Run("decline", func(t *testing.T) {
+		declined, err := DeclineArrangement(ctx, aurene.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ArrangementStatusDeclined, declined.Status)
+
+		season4, err := voting.GetElection(ctx, ArrangementsElectionID("season4"))
+		require.NoError(t, err)
+		assert.Empty(t, season4.Choices, "leaves the ballot")
+	})
+
+	t.Run("same title and arranger", func(t *testing.T) {
+		revised := NewArrangementSubmission("Snake Eater", "Norihiko Hibino", "Jackson", "1", ArrangementScaleFull, "orchestra")
+		require.NoError(t, SaveArrangementSubmission(ctx, revised))
+		election, err := PromoteArrangements(ctx, "season5", snakeEater.ID, revised.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{snakeEater.ID, revised.ID}, election.Choices)
+
+		_, err = DeclineArrangement(ctx, revised.ID)
+		require.NoError(t, err)
+		election, err = voting.GetElection(ctx, ArrangementsElectionID("season5"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{snakeEater.ID}, election.Choices, "only the declined arrangement leaves the ballot")
+	})
+
+	t.Run("concurrent reviews", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(reviewer string) {
+				defer wg.Done()
+				_, err := UpdateArrangementSubmission(ctx, snakeEater.ID, func(submission *ArrangementSubmission) error {
+					submission.AddReview(ArrangementReview{ReviewerDiscordID: reviewer, Feasibility: 3})
+					return nil
+				})
+				assert.NoError(t, err)
+			}(strconv.Itoa(i))
+		}
+		wg.Wait()
+
+		submission, err := GetArrangementSubmission(ctx, snakeEater.ID)
+		require.NoError(t, err)
+		assert.Len(t, submission.Reviews, 10, "no review is lost")
+	})
+}
+
+func TestMigrateLegacyArrangementsBallots(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+
+	migrated, skipped, err := MigrateLegacyArrangementsBallots(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, migrated, "nothing to migrate")
+	assert.Equal(t, 0, skipped)
+
+	require.NoError(t, redis.RPush(ctx, "arrangements:season2:submissions", "Snake Eater", "Aurene"))
+	require.NoError(t, redis.HSet(ctx, "arrangements:season2:ballots", map[string]string{
+		"1": `["Aurene","Snake Eater"]`,
+		"2": `["Snake Eater","Aurene"]`,
+		"3": `[]`,
+		"4": `["Aurene","Aurene"]`,
+		"5": `not json`,
+	}))
+	migrated, skipped, err = MigrateLegacyArrangementsBallots(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+	assert.Equal(t, 3, skipped, "invalid ballots do not stop the migration")
+
+	election, err := voting.GetElection(ctx, ArrangementsElectionID(LegacyArrangementsSeason))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Snake Eater", "Aurene"}, election.Choices)
+	ballots, err := voting.ListBallots(ctx, election)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]voting.Ballot{"1": {"Aurene", "Snake Eater"}, "2": {"Snake Eater", "Aurene"}}, ballots)
+
+	require.NoError(t, voting.CastBallot(ctx, election, "1", voting.Ballot{"Snake Eater"}, time.Now()))
+	migrated, _, err = MigrateLegacyArrangementsBallots(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, migrated, "runs once")
+	ballot, err := voting.GetBallot(ctx, election, "1")
+	require.NoError(t, err)
+	assert.Equal(t, voting.Ballot{"Snake Eater"}, ballot, "new ballots are kept")
+}
+
+func (x ArrangementSubmission) withStatus(status ArrangementStatus, season string) ArrangementSubmission {
+	x.Status, x.Season = status, season
+	return x
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"time"
+)
+
+const AuditLogRedisKey = "audit_log"
+
+// AuditEntry records a change made by a member of the production team.
+type AuditEntry struct {
+	At      time.Time
+	By      string
+	Action  string
+	Project string `json:"Project,omitempty"`
+	Details string `json:"Details,omitempty"`
+}
+
+func SaveAuditEntry(ctx context.Context, entry AuditEntry) error {
+	if entry.At.IsZero() {
+		entry.At = time.Now().UTC()
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.RPush(ctx, AuditLogRedisKey, string(entryJSON)); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns up to limit of the most recent entries, newest first.
+func ListAuditEntries(ctx context.Context, limit int) ([]AuditEntry, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	entriesJSON, err := redis.LRange(ctx, AuditLogRedisKey, -limit, -1)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	entries := make([]AuditEntry, 0, len(entriesJSON))
+	for i := len(entriesJSON) - 1; i >= 0; i-- {
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(entriesJSON[i]), &entry); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
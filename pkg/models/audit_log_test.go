@@ -0,0 +1,32 @@
+package models
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+
+	at := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, action := range []string{"release parts", "extend deadline", "close submissions"} {
+		require.NoError(t, SaveAuditEntry(ctx, AuditEntry{At: at, By: "discord:42069", Action: action, Project: "01-snake-eater"}))
+	}
+
+	entries, err := ListAuditEntries(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []AuditEntry{
+		{At: at, By: "discord:42069", Action: "close submissions", Project: "01-snake-eater"},
+		{At: at, By: "discord:42069", Action: "extend deadline", Project: "01-snake-eater"},
+	}, entries)
+
+	require.NoError(t, SaveAuditEntry(ctx, AuditEntry{Action: "announce"}))
+	entries, err = ListAuditEntries(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, entries[0].At.IsZero(), "entries are timestamped")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"sort"
 	"strings"
 )
@@ -11,22 +12,38 @@ import (
 const SheetCredits = "Credits"
 
 type Credit struct {
-	Project       string
+	Project       string `sheet:",required"`
 	Order         int
 	MajorCategory string
 	MinorCategory string
 	Name          string
 	BottomText    string
+	// DiscordID links the credit to the member, so they can look it up with /credits.
+	// It only comes from the sheet's Discord ID column, which published credits drafts fill in from submission uploads.
+	// Credits are never linked by name, since members can change their nickname to anyone's name.
+	// It is not shown on the website.
+	DiscordID string `json:"-"`
+	// CreditsDraft marks the rows published from the project's credits draft.
+	// The next publish replaces the marked rows, so changes to them belong in the draft.
+	CreditsDraft bool `json:"-"`
 }
 
 type Credits []Credit
 
+// ListCredits returns the credits in the sheet with approved name corrections applied.
+// The corrections are best-effort: if they cannot be read, the credits are returned as they are in the sheet.
 func ListCredits(ctx context.Context) (Credits, error) {
 	values, err := redis.ReadSheet(ctx, SpreadsheetWebsiteData, SheetCredits)
 	if err != nil {
 		return nil, err
 	}
-	return valuesToCredits(values), nil
+	credits := Credits(valuesToCredits(values))
+	corrections, err := ListNameCorrections(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListNameCorrections", err)
+		return credits, nil
+	}
+	return credits.WithNameCorrections(corrections), nil
 }
 
 func valuesToCredits(values [][]interface{}) []Credit {
@@ -34,11 +51,15 @@ func valuesToCredits(values [][]interface{}) []Credit {
 		return nil
 	}
 	credits := make([]Credit, 0, len(values)-1)
-	UnmarshalSheet(values, &credits)
+	if err := UnmarshalSheet(values, &credits); err != nil {
+		logger.WithError(err).WithField("sheet", SheetCredits).Warn("models.UnmarshalSheet() reported errors")
+	}
 	Credits(credits).Sort()
 	return credits
 }
 
+func (x Credits) ToValues() ([][]interface{}, error) { return MarshalSheet([]Credit(x)) }
+
 func (x Credits) Len() int           { return len(x) }
 func (x Credits) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
 func (x Credits) Less(i, j int) bool { return x[i].Order < x[j].Order }
@@ -54,6 +75,16 @@ func (x Credits) ForProject(name string) Credits {
 	return want
 }
 
+func (x Credits) ForDiscordID(discordID string) Credits {
+	var want Credits
+	for _, credit := range x {
+		if credit.DiscordID != "" && credit.DiscordID == discordID {
+			want = append(want, credit)
+		}
+	}
+	return want
+}
+
 func (x Credits) WebsitePasta() string {
 	var output string
 	for _, credit := range x {
@@ -0,0 +1,554 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const CreditsDraftsRedisKey = "credits:drafts"
+
+// PerformersCategory is the major category of credits generated from submissions.
+const PerformersCategory = "PERFORMERS"
+
+var ErrCreditsDraftNotFound = errors.New("credits draft not found")
+var ErrCreditsDraftNotApproved = errors.New("credits draft is not approved")
+var ErrCreditsDraftChanged = errors.New("credits draft changed")
+
+// creditsDraftLockKey serializes changes to a project's draft.
+func creditsDraftLockKey(project string) string { return CreditsDraftsRedisKey + ":lock:" + project }
+
+type CreditsDraftStatus string
+
+const (
+	CreditsDraftStatusDraft     CreditsDraftStatus = "draft"
+	CreditsDraftStatusApproved  CreditsDraftStatus = "approved"
+	CreditsDraftStatusPublished CreditsDraftStatus = "published"
+)
+
+// CreditsDraft is a project's credits generated from its submission uploads.
+// The production team edits and approves the draft before it is published into the Credits sheet.
+type CreditsDraft struct {
+	Project     string
+	Credits     []DraftCredit
+	Status      CreditsDraftStatus
+	GeneratedAt time.Time
+	UpdatedAt   time.Time
+	UpdatedBy   string
+	ApprovedBy  string    `json:"ApprovedBy,omitempty"`
+	ApprovedAt  time.Time `json:"ApprovedAt,omitempty"`
+	PublishedAt time.Time `json:"PublishedAt,omitempty"`
+}
+
+// DraftCredit is a credit in a draft.
+// Unlike credits in the credits api, draft credits show the member's discord id, so they are merged by member.
+type DraftCredit struct {
+	MajorCategory string
+	MinorCategory string
+	Name          string
+	BottomText    string
+	DiscordID     string
+}
+
+func draftCredits(credits Credits) []DraftCredit {
+	drafts := make([]DraftCredit, 0, len(credits))
+	for _, credit := range credits {
+		drafts = append(drafts, DraftCredit{
+			MajorCategory: credit.MajorCategory,
+			MinorCategory: credit.MinorCategory,
+			Name:          credit.Name,
+			BottomText:    credit.BottomText,
+			DiscordID:     credit.DiscordID,
+		})
+	}
+	return drafts
+}
+
+// ToCredits returns the draft's credits in order.
+func (x CreditsDraft) ToCredits() Credits {
+	credits := make(Credits, 0, len(x.Credits))
+	for i, draft := range x.Credits {
+		credits = append(credits, Credit{
+			Project:       x.Project,
+			Order:         i,
+			MajorCategory: draft.MajorCategory,
+			MinorCategory: draft.MinorCategory,
+			Name:          draft.Name,
+			BottomText:    draft.BottomText,
+			DiscordID:     draft.DiscordID,
+			CreditsDraft:  true,
+		})
+	}
+	return credits
+}
+
+// GenerateCredits builds performer credits from the project's uploads.
+// Each member is credited once for each instrument, with the numbers of the parts they played, like "(1, 2)".
+// Instruments are in the score order of their first part, and names are sorted within an instrument.
+// Names maps discord ids to credited names; members without a name are credited by id.
+// Uploads are matched to instruments by the project's parts, so the parts' Instrument column is used.
+func GenerateCredits(project Project, parts Parts, uploads SubmissionUploads, names map[string]string) (Credits, error) {
+	scale := project.Instrumentation
+	if scale == "" {
+		scale = ArrangementScaleFull
+	}
+	template, ok := GetInstrumentationTemplate(scale)
+	if !ok {
+		return nil, fmt.Errorf("%w `%s`", ErrNoInstrumentationTemplate, scale)
+	}
+
+	projectParts := make(map[string]Part)
+	for _, part := range parts.ForProject(project.Name) {
+		projectParts[strings.ToLower(part.PartName)] = part
+	}
+
+	type performer struct {
+		discordID string
+		numbers   []string
+	}
+	var categories []string
+	categoryOrder := make(map[string]int)
+	performers := make(map[string]map[string]*performer)
+
+	for _, upload := range uploads {
+		if upload.Project != project.Name || upload.DiscordID == "" {
+			continue
+		}
+		part, listed := projectParts[strings.ToLower(upload.PartName)]
+		if !listed {
+			part = Part{Project: project.Name, PartName: upload.PartName}
+		}
+		category, number := strings.ToUpper(upload.PartName), ""
+		if _, instrument, ok := template.Match(part); ok {
+			category = strings.ToUpper(instrument.Name)
+			if instrument.Parts > 1 {
+				number = partNumber(upload.PartName)
+			}
+		}
+
+		scoreOrder := part.ScoreOrder
+		if !listed {
+			scoreOrder = int(^uint(0) >> 1) // unlisted parts go last
+		}
+		if order, ok := categoryOrder[category]; !ok || scoreOrder < order {
+			categoryOrder[category] = scoreOrder
+		}
+		if performers[category] == nil {
+			categories = append(categories, category)
+			performers[category] = make(map[string]*performer)
+		}
+
+		credited := performers[category][upload.DiscordID]
+		if credited == nil {
+			credited = &performer{discordID: upload.DiscordID}
+			performers[category][upload.DiscordID] = credited
+		}
+		if number != "" && !containsString(credited.numbers, number) {
+			credited.numbers = append(credited.numbers, number)
+		}
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool { return categoryOrder[categories[i]] < categoryOrder[categories[j]] })
+
+	nameOf := func(discordID string) string {
+		if name := names[discordID]; name != "" {
+			return name
+		}
+		return discordID
+	}
+
+	var credits Credits
+	for _, category := range categories {
+		var credited []*performer
+		for _, p := range performers[category] {
+			credited = append(credited, p)
+		}
+		sort.Slice(credited, func(i, j int) bool {
+			nameI, nameJ := strings.ToLower(nameOf(credited[i].discordID)), strings.ToLower(nameOf(credited[j].discordID))
+			if nameI == nameJ {
+				return credited[i].discordID < credited[j].discordID
+			}
+			return nameI < nameJ
+		})
+
+		for _, p := range credited {
+			var bottomText string
+			if len(p.numbers) != 0 {
+				sort.Slice(p.numbers, func(i, j int) bool { return lessPartNumber(p.numbers[i], p.numbers[j]) })
+				bottomText = "(" + strings.Join(p.numbers, ", ") + ")"
+			}
+			credits = append(credits, Credit{
+				Project:       project.Name,
+				Order:         len(credits),
+				MajorCategory: PerformersCategory,
+				MinorCategory: category,
+				Name:          nameOf(p.discordID),
+				BottomText:    bottomText,
+				DiscordID:     p.discordID,
+			})
+		}
+	}
+	return credits, nil
+}
+
+var arabicNumerals = map[string]string{"i": "1", "ii": "2", "iii": "3", "iv": "4", "v": "5", "vi": "6"}
+
+// partNumber returns the number at the end of a part name, like 2 for "Trumpet 2" or "Violin II".
+func partNumber(partName string) string {
+	words := strings.Fields(partKey(partName))
+	if len(words) < 2 {
+		return ""
+	}
+	last := words[len(words)-1]
+	if arabic, ok := arabicNumerals[last]; ok {
+		return arabic
+	}
+	if isPartNumber(last) {
+		return last
+	}
+	return ""
+}
+
+func lessPartNumber(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// CreditedNames returns the names to credit members by.
+// Members keep the name they are credited by in other projects, including approved name corrections.
+// Other members are credited by their nick or username in the guild member directory.
+func CreditedNames(ctx context.Context, discordIDs ...string) (map[string]string, error) {
+	credits, err := ListCredits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(discordIDs))
+	for _, discordID := range discordIDs {
+		if _, ok := names[discordID]; ok {
+			continue
+		}
+		if memberCredits := credits.ForDiscordID(discordID); len(memberCredits) != 0 {
+			names[discordID] = memberCredits[len(memberCredits)-1].Name
+			continue
+		}
+
+		member, err := GetGuildMember(ctx, discord.Snowflake(discordID))
+		switch {
+		case err != nil:
+			return nil, err
+		case member == nil:
+			continue
+		case member.Nick != "":
+			names[discordID] = member.Nick
+		default:
+			names[discordID] = member.User.Username
+		}
+	}
+	return names, nil
+}
+
+// GenerateCreditsDraft generates a new draft from the project's uploads, replacing any draft of the project.
+func GenerateCreditsDraft(ctx context.Context, project Project, parts Parts, by string) (CreditsDraft, error) {
+	uploads, err := ListSubmissionUploads(ctx, project.Name)
+	if err != nil {
+		return CreditsDraft{}, err
+	}
+	discordIDs := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		discordIDs = append(discordIDs, upload.DiscordID)
+	}
+	names, err := CreditedNames(ctx, discordIDs...)
+	if err != nil {
+		return CreditsDraft{}, err
+	}
+
+	generated, err := GenerateCredits(project, parts, uploads, names)
+	if err != nil {
+		return CreditsDraft{}, err
+	}
+	credits := draftCredits(generated)
+	var draft CreditsDraft
+	err = redis.WithLock(ctx, creditsDraftLockKey(project.Name), func() error {
+		now := time.Now().UTC()
+		draft = CreditsDraft{
+			Project:     project.Name,
+			Credits:     credits,
+			Status:      CreditsDraftStatusDraft,
+			GeneratedAt: now,
+			UpdatedAt:   now,
+			UpdatedBy:   by,
+		}
+		return saveCreditsDraft(ctx, draft)
+	})
+	if err != nil {
+		return CreditsDraft{}, err
+	}
+	return draft, nil
+}
+
+func GetCreditsDraft(ctx context.Context, project string) (CreditsDraft, error) {
+	draftJSON, err := redis.HGet(ctx, CreditsDraftsRedisKey, project)
+	switch {
+	case err != nil:
+		return CreditsDraft{}, errors.RedisFailure(err)
+	case draftJSON == "":
+		return CreditsDraft{}, fmt.Errorf("%w: %s", ErrCreditsDraftNotFound, project)
+	}
+
+	var draft CreditsDraft
+	if err := json.Unmarshal([]byte(draftJSON), &draft); err != nil {
+		return CreditsDraft{}, errors.JsonDecodeFailure(err)
+	}
+	return draft, nil
+}
+
+func saveCreditsDraft(ctx context.Context, draft CreditsDraft) error {
+	draftJSON, err := json.Marshal(draft)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.HSet(ctx, CreditsDraftsRedisKey, map[string]string{draft.Project: string(draftJSON)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// updateCreditsDraft applies the change to the project's draft and saves it.
+// The change is only applied to the draft as it was when updatedAt was read, so that edits,
+// approvals and publishes act on the draft the reviewer saw.
+func updateCreditsDraft(ctx context.Context, project string, updatedAt time.Time, change func(draft *CreditsDraft) error) (CreditsDraft, error) {
+	var draft CreditsDraft
+	err := redis.WithLock(ctx, creditsDraftLockKey(project), func() error {
+		var err error
+		if draft, err = GetCreditsDraft(ctx, project); err != nil {
+			return err
+		}
+		if !draft.UpdatedAt.Equal(updatedAt) {
+			return fmt.Errorf("%w: %s was updated at %s by %s", ErrCreditsDraftChanged,
+				project, draft.UpdatedAt.Format(time.RFC3339), draft.UpdatedBy)
+		}
+		if err := change(&draft); err != nil {
+			return err
+		}
+		return saveCreditsDraft(ctx, draft)
+	})
+	if err != nil {
+		return CreditsDraft{}, err
+	}
+	return draft, nil
+}
+
+// EditCreditsDraft replaces the credits of a draft last updated at updatedAt.
+// Edits need a new approval before the draft is published.
+func EditCreditsDraft(ctx context.Context, project string, updatedAt time.Time, credits []DraftCredit, by string) (CreditsDraft, error) {
+	return updateCreditsDraft(ctx, project, updatedAt, func(draft *CreditsDraft) error {
+		draft.Credits = credits
+		draft.Status = CreditsDraftStatusDraft
+		draft.UpdatedAt = time.Now().UTC()
+		draft.UpdatedBy = by
+		draft.ApprovedBy, draft.ApprovedAt = "", time.Time{}
+		return nil
+	})
+}
+
+// ApproveCreditsDraft approves the draft last updated at updatedAt.
+func ApproveCreditsDraft(ctx context.Context, project string, updatedAt time.Time, by string) (CreditsDraft, error) {
+	return updateCreditsDraft(ctx, project, updatedAt, func(draft *CreditsDraft) error {
+		draft.Status = CreditsDraftStatusApproved
+		draft.ApprovedBy = by
+		draft.ApprovedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// PublishCreditsDraft writes the approved draft last updated at updatedAt into the Credits sheet.
+// The rows marked in the Credits Draft column are replaced; other rows and columns of the sheet are kept as they are.
+// A published draft can be published again, so a publish that failed to reach google can be retried.
+// The sheet change is saved as a new version, so publishing can be rolled back like any other edit.
+//
+// push copies the written sheet to google. The draft is only marked published if it succeeds;
+// otherwise the sheet in redis is put back as it was.
+func PublishCreditsDraft(ctx context.Context, project string, updatedAt time.Time, by string, push func() error) (CreditsDraft, error) {
+	return updateCreditsDraft(ctx, project, updatedAt, func(draft *CreditsDraft) error {
+		if draft.Status != CreditsDraftStatusApproved && draft.Status != CreditsDraftStatusPublished {
+			return fmt.Errorf("%w: %s", ErrCreditsDraftNotApproved, project)
+		}
+
+		values, err := redis.ReadSheet(ctx, SpreadsheetWebsiteData, SheetCredits)
+		if err != nil {
+			return err
+		}
+		publishedValues, err := publishDraftCredits(values, *draft)
+		if err != nil {
+			return err
+		}
+		if err := redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, by, publishedValues); err != nil {
+			return err
+		}
+		if err := push(); err != nil {
+			if restoreErr := redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, by, values); restoreErr != nil {
+				logger.MethodFailure(ctx, "redis.WriteSheet", restoreErr)
+			}
+			return err
+		}
+
+		draft.Status = CreditsDraftStatusPublished
+		draft.PublishedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// publishDraftCredits replaces the project's rows marked in the Credits Draft column with the draft's credits.
+// Rows are found by the marker rather than by their cells, so a row fixed by hand in the sheet is replaced, not duplicated.
+// The new rows take the place and orders of the old ones, and later credits of the project are reordered after them.
+// On the first publish, the credits go after the project's other credits.
+// Columns that Credit does not model are left blank in the new rows and kept in all other rows.
+func publishDraftCredits(values [][]interface{}, draft CreditsDraft) ([][]interface{}, error) {
+	var header []interface{}
+	if len(values) != 0 {
+		header = append(header, values[0]...)
+	}
+	headerIndex := make(map[string]int, len(header))
+	for i, cell := range header {
+		name := normalizeColumnName(cellString(cell))
+		if _, ok := headerIndex[name]; !ok && name != "" {
+			headerIndex[name] = i
+		}
+	}
+
+	// cells maps the columns of a marshaled Credit to the columns of the sheet.
+	// Columns missing from the sheet are added to the end of the header.
+	fields := sheetColumns(reflect.TypeOf(Credit{}))
+	cells := make([]int, len(fields))
+	var projectCell, orderCell, draftCell int
+	for i, field := range fields {
+		index, ok := headerIndex[normalizeColumnName(field.Name)]
+		if !ok {
+			index = len(header)
+			header = append(header, field.Name)
+		}
+		cells[i] = index
+		switch field.Name {
+		case "Project":
+			projectCell = index
+		case "Order":
+			orderCell = index
+		case "Credits Draft":
+			draftCell = index
+		}
+	}
+
+	isPrevious := func(row []interface{}) bool {
+		published, err := strconv.ParseBool(cellString(sheetCell(row, draftCell)))
+		return err == nil && published
+	}
+	orderOf := func(row []interface{}) (int, bool) {
+		order, err := strconv.Atoi(cellString(sheetCell(row, orderCell)))
+		return order, err == nil
+	}
+
+	rows := [][]interface{}{header}
+	insertAt, removed, start, maxOrder := -1, 0, 0, -1
+	var projectRows []int
+	var dataRows [][]interface{}
+	if len(values) > 1 {
+		dataRows = values[1:]
+	}
+	for _, row := range dataRows {
+		if cellString(sheetCell(row, projectCell)) != draft.Project {
+			rows = append(rows, row)
+			continue
+		}
+		order, ok := orderOf(row)
+		if isPrevious(row) {
+			if insertAt == -1 || (ok && order < start) {
+				start = order
+			}
+			if insertAt == -1 {
+				insertAt = len(rows)
+			}
+			removed++
+			continue
+		}
+		if ok && order > maxOrder {
+			maxOrder = order
+		}
+		projectRows = append(projectRows, len(rows))
+		rows = append(rows, row)
+	}
+
+	if insertAt == -1 {
+		start = maxOrder + 1
+		insertAt = len(rows)
+		if len(projectRows) != 0 {
+			insertAt = projectRows[len(projectRows)-1] + 1
+		}
+	} else if shift := len(draft.Credits) - removed; shift != 0 {
+		for _, i := range projectRows {
+			if order, ok := orderOf(rows[i]); ok && order >= start {
+				rows[i] = setSheetCell(rows[i], orderCell, order+shift)
+			}
+		}
+	}
+
+	creditValues, err := draft.ToCredits().ToValues()
+	if err != nil {
+		return nil, err
+	}
+	published := make([][]interface{}, 0, len(creditValues)-1)
+	for i, credit := range creditValues[1:] {
+		row := make([]interface{}, len(header))
+		for j := range row {
+			row[j] = ""
+		}
+		for j := range fields {
+			row[cells[j]] = credit[j]
+		}
+		row[orderCell] = start + i
+		published = append(published, row)
+	}
+
+	want := make([][]interface{}, 0, len(rows)+len(published))
+	want = append(want, rows[:insertAt]...)
+	want = append(want, published...)
+	return append(want, rows[insertAt:]...), nil
+}
+
+func sheetCell(row []interface{}, i int) interface{} {
+	if i < len(row) {
+		return row[i]
+	}
+	return nil
+}
+
+// setSheetCell sets a cell on a copy of the row, padding short rows with blank cells.
+func setSheetCell(row []interface{}, i int, value interface{}) []interface{} {
+	want := make([]interface{}, len(row))
+	copy(want, row)
+	for len(want) <= i {
+		want = append(want, "")
+	}
+	want[i] = value
+	return want
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestGenerateCredits(t *testing.T) {
+	project := Project{Name: "06-aurene"}
+	parts := Parts{
+		{Project: "06-aurene", PartName: "Violin I", ScoreOrder: 30},
+		{Project: "06-aurene", PartName: "Trumpet 1", ScoreOrder: 10},
+		{Project: "06-aurene", PartName: "Trumpet 2", ScoreOrder: 11},
+		{Project: "06-aurene", PartName: "Flute 1", ScoreOrder: 1},
+	}
+	uploads := SubmissionUploads{
+		{Project: "06-aurene", PartName: "Violin I", DiscordID: "1"},
+		{Project: "06-aurene", PartName: "Trumpet 2", DiscordID: "2"},
+		{Project: "06-aurene", PartName: "Trumpet 1", DiscordID: "2"},
+		{Project: "06-aurene", PartName: "Trumpet 1", DiscordID: "2"},
+		{Project: "06-aurene", PartName: "Trumpet 1", DiscordID: "3"},
+		{Project: "06-aurene", PartName: "Otamatone", DiscordID: "1"},
+		{Project: "06-aurene", PartName: "Flute 1", DiscordID: ""},
+		{Project: "01-snake-eater", PartName: "Trumpet 1", DiscordID: "4"},
+	}
+	names := map[string]string{"1": "Jackson", "2": "brandon"}
+
+	credits, err := GenerateCredits(project, parts, uploads, names)
+	require.NoError(t, err)
+	assert.Equal(t, Credits{
+		{Project: "06-aurene", Order: 0, MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "3", BottomText: "(1)", DiscordID: "3"},
+		{Project: "06-aurene", Order: 1, MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "brandon", BottomText: "(1, 2)", DiscordID: "2"},
+		{Project: "06-aurene", Order: 2, MajorCategory: "PERFORMERS", MinorCategory: "VIOLIN I", Name: "Jackson", DiscordID: "1"},
+		{Project: "06-aurene", Order: 3, MajorCategory: "PERFORMERS", MinorCategory: "OTAMATONE", Name: "Jackson", DiscordID: "1"},
+	}, credits)
+
+	t.Run("instrument column", func(t *testing.T) {
+		parts := Parts{{Project: "06-aurene", PartName: "Lead", Instrument: "Trumpet", ScoreOrder: 1}}
+		uploads := SubmissionUploads{{Project: "06-aurene", PartName: "lead", DiscordID: "1"}}
+		credits, err := GenerateCredits(project, parts, uploads, names)
+		require.NoError(t, err)
+		assert.Equal(t, Credits{
+			{Project: "06-aurene", Order: 0, MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "Jackson", DiscordID: "1"},
+		}, credits)
+	})
+
+	t.Run("no template", func(t *testing.T) {
+		project := Project{Name: "06-aurene", Instrumentation: ArrangementScaleOther}
+		_, err := GenerateCredits(project, parts, uploads, names)
+		assert.True(t, errors.Is(err, ErrNoInstrumentationTemplate), "got %v", err)
+	})
+}
+
+func TestCreditsDraft(t *testing.T) {
+	ctx := context.Background()
+	useGuildMemberStore()
+
+	require.NoError(t, redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, "test", [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text", "Discord ID", "Notes"},
+		{"01-snake-eater", 0, "PERFORMERS", "TRUMPET", "Jackson", "", "1", ""},
+		{"06-aurene", 5, "VVGO PRODUCTION TEAM", "EXECUTIVE DIRECTOR", "Brandon", "", "", "from the sheet"},
+		{"06-aurene", 6, "PERFORMERS", "TRUMPET", "Dolphin", "", "", "entered by hand"},
+	}))
+	require.NoError(t, SaveGuildMembers(ctx, time.Now(),
+		discord.GuildMember{User: discord.User{ID: "2", Username: "brandon"}},
+		discord.GuildMember{User: discord.User{ID: "3", Username: "cheese"}, Nick: "Cheese"}))
+	for _, upload := range []SubmissionUpload{
+		NewSubmissionUpload("06-aurene", "Trumpet 1", "1", "a.mp3", 1, ""),
+		NewSubmissionUpload("06-aurene", "Trumpet 2", "2", "b.mp3", 1, ""),
+		NewSubmissionUpload("06-aurene", "Trumpet 3", "3", "c.mp3", 1, ""),
+	} {
+		require.NoError(t, SaveSubmissionUpload(ctx, upload))
+	}
+
+	pushed := func() error { return nil }
+	draft, err := GenerateCreditsDraft(ctx, Project{Name: "06-aurene"}, nil, "Jackson")
+	require.NoError(t, err)
+	assert.Equal(t, CreditsDraftStatusDraft, draft.Status)
+	assert.Equal(t, []DraftCredit{
+		{MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "brandon", BottomText: "(2)", DiscordID: "2"},
+		{MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "Cheese", BottomText: "(3)", DiscordID: "3"},
+		{MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "Jackson", BottomText: "(1)", DiscordID: "1"},
+	}, draft.Credits, "names come from existing credits by discord id, then nicks, then usernames")
+
+	_, err = PublishCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, "Jackson", pushed)
+	assert.ErrorIs(t, err, ErrCreditsDraftNotApproved)
+	_, err = ApproveCreditsDraft(ctx, "11-the-end", draft.UpdatedAt, "Jackson")
+	assert.ErrorIs(t, err, ErrCreditsDraftNotFound)
+
+	seen := draft
+	draft, err = ApproveCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, "Jackson")
+	require.NoError(t, err)
+	draft.Credits[0].Name = "Brandon H."
+	draft, err = EditCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, draft.Credits, "Brandon")
+	require.NoError(t, err)
+	assert.Equal(t, CreditsDraftStatusDraft, draft.Status, "edits need a new approval")
+
+	_, err = ApproveCreditsDraft(ctx, "06-aurene", seen.UpdatedAt, "Jackson")
+	assert.ErrorIs(t, err, ErrCreditsDraftChanged, "approvals are for the draft the reviewer saw")
+	_, err = EditCreditsDraft(ctx, "06-aurene", seen.UpdatedAt, seen.Credits, "Jackson")
+	assert.ErrorIs(t, err, ErrCreditsDraftChanged, "edits do not overwrite other edits")
+
+	_, err = ApproveCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, "Jackson")
+	require.NoError(t, err)
+	_, err = PublishCreditsDraft(ctx, "06-aurene", seen.UpdatedAt, "Jackson", pushed)
+	assert.ErrorIs(t, err, ErrCreditsDraftChanged)
+
+	before, err := redis.ReadSheet(ctx, SpreadsheetWebsiteData, SheetCredits)
+	require.NoError(t, err)
+	_, err = PublishCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, "Jackson", func() error { return errors.New("google is down") })
+	assert.Error(t, err)
+	got, err := GetCreditsDraft(ctx, "06-aurene")
+	require.NoError(t, err)
+	assert.Equal(t, CreditsDraftStatusApproved, got.Status, "drafts are published when the push succeeds")
+	after, err := redis.ReadSheet(ctx, SpreadsheetWebsiteData, SheetCredits)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "a failed push leaves the sheet as it was")
+	draft, err = PublishCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, "Jackson", pushed)
+	require.NoError(t, err)
+	assert.Equal(t, CreditsDraftStatusPublished, draft.Status)
+
+	values, err := redis.ReadSheet(ctx, SpreadsheetWebsiteData, SheetCredits)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text", "Discord ID", "Notes", "Credits Draft"},
+		{"01-snake-eater", 0.0, "PERFORMERS", "TRUMPET", "Jackson", "", "1", ""},
+		{"06-aurene", 5.0, "VVGO PRODUCTION TEAM", "EXECUTIVE DIRECTOR", "Brandon", "", "", "from the sheet"},
+		{"06-aurene", 6.0, "PERFORMERS", "TRUMPET", "Dolphin", "", "", "entered by hand"},
+		{"06-aurene", 7.0, "PERFORMERS", "TRUMPET", "Brandon H.", "(2)", "2", "", true},
+		{"06-aurene", 8.0, "PERFORMERS", "TRUMPET", "Cheese", "(3)", "3", "", true},
+		{"06-aurene", 9.0, "PERFORMERS", "TRUMPET", "Jackson", "(1)", "1", "", true},
+	}, values, "the draft is added after the project's credits, and the rest of the sheet is kept")
+
+	t.Run("publish again", func(t *testing.T) {
+		// Someone fixes a published credit and adds a credit in the sheet after the draft was published.
+		values[5][4], values[5][8] = "Cheese!", "TRUE"
+		require.NoError(t, redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, "test",
+			append(values, []interface{}{"06-aurene", 10, "SPECIAL THANKS", "", "Everyone", "", "", "added later"})))
+
+		draft, err := GenerateCreditsDraft(ctx, Project{Name: "06-aurene"}, nil, "Jackson")
+		require.NoError(t, err)
+		draft, err = EditCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, []DraftCredit{
+			{MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "Brandon H.", BottomText: "(2)", DiscordID: "2"},
+			{MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "Jackson", BottomText: "(1)", DiscordID: "1"},
+		}, "Jackson")
+		require.NoError(t, err)
+		_, err = ApproveCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, "Jackson")
+		require.NoError(t, err)
+		_, err = PublishCreditsDraft(ctx, "06-aurene", draft.UpdatedAt, "Jackson", pushed)
+		require.NoError(t, err)
+
+		values, err := redis.ReadSheet(ctx, SpreadsheetWebsiteData, SheetCredits)
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{
+			{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text", "Discord ID", "Notes", "Credits Draft"},
+			{"01-snake-eater", 0.0, "PERFORMERS", "TRUMPET", "Jackson", "", "1", ""},
+			{"06-aurene", 5.0, "VVGO PRODUCTION TEAM", "EXECUTIVE DIRECTOR", "Brandon", "", "", "from the sheet"},
+			{"06-aurene", 6.0, "PERFORMERS", "TRUMPET", "Dolphin", "", "", "entered by hand"},
+			{"06-aurene", 7.0, "PERFORMERS", "TRUMPET", "Brandon H.", "(2)", "2", "", true},
+			{"06-aurene", 8.0, "PERFORMERS", "TRUMPET", "Jackson", "(1)", "1", "", true},
+			{"06-aurene", 9.0, "SPECIAL THANKS", "", "Everyone", "", "", "added later"},
+		}, values, "only the marked rows are replaced, even if they were edited in the sheet")
+	})
+}
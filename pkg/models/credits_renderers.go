@@ -0,0 +1,303 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrUnknownCreditsFormat = errors.New("unknown credits format")
+
+// DefaultRollSpeed is how many lines of rolling credits go by each second.
+const DefaultRollSpeed = 1.5
+
+// MaxRollSpeed caps the roll speed, so each line is still on screen for a few frames.
+const MaxRollSpeed = 100
+
+// CreditsRenderer renders a project's credits in a file format.
+type CreditsRenderer struct {
+	Format      string
+	Description string
+	ContentType string
+	// Extension is the file extension of downloads, including the dot.
+	Extension string
+	Render    func(project Project, credits Credits, options CreditsRenderOptions) ([]byte, error)
+}
+
+// CreditsRenderOptions configure timed formats like captions.
+type CreditsRenderOptions struct {
+	// Start is when the credits start rolling in the video.
+	Start time.Duration
+	// RollSpeed is how many lines go by each second. Zero, negative and NaN speeds use DefaultRollSpeed, and faster speeds are capped at MaxRollSpeed.
+	RollSpeed float64
+}
+
+func (x CreditsRenderOptions) lineDuration() time.Duration {
+	speed := x.RollSpeed
+	switch {
+	case math.IsNaN(speed) || speed <= 0:
+		speed = DefaultRollSpeed
+	case speed > MaxRollSpeed:
+		speed = MaxRollSpeed
+	}
+	return time.Duration(float64(time.Second) / speed).Round(time.Millisecond)
+}
+
+var creditsRenderers = make(map[string]CreditsRenderer)
+
+// RegisterCreditsRenderer adds or replaces the renderer for a format.
+func RegisterCreditsRenderer(renderer CreditsRenderer) { creditsRenderers[renderer.Format] = renderer }
+
+func GetCreditsRenderer(format string) (CreditsRenderer, bool) {
+	renderer, ok := creditsRenderers[format]
+	return renderer, ok
+}
+
+// ListCreditsRenderers returns the renderers sorted by format.
+func ListCreditsRenderers() []CreditsRenderer {
+	renderers := make([]CreditsRenderer, 0, len(creditsRenderers))
+	for _, renderer := range creditsRenderers {
+		renderers = append(renderers, renderer)
+	}
+	sort.Slice(renderers, func(i, j int) bool { return renderers[i].Format < renderers[j].Format })
+	return renderers
+}
+
+// RenderCredits renders the project's credits in the format.
+func RenderCredits(format string, project Project, credits Credits, options CreditsRenderOptions) ([]byte, error) {
+	renderer, ok := GetCreditsRenderer(format)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCreditsFormat, format)
+	}
+	return renderer.Render(project, credits.ForProject(project.Name), options)
+}
+
+func init() {
+	pasta := func(render func(Credits) string) func(Project, Credits, CreditsRenderOptions) ([]byte, error) {
+		return func(_ Project, credits Credits, _ CreditsRenderOptions) ([]byte, error) {
+			return []byte(render(credits)), nil
+		}
+	}
+	for _, renderer := range []CreditsRenderer{
+		{Format: "website", Description: "Tab separated rows for the Credits sheet.", ContentType: "text/plain; charset=utf-8", Extension: ".txt",
+			Render: pasta(Credits.WebsitePasta)},
+		{Format: "video", Description: "Tab separated rows for the video editor.", ContentType: "text/plain; charset=utf-8", Extension: ".txt",
+			Render: pasta(Credits.VideoPasta)},
+		{Format: "youtube", Description: "Text for the youtube description.", ContentType: "text/plain; charset=utf-8", Extension: ".txt",
+			Render: pasta(Credits.YoutubePasta)},
+		{Format: "srt", Description: "SubRip captions of rolling end credits.", ContentType: "application/x-subrip; charset=utf-8", Extension: ".srt",
+			Render: renderCreditsSRT},
+		{Format: "ass", Description: "Advanced SubStation Alpha captions of rolling end credits.", ContentType: "text/x-ssa; charset=utf-8", Extension: ".ass",
+			Render: renderCreditsASS},
+		{Format: "jsonld", Description: "A schema.org MusicRecording with the performers as contributors.", ContentType: "application/ld+json", Extension: ".jsonld",
+			Render: renderCreditsJSONLD},
+		{Format: "markdown", Description: "Markdown with a heading for each category.", ContentType: "text/markdown; charset=utf-8", Extension: ".md",
+			Render: renderCreditsMarkdown},
+		{Format: "csv", Description: "Rows of the Credits sheet.", ContentType: "text/csv; charset=utf-8", Extension: ".csv",
+			Render: renderCreditsCSV},
+	} {
+		RegisterCreditsRenderer(renderer)
+	}
+}
+
+// creditedName is the name with its bottom text, as it is shown in the video.
+func creditedName(credit Credit) string {
+	if credit.BottomText == "" {
+		return credit.Name
+	}
+	return credit.Name + " " + credit.BottomText
+}
+
+type creditLine struct {
+	Text    string
+	Heading bool
+}
+
+// creditLines are the lines of rolling end credits: a heading for each category, then the names.
+// Empty lines separate the categories.
+func creditLines(credits Credits) []creditLine {
+	var lines []creditLine
+	var lastMajor, lastMinor string
+	for _, credit := range credits {
+		if credit.MajorCategory != lastMajor {
+			if lastMajor != "" {
+				lines = append(lines, creditLine{})
+			}
+			lastMajor, lastMinor = credit.MajorCategory, ""
+			lines = append(lines, creditLine{Text: fmt.Sprintf("— %s —", credit.MajorCategory), Heading: true})
+		}
+		if credit.MinorCategory != lastMinor {
+			lastMinor = credit.MinorCategory
+			lines = append(lines, creditLine{}, creditLine{Text: credit.MinorCategory, Heading: true})
+		}
+		lines = append(lines, creditLine{Text: creditedName(credit)})
+	}
+	return lines
+}
+
+// renderCreditsSRT shows each line of the credits for one line duration, one after another.
+// Empty lines are a pause with no caption.
+func renderCreditsSRT(_ Project, credits Credits, options CreditsRenderOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	lineDuration := options.lineDuration()
+	var cue int
+	for i, line := range creditLines(credits) {
+		if line.Text == "" {
+			continue
+		}
+		cue++
+		start := options.Start + time.Duration(i)*lineDuration
+		fmt.Fprintf(&buf, "%d\n%s --> %s\n%s\n\n", cue, srtTimestamp(start), srtTimestamp(start+lineDuration), line.Text)
+	}
+	return buf.Bytes(), nil
+}
+
+func srtTimestamp(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+}
+
+// ASS captions are laid out on a 1920x1080 script, where each line scrolls from below the screen to above it.
+const assWidth, assHeight, assLineHeight = 1920, 1080, 60
+
+func renderCreditsASS(project Project, credits Credits, options CreditsRenderOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "[Script Info]\nTitle: %s\nScriptType: v4.00+\nPlayResX: %d\nPlayResY: %d\nWrapStyle: 2\n\n",
+		assHeaderEscape(project.Title), assWidth, assHeight)
+	buf.WriteString("[V4+ Styles]\n" +
+		"Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, " +
+		"ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	fmt.Fprintf(&buf, "Style: Heading,Arial,%d,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,-1,0,0,0,100,100,0,0,1,2,0,5,0,0,0,1\n", assLineHeight*2/3)
+	fmt.Fprintf(&buf, "Style: Name,Arial,%d,&H00FFFFFF,&H00FFFFFF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,2,0,5,0,0,0,1\n\n", assLineHeight/2)
+	buf.WriteString("[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+
+	lineDuration := options.lineDuration()
+	// Lines cross the screen, plus a line above and below it, in the time it takes for a screen of lines to go by.
+	crossing := time.Duration(assHeight/assLineHeight+2) * lineDuration
+	for i, line := range creditLines(credits) {
+		if line.Text == "" {
+			continue
+		}
+		style := "Name"
+		if line.Heading {
+			style = "Heading"
+		}
+		start := options.Start + time.Duration(i)*lineDuration
+		fmt.Fprintf(&buf, "Dialogue: 0,%s,%s,%s,,0,0,0,,{\\move(%d,%d,%d,%d)}%s\n",
+			assTimestamp(start), assTimestamp(start+crossing), style,
+			assWidth/2, assHeight+assLineHeight, assWidth/2, -assLineHeight, assEscape(line.Text))
+	}
+	return buf.Bytes(), nil
+}
+
+func assTimestamp(d time.Duration) string {
+	return fmt.Sprintf("%d:%02d:%02d.%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000/10)
+}
+
+// assEscape keeps names from being read as override blocks or line breaks.
+func assEscape(text string) string {
+	return strings.NewReplacer("{", "(", "}", ")", "\\", "/").Replace(text)
+}
+
+// assHeaderEscape keeps a header value on its line, so it can't add lines to the script.
+func assHeaderEscape(text string) string { return strings.Join(strings.Fields(text), " ") }
+
+type creditsJSONLD struct {
+	Context     string       `json:"@context"`
+	Type        string       `json:"@type"`
+	Name        string       `json:"name"`
+	Url         string       `json:"url"`
+	ByArtist    jsonLDThing  `json:"byArtist"`
+	Composer    string       `json:"composer,omitempty"`
+	Contributor []jsonLDRole `json:"contributor"`
+	SameAs      string       `json:"sameAs,omitempty"`
+}
+
+type jsonLDThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// jsonLDRole is a schema.org Role, which adds the instrument or job to a contributor.
+type jsonLDRole struct {
+	Type        string      `json:"@type"`
+	RoleName    string      `json:"roleName"`
+	Description string      `json:"description,omitempty"`
+	Contributor jsonLDThing `json:"contributor"`
+}
+
+func renderCreditsJSONLD(project Project, credits Credits, _ CreditsRenderOptions) ([]byte, error) {
+	recording := creditsJSONLD{
+		Context:     "https://schema.org",
+		Type:        "MusicRecording",
+		Name:        project.Title,
+		Url:         config.Config().VVGO.ServerUrl + project.ProjectPage(),
+		ByArtist:    jsonLDThing{Type: "MusicGroup", Name: "Virtual Video Game Orchestra"},
+		Composer:    project.Composers,
+		Contributor: make([]jsonLDRole, 0, len(credits)),
+		SameAs:      project.YoutubeLink,
+	}
+	for _, credit := range credits {
+		recording.Contributor = append(recording.Contributor, jsonLDRole{
+			Type:        "Role",
+			RoleName:    credit.MinorCategory,
+			Description: credit.BottomText,
+			Contributor: jsonLDThing{Type: "Person", Name: credit.Name},
+		})
+	}
+
+	recordingJSON, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return nil, errors.JsonEncodeFailure(err)
+	}
+	return append(recordingJSON, '\n'), nil
+}
+
+func renderCreditsMarkdown(project Project, credits Credits, _ CreditsRenderOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", markdownEscape(project.Title))
+	var lastMajor, lastMinor string
+	for _, credit := range credits {
+		if credit.MajorCategory != lastMajor {
+			lastMajor, lastMinor = credit.MajorCategory, ""
+			fmt.Fprintf(&buf, "\n## %s\n", markdownEscape(credit.MajorCategory))
+		}
+		if credit.MinorCategory != lastMinor {
+			lastMinor = credit.MinorCategory
+			fmt.Fprintf(&buf, "\n### %s\n\n", markdownEscape(credit.MinorCategory))
+		}
+		fmt.Fprintf(&buf, "- %s\n", markdownEscape(creditedName(credit)))
+	}
+	return buf.Bytes(), nil
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "#", `\#`)
+
+func markdownEscape(text string) string { return markdownEscaper.Replace(text) }
+
+// renderCreditsCSV writes the columns of the Credits sheet, without discord ids.
+func renderCreditsCSV(_ Project, credits Credits, _ CreditsRenderOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text"})
+	for _, credit := range credits {
+		_ = writer.Write([]string{csvEscape(credit.Project), fmt.Sprint(credit.Order), csvEscape(credit.MajorCategory),
+			csvEscape(credit.MinorCategory), csvEscape(credit.Name), csvEscape(credit.BottomText)})
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// csvEscape keeps names from being read as formulas when the file is opened in a spreadsheet.
+func csvEscape(text string) string {
+	if text != "" && strings.ContainsRune("=+-@", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
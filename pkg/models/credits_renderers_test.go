@@ -0,0 +1,170 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderCredits(t *testing.T) {
+	project := Project{Name: "06-aurene", Title: "Aurene", Composers: "Lena Chappelle"}
+	credits := Credits{
+		{Project: "06-aurene", MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "brandon", BottomText: "(1, 2)"},
+		{Project: "06-aurene", MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "jack_son"},
+		{Project: "06-aurene", MajorCategory: "PERFORMERS", MinorCategory: "VIOLIN I", Name: "Jackson"},
+		{Project: "06-aurene", MajorCategory: "CREW", MinorCategory: "AUDIO", Name: "Chris"},
+		{Project: "01-snake-eater", MajorCategory: "CREW", MinorCategory: "AUDIO", Name: "Chris"},
+	}
+
+	t.Run("srt", func(t *testing.T) {
+		got, err := RenderCredits("srt", project, credits, CreditsRenderOptions{Start: 3*time.Minute + 20*time.Second, RollSpeed: 2})
+		require.NoError(t, err)
+		assert.Equal(t, `1
+00:03:20,000 --> 00:03:20,500
+— PERFORMERS —
+
+2
+00:03:21,000 --> 00:03:21,500
+TRUMPET
+
+3
+00:03:21,500 --> 00:03:22,000
+brandon (1, 2)
+
+4
+00:03:22,000 --> 00:03:22,500
+jack_son
+
+5
+00:03:23,000 --> 00:03:23,500
+VIOLIN I
+
+6
+00:03:23,500 --> 00:03:24,000
+Jackson
+
+7
+00:03:24,500 --> 00:03:25,000
+— CREW —
+
+8
+00:03:25,500 --> 00:03:26,000
+AUDIO
+
+9
+00:03:26,000 --> 00:03:26,500
+Chris
+
+`, string(got))
+	})
+
+	t.Run("ass", func(t *testing.T) {
+		got, err := RenderCredits("ass", project, credits, CreditsRenderOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, string(got), "PlayResY: 1080\n")
+		assert.Contains(t, string(got), "Dialogue: 0,0:00:00.00,0:00:13.34,Heading,,0,0,0,,{\\move(960,1140,960,-60)}— PERFORMERS —\n")
+		assert.Contains(t, string(got), "Dialogue: 0,0:00:02.00,0:00:15.34,Name,,0,0,0,,{\\move(960,1140,960,-60)}brandon (1, 2)\n")
+	})
+
+	t.Run("ass title", func(t *testing.T) {
+		project := Project{Name: "06-aurene", Title: "Aurene\r\n[Events]\nDialogue: 0"}
+		got, err := RenderCredits("ass", project, credits, CreditsRenderOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, string(got), "Title: Aurene [Events] Dialogue: 0\nScriptType: v4.00+\n")
+		assert.Equal(t, 1, strings.Count(string(got), "[Events]\n"))
+	})
+
+	t.Run("jsonld", func(t *testing.T) {
+		got, err := RenderCredits("jsonld", project, credits[3:4], CreditsRenderOptions{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"@context": "https://schema.org",
+			"@type": "MusicRecording",
+			"name": "Aurene",
+			"url": "https://vvgo.org/projects?name=06-aurene",
+			"byArtist": {"@type": "MusicGroup", "name": "Virtual Video Game Orchestra"},
+			"composer": "Lena Chappelle",
+			"contributor": [
+				{"@type": "Role", "roleName": "AUDIO", "contributor": {"@type": "Person", "name": "Chris"}}
+			]
+		}`, string(got))
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		got, err := RenderCredits("markdown", project, credits, CreditsRenderOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, `# Aurene
+
+## PERFORMERS
+
+### TRUMPET
+
+- brandon (1, 2)
+- jack\_son
+
+### VIOLIN I
+
+- Jackson
+
+## CREW
+
+### AUDIO
+
+- Chris
+`, string(got))
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		got, err := RenderCredits("csv", project, credits[:1], CreditsRenderOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "Project,Order,Major Category,Minor Category,Name,Bottom Text\n"+
+			"06-aurene,0,PERFORMERS,TRUMPET,brandon,\"(1, 2)\"\n", string(got))
+	})
+
+	t.Run("csv formulas", func(t *testing.T) {
+		got, err := RenderCredits("csv", project, Credits{
+			{Project: "06-aurene", MajorCategory: "PERFORMERS", MinorCategory: "+TRUMPET", Name: "=HYPERLINK(\"x\")", BottomText: "@home"},
+			{Project: "06-aurene", Order: 1, MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "-brandon-"},
+		}, CreditsRenderOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "Project,Order,Major Category,Minor Category,Name,Bottom Text\n"+
+			"06-aurene,0,PERFORMERS,'+TRUMPET,\"'=HYPERLINK(\"\"x\"\")\",'@home\n"+
+			"06-aurene,1,PERFORMERS,TRUMPET,'-brandon-,\n", string(got))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := RenderCredits("pdf", project, credits, CreditsRenderOptions{})
+		assert.True(t, errors.Is(err, ErrUnknownCreditsFormat), "errors.Is(%v, ErrUnknownCreditsFormat)", err)
+	})
+}
+
+func TestCreditsRenderOptions_lineDuration(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		rollSpeed float64
+		want      time.Duration
+	}{
+		{"zero", 0, 667 * time.Millisecond},
+		{"negative", -2, 667 * time.Millisecond},
+		{"nan", math.NaN(), 667 * time.Millisecond},
+		{"infinity", math.Inf(1), 10 * time.Millisecond},
+		{"negative infinity", math.Inf(-1), 667 * time.Millisecond},
+		{"speed", 2, 500 * time.Millisecond},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CreditsRenderOptions{RollSpeed: tt.rollSpeed}.lineDuration())
+		})
+	}
+}
+
+func TestListCreditsRenderers(t *testing.T) {
+	var formats []string
+	for _, renderer := range ListCreditsRenderers() {
+		formats = append(formats, renderer.Format)
+	}
+	assert.Equal(t, []string{"ass", "csv", "jsonld", "markdown", "srt", "video", "website", "youtube"}, formats)
+}
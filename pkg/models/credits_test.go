@@ -1,8 +1,13 @@
 package models
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
 	"testing"
+	"time"
 )
 
 func TestCredits_WebsitePasta(t *testing.T) {
@@ -181,3 +186,55 @@ func Test_valuesToCredits(t *testing.T) {
 
 	assert.Equal(t, want, got)
 }
+
+func TestListCredits_DiscordIDs(t *testing.T) {
+	ctx := context.Background()
+	useGuildMemberStore()
+	require.NoError(t, redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, "test", [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Discord ID"},
+		{"01-snake-eater", 0, "PERFORMERS", "TRUMPET", "Jackson H.", "1234"},
+		{"01-snake-eater", 1, "PERFORMERS", "TROMBONE", "Brandon Harnish"},
+	}))
+	require.NoError(t, SaveGuildMembers(ctx, time.Now(),
+		discord.GuildMember{User: discord.User{ID: "5678", Username: "bharnish"}, Nick: "Brandon Harnish"}))
+
+	credits, err := ListCredits(ctx)
+	require.NoError(t, err)
+	var ids []string
+	for _, credit := range credits {
+		ids = append(ids, credit.DiscordID)
+	}
+	assert.Equal(t, []string{"1234", ""}, ids, "credits are not linked by name")
+}
+
+func TestListCredits_NameCorrections(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, "test", [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Discord ID"},
+		{"01-snake-eater", 0, "PERFORMERS", "TRUMPET", "Jackson H.", "1234"},
+	}))
+	correction, err := RequestNameCorrection(ctx, Credit{Project: "01-snake-eater", Name: "Jackson H.", DiscordID: "1234"}, "Jackson Hall")
+	require.NoError(t, err)
+	_, err = ReviewNameCorrection(ctx, correction.ID, true, "test")
+	require.NoError(t, err)
+
+	// Someone fixes the name in the sheet after the correction was approved.
+	require.NoError(t, redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, "test", [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Discord ID"},
+		{"01-snake-eater", 0, "PERFORMERS", "TRUMPET", "Jackson Hal", "1234"},
+	}))
+	credits, err := ListCredits(ctx)
+	require.NoError(t, err)
+	require.Len(t, credits, 1)
+	assert.Equal(t, "Jackson Hall", credits[0].Name)
+
+	t.Run("corrections unavailable", func(t *testing.T) {
+		require.NoError(t, redis.HSet(ctx, NameCorrectionsRedisKey, map[string]string{"broken": "not json"}))
+		t.Cleanup(func() { require.NoError(t, redis.HDel(ctx, NameCorrectionsRedisKey, "broken")) })
+
+		credits, err := ListCredits(ctx)
+		require.NoError(t, err)
+		require.Len(t, credits, 1)
+		assert.Equal(t, "Jackson Hal", credits[0].Name, "the credits are listed without corrections")
+	})
+}
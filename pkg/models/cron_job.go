@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CronJob is the status of a background job.
+type CronJob struct {
+	Name        string
+	Description string
+	// Schedule is a cron expression, or empty for jobs that only run when triggered.
+	Schedule     string
+	Paused       bool
+	Running      bool
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string `json:"LastError,omitempty"`
+	NextRun      time.Time
+}
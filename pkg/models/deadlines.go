@@ -0,0 +1,154 @@
+package models
+
+import (
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"strings"
+	"time"
+)
+
+var ErrInvalidDeadline = errors.New("invalid deadline")
+
+// deadlineLayouts are the layouts tried, in order, when reading a deadline with a date and time.
+var deadlineLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"1/2/2006 3:04 PM",
+	"1/2/2006 3PM",
+	"January 2, 2006 15:04",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006 3PM",
+	"Monday, January 2, 2006 3:04 PM",
+}
+
+// deadlineDateLayouts are the layouts for deadlines with a date only.
+// These deadlines are at the end of the day.
+var deadlineDateLayouts = []string{
+	"2006-01-02",
+	"1/2/2006",
+	"January 2, 2006",
+	"January 2 2006",
+	"Monday, January 2, 2006",
+}
+
+// deadlineZones are the timezone names we see in the Projects sheet.
+var deadlineZones = map[string]string{
+	"hst":          "Pacific/Honolulu",
+	"hawaii":       "Pacific/Honolulu",
+	"hawaii time":  "Pacific/Honolulu",
+	"pt":           "America/Los_Angeles",
+	"pst":          "America/Los_Angeles",
+	"pdt":          "America/Los_Angeles",
+	"pacific":      "America/Los_Angeles",
+	"pacific time": "America/Los_Angeles",
+	"et":           "America/New_York",
+	"est":          "America/New_York",
+	"edt":          "America/New_York",
+	"eastern":      "America/New_York",
+	"eastern time": "America/New_York",
+	"utc":          "UTC",
+	"gmt":          "UTC",
+}
+
+// DeadlineLocation returns the timezone for deadlines that do not name one.
+func DeadlineLocation() *time.Location {
+	location, err := time.LoadLocation(config.Config().Deadlines.Timezone)
+	if err != nil {
+		logger.WithError(err).WithField("timezone", config.Config().Deadlines.Timezone).
+			Warn("time.LoadLocation() failed, using UTC")
+		return time.UTC
+	}
+	return location
+}
+
+// ParseDeadline reads a deadline from the Projects sheet.
+// The text may end with a timezone, like `2021-06-01 23:59 America/New_York` or `June 1, 2021 (Hawaii Time)`.
+// Otherwise the deadline is in location. Deadlines without a time are at the end of the day.
+// An empty deadline returns the zero time.
+func ParseDeadline(text string, location *time.Location) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, nil
+	}
+
+	value, zoneLocation := splitDeadlineZone(text)
+	if zoneLocation != nil {
+		location = zoneLocation
+	}
+	value = strings.ToUpper(strings.TrimSuffix(value, "."))
+
+	for _, layout := range deadlineLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range deadlineDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t.Add(24*time.Hour - time.Second), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w `%s`", ErrInvalidDeadline, text)
+}
+
+// splitDeadlineZone removes a trailing timezone from the text.
+// The timezone may be in parentheses, an iana name, or one of deadlineZones.
+func splitDeadlineZone(text string) (string, *time.Location) {
+	if strings.HasSuffix(text, ")") {
+		if i := strings.LastIndex(text, "("); i != -1 {
+			if location := lookupDeadlineZone(text[i+1 : len(text)-1]); location != nil {
+				return strings.TrimSpace(text[:i]), location
+			}
+		}
+	}
+
+	for _, n := range []int{2, 1} {
+		fields := strings.Fields(text)
+		if len(fields) <= n {
+			continue
+		}
+		if location := lookupDeadlineZone(strings.Join(fields[len(fields)-n:], " ")); location != nil {
+			return strings.Join(fields[:len(fields)-n], " "), location
+		}
+	}
+	return text, nil
+}
+
+func lookupDeadlineZone(name string) *time.Location {
+	name = strings.TrimSpace(name)
+	if zone, ok := deadlineZones[strings.ToLower(name)]; ok {
+		name = zone
+	} else if !strings.Contains(name, "/") {
+		return nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return location
+}
+
+// Deadline returns the submission deadline, and false if the project does not have one.
+func (x Project) Deadline() (time.Time, bool) {
+	return x.SubmissionDeadlineAt, !x.SubmissionDeadlineAt.IsZero()
+}
+
+// withDeadlines parses the submission deadline of each project.
+func (x Projects) withDeadlines(location *time.Location) Projects {
+	for i := range x {
+		deadline, err := ParseDeadline(x[i].SubmissionDeadline, location)
+		if err != nil {
+			logger.WithError(err).WithField("project", x[i].Name).Warn("models.ParseDeadline() failed")
+		}
+		x[i].SubmissionDeadlineAt = deadline
+	}
+	return x
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseDeadline(t *testing.T) {
+	honolulu, err := time.LoadLocation("Pacific/Honolulu")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		text string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"2021-06-01T23:59:00Z", time.Date(2021, 6, 1, 23, 59, 0, 0, time.UTC)},
+		{"2021-06-01 23:59", time.Date(2021, 6, 1, 23, 59, 0, 0, honolulu)},
+		{"2021-06-01", time.Date(2021, 6, 1, 23, 59, 59, 0, honolulu)},
+		{"6/1/2021 11:59 pm", time.Date(2021, 6, 1, 23, 59, 0, 0, honolulu)},
+		{"June 1, 2021", time.Date(2021, 6, 1, 23, 59, 59, 0, honolulu)},
+		{"June 1, 2021 (Hawaii Time)", time.Date(2021, 6, 1, 23, 59, 59, 0, honolulu)},
+		{"June 1, 2021 11:59 PM EST", time.Date(2021, 6, 1, 23, 59, 0, 0, newYork)},
+		{"2021-06-01 23:59 America/New_York", time.Date(2021, 6, 1, 23, 59, 0, 0, newYork)},
+		{"Tuesday, June 1, 2021 3:00 PM pacific time", time.Date(2021, 6, 1, 22, 0, 0, 0, time.UTC)},
+	} {
+		got, err := ParseDeadline(tt.text, honolulu)
+		if assert.NoError(t, err, tt.text) {
+			assert.True(t, tt.want.Equal(got), "%s: want %s, got %s", tt.text, tt.want, got)
+		}
+	}
+
+	_, err = ParseDeadline("when it's done", honolulu)
+	assert.True(t, errors.Is(err, ErrInvalidDeadline), "errors.Is(err, ErrInvalidDeadline)")
+}
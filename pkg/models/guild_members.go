@@ -0,0 +1,461 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// GuildMembersRedisKey is a hash of guild member json by user id.
+	GuildMembersRedisKey = "guild_members"
+	// GuildMembersVersionRedisKey is incremented on every change to the directory.
+	GuildMembersVersionRedisKey = "guild_members:version"
+	// GuildMembersLastSyncRedisKey is the json of the last sync.
+	GuildMembersLastSyncRedisKey = "guild_members:last_sync"
+	// guildMemberRolesRedisKey is prefixed to the user id to get a list of role changes.
+	guildMemberRolesRedisKey = "guild_members:role_history:"
+)
+
+// MaxGuildMemberRoleChanges is how many role changes are kept for each member.
+const MaxGuildMemberRoleChanges = 500
+
+// GuildMemberRoleChange records a role given to or taken from a member.
+type GuildMemberRoleChange struct {
+	UserID discord.Snowflake
+	RoleID string
+	Added  bool
+	At     time.Time
+}
+
+// GuildMemberSync records one pass through the guild's members.
+type GuildMemberSync struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	Members   int
+	Added     int
+	Updated   int
+	Removed   int
+	Error     string `json:"Error,omitempty"`
+}
+
+// guildMembersLockKey serializes writes across replicas, so role changes are diffed against the latest member.
+const guildMembersLockKey = GuildMembersRedisKey + ":lock"
+
+// ListGuildMembers returns every member in the directory.
+func ListGuildMembers(ctx context.Context) ([]discord.GuildMember, error) {
+	byID, err := readGuildMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]discord.GuildMember, 0, len(byID))
+	for _, member := range byID {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].User.ID.Less(members[j].User.ID) })
+	return members, nil
+}
+
+// GetGuildMember returns a member from the directory.
+func GetGuildMember(ctx context.Context, id discord.Snowflake) (*discord.GuildMember, error) {
+	memberJSON, err := redis.HGet(ctx, GuildMembersRedisKey, id.String())
+	switch {
+	case err != nil:
+		return nil, errors.RedisFailure(err)
+	case memberJSON == "":
+		return nil, nil
+	}
+	var member discord.GuildMember
+	if err := json.Unmarshal([]byte(memberJSON), &member); err != nil {
+		return nil, errors.JsonDecodeFailure(err)
+	}
+	return &member, nil
+}
+
+// GetGuildMembers returns the members with the given ids that are in the directory.
+func GetGuildMembers(ctx context.Context, ids ...discord.Snowflake) (map[discord.Snowflake]discord.GuildMember, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = id.String()
+	}
+	membersJSON, err := redis.HMGet(ctx, GuildMembersRedisKey, fields...)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+	members := make(map[discord.Snowflake]discord.GuildMember, len(ids))
+	for i, memberJSON := range membersJSON {
+		if memberJSON == "" {
+			continue
+		}
+		var member discord.GuildMember
+		if err := json.Unmarshal([]byte(memberJSON), &member); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		members[ids[i]] = member
+	}
+	return members, nil
+}
+
+func readGuildMembers(ctx context.Context) (map[discord.Snowflake]discord.GuildMember, error) {
+	membersJSON, err := redis.HGetAll(ctx, GuildMembersRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+	members := make(map[discord.Snowflake]discord.GuildMember, len(membersJSON))
+	for id, memberJSON := range membersJSON {
+		var member discord.GuildMember
+		if err := json.Unmarshal([]byte(memberJSON), &member); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		members[discord.Snowflake(id)] = member
+	}
+	return members, nil
+}
+
+// SaveGuildMembers writes members to the directory and records their role changes.
+func SaveGuildMembers(ctx context.Context, at time.Time, members ...discord.GuildMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	ids := make([]discord.Snowflake, len(members))
+	for i, member := range members {
+		ids[i] = member.User.ID
+	}
+	return redis.WithLock(ctx, guildMembersLockKey, func() error {
+		previous, err := GetGuildMembers(ctx, ids...)
+		if err != nil {
+			return err
+		}
+		return saveGuildMembers(ctx, at, previous, members)
+	})
+}
+
+// UpdateGuildMembers is SaveGuildMembers for callers that already read the members from the directory.
+// Role changes are diffed against previous.
+func UpdateGuildMembers(ctx context.Context, at time.Time, previous map[discord.Snowflake]discord.GuildMember, members ...discord.GuildMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	return redis.WithLock(ctx, guildMembersLockKey, func() error {
+		return saveGuildMembers(ctx, at, previous, members)
+	})
+}
+
+func saveGuildMembers(ctx context.Context, at time.Time, previous map[discord.Snowflake]discord.GuildMember, members []discord.GuildMember) error {
+	fields := make(map[string]string, len(members))
+	var changes []GuildMemberRoleChange
+	for _, member := range members {
+		memberJSON, err := json.Marshal(member)
+		if err != nil {
+			return errors.JsonEncodeFailure(err)
+		}
+		fields[member.User.ID.String()] = string(memberJSON)
+		changes = append(changes, diffRoles(member.User.ID, previous[member.User.ID].Roles, member.Roles, at)...)
+	}
+
+	if err := redis.HSet(ctx, GuildMembersRedisKey, fields); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return saveRoleChanges(ctx, changes)
+}
+
+// RemoveGuildMembers removes members that left the guild from the directory.
+// Their roles are recorded as taken away.
+func RemoveGuildMembers(ctx context.Context, at time.Time, ids ...discord.Snowflake) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return redis.WithLock(ctx, guildMembersLockKey, func() error {
+		previous, err := GetGuildMembers(ctx, ids...)
+		if err != nil {
+			return err
+		}
+		fields := make([]string, 0, len(ids))
+		var changes []GuildMemberRoleChange
+		for _, id := range ids {
+			previous, ok := previous[id]
+			if !ok {
+				continue
+			}
+			fields = append(fields, id.String())
+			changes = append(changes, diffRoles(id, previous.Roles, nil, at)...)
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+
+		if err := redis.HDel(ctx, GuildMembersRedisKey, fields...); err != nil {
+			return errors.RedisFailure(err)
+		}
+		return saveRoleChanges(ctx, changes)
+	})
+}
+
+func diffRoles(userID discord.Snowflake, before, after []string, at time.Time) []GuildMemberRoleChange {
+	had := make(map[string]bool, len(before))
+	for _, role := range before {
+		had[role] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, role := range after {
+		has[role] = true
+	}
+
+	var changes []GuildMemberRoleChange
+	for _, role := range after {
+		if !had[role] {
+			changes = append(changes, GuildMemberRoleChange{UserID: userID, RoleID: role, Added: true, At: at})
+		}
+	}
+	for _, role := range before {
+		if !has[role] {
+			changes = append(changes, GuildMemberRoleChange{UserID: userID, RoleID: role, Added: false, At: at})
+		}
+	}
+	return changes
+}
+
+// saveRoleChanges appends the changes to each member's history and bumps the directory version.
+// Only the latest MaxGuildMemberRoleChanges changes of each member are kept.
+func saveRoleChanges(ctx context.Context, changes []GuildMemberRoleChange) error {
+	byUser := make(map[discord.Snowflake][]string)
+	var users []discord.Snowflake
+	for _, change := range changes {
+		changeJSON, err := json.Marshal(change)
+		if err != nil {
+			return errors.JsonEncodeFailure(err)
+		}
+		if _, ok := byUser[change.UserID]; !ok {
+			users = append(users, change.UserID)
+		}
+		byUser[change.UserID] = append(byUser[change.UserID], string(changeJSON))
+	}
+	for _, userID := range users {
+		key := guildMemberRolesRedisKey + userID.String()
+		if err := redis.RPush(ctx, key, byUser[userID]...); err != nil {
+			return errors.RedisFailure(err)
+		}
+		if err := redis.LTrim(ctx, key, -MaxGuildMemberRoleChanges, -1); err != nil {
+			return errors.RedisFailure(err)
+		}
+	}
+	if _, err := redis.Incr(ctx, GuildMembersVersionRedisKey); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// ListGuildMemberRoleChanges returns the role history of a member, oldest first.
+func ListGuildMemberRoleChanges(ctx context.Context, userID discord.Snowflake) ([]GuildMemberRoleChange, error) {
+	changesJSON, err := redis.LRange(ctx, guildMemberRolesRedisKey+userID.String(), 0, -1)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+	changes := make([]GuildMemberRoleChange, 0, len(changesJSON))
+	for _, changeJSON := range changesJSON {
+		var change GuildMemberRoleChange
+		if err := json.Unmarshal([]byte(changeJSON), &change); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func SaveGuildMemberSync(ctx context.Context, sync GuildMemberSync) error {
+	syncJSON, err := json.Marshal(sync)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.Set(ctx, GuildMembersLastSyncRedisKey, string(syncJSON)); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// GetGuildMemberSync returns the last sync, or nil if the guild was never synced.
+func GetGuildMemberSync(ctx context.Context) (*GuildMemberSync, error) {
+	syncJSON, err := redis.Get(ctx, GuildMembersLastSyncRedisKey)
+	switch {
+	case err != nil:
+		return nil, errors.RedisFailure(err)
+	case syncJSON == "":
+		return nil, nil
+	}
+	var sync GuildMemberSync
+	if err := json.Unmarshal([]byte(syncJSON), &sync); err != nil {
+		return nil, errors.JsonDecodeFailure(err)
+	}
+	return &sync, nil
+}
+
+// guildMemberIndex indexes the directory by id and by lowercase nickname and username.
+type guildMemberIndex struct {
+	version string
+	byID    map[discord.Snowflake]discord.GuildMember
+	// names is sorted by name for prefix searches.
+	names []guildMemberName
+}
+
+type guildMemberName struct {
+	name string
+	id   discord.Snowflake
+}
+
+var guildMembersIndex struct {
+	lock  sync.Mutex
+	index *guildMemberIndex
+}
+
+// loadGuildMemberIndex returns the index, rebuilding it if the directory changed since it was built.
+func loadGuildMemberIndex(ctx context.Context) (*guildMemberIndex, error) {
+	version, err := redis.Get(ctx, GuildMembersVersionRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	guildMembersIndex.lock.Lock()
+	defer guildMembersIndex.lock.Unlock()
+	if index := guildMembersIndex.index; index != nil && index.version == version {
+		return index, nil
+	}
+
+	byID, err := readGuildMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := &guildMemberIndex{version: version, byID: byID}
+	for id, member := range byID {
+		for _, name := range []string{member.Nick, member.User.Username} {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				index.names = append(index.names, guildMemberName{name: name, id: id})
+			}
+		}
+	}
+	sort.Slice(index.names, func(i, j int) bool {
+		if index.names[i].name == index.names[j].name {
+			return index.names[i].id.Less(index.names[j].id)
+		}
+		return index.names[i].name < index.names[j].name
+	})
+	guildMembersIndex.index = index
+	return index, nil
+}
+
+// SearchGuildMembers finds members by nickname or username.
+// Exact matches come first, then prefix matches, then names containing the query,
+// then names within a few typos of the query.
+func SearchGuildMembers(ctx context.Context, query string, limit int) ([]discord.GuildMember, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" || limit <= 0 {
+		return nil, nil
+	}
+	index, err := loadGuildMemberIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		id    discord.Snowflake
+		name  string
+		score int
+	}
+	best := make(map[discord.Snowflake]match)
+	consider := func(name guildMemberName, score int) {
+		if current, ok := best[name.id]; !ok || score < current.score {
+			best[name.id] = match{id: name.id, name: name.name, score: score}
+		}
+	}
+
+	// Prefix matches are a contiguous run of the sorted names.
+	start := sort.Search(len(index.names), func(i int) bool { return index.names[i].name >= query })
+	for i := start; i < len(index.names) && strings.HasPrefix(index.names[i].name, query); i++ {
+		if index.names[i].name == query {
+			consider(index.names[i], 0)
+		} else {
+			consider(index.names[i], 1)
+		}
+	}
+
+	maxTypos := len([]rune(query)) / 4
+	for _, name := range index.names {
+		if _, ok := best[name.id]; ok {
+			continue
+		}
+		switch {
+		case strings.Contains(name.name, query):
+			consider(name, 2)
+		case maxTypos > 0:
+			if typos := fuzzyPrefixDistance(query, name.name); typos <= maxTypos {
+				consider(name, 2+typos)
+			}
+		}
+	}
+
+	matches := make([]match, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		switch {
+		case matches[i].score != matches[j].score:
+			return matches[i].score < matches[j].score
+		case matches[i].name != matches[j].name:
+			return matches[i].name < matches[j].name
+		default:
+			return matches[i].id.Less(matches[j].id)
+		}
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	members := make([]discord.GuildMember, 0, len(matches))
+	for _, m := range matches {
+		members = append(members, index.byID[m.id])
+	}
+	return members, nil
+}
+
+// fuzzyPrefixDistance returns the edit distance between the query and the closest prefix of name.
+func fuzzyPrefixDistance(query string, name string) int {
+	q, n := []rune(query), []rune(name)
+	row := make([]int, len(n)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(q); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(n); j++ {
+			cost := 1
+			if q[i-1] == n[j-1] {
+				cost = 0
+			}
+			next := minInt(minInt(row[j]+1, row[j-1]+1), prev+cost)
+			prev, row[j] = row[j], next
+		}
+	}
+	// The name can end anywhere after the query.
+	best := row[0]
+	for _, d := range row {
+		best = minInt(best, d)
+	}
+	return best
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"testing"
+	"time"
+)
+
+// useGuildMemberStore switches to an empty store and drops the cached member index,
+// since a new store starts its directory version over.
+func useGuildMemberStore() {
+	redis.UseStore(redis.NewMemoryStore())
+	guildMembersIndex.lock.Lock()
+	defer guildMembersIndex.lock.Unlock()
+	guildMembersIndex.index = nil
+}
+
+func TestGuildMembers(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	joined := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	promoted := joined.Add(24 * time.Hour)
+	left := promoted.Add(24 * time.Hour)
+
+	chester := discord.GuildMember{User: discord.User{ID: "42", Username: "chester"}, Roles: []string{"member"}}
+	require.NoError(t, SaveGuildMembers(ctx, joined, chester))
+	chester.Roles = []string{"member", "teams"}
+	require.NoError(t, SaveGuildMembers(ctx, promoted, chester))
+
+	got, err := GetGuildMember(ctx, "42")
+	require.NoError(t, err)
+	assert.Equal(t, &chester, got)
+
+	require.NoError(t, RemoveGuildMembers(ctx, left, "42"))
+	got, err = GetGuildMember(ctx, "42")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	changes, err := ListGuildMemberRoleChanges(ctx, "42")
+	require.NoError(t, err)
+	assert.Equal(t, []GuildMemberRoleChange{
+		{UserID: "42", RoleID: "member", Added: true, At: joined},
+		{UserID: "42", RoleID: "teams", Added: true, At: promoted},
+		{UserID: "42", RoleID: "member", Added: false, At: left},
+		{UserID: "42", RoleID: "teams", Added: false, At: left},
+	}, changes)
+}
+
+func TestGetGuildMembers(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	chester := discord.GuildMember{User: discord.User{ID: "42", Username: "chester"}}
+	brie := discord.GuildMember{User: discord.User{ID: "43", Username: "brie"}}
+	require.NoError(t, SaveGuildMembers(ctx, time.Now(), chester, brie))
+
+	got, err := GetGuildMembers(ctx, "42", "404", "43")
+	require.NoError(t, err)
+	assert.Equal(t, map[discord.Snowflake]discord.GuildMember{"42": chester, "43": brie}, got)
+}
+
+func TestGuildMemberRoleChanges_Trimmed(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	chester := discord.GuildMember{User: discord.User{ID: "42", Username: "chester"}}
+	for i := 0; i < MaxGuildMemberRoleChanges/2+1; i++ {
+		chester.Roles = []string{"teams"}
+		require.NoError(t, SaveGuildMembers(ctx, time.Now(), chester))
+		chester.Roles = nil
+		require.NoError(t, SaveGuildMembers(ctx, time.Now(), chester))
+	}
+
+	changes, err := ListGuildMemberRoleChanges(ctx, "42")
+	require.NoError(t, err)
+	require.Len(t, changes, MaxGuildMemberRoleChanges)
+	assert.False(t, changes[len(changes)-1].Added, "keeps the latest changes")
+}
+
+func TestSearchGuildMembers(t *testing.T) {
+	ctx := context.Background()
+	useGuildMemberStore()
+	members := []discord.GuildMember{
+		{User: discord.User{ID: "1", Username: "cheddar"}},
+		{User: discord.User{ID: "2", Username: "brie"}, Nick: "Chester"},
+		{User: discord.User{ID: "3", Username: "mozzarella"}, Nick: "Ches"},
+		{User: discord.User{ID: "10", Username: "the chess player"}},
+		{User: discord.User{ID: "11", Username: "gouda"}},
+	}
+	require.NoError(t, SaveGuildMembers(ctx, time.Now(), members...))
+
+	search := func(t *testing.T, query string, limit int) []discord.Snowflake {
+		got, err := SearchGuildMembers(ctx, query, limit)
+		require.NoError(t, err)
+		var ids []discord.Snowflake
+		for _, member := range got {
+			ids = append(ids, member.User.ID)
+		}
+		return ids
+	}
+
+	t.Run("exact then prefix then contains then fuzzy", func(t *testing.T) {
+		assert.Equal(t, []discord.Snowflake{"3", "2", "10", "1"}, search(t, "ches", 10))
+	})
+	t.Run("limit", func(t *testing.T) {
+		assert.Equal(t, []discord.Snowflake{"3"}, search(t, "CHES", 1))
+	})
+	t.Run("fuzzy", func(t *testing.T) {
+		assert.Equal(t, []discord.Snowflake{"1"}, search(t, "chedar", 10))
+	})
+	t.Run("rebuilds after changes", func(t *testing.T) {
+		require.NoError(t, SaveGuildMembers(ctx, time.Now(), discord.GuildMember{User: discord.User{ID: "12", Username: "goudapest"}}))
+		assert.Equal(t, []discord.Snowflake{"11", "12"}, search(t, "gouda", 10))
+	})
+}
+
+func TestSnowflake_Less(t *testing.T) {
+	assert.True(t, discord.Snowflake("9").Less("10"))
+	assert.False(t, discord.Snowflake("10").Less("9"))
+}
@@ -0,0 +1,513 @@
+package models
+
+import (
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"strings"
+	"unicode"
+)
+
+var ErrNoInstrumentationTemplate = errors.New("there is no template for instrumentation")
+
+// InstrumentationTemplate is one of the standard score orders described in scoreOrder.md.
+type InstrumentationTemplate struct {
+	Scale    ArrangementScale
+	Title    string
+	Sections []TemplateSection
+}
+
+// TemplateSection is a group of instruments in score order, like the saxophones or the brass.
+type TemplateSection struct {
+	Name        string
+	Instruments []TemplateInstrument
+}
+
+// TemplateInstrument is an instrument of a template.
+type TemplateInstrument struct {
+	Name string
+	// Parts is the number of parts written for the instrument, like the 4 horns in F.
+	Parts    int
+	Optional bool `json:"Optional,omitempty"`
+	// Aliases are other names used for the instrument in part names.
+	Aliases []string `json:"Aliases,omitempty"`
+}
+
+func instrument(name string, parts int, aliases ...string) TemplateInstrument {
+	return TemplateInstrument{Name: name, Parts: parts, Aliases: aliases}
+}
+
+func optional(name string, parts int, aliases ...string) TemplateInstrument {
+	return TemplateInstrument{Name: name, Parts: parts, Optional: true, Aliases: aliases}
+}
+
+// InstrumentationTemplates are the templates in the order they appear in scoreOrder.md.
+var InstrumentationTemplates = []InstrumentationTemplate{
+	{
+		Scale: ArrangementScaleFull,
+		Title: "VVGO Standard Full Instrumentation",
+		Sections: []TemplateSection{
+			{Name: "Orchestral Woodwinds", Instruments: []TemplateInstrument{
+				instrument("Piccolo", 1),
+				instrument("Flute", 2),
+				instrument("Oboe", 2),
+				instrument("English Horn", 1, "Cor Anglais"),
+				instrument("Eb Clarinet", 1, "Eb Soprano Clarinet", "Eb Sopranino Clarinet"),
+				instrument("Clarinet", 2, "Bb Clarinet"),
+				instrument("Bass Clarinet", 1),
+				instrument("Contrabass Clarinet", 1),
+				instrument("Bassoon", 2),
+				instrument("Contrabassoon", 1),
+			}},
+			{Name: "Saxophones", Instruments: []TemplateInstrument{
+				instrument("Soprano Saxophone", 1, "Soprano Sax"),
+				instrument("Alto Saxophone", 2, "Alto Sax", "Eb Alto Saxophone"),
+				instrument("Tenor Saxophone", 1, "Tenor Sax"),
+				instrument("Baritone Saxophone", 1, "Baritone Sax", "Bari Sax", "Eb Baritone Saxophone"),
+			}},
+			{Name: "Brass", Instruments: []TemplateInstrument{
+				instrument("Horn", 4, "French Horn"),
+				instrument("Trumpet", 3),
+				instrument("Trombone", 2),
+				instrument("Bass Trombone", 1),
+				instrument("Euphonium", 1),
+				instrument("Tuba", 1),
+			}},
+			{Name: "Percussion", Instruments: []TemplateInstrument{
+				instrument("Timpani", 1),
+				instrument("Snare Drum", 1),
+				instrument("Bass Drum", 1),
+				instrument("Piatti", 1, "Clash Cymbals", "Crash Cymbals"),
+				instrument("Suspended Cymbal", 1),
+				instrument("Tam-tam", 1),
+				instrument("Triangle", 1),
+				instrument("Tambourine", 1),
+				instrument("Shaker", 1),
+				instrument("Glockenspiel", 1, "Bells"),
+			}},
+			{Name: "Modern Band and Non-Bowed Strings", Instruments: []TemplateInstrument{
+				instrument("Electric Guitar", 1, "Guitar"),
+				instrument("Electric Bass", 1, "Bass Guitar"),
+				instrument("Drums", 1, "Drum Set", "Drum Kit"),
+				instrument("Harp", 1),
+				instrument("Piano", 1),
+			}},
+			{Name: "Vocals", Instruments: []TemplateInstrument{
+				instrument("Soprano", 1),
+				instrument("Alto", 1),
+				instrument("Tenor", 1),
+				instrument("Bass", 1),
+			}},
+			{Name: "Bowed Strings", Instruments: []TemplateInstrument{
+				instrument("Violin I", 1),
+				instrument("Violin II", 1),
+				instrument("Viola", 1),
+				instrument("Violoncello", 1, "Cello"),
+				instrument("Contrabass", 1, "Double Bass", "Upright Bass", "String Bass"),
+			}},
+		},
+	},
+	{
+		Scale: ArrangementScaleBigBand,
+		Title: "Big Band Instrumentation",
+		Sections: []TemplateSection{
+			{Name: "Saxophones", Instruments: []TemplateInstrument{
+				optional("Soprano Saxophone", 1, "Soprano Sax"),
+				instrument("Alto Saxophone", 2, "Alto Sax", "Eb Alto Saxophone"),
+				instrument("Tenor Saxophone", 2, "Tenor Sax"),
+				instrument("Baritone Saxophone", 1, "Baritone Sax", "Bari Sax", "Eb Baritone Saxophone"),
+				optional("Bass Saxophone", 1, "Bass Sax"),
+			}},
+			{Name: "Other Woodwinds", Instruments: []TemplateInstrument{
+				optional("Flute", 1),
+				optional("Clarinet", 1, "Bb Clarinet"),
+				optional("Bass Clarinet", 1),
+			}},
+			{Name: "Brass", Instruments: []TemplateInstrument{
+				instrument("Trumpet", 4),
+				optional("Flügelhorn", 1, "Flugelhorn"),
+				optional("Horn", 1, "French Horn", "Mellophone"),
+				instrument("Trombone", 4),
+				optional("Bass Trombone", 1),
+				optional("Euphonium", 1, "Baritone Horn"),
+				optional("Tuba", 1),
+			}},
+			{Name: "Bowed Strings", Instruments: []TemplateInstrument{
+				optional("Violin", 1),
+				optional("Viola", 1),
+				optional("Cello", 1, "Violoncello"),
+			}},
+			{Name: "Rhythm Section", Instruments: []TemplateInstrument{
+				optional("Mallet Percussion", 1, "Vibraphone", "Xylophone", "Marimba", "Glockenspiel"),
+				instrument("Guitar", 1, "Electric Guitar"),
+				instrument("Piano", 1),
+				instrument("Bass", 1, "Electric Bass", "Upright Bass", "Double Bass", "String Bass"),
+				instrument("Drums", 1, "Drum Set", "Drum Kit"),
+				optional("Auxiliary Percussion", 1, "Aux Percussion"),
+			}},
+		},
+	},
+	{
+		Scale: ArrangementScaleWindEnsemble,
+		Title: "Wind Ensemble Instrumentation",
+		Sections: []TemplateSection{
+			{Name: "Orchestral Woodwinds", Instruments: []TemplateInstrument{
+				instrument("Piccolo", 1),
+				instrument("Flute", 2),
+				instrument("Alto Flute", 1),
+				instrument("Oboe", 2),
+				instrument("English Horn", 1, "Cor Anglais"),
+				instrument("Bassoon", 2),
+				instrument("Contrabassoon", 1),
+				instrument("Eb Clarinet", 1, "Eb Soprano Clarinet", "Eb Sopranino Clarinet"),
+				instrument("Clarinet", 2, "Bb Clarinet"),
+				instrument("Alto Clarinet", 1, "Eb Alto Clarinet"),
+				instrument("Bass Clarinet", 1),
+				instrument("Contrabass Clarinet", 1, "Contra-alto Clarinet", "Eb Contra-alto Clarinet", "Great Bass Clarinet"),
+			}},
+			{Name: "Saxophones", Instruments: []TemplateInstrument{
+				instrument("Soprano Saxophone", 1, "Soprano Sax"),
+				instrument("Alto Saxophone", 2, "Alto Sax", "Eb Alto Saxophone"),
+				instrument("Tenor Saxophone", 2, "Tenor Sax"),
+				instrument("Baritone Saxophone", 1, "Baritone Sax", "Bari Sax", "Eb Baritone Saxophone"),
+				instrument("Bass Saxophone", 1, "Bass Sax"),
+			}},
+			{Name: "Brass", Instruments: []TemplateInstrument{
+				instrument("Trumpet", 3),
+				instrument("Horn", 4, "French Horn"),
+				instrument("Alto Horn", 1, "Tenor Horn", "Eb Alto Horn", "Eb Tenor Horn"),
+				instrument("Trombone", 2),
+				optional("Bass Trombone", 1),
+				instrument("Euphonium", 1, "Baritone Horn", "Baritone"),
+				instrument("Tuba", 1),
+			}},
+			{Name: "Percussion", Instruments: []TemplateInstrument{
+				instrument("Timpani", 1),
+				optional("Snare Drum", 1),
+				optional("Bass Drum", 1),
+				optional("Bongos", 1),
+				optional("Congas", 1),
+				optional("Tom-toms", 1, "Tenor Drums"),
+				optional("Piatti", 1, "Clash Cymbals", "Crash Cymbals"),
+				optional("Suspended Cymbal", 1),
+				optional("Tam-tam", 1),
+				optional("Triangle", 1),
+				optional("Tambourine", 1),
+				optional("Shaker", 1),
+				optional("Glockenspiel", 1, "Bells"),
+				optional("Xylophone", 1),
+				optional("Vibraphone", 1),
+				optional("Marimba", 1),
+				optional("Chimes", 1, "Tubular Bells"),
+			}},
+		},
+	},
+	{
+		Scale: ArrangementScaleEverythingWeHave,
+		Title: "\"Everything We Have\" Instrumentation",
+		Sections: []TemplateSection{
+			{Name: "Flutes", Instruments: []TemplateInstrument{
+				instrument("Piccolo", 1),
+				instrument("Flute", 2),
+				optional("Alto Flute", 1),
+				optional("Bass Flute", 1),
+				optional("Kaval", 1),
+				optional("Ryūteki", 1, "Ryuteki"),
+				optional("Ocarina", 1),
+				optional("Recorder", 1),
+				optional("Tin Whistle", 1, "Irish Whistle", "Low Whistle"),
+			}},
+			{Name: "Double Reeds", Instruments: []TemplateInstrument{
+				optional("Tenor Crumhorn", 1, "Crumhorn"),
+				instrument("Oboe", 2),
+				instrument("English Horn", 1, "Cor Anglais"),
+				optional("Heckelphone", 1),
+				optional("Duduk", 1),
+			}},
+			{Name: "Clarinets", Instruments: []TemplateInstrument{
+				instrument("Eb Clarinet", 1, "Eb Soprano Clarinet", "Eb Sopranino Clarinet"),
+				instrument("Clarinet", 2, "Bb Clarinet"),
+				optional("Alto Clarinet", 1, "Eb Alto Clarinet"),
+				instrument("Bass Clarinet", 1),
+				optional("Contra-alto Clarinet", 1, "Eb Contra-alto Clarinet", "Great Bass Clarinet"),
+				optional("Contrabass Clarinet", 1),
+			}},
+			{Name: "Bassoons", Instruments: []TemplateInstrument{
+				instrument("Bassoon", 2),
+				instrument("Contrabassoon", 1),
+			}},
+			{Name: "Saxophones", Instruments: []TemplateInstrument{
+				instrument("Soprano Saxophone", 1, "Soprano Sax"),
+				instrument("Alto Saxophone", 2, "Alto Sax", "Eb Alto Saxophone"),
+				instrument("Tenor Saxophone", 2, "Tenor Sax"),
+				instrument("Baritone Saxophone", 1, "Baritone Sax", "Bari Sax", "Eb Baritone Saxophone"),
+				optional("Bass Saxophone", 1, "Bass Sax"),
+				optional("Electronic Wind Instrument", 1, "EWI"),
+			}},
+			{Name: "Free Reed", Instruments: []TemplateInstrument{
+				optional("Shō", 1, "Sho"),
+				optional("Melodica", 1),
+				optional("Accordion", 1),
+				optional("Concertina", 1, "Anglo Concertina"),
+			}},
+			{Name: "Brass", Instruments: []TemplateInstrument{
+				instrument("Horn", 4, "French Horn"),
+				optional("Mellophone", 1, "French Horn Bugle"),
+				optional("Piccolo Trumpet", 1),
+				instrument("Trumpet", 2),
+				optional("Cornet", 1, "Eb Soprano Cornet"),
+				optional("Flügelhorn", 1, "Flugelhorn"),
+				optional("Alto Trumpet", 1),
+				optional("Alto Horn", 1, "Tenor Horn", "Eb Alto Horn", "Eb Tenor Horn", "Baritone Horn"),
+				optional("Soprano Trombone", 1),
+				optional("Alto Trombone", 1),
+				instrument("Trombone", 3),
+				instrument("Bass Trombone", 1),
+				instrument("Euphonium", 1),
+				optional("Baritone Bugle", 1),
+				instrument("Tuba", 1, "Helicon"),
+			}},
+			{Name: "Percussion", Instruments: []TemplateInstrument{
+				optional("Timpani", 1),
+				optional("Snare Drum", 1),
+				optional("Bass Drum", 1, "Gran Cassa"),
+				optional("Kakko", 1),
+				optional("Tom-toms", 1, "Tenor Drums"),
+				optional("Bongos", 1),
+				optional("Congas", 1),
+				optional("Taiko", 1, "Taiko Drums", "Chū-daiko"),
+				optional("Bodhrán", 1, "Bodhran"),
+				optional("Piatti", 1, "Clash Cymbals", "Crash Cymbals"),
+				optional("Suspended Cymbal", 1),
+				optional("Tam-tam", 1),
+				optional("Auxiliary Percussion", 1, "Aux Percussion"),
+				optional("Triangle", 1),
+				optional("Wood Block", 1),
+				optional("Cajón", 1, "Cajon"),
+				optional("Tambourine", 1),
+				optional("Shaker", 1),
+				optional("Crotales", 1),
+				optional("Glockenspiel", 1, "Bells"),
+				optional("Xylophone", 1),
+				optional("Vibraphone", 1),
+				optional("Marimba", 1),
+				optional("Chimes", 1, "Tubular Bells"),
+				optional("Waterphone", 1),
+			}},
+			{Name: "Lamellophones, Zithers, Lutes, and Modern Band", Instruments: []TemplateInstrument{
+				optional("Kalimba", 1),
+				optional("Kantele", 1),
+				optional("Koto", 1),
+				optional("Bouzouki", 1),
+				optional("Mandolin", 1),
+				optional("Ukulele", 1),
+				optional("Acoustic Guitar", 1),
+				instrument("Electric Guitar", 1, "Guitar"),
+				instrument("Electric Bass", 1, "Bass Guitar"),
+				instrument("Drums", 1, "Drum Set", "Drum Kit"),
+			}},
+			{Name: "Harp, Keyboards, and Synthesizers", Instruments: []TemplateInstrument{
+				instrument("Harp", 1, "Pedal Harp", "Lever Harp"),
+				instrument("Piano", 1),
+				optional("Keyboard", 1),
+				optional("Synthesizer", 1, "Synth"),
+				optional("Otamatone", 1),
+				optional("Celesta", 1),
+				optional("Organ", 1),
+			}},
+			{Name: "Vocals", Instruments: []TemplateInstrument{
+				instrument("Soprano", 1),
+				optional("Mezzo-soprano", 1),
+				instrument("Alto", 1),
+				optional("Countertenor", 1),
+				instrument("Tenor", 1),
+				optional("Lead", 1),
+				optional("Baritone", 1),
+				instrument("Bass", 1),
+				optional("Vocal Percussion", 1, "Beatbox"),
+			}},
+			{Name: "Bowed Strings", Instruments: []TemplateInstrument{
+				instrument("Violin I", 1),
+				instrument("Violin II", 1),
+				instrument("Viola", 1),
+				instrument("Violoncello", 1, "Cello"),
+				instrument("Contrabass", 1, "Double Bass", "Upright Bass", "String Bass"),
+			}},
+		},
+	},
+}
+
+// GetInstrumentationTemplate returns the template for the scale.
+// Arrangements with the scale "other" have no template.
+func GetInstrumentationTemplate(scale ArrangementScale) (InstrumentationTemplate, bool) {
+	for _, template := range InstrumentationTemplates {
+		if template.Scale == scale {
+			return template, true
+		}
+	}
+	return InstrumentationTemplate{}, false
+}
+
+// UnmarshalSheetCell reads the scale from a sheet, like the Instrumentation column of the Projects sheet.
+// Case and spacing are ignored, and the scale must have a template.
+func (x *ArrangementScale) UnmarshalSheetCell(value string) error {
+	scale := ParseArrangementScale(value)
+	if _, ok := GetInstrumentationTemplate(scale); !ok {
+		scales := make([]ArrangementScale, len(InstrumentationTemplates))
+		for i, template := range InstrumentationTemplates {
+			scales[i] = template.Scale
+		}
+		return fmt.Errorf("there is no template for instrumentation `%s`, must be one of %v", value, scales)
+	}
+	*x = scale
+	return nil
+}
+
+// Match returns the section and instrument that a part is written for.
+// Parts are matched on their Instrument column if it is set, or else their part name.
+// Keys, clefs and part numbers are ignored, so that "Bb Trumpet 2" is a Trumpet part and "Horn in F 3" is a Horn part,
+// unless the template names the part, like Violin I and Violin II.
+func (x InstrumentationTemplate) Match(part Part) (TemplateSection, TemplateInstrument, bool) {
+	name := part.Instrument
+	if name == "" {
+		name = part.PartName
+	}
+
+	for _, keyFunc := range []func(string) string{partKey, instrumentKey} {
+		key := keyFunc(name)
+		for _, section := range x.Sections {
+			for _, instrument := range section.Instruments {
+				for _, instrumentName := range append([]string{instrument.Name}, instrument.Aliases...) {
+					if key != "" && keyFunc(instrumentName) == key {
+						return section, instrument, true
+					}
+				}
+			}
+		}
+	}
+	return TemplateSection{}, TemplateInstrument{}, false
+}
+
+var ignoredInstrumentWords = map[string]bool{
+	"bb": true, "in": true, "c": true, "f": true,
+	"tc": true, "bc": true, "div": true, "divisi": true, "solo": true,
+}
+
+var romanNumerals = map[string]string{"1": "i", "2": "ii", "3": "iii", "4": "iv", "5": "v", "6": "vi"}
+
+// partKey normalizes a part name: keys and clefs are dropped, part numbers are roman numerals, and the last word is singular.
+// Eb is kept, since the Eb Clarinet is not a Clarinet part.
+func partKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var kept []string
+	for _, word := range words {
+		if roman, ok := romanNumerals[word]; ok {
+			word = roman
+		}
+		if !ignoredInstrumentWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return singular(kept)
+}
+
+// instrumentKey normalizes a part name like partKey, and also drops the part numbers.
+func instrumentKey(name string) string {
+	words := strings.Fields(partKey(name))
+	for len(words) > 1 && isPartNumber(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	return singular(words)
+}
+
+func singular(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	last := words[len(words)-1]
+	if !isPartNumber(last) && len(last) > 3 && strings.HasSuffix(last, "s") && !strings.HasSuffix(last, "ss") {
+		words[len(words)-1] = strings.TrimSuffix(last, "s")
+	}
+	return strings.Join(words, " ")
+}
+
+func isPartNumber(word string) bool {
+	for _, roman := range romanNumerals {
+		if word == roman {
+			return true
+		}
+	}
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// InstrumentationReport shows how a project's parts cover a template.
+type InstrumentationReport struct {
+	Project  string
+	Template ArrangementScale
+	Sections []SectionCoverage
+	// UnmappedParts are parts that do not match an instrument in the template.
+	UnmappedParts []string
+}
+
+type SectionCoverage struct {
+	Section     string
+	Instruments []InstrumentCoverage
+	// Covered is true when every required instrument has all of its parts.
+	Covered bool
+	// Missing lists the required instruments that are short of parts.
+	Missing []string `json:"Missing,omitempty"`
+}
+
+type InstrumentCoverage struct {
+	Instrument string
+	Optional   bool `json:"Optional,omitempty"`
+	Want       int
+	Parts      []string
+	Covered    bool
+}
+
+// BuildInstrumentationReport maps the project's parts onto the template.
+func BuildInstrumentationReport(template InstrumentationTemplate, project Project, parts Parts) InstrumentationReport {
+	mapped := make(map[string]map[string][]string)
+	var unmapped []string
+	for _, part := range parts.ForProject(project.Name).Sort() {
+		section, instrument, ok := template.Match(part)
+		if !ok {
+			unmapped = append(unmapped, part.PartName)
+			continue
+		}
+		if mapped[section.Name] == nil {
+			mapped[section.Name] = make(map[string][]string)
+		}
+		mapped[section.Name][instrument.Name] = append(mapped[section.Name][instrument.Name], part.PartName)
+	}
+
+	report := InstrumentationReport{Project: project.Name, Template: template.Scale, UnmappedParts: unmapped}
+	for _, section := range template.Sections {
+		coverage := SectionCoverage{Section: section.Name, Covered: true}
+		for _, instrument := range section.Instruments {
+			partNames := mapped[section.Name][instrument.Name]
+			instrumentCoverage := InstrumentCoverage{
+				Instrument: instrument.Name,
+				Optional:   instrument.Optional,
+				Want:       instrument.Parts,
+				Parts:      partNames,
+				Covered:    len(partNames) >= instrument.Parts,
+			}
+			if !instrumentCoverage.Covered && !instrument.Optional {
+				coverage.Covered = false
+				coverage.Missing = append(coverage.Missing, instrument.Name)
+			}
+			coverage.Instruments = append(coverage.Instruments, instrumentCoverage)
+		}
+		report.Sections = append(report.Sections, coverage)
+	}
+	return report
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInstrumentationTemplate_Match(t *testing.T) {
+	template, ok := GetInstrumentationTemplate(ArrangementScaleFull)
+	assert.True(t, ok)
+
+	for _, tt := range []struct {
+		part    Part
+		section string
+		want    string
+	}{
+		{Part{PartName: "Bb Trumpet 2"}, "Brass", "Trumpet"},
+		{Part{PartName: "Horn in F 3"}, "Brass", "Horn"},
+		{Part{PartName: "Euphonium TC"}, "Brass", "Euphonium"},
+		{Part{PartName: "Eb Clarinet"}, "Orchestral Woodwinds", "Eb Clarinet"},
+		{Part{PartName: "Clarinet 1"}, "Orchestral Woodwinds", "Clarinet"},
+		{Part{PartName: "Eb Alto Saxophone 2"}, "Saxophones", "Alto Saxophone"},
+		{Part{PartName: "Violin 1"}, "Bowed Strings", "Violin I"},
+		{Part{PartName: "Violin II"}, "Bowed Strings", "Violin II"},
+		{Part{PartName: "Cello"}, "Bowed Strings", "Violoncello"},
+		{Part{PartName: "Crash Cymbals"}, "Percussion", "Piatti"},
+		{Part{PartName: "Percussion 1", Instrument: "Snare Drum"}, "Percussion", "Snare Drum"},
+	} {
+		t.Run(tt.part.PartName, func(t *testing.T) {
+			section, instrument, ok := template.Match(tt.part)
+			assert.True(t, ok)
+			assert.Equal(t, tt.section, section.Name)
+			assert.Equal(t, tt.want, instrument.Name)
+		})
+	}
+
+	_, _, ok = template.Match(Part{PartName: "Otamatone"})
+	assert.False(t, ok)
+}
+
+func TestBuildInstrumentationReport(t *testing.T) {
+	template, _ := GetInstrumentationTemplate(ArrangementScaleBigBand)
+	project := Project{Name: "14-aurene"}
+	parts := Parts{
+		{Project: "14-aurene", PartName: "Alto Saxophone 1", ScoreOrder: 1},
+		{Project: "14-aurene", PartName: "Alto Saxophone 2", ScoreOrder: 2},
+		{Project: "14-aurene", PartName: "Tenor Saxophone 1", ScoreOrder: 3},
+		{Project: "14-aurene", PartName: "Tenor Saxophone 2", ScoreOrder: 4},
+		{Project: "14-aurene", PartName: "Otamatone", ScoreOrder: 5},
+		{Project: "01-snake-eater", PartName: "Baritone Saxophone"},
+	}
+
+	report := BuildInstrumentationReport(template, project, parts)
+	assert.Equal(t, "14-aurene", report.Project)
+	assert.Equal(t, ArrangementScaleBigBand, report.Template)
+	assert.Equal(t, []string{"Otamatone"}, report.UnmappedParts)
+
+	saxophones := report.Sections[0]
+	assert.Equal(t, "Saxophones", saxophones.Section)
+	assert.False(t, saxophones.Covered)
+	assert.Equal(t, []string{"Baritone Saxophone"}, saxophones.Missing)
+	assert.Equal(t, InstrumentCoverage{Instrument: "Alto Saxophone", Want: 2,
+		Parts: []string{"Alto Saxophone 1", "Alto Saxophone 2"}, Covered: true}, saxophones.Instruments[1])
+
+	otherWoodwinds := report.Sections[1]
+	assert.True(t, otherWoodwinds.Covered, "optional instruments are not missing")
+	assert.Empty(t, otherWoodwinds.Missing)
+}
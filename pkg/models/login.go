@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
 	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/config"
 	"strings"
 	"time"
 )
@@ -41,6 +43,34 @@ const (
 	RoleDownload         Role = "download"
 )
 
+// mappedRoles are the roles that discord roles can be mapped to in the config's RoleMap.
+var mappedRoles = []Role{
+	RoleVVGOVerifiedMember, RoleVVGOProductionTeam, RoleVVGOExecutiveDirector,
+	RoleWriteSpreadsheet, RoleReadSpreadsheet, RoleDownload,
+}
+
+func init() { config.AddValidator(validateRoleMap) }
+
+// validateRoleMap rejects role map entries with an unknown vvgo role, so a typo does not silently give nobody the role.
+// An empty role ignores the discord role.
+func validateRoleMap(config config.Configuration) error {
+	for discordRole, vvgoRole := range config.Discord.RoleMap {
+		if vvgoRole != "" && !containsRole(mappedRoles, Role(vvgoRole)) {
+			return fmt.Errorf("discord role map: %s maps to unknown role %q", discordRole, vvgoRole)
+		}
+	}
+	return nil
+}
+
+func containsRole(roles []Role, role Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 var anonymous = Identity{
 	Kind:  "anonymous",
 	Roles: []Role{RoleAnonymous},
@@ -48,6 +78,36 @@ var anonymous = Identity{
 
 func Anonymous() Identity { return anonymous }
 
+// RolesForGuildMember maps the member's discord roles to vvgo roles using the configured role map.
+func RolesForGuildMember(guildMember discord.GuildMember) []Role {
+	var roles []Role
+	seen := make(map[Role]bool)
+	for _, discordRole := range guildMember.Roles {
+		if discordRole == "" { // ignore empty strings
+			continue
+		}
+		vvgoRole, ok := config.Config().Discord.RoleMap[discordRole]
+		if !ok || vvgoRole == "" {
+			continue
+		}
+		role := Role(vvgoRole)
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// IdentityForGuildMember returns the identity of a discord member, or the anonymous identity if they have no vvgo roles.
+func IdentityForGuildMember(guildMember discord.GuildMember) Identity {
+	roles := RolesForGuildMember(guildMember)
+	if len(roles) == 0 {
+		return Anonymous()
+	}
+	return Identity{Kind: KindDiscord, Roles: roles, DiscordID: guildMember.User.ID.String()}
+}
+
 // Identity A user identity.
 type Identity struct {
 	Key       string
@@ -59,11 +119,15 @@ type Identity struct {
 }
 
 func ListSessions(ctx context.Context, identity Identity) ([]Identity, error) {
-	var keys []string
-	redis.Do(ctx, redis.Cmd(&keys, "KEYS", "sessions:*"))
+	keys, err := redis.Keys(ctx, "sessions:")
+	if err != nil {
+		return nil, err
+	}
 
-	sessionData := make([]string, 0, len(keys))
-	redis.Do(ctx, redis.Cmd(&sessionData, "MGET", keys...))
+	sessionData, err := redis.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
 
 	sessions := make([]Identity, len(keys))
 	for i := range sessionData {
@@ -2,6 +2,7 @@ package models
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/virtual-vgo/vvgo/pkg/config"
 	"testing"
 )
 
@@ -49,3 +50,13 @@ func TestIdentity_AssumeRoles(t *testing.T) {
 	want := Identity{Kind: "weenie", Roles: []Role{"flute", "piccolo"}}
 	assert.Equal(t, want, got)
 }
+
+func TestValidateRoleMap(t *testing.T) {
+	var valid config.Configuration
+	valid.Discord.RoleMap = map[string]string{"leader-role": "vvgo-leader", "ignored-role": ""}
+	assert.NoError(t, validateRoleMap(valid))
+
+	var typo config.Configuration
+	typo.Discord.RoleMap = map[string]string{"teams-role": "vvgo-team"}
+	assert.EqualError(t, validateRoleMap(typo), `discord role map: teams-role maps to unknown role "vvgo-team"`)
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"sort"
+	"time"
+)
+
+const NameCorrectionsRedisKey = "credits:name_corrections"
+
+var ErrNameCorrectionNotFound = errors.New("name correction not found")
+var ErrNameCorrectionReviewed = errors.New("name correction is already reviewed")
+
+type NameCorrectionStatus string
+
+const (
+	NameCorrectionStatusPending  NameCorrectionStatus = "pending"
+	NameCorrectionStatusApproved NameCorrectionStatus = "approved"
+	NameCorrectionStatusRejected NameCorrectionStatus = "rejected"
+)
+
+// NameCorrection is a member's request to change their credited name for a project.
+// Approved corrections are applied on top of the Credits sheet by ListCredits; the sheet itself is not changed.
+// Corrections apply to the member's credits in the project by discord id, so they keep applying if the sheet's name changes.
+type NameCorrection struct {
+	ID            string
+	Project       string
+	DiscordID     string
+	CurrentName   string
+	RequestedName string
+	Status        NameCorrectionStatus
+	RequestedAt   time.Time
+	ReviewedAt    time.Time `json:"ReviewedAt,omitempty"`
+	ReviewedBy    string    `json:"ReviewedBy,omitempty"`
+}
+
+type NameCorrections []NameCorrection
+
+// ListNameCorrections returns every name correction, oldest first.
+func ListNameCorrections(ctx context.Context) (NameCorrections, error) {
+	correctionsMap, err := redis.HGetAll(ctx, NameCorrectionsRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	corrections := make(NameCorrections, 0, len(correctionsMap))
+	for _, correctionJSON := range correctionsMap {
+		var correction NameCorrection
+		if err := json.Unmarshal([]byte(correctionJSON), &correction); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		corrections = append(corrections, correction)
+	}
+	return corrections.Sort(), nil
+}
+
+func saveNameCorrection(ctx context.Context, correction NameCorrection) error {
+	correctionJSON, err := json.Marshal(correction)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.HSet(ctx, NameCorrectionsRedisKey, map[string]string{correction.ID: string(correctionJSON)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// RequestNameCorrection adds a correction for the credit to the review queue.
+// A pending correction from the same member for the same project is replaced.
+func RequestNameCorrection(ctx context.Context, credit Credit, requestedName string) (NameCorrection, error) {
+	corrections, err := ListNameCorrections(ctx)
+	if err != nil {
+		return NameCorrection{}, err
+	}
+
+	now := time.Now().UTC()
+	correction := NameCorrection{
+		ID:            fmt.Sprintf("%s-%d", credit.DiscordID, now.UnixNano()),
+		Project:       credit.Project,
+		DiscordID:     credit.DiscordID,
+		CurrentName:   credit.Name,
+		RequestedName: requestedName,
+		Status:        NameCorrectionStatusPending,
+		RequestedAt:   now,
+	}
+	for _, pending := range corrections.Pending().ForDiscordID(credit.DiscordID) {
+		if pending.Project == credit.Project {
+			correction.ID = pending.ID
+		}
+	}
+	if err := saveNameCorrection(ctx, correction); err != nil {
+		return NameCorrection{}, err
+	}
+	return correction, nil
+}
+
+// ReviewNameCorrection approves or rejects a pending correction.
+// by identifies who made the change.
+func ReviewNameCorrection(ctx context.Context, id string, approve bool, by string) (NameCorrection, error) {
+	corrections, err := ListNameCorrections(ctx)
+	if err != nil {
+		return NameCorrection{}, err
+	}
+	correction, ok := corrections.Get(id)
+	switch {
+	case !ok:
+		return NameCorrection{}, fmt.Errorf("%w: %s", ErrNameCorrectionNotFound, id)
+	case correction.Status != NameCorrectionStatusPending:
+		return NameCorrection{}, fmt.Errorf("%w: %s is %s", ErrNameCorrectionReviewed, id, correction.Status)
+	}
+
+	correction.Status = NameCorrectionStatusRejected
+	if approve {
+		correction.Status = NameCorrectionStatusApproved
+	}
+	correction.ReviewedAt = time.Now().UTC()
+	correction.ReviewedBy = by
+	if err := saveNameCorrection(ctx, correction); err != nil {
+		return NameCorrection{}, err
+	}
+	return correction, nil
+}
+
+func (x NameCorrections) Get(id string) (NameCorrection, bool) {
+	for _, correction := range x {
+		if correction.ID == id {
+			return correction, true
+		}
+	}
+	return NameCorrection{}, false
+}
+
+func (x NameCorrections) ForDiscordID(discordID string) NameCorrections {
+	var want NameCorrections
+	for _, correction := range x {
+		if correction.DiscordID == discordID {
+			want = append(want, correction)
+		}
+	}
+	return want
+}
+
+func (x NameCorrections) Pending() NameCorrections { return x.WithStatus(NameCorrectionStatusPending) }
+
+func (x NameCorrections) WithStatus(status NameCorrectionStatus) NameCorrections {
+	var want NameCorrections
+	for _, correction := range x {
+		if correction.Status == status {
+			want = append(want, correction)
+		}
+	}
+	return want
+}
+
+// WithNameCorrections renames the credits with approved corrections.
+// A correction renames every credit of the member in the project.
+// Corrections are applied in the order they were requested, so the latest wins.
+func (x Credits) WithNameCorrections(corrections NameCorrections) Credits {
+	want := make(Credits, len(x))
+	copy(want, x)
+	for _, correction := range corrections.WithStatus(NameCorrectionStatusApproved) {
+		for i := range want {
+			if correction.DiscordID != "" && want[i].Project == correction.Project && want[i].DiscordID == correction.DiscordID {
+				want[i].Name = correction.RequestedName
+			}
+		}
+	}
+	return want
+}
+
+// Sorting
+
+func (x NameCorrections) Len() int      { return len(x) }
+func (x NameCorrections) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x NameCorrections) Less(i, j int) bool {
+	if x[i].RequestedAt.Equal(x[j].RequestedAt) {
+		return x[i].ID < x[j].ID
+	}
+	return x[i].RequestedAt.Before(x[j].RequestedAt)
+}
+func (x NameCorrections) Sort() NameCorrections { sort.Sort(x); return x }
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"testing"
+)
+
+func TestNameCorrections(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	require.NoError(t, redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetCredits, "test", [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Discord ID"},
+		{"01-snake-eater", 0, "PERFORMERS", "TRUMPET", "Jackson H.", "1234"},
+		{"01-snake-eater", 1, "PERFORMERS", "TROMBONE", "Jackson H.", "1234"},
+		{"02-proof-of-a-hero", 2, "PERFORMERS", "TRUMPET", "Jackson H.", "1234"},
+	}))
+
+	credits, err := ListCredits(ctx)
+	require.NoError(t, err)
+	credit := credits.ForProject("01-snake-eater").ForDiscordID("1234")[0]
+
+	first, err := RequestNameCorrection(ctx, credit, "Jakson H.")
+	require.NoError(t, err)
+	correction, err := RequestNameCorrection(ctx, credit, "Jackson Hall")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, correction.ID, "pending corrections are replaced")
+	assert.Equal(t, NameCorrectionStatusPending, correction.Status)
+
+	corrections, err := ListNameCorrections(ctx)
+	require.NoError(t, err)
+	require.Len(t, corrections, 1)
+	assert.Equal(t, "Jackson Hall", corrections[0].RequestedName)
+
+	_, err = ReviewNameCorrection(ctx, "cheese", true, "discord:42069")
+	assert.ErrorIs(t, err, ErrNameCorrectionNotFound)
+
+	correction, err = ReviewNameCorrection(ctx, correction.ID, true, "discord:42069")
+	require.NoError(t, err)
+	assert.Equal(t, NameCorrectionStatusApproved, correction.Status)
+	assert.Equal(t, "discord:42069", correction.ReviewedBy)
+
+	_, err = ReviewNameCorrection(ctx, correction.ID, false, "discord:42069")
+	assert.ErrorIs(t, err, ErrNameCorrectionReviewed)
+
+	credits, err = ListCredits(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, credit := range credits {
+		names = append(names, credit.Project+" "+credit.Name)
+	}
+	assert.Equal(t, []string{
+		"01-snake-eater Jackson Hall",
+		"01-snake-eater Jackson Hall",
+		"02-proof-of-a-hero Jackson H.",
+	}, names)
+}
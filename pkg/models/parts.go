@@ -3,15 +3,18 @@ package models
 import (
 	"context"
 	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"sort"
 )
 
 const SheetParts = "Parts"
 
 type Part struct {
-	Project            string
-	PartName           string
-	ScoreOrder         int
+	Project    string `sheet:",required"`
+	PartName   string `sheet:",required"`
+	ScoreOrder int
+	// Instrument is the template instrument the part is written for, if the part name does not say.
+	Instrument         string
 	SheetMusicFile     string
 	ClickTrackFile     string
 	ConductorVideo     string
@@ -43,15 +46,8 @@ func ListParts(ctx context.Context, identity Identity) (Parts, error) {
 
 	var allowed []Part
 	for _, part := range parts {
-		if project, ok := projects.Get(part.Project); ok {
-			switch {
-			case project.PartsReleased == true && project.PartsArchived == false:
-				allowed = append(allowed, part)
-			case identity.HasRole(RoleVVGOProductionTeam) && project.PartsArchived == false:
-				allowed = append(allowed, part)
-			case identity.HasRole(RoleVVGOExecutiveDirector):
-				allowed = append(allowed, part)
-			}
+		if project, ok := projects.Get(part.Project); ok && project.PartsVisibleTo(identity) {
+			allowed = append(allowed, part)
 		}
 	}
 	return allowed, nil
@@ -62,7 +58,9 @@ func valuesToParts(values [][]interface{}) Parts {
 		return nil
 	}
 	parts := make([]Part, 0, len(values)-1)
-	UnmarshalSheet(values, &parts)
+	if err := UnmarshalSheet(values, &parts); err != nil {
+		logger.WithError(err).WithField("sheet", SheetParts).Warn("models.UnmarshalSheet() reported errors")
+	}
 	for i := range parts {
 		if parts[i].SheetMusicLink == "" {
 			parts[i].SheetMusicLink = downloadLink(parts[i].SheetMusicFile)
@@ -97,6 +95,8 @@ func (x Parts) ForProject(projects ...string) Parts {
 	return want
 }
 
+func (x Parts) ToValues() ([][]interface{}, error) { return MarshalSheet([]Part(x)) }
+
 func (x Parts) Append(parts Parts) Parts {
 	return append(x, parts...)
 }
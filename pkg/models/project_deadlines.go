@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"time"
+)
+
+const DeadlineExtensionsRedisKey = "projects:deadline_extensions"
+
+var ErrDeadlineNotExtended = errors.New("new deadline is not after the current deadline")
+
+// DeadlineExtension moves the submission deadline of a project later than the deadline in the Projects sheet.
+// A later deadline in the sheet takes precedence.
+type DeadlineExtension struct {
+	Project  string
+	Deadline time.Time
+	// Previous is the deadline before the extension, or the zero time if there was none.
+	Previous time.Time `json:"Previous,omitempty"`
+	At       time.Time
+	By       string
+}
+
+// ListDeadlineExtensions returns the deadline extensions by project name.
+func ListDeadlineExtensions(ctx context.Context) (map[string]DeadlineExtension, error) {
+	extensionsMap, err := redis.HGetAll(ctx, DeadlineExtensionsRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	extensions := make(map[string]DeadlineExtension, len(extensionsMap))
+	for name, extensionJSON := range extensionsMap {
+		var extension DeadlineExtension
+		if err := json.Unmarshal([]byte(extensionJSON), &extension); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		extensions[name] = extension
+	}
+	return extensions, nil
+}
+
+// ExtendDeadline sets a later submission deadline for the project.
+// by identifies who made the change.
+// Extensions of the same project are made one at a time, and each is checked against the latest extension.
+func ExtendDeadline(ctx context.Context, project Project, deadline time.Time, by string) (DeadlineExtension, error) {
+	var extension DeadlineExtension
+	err := redis.WithLock(ctx, projectLifecycleLockKey(project.Name), func() error {
+		previous, _ := project.Deadline()
+		latest, err := getDeadlineExtension(ctx, project.Name)
+		if err != nil {
+			return err
+		}
+		if latest.Deadline.After(previous) {
+			previous = latest.Deadline
+		}
+		if !deadline.After(previous) {
+			return fmt.Errorf("%w: %s is before %s", ErrDeadlineNotExtended,
+				deadline.Format(time.RFC3339), previous.Format(time.RFC3339))
+		}
+
+		extension = DeadlineExtension{
+			Project:  project.Name,
+			Deadline: deadline.UTC(),
+			Previous: previous.UTC(),
+			At:       time.Now().UTC(),
+			By:       by,
+		}
+		extensionJSON, err := json.Marshal(extension)
+		if err != nil {
+			return errors.JsonEncodeFailure(err)
+		}
+		if err := redis.HSet(ctx, DeadlineExtensionsRedisKey, map[string]string{project.Name: string(extensionJSON)}); err != nil {
+			return errors.RedisFailure(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return DeadlineExtension{}, err
+	}
+	return extension, nil
+}
+
+func getDeadlineExtension(ctx context.Context, project string) (DeadlineExtension, error) {
+	extensionJSON, err := redis.HGet(ctx, DeadlineExtensionsRedisKey, project)
+	if err != nil {
+		return DeadlineExtension{}, errors.RedisFailure(err)
+	}
+	var extension DeadlineExtension
+	if extensionJSON == "" {
+		return extension, nil
+	}
+	if err := json.Unmarshal([]byte(extensionJSON), &extension); err != nil {
+		return DeadlineExtension{}, errors.JsonDecodeFailure(err)
+	}
+	return extension, nil
+}
+
+// WithDeadlineExtensions sets the deadline of each extended project.
+func (x Projects) WithDeadlineExtensions(extensions map[string]DeadlineExtension) Projects {
+	want := make(Projects, len(x))
+	for i, project := range x {
+		want[i] = project
+		extension, ok := extensions[project.Name]
+		if ok && extension.Deadline.After(project.SubmissionDeadlineAt) {
+			want[i].SubmissionDeadlineAt = extension.Deadline.In(DeadlineLocation())
+			want[i].SubmissionDeadline = want[i].SubmissionDeadlineAt.Format(time.RFC3339)
+		}
+	}
+	return want
+}
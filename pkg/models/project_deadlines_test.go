@@ -0,0 +1,72 @@
+package models
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"testing"
+	"time"
+)
+
+func TestExtendDeadline(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+
+	deadline := time.Date(2021, 6, 1, 23, 59, 0, 0, time.UTC)
+	project := Project{Name: "01-snake-eater", SubmissionDeadlineAt: deadline}
+
+	_, err := ExtendDeadline(ctx, project, deadline.Add(-time.Hour), "discord:42069")
+	assert.ErrorIs(t, err, ErrDeadlineNotExtended)
+
+	extension, err := ExtendDeadline(ctx, project, deadline.Add(7*24*time.Hour), "discord:42069")
+	require.NoError(t, err)
+	assert.Equal(t, deadline.Add(7*24*time.Hour), extension.Deadline)
+	assert.Equal(t, deadline, extension.Previous)
+	assert.Equal(t, "discord:42069", extension.By)
+
+	extensions, err := ListDeadlineExtensions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]DeadlineExtension{"01-snake-eater": extension}, extensions)
+
+	_, err = ExtendDeadline(ctx, project, deadline.Add(3*24*time.Hour), "discord:42069")
+	assert.ErrorIs(t, err, ErrDeadlineNotExtended, "checked against the latest extension, not the project that was read before it")
+}
+
+func TestProjects_WithDeadlineExtensions(t *testing.T) {
+	deadline := time.Date(2021, 6, 1, 23, 59, 0, 0, time.UTC)
+	extensions := map[string]DeadlineExtension{
+		"01-snake-eater":     {Deadline: deadline.Add(24 * time.Hour)},
+		"02-proof-of-a-hero": {Deadline: deadline.Add(-24 * time.Hour)},
+	}
+	got := Projects{
+		{Name: "01-snake-eater", SubmissionDeadlineAt: deadline},
+		{Name: "02-proof-of-a-hero", SubmissionDeadlineAt: deadline},
+		{Name: "03-the-end", SubmissionDeadlineAt: deadline},
+	}.WithDeadlineExtensions(extensions)
+
+	for i, want := range []time.Time{deadline.Add(24 * time.Hour), deadline, deadline} {
+		gotDeadline, ok := got[i].Deadline()
+		assert.True(t, ok)
+		assert.True(t, want.Equal(gotDeadline), "%s: got %s, want %s", got[i].Name, gotDeadline, want)
+	}
+	assert.Equal(t, "2021-06-02T", got[0].SubmissionDeadline[:11])
+}
+
+func TestListProjects_DeadlineExtensions(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	require.NoError(t, redis.WriteSheet(ctx, SpreadsheetWebsiteData, SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "Parts Released", "Submission Deadline"},
+		{"01-snake-eater", "Snake Eater", true, "2021-06-01 23:59 UTC"},
+	}))
+	projects, err := ListProjects(ctx, Anonymous())
+	require.NoError(t, err)
+	_, err = ExtendDeadline(ctx, projects[0], time.Date(2021, 6, 8, 23, 59, 0, 0, time.UTC), "discord:42069")
+	require.NoError(t, err)
+
+	projects, err = ListProjects(ctx, Anonymous())
+	require.NoError(t, err)
+	deadline, _ := projects[0].Deadline()
+	assert.True(t, time.Date(2021, 6, 8, 23, 59, 0, 0, time.UTC).Equal(deadline))
+}
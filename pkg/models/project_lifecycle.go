@@ -0,0 +1,267 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"time"
+)
+
+const ProjectLifecyclesRedisKey = "projects:lifecycles"
+
+var ErrInvalidTransition = errors.New("invalid project state transition")
+
+// ProjectState is a stage in the life of a project.
+type ProjectState string
+
+func (x ProjectState) String() string { return string(x) }
+
+const (
+	ProjectStateDraft             ProjectState = "draft"
+	ProjectStatePreProduction     ProjectState = "pre-production"
+	ProjectStatePartsReleased     ProjectState = "parts-released"
+	ProjectStateSubmissionsClosed ProjectState = "submissions-closed"
+	ProjectStateMixing            ProjectState = "mixing"
+	ProjectStateReleased          ProjectState = "released"
+	ProjectStateArchived          ProjectState = "archived"
+)
+
+// ProjectStates are the states in lifecycle order.
+var ProjectStates = []ProjectState{
+	ProjectStateDraft,
+	ProjectStatePreProduction,
+	ProjectStatePartsReleased,
+	ProjectStateSubmissionsClosed,
+	ProjectStateMixing,
+	ProjectStateReleased,
+	ProjectStateArchived,
+}
+
+// projectTransitions lists the states a project can move to from each state.
+// Projects mostly move forward, but submissions can be reopened and a project can go back to draft before parts are released.
+var projectTransitions = map[ProjectState][]ProjectState{
+	ProjectStateDraft:             {ProjectStatePreProduction},
+	ProjectStatePreProduction:     {ProjectStateDraft, ProjectStatePartsReleased},
+	ProjectStatePartsReleased:     {ProjectStateSubmissionsClosed},
+	ProjectStateSubmissionsClosed: {ProjectStatePartsReleased, ProjectStateMixing},
+	ProjectStateMixing:            {ProjectStateReleased},
+	ProjectStateReleased:          {ProjectStateArchived},
+	ProjectStateArchived:          {},
+}
+
+func (x ProjectState) IsValid() bool { _, ok := projectTransitions[x]; return ok }
+
+// Transitions returns the states the project can move to.
+func (x ProjectState) Transitions() []ProjectState { return projectTransitions[x] }
+
+func (x ProjectState) CanTransitionTo(to ProjectState) bool {
+	for _, state := range projectTransitions[x] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AtLeast is true if x is the same as or later than state in the lifecycle.
+func (x ProjectState) AtLeast(state ProjectState) bool { return x.index() >= state.index() }
+
+func (x ProjectState) index() int {
+	for i, state := range ProjectStates {
+		if state == x {
+			return i
+		}
+	}
+	return -1
+}
+
+// LifecycleState returns the state of the project.
+// Projects without a state get one derived from the legacy Hidden, PartsReleased, PartsArchived, and VideoReleased columns.
+// Hidden projects are drafts, whatever else is set.
+func (x Project) LifecycleState() ProjectState {
+	switch {
+	case x.State.IsValid():
+		return x.State
+	case x.Hidden:
+		return ProjectStateDraft
+	case x.VideoReleased:
+		return ProjectStateReleased
+	case x.PartsArchived:
+		return ProjectStateMixing
+	case x.PartsReleased:
+		return ProjectStatePartsReleased
+	default:
+		return ProjectStatePreProduction
+	}
+}
+
+// WithState sets the state and the legacy columns that depend on it.
+// Hidden is kept as it is in the sheet.
+func (x Project) WithState(state ProjectState) Project {
+	x.State = state
+	x.PartsReleased = state.AtLeast(ProjectStatePartsReleased)
+	x.PartsArchived = state.AtLeast(ProjectStateMixing)
+	x.VideoReleased = state.AtLeast(ProjectStateReleased)
+	return x
+}
+
+// VisibleTo is true if the identity can see the project.
+// Drafts are only visible to executive directors and pre-production to the production team.
+func (x Project) VisibleTo(identity Identity) bool {
+	switch x.LifecycleState() {
+	case ProjectStateDraft:
+		return identity.HasRole(RoleVVGOExecutiveDirector)
+	case ProjectStatePreProduction:
+		return identity.HasRole(RoleVVGOProductionTeam) || identity.HasRole(RoleVVGOExecutiveDirector)
+	default:
+		return true
+	}
+}
+
+// PartsVisibleTo is true if the identity can see the parts of the project.
+// Parts are public while the project is released to members, and archived once mixing starts.
+func (x Project) PartsVisibleTo(identity Identity) bool {
+	state := x.LifecycleState()
+	switch {
+	case identity.HasRole(RoleVVGOExecutiveDirector):
+		return true
+	case state == ProjectStatePartsReleased, state == ProjectStateSubmissionsClosed:
+		return true
+	case identity.HasRole(RoleVVGOProductionTeam):
+		return !state.AtLeast(ProjectStateMixing)
+	default:
+		return false
+	}
+}
+
+// TransitionAllowed is true if the identity can move a project from x to the state.
+// The production team runs the lifecycle, but only executive directors can send a project back to draft.
+func (x ProjectState) TransitionAllowed(identity Identity, to ProjectState) bool {
+	switch {
+	case identity.HasRole(RoleVVGOExecutiveDirector):
+		return true
+	case to == ProjectStateDraft:
+		return false
+	default:
+		return identity.HasRole(RoleVVGOProductionTeam)
+	}
+}
+
+// AcceptingSubmissions is true if members can submit recordings for the project.
+func (x Project) AcceptingSubmissions() bool {
+	return x.LifecycleState() == ProjectStatePartsReleased
+}
+
+// ProjectTransition is a change of state.
+type ProjectTransition struct {
+	From ProjectState
+	To   ProjectState
+	At   time.Time
+	By   string
+}
+
+// ProjectLifecycle is the state of a project set through the api, and the history of how it got there.
+// It takes precedence over the state in the Projects sheet.
+type ProjectLifecycle struct {
+	Project     string
+	State       ProjectState
+	Transitions []ProjectTransition
+}
+
+// StateSince returns the time the project entered its current state, or the zero time if it is unknown.
+func (x ProjectLifecycle) StateSince() time.Time {
+	if len(x.Transitions) == 0 {
+		return time.Time{}
+	}
+	return x.Transitions[len(x.Transitions)-1].At
+}
+
+// ListProjectLifecycles returns the lifecycles of all projects by project name.
+func ListProjectLifecycles(ctx context.Context) (map[string]ProjectLifecycle, error) {
+	lifecyclesMap, err := redis.HGetAll(ctx, ProjectLifecyclesRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	lifecycles := make(map[string]ProjectLifecycle, len(lifecyclesMap))
+	for name, lifecycleJSON := range lifecyclesMap {
+		var lifecycle ProjectLifecycle
+		if err := json.Unmarshal([]byte(lifecycleJSON), &lifecycle); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		lifecycles[name] = lifecycle
+	}
+	return lifecycles, nil
+}
+
+// GetProjectLifecycle returns the lifecycle of the project.
+// Projects that were never moved through the api return a lifecycle with the state from the sheet and no transitions.
+func GetProjectLifecycle(ctx context.Context, project Project) (ProjectLifecycle, error) {
+	lifecycleJSON, err := redis.HGet(ctx, ProjectLifecyclesRedisKey, project.Name)
+	if err != nil {
+		return ProjectLifecycle{}, errors.RedisFailure(err)
+	}
+	if lifecycleJSON == "" {
+		return ProjectLifecycle{Project: project.Name, State: project.LifecycleState()}, nil
+	}
+
+	var lifecycle ProjectLifecycle
+	if err := json.Unmarshal([]byte(lifecycleJSON), &lifecycle); err != nil {
+		return ProjectLifecycle{}, errors.JsonDecodeFailure(err)
+	}
+	return lifecycle, nil
+}
+
+func projectLifecycleLockKey(project string) string {
+	return ProjectLifecyclesRedisKey + ":lock:" + project
+}
+
+// TransitionProject moves the project to a new state.
+// by identifies who made the change.
+// Transitions of the same project are made one at a time, so concurrent transitions are checked against each other.
+func TransitionProject(ctx context.Context, project Project, to ProjectState, by string) (ProjectLifecycle, error) {
+	var lifecycle ProjectLifecycle
+	err := redis.WithLock(ctx, projectLifecycleLockKey(project.Name), func() error {
+		var err error
+		lifecycle, err = GetProjectLifecycle(ctx, project)
+		if err != nil {
+			return err
+		}
+		if !lifecycle.State.CanTransitionTo(to) {
+			return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, lifecycle.State, to)
+		}
+
+		lifecycle.Transitions = append(lifecycle.Transitions, ProjectTransition{
+			From: lifecycle.State, To: to, At: time.Now().UTC(), By: by,
+		})
+		lifecycle.State = to
+
+		lifecycleJSON, err := json.Marshal(lifecycle)
+		if err != nil {
+			return errors.JsonEncodeFailure(err)
+		}
+		if err := redis.HSet(ctx, ProjectLifecyclesRedisKey, map[string]string{project.Name: string(lifecycleJSON)}); err != nil {
+			return errors.RedisFailure(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return ProjectLifecycle{}, err
+	}
+	return lifecycle, nil
+}
+
+// WithLifecycles sets the state of each project from its lifecycle.
+func (x Projects) WithLifecycles(lifecycles map[string]ProjectLifecycle) Projects {
+	want := make(Projects, len(x))
+	for i, project := range x {
+		if lifecycle, ok := lifecycles[project.Name]; ok {
+			want[i] = project.WithState(lifecycle.State)
+		} else {
+			want[i] = project.WithState(project.LifecycleState())
+		}
+	}
+	return want
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"sync"
+	"testing"
+)
+
+func TestProject_LifecycleState(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		project Project
+		want    ProjectState
+	}{
+		{"state", Project{State: ProjectStateMixing, PartsReleased: true}, ProjectStateMixing},
+		{"invalid state", Project{State: "cheese", PartsReleased: true}, ProjectStatePartsReleased},
+		{"hidden", Project{Hidden: true}, ProjectStateDraft},
+		{"hidden with parts released", Project{Hidden: true, PartsReleased: true}, ProjectStateDraft},
+		{"hidden with video released", Project{Hidden: true, PartsReleased: true, VideoReleased: true}, ProjectStateDraft},
+		{"not released", Project{}, ProjectStatePreProduction},
+		{"parts released", Project{PartsReleased: true}, ProjectStatePartsReleased},
+		{"parts archived", Project{PartsReleased: true, PartsArchived: true}, ProjectStateMixing},
+		{"video released", Project{PartsReleased: true, PartsArchived: true, VideoReleased: true}, ProjectStateReleased},
+	} {
+		assert.Equal(t, tt.want, tt.project.LifecycleState(), tt.name)
+	}
+}
+
+func TestProject_WithState(t *testing.T) {
+	assert.Equal(t, Project{State: ProjectStateDraft}, Project{}.WithState(ProjectStateDraft))
+	assert.Equal(t, Project{State: ProjectStatePreProduction, Hidden: true},
+		Project{Hidden: true}.WithState(ProjectStatePreProduction), "Hidden is kept as stored")
+	assert.Equal(t, Project{State: ProjectStateSubmissionsClosed, PartsReleased: true},
+		Project{VideoReleased: true}.WithState(ProjectStateSubmissionsClosed))
+	assert.Equal(t, Project{State: ProjectStateArchived, PartsReleased: true, PartsArchived: true, VideoReleased: true},
+		Project{}.WithState(ProjectStateArchived))
+}
+
+func TestProject_Visibility(t *testing.T) {
+	member := Identity{Roles: []Role{RoleVVGOVerifiedMember}}
+	teams := Identity{Roles: []Role{RoleVVGOProductionTeam}}
+	leader := Identity{Roles: []Role{RoleVVGOExecutiveDirector}}
+
+	for _, tt := range []struct {
+		state                                      ProjectState
+		project, projectTeams, projectLeader       bool
+		parts, partsTeams, partsLeader, submitting bool
+	}{
+		{ProjectStateDraft, false, false, true, false, true, true, false},
+		{ProjectStatePreProduction, false, true, true, false, true, true, false},
+		{ProjectStatePartsReleased, true, true, true, true, true, true, true},
+		{ProjectStateSubmissionsClosed, true, true, true, true, true, true, false},
+		{ProjectStateMixing, true, true, true, false, false, true, false},
+		{ProjectStateArchived, true, true, true, false, false, true, false},
+	} {
+		project := Project{State: tt.state}
+		assert.Equal(t, tt.project, project.VisibleTo(member), "%s: VisibleTo(member)", tt.state)
+		assert.Equal(t, tt.projectTeams, project.VisibleTo(teams), "%s: VisibleTo(teams)", tt.state)
+		assert.Equal(t, tt.projectLeader, project.VisibleTo(leader), "%s: VisibleTo(leader)", tt.state)
+		assert.Equal(t, tt.parts, project.PartsVisibleTo(member), "%s: PartsVisibleTo(member)", tt.state)
+		assert.Equal(t, tt.partsTeams, project.PartsVisibleTo(teams), "%s: PartsVisibleTo(teams)", tt.state)
+		assert.Equal(t, tt.partsLeader, project.PartsVisibleTo(leader), "%s: PartsVisibleTo(leader)", tt.state)
+		assert.Equal(t, tt.submitting, project.AcceptingSubmissions(), "%s: AcceptingSubmissions()", tt.state)
+	}
+}
+
+func TestProjectState_TransitionAllowed(t *testing.T) {
+	member := Identity{Roles: []Role{RoleVVGOVerifiedMember}}
+	teams := Identity{Roles: []Role{RoleVVGOProductionTeam}}
+	leader := Identity{Roles: []Role{RoleVVGOExecutiveDirector}}
+
+	assert.True(t, ProjectStateDraft.TransitionAllowed(teams, ProjectStatePreProduction))
+	assert.False(t, ProjectStatePreProduction.TransitionAllowed(teams, ProjectStateDraft))
+	assert.True(t, ProjectStatePreProduction.TransitionAllowed(leader, ProjectStateDraft))
+	assert.False(t, ProjectStatePartsReleased.TransitionAllowed(member, ProjectStateSubmissionsClosed))
+}
+
+func TestTransitionProject(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	project := Project{Name: "01-snake-eater", PartsReleased: true}
+
+	lifecycle, err := TransitionProject(ctx, project, ProjectStateSubmissionsClosed, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, ProjectStateSubmissionsClosed, lifecycle.State)
+	require.Len(t, lifecycle.Transitions, 1)
+	assert.Equal(t, ProjectStatePartsReleased, lifecycle.Transitions[0].From)
+	assert.Equal(t, "alice", lifecycle.Transitions[0].By)
+	assert.Equal(t, lifecycle.Transitions[0].At, lifecycle.StateSince())
+
+	_, err = TransitionProject(ctx, project, ProjectStateArchived, "alice")
+	assert.True(t, errors.Is(err, ErrInvalidTransition), "errors.Is(err, ErrInvalidTransition)")
+
+	lifecycle, err = TransitionProject(ctx, project, ProjectStateMixing, "bob")
+	require.NoError(t, err)
+	assert.Len(t, lifecycle.Transitions, 2)
+
+	lifecycles, err := ListProjectLifecycles(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Projects{project.WithState(ProjectStateMixing)}, Projects{project}.WithLifecycles(lifecycles))
+
+	t.Run("concurrent", func(t *testing.T) {
+		project := Project{Name: "02-proof-of-a-hero", PartsReleased: true}
+		var wg sync.WaitGroup
+		errs := make(chan error, 2)
+		for _, to := range []ProjectState{ProjectStateSubmissionsClosed, ProjectStateSubmissionsClosed} {
+			wg.Add(1)
+			go func(to ProjectState) {
+				defer wg.Done()
+				_, err := TransitionProject(ctx, project, to, "alice")
+				errs <- err
+			}(to)
+		}
+		wg.Wait()
+		close(errs)
+
+		var invalid int
+		for err := range errs {
+			if errors.Is(err, ErrInvalidTransition) {
+				invalid++
+			}
+		}
+		assert.Equal(t, 1, invalid, "only one of the transitions is made")
+		lifecycle, err := GetProjectLifecycle(ctx, project)
+		require.NoError(t, err)
+		assert.Len(t, lifecycle.Transitions, 1)
+	})
+}
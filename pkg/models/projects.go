@@ -3,16 +3,22 @@ package models
 import (
 	"context"
 	"sort"
+	"time"
 
 	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
 )
 
 const SheetProjects = "Projects"
 
+var ErrProjectNotFound = errors.New("project not found")
+
 type Project struct {
-	Name                    string
-	Title                   string
+	Name                    string `sheet:",required"`
+	Title                   string `sheet:",required"`
 	Season                  string
+	State                   ProjectState
 	Hidden                  bool
 	PartsReleased           bool
 	PartsArchived           bool
@@ -36,6 +42,12 @@ type Project struct {
 	SubmissionDeadline      string
 	SubmissionLink          string
 	BandcampAlbum           string
+	// Instrumentation is the scale of the arrangement, which picks the template for instrumentation reports.
+	// It is read without case, and scales without a template are reported when the sheet is read.
+	Instrumentation ArrangementScale
+
+	// SubmissionDeadlineAt is parsed from SubmissionDeadline.
+	SubmissionDeadlineAt time.Time `sheet:"-"`
 }
 
 func (x Project) ProjectPage() string { return "/projects?name=" + x.Name }
@@ -44,11 +56,43 @@ func (x Project) PartsPage() string   { return "/parts?project=" + x.Name }
 type Projects []Project
 
 func ListProjects(ctx context.Context, identity Identity) (Projects, error) {
+	projects, err := ListAllProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return projects.ForIdentity(identity), nil
+}
+
+// ListAllProjects returns every project, whoever can see it.
+// It is meant for changes that check permissions themselves, like lifecycle transitions.
+func ListAllProjects(ctx context.Context) (Projects, error) {
 	values, err := redis.ReadSheet(ctx, SpreadsheetWebsiteData, SheetProjects)
 	if err != nil {
 		return nil, err
 	}
-	return ValuesToProjects(values).ForIdentity(identity), nil
+	lifecycles, err := ListProjectLifecycles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := ListDeadlineExtensions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ValuesToProjects(values).WithLifecycles(lifecycles).WithDeadlineExtensions(extensions), nil
+}
+
+// GetProject returns the project with the name, drafts included.
+// Like ListAllProjects, it is meant for changes that check permissions themselves.
+func GetProject(ctx context.Context, name string) (Project, error) {
+	projects, err := ListAllProjects(ctx)
+	if err != nil {
+		return Project{}, err
+	}
+	project, ok := projects.Get(name)
+	if !ok {
+		return Project{}, ErrProjectNotFound
+	}
+	return project, nil
 }
 
 func ValuesToProjects(values [][]interface{}) Projects {
@@ -56,10 +100,14 @@ func ValuesToProjects(values [][]interface{}) Projects {
 		return nil
 	}
 	var projects Projects
-	UnmarshalSheet(values, &projects)
-	return projects.prune()
+	if err := UnmarshalSheet(values, &projects); err != nil {
+		logger.WithError(err).WithField("sheet", SheetProjects).Warn("models.UnmarshalSheet() reported errors")
+	}
+	return projects.prune().withDeadlines(DeadlineLocation())
 }
 
+func (x Projects) ToValues() ([][]interface{}, error) { return MarshalSheet([]Project(x)) }
+
 func (x Projects) prune() Projects {
 	want := make(Projects, 0, len(x))
 	for _, project := range x {
@@ -75,10 +123,16 @@ func (x Projects) prune() Projects {
 	return want[:]
 }
 
+// Current returns the projects with parts released to members.
 func (x Projects) Current() Projects {
-	return x.Query(map[string]interface{}{
-		"Hidden": false, "Video Released": false,
-		"Parts Archived": false, "Parts Released": true})
+	var want Projects
+	for _, project := range x {
+		switch project.LifecycleState() {
+		case ProjectStatePartsReleased, ProjectStateSubmissionsClosed:
+			want = append(want, project)
+		}
+	}
+	return want
 }
 
 func (x Projects) Get(name string) (Project, bool) {
@@ -95,12 +149,7 @@ func (x Projects) Has(name string) bool { _, ok := x.Get(name); return ok }
 func (x Projects) ForIdentity(identity Identity) Projects {
 	var want Projects
 	for _, project := range x {
-		switch {
-		case project.PartsReleased == true:
-			want = append(want, project)
-		case identity.HasRole(RoleVVGOProductionTeam):
-			want = append(want, project)
-		case identity.HasRole(RoleVVGOExecutiveDirector):
+		if project.VisibleTo(identity) {
 			want = append(want, project)
 		}
 	}
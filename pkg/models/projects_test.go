@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_valuesToProjects(t *testing.T) {
@@ -71,6 +72,29 @@ func Test_valuesToProjects(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func Test_valuesToProjects_Instrumentation(t *testing.T) {
+	values := [][]interface{}{
+		{"Name", "Title", "Instrumentation"},
+		{"01-snake-eater", "Snake Eater", "Full"},
+		{"02-proof-of-a-hero", "Proof of a Hero", "Big Band"},
+		{"03-the-end-begins-to-rock", "The End Begins (To Rock)", "ful"},
+	}
+	got := ValuesToProjects(values)
+	var scales []ArrangementScale
+	for _, project := range got {
+		scales = append(scales, project.Instrumentation)
+	}
+	assert.Equal(t, []ArrangementScale{ArrangementScaleFull, ArrangementScaleBigBand, ""}, scales)
+
+	var projects Projects
+	err := UnmarshalSheet(values, &projects)
+	var report SheetErrors
+	require.ErrorAs(t, err, &report)
+	require.Len(t, report, 1)
+	assert.Equal(t, 4, report[0].Row)
+	assert.Equal(t, "Instrumentation", report[0].Column)
+}
+
 func TestProjects_Query(t *testing.T) {
 	assert.Equal(t, Projects{
 		{
@@ -1,10 +1,14 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 const SpreadsheetWebsiteData = "website_data"
@@ -38,34 +42,385 @@ func ValuesToMap(rows [][]interface{}) []map[string]string {
 	return data
 }
 
-func UnmarshalSheet(rows [][]interface{}, dest interface{}) {
-	colNames := make([]string, len(rows[0]))
-	for i := range colNames {
-		colNames[i] = fmt.Sprintf("%s", rows[0][i])
-		colNames[i] = strings.ReplaceAll(colNames[i], " ", "")
+var ErrMissingColumn = errors.New("required column is missing")
+var ErrMissingValue = errors.New("required value is missing")
+
+// SheetTimeLayouts are the layouts tried, in order, when reading a time from a cell.
+var SheetTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"1/2/2006 15:04:05",
+	"1/2/2006",
+}
+
+// SheetCellError is a problem with a single cell.
+// Row is the 1-indexed row in the sheet, where the header is row 1.
+type SheetCellError struct {
+	Row    int
+	Column string
+	Value  string
+	Err    error
+}
+
+func (x SheetCellError) Error() string {
+	if x.Column == "" {
+		return fmt.Sprintf("row %d: %v", x.Row, x.Err)
+	}
+	if x.Value == "" {
+		return fmt.Sprintf("row %d, column `%s`: %v", x.Row, x.Column, x.Err)
 	}
+	return fmt.Sprintf("row %d, column `%s`, value `%s`: %v", x.Row, x.Column, x.Value, x.Err)
+}
 
-	destValue := reflect.ValueOf(dest).Elem()
-	dataType := destValue.Type().Elem()
-	for _, row := range rows[1:] {
-		data := reflect.New(dataType)
-		for i := range row {
-			field := data.Elem().FieldByName(colNames[i])
-			if field.Kind() == reflect.Invalid {
+func (x SheetCellError) Unwrap() error { return x.Err }
+
+func (x SheetCellError) MarshalJSON() ([]byte, error) {
+	var errString string
+	if x.Err != nil {
+		errString = x.Err.Error()
+	}
+	return json.Marshal(struct {
+		Row    int
+		Column string `json:"Column,omitempty"`
+		Value  string `json:"Value,omitempty"`
+		Error  string
+	}{x.Row, x.Column, x.Value, errString})
+}
+
+// SheetErrors is the report returned by UnmarshalSheet.
+type SheetErrors []SheetCellError
+
+func (x SheetErrors) Error() string {
+	errs := make([]string, len(x))
+	for i := range x {
+		errs[i] = x[i].Error()
+	}
+	return strings.Join(errs, "; ")
+}
+
+// sheetColumn is a struct field mapped to a sheet column.
+// The column name comes from the `sheet:"Column Name"` tag.
+// Without a tag, the field name split into words is used, so `PartsReleased` is written as `Parts Released`.
+// Use `sheet:"-"` to skip a field and `sheet:"Column Name,required"` for a column that must be present and filled in.
+type sheetColumn struct {
+	Name     string
+	Field    int
+	Required bool
+}
+
+func sheetColumns(structType reflect.Type) []sheetColumn {
+	var columns []sheetColumn
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		column := sheetColumn{Name: splitWords(field.Name), Field: i}
+		if tag, ok := field.Tag.Lookup("sheet"); ok {
+			opts := strings.Split(tag, ",")
+			if opts[0] == "-" {
+				continue
+			}
+			if opts[0] != "" {
+				column.Name = opts[0]
+			}
+			for _, opt := range opts[1:] {
+				if strings.TrimSpace(opt) == "required" {
+					column.Required = true
+				}
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// splitWords inserts spaces between the words of a camel case name.
+func splitWords(name string) string {
+	runes := []rune(name)
+	var words strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))) {
+			words.WriteRune(' ')
+		}
+		words.WriteRune(r)
+	}
+	return words.String()
+}
+
+// normalizeColumnName is used to match header cells with column names.
+func normalizeColumnName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", ""))
+}
+
+func cellString(cell interface{}) string {
+	if cell == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(cell))
+}
+
+// UnmarshalSheet reads the rows of a sheet into dest, which must be a pointer to a slice of structs.
+// The first row is the header.
+// Every data row is appended to dest, even if some of its cells could not be read.
+// Those cells are left at their zero value and reported in the returned SheetErrors.
+func UnmarshalSheet(rows [][]interface{}, dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice ||
+		destValue.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a slice of structs, got %T", dest)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sliceValue := destValue.Elem()
+	dataType := sliceValue.Type().Elem()
+	columns := sheetColumns(dataType)
+
+	var report SheetErrors
+	headerIndex := make(map[string]int, len(rows[0]))
+	for i, cell := range rows[0] {
+		name := normalizeColumnName(cellString(cell))
+		if _, ok := headerIndex[name]; !ok && name != "" {
+			headerIndex[name] = i
+		}
+	}
+
+	cellIndex := make([]int, len(columns))
+	for i, column := range columns {
+		index, ok := headerIndex[normalizeColumnName(column.Name)]
+		if !ok {
+			index = -1
+			if column.Required {
+				report = append(report, SheetCellError{Row: 1, Column: column.Name, Err: ErrMissingColumn})
+			}
+		}
+		cellIndex[i] = index
+	}
+
+	for rowNum, row := range rows[1:] {
+		data := reflect.New(dataType).Elem()
+		for i, column := range columns {
+			if cellIndex[i] == -1 {
 				continue
 			}
-			switch field.Type().Kind() {
-			case reflect.String:
-				field.SetString(fmt.Sprint(row[i]))
-			case reflect.Bool:
-				val, _ := strconv.ParseBool(fmt.Sprint(row[i]))
-				field.SetBool(val)
-			case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
-				val, _ := strconv.ParseInt(fmt.Sprint(row[i]), 10, 64)
-				field.SetInt(val)
+			var value string
+			if cellIndex[i] < len(row) {
+				value = cellString(row[cellIndex[i]])
 			}
+			if value == "" {
+				if column.Required {
+					report = append(report, SheetCellError{Row: rowNum + 2, Column: column.Name, Err: ErrMissingValue})
+				}
+				continue
+			}
+			if err := setSheetValue(data.Field(column.Field), value); err != nil {
+				report = append(report, SheetCellError{Row: rowNum + 2, Column: column.Name, Value: value, Err: err})
+			}
+		}
+		sliceValue = reflect.Append(sliceValue, data)
+	}
+	destValue.Elem().Set(sliceValue)
+
+	if len(report) != 0 {
+		return report
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// sheetCellUnmarshaler is implemented by types that read their own cells, to normalize or check them.
+type sheetCellUnmarshaler interface {
+	UnmarshalSheetCell(value string) error
+}
+
+func setSheetValue(field reflect.Value, value string) error {
+	if field.CanAddr() {
+		if unmarshaler, ok := field.Addr().Interface().(sheetCellUnmarshaler); ok {
+			return unmarshaler.UnmarshalSheetCell(value)
+		}
+	}
+	if field.Type() == timeType {
+		for _, layout := range SheetTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("cannot parse `%s` as a time", value)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(val)
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		val, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(val)
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		val, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(val)
+	case reflect.Float64, reflect.Float32:
+		val, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(val)
+	case reflect.Slice:
+		parts := strings.Split(value, ",")
+		slice := reflect.MakeSlice(field.Type(), 0, len(parts))
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setSheetValue(elem, part); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// MarshalSheet is the inverse of UnmarshalSheet.
+// src must be a slice of structs.
+// The first row of the result is the header.
+func MarshalSheet(src interface{}) ([][]interface{}, error) {
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Kind() != reflect.Slice || srcValue.Type().Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("src must be a slice of structs, got %T", src)
+	}
+
+	columns := sheetColumns(srcValue.Type().Elem())
+	rows := make([][]interface{}, 0, 1+srcValue.Len())
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	rows = append(rows, header)
+
+	for i := 0; i < srcValue.Len(); i++ {
+		row := make([]interface{}, len(columns))
+		for j, column := range columns {
+			cell, err := sheetValue(srcValue.Index(i).Field(column.Field))
+			if err != nil {
+				return nil, SheetCellError{Row: i + 2, Column: column.Name, Err: err}
+			}
+			row[j] = cell
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func sheetValue(field reflect.Value) (interface{}, error) {
+	if field.Type() == timeType {
+		t := field.Interface().(time.Time)
+		if t.IsZero() {
+			return "", nil
+		}
+		return t.Format(time.RFC3339), nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return field.Bool(), nil
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		return field.Int(), nil
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return field.Uint(), nil
+	case reflect.Float64, reflect.Float32:
+		return field.Float(), nil
+	case reflect.Slice:
+		parts := make([]string, field.Len())
+		for i := range parts {
+			part, err := sheetValue(field.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = fmt.Sprint(part)
+		}
+		return strings.Join(parts, ", "), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", field.Type())
+	}
+}
+
+// SheetRowDiff is a row that differs between two versions of a sheet.
+// Row is the 1-indexed row in the sheet, where the header is row 1.
+// Old is nil for added rows and New is nil for removed rows.
+type SheetRowDiff struct {
+	Row int
+	Old []interface{}
+	New []interface{}
+}
+
+type SheetDiff []SheetRowDiff
+
+// DiffSheets compares two versions of a sheet row by row.
+// Empty cells and trailing empty cells are ignored, since the sheets api omits them.
+func DiffSheets(old, new [][]interface{}) SheetDiff {
+	rows := len(old)
+	if len(new) > rows {
+		rows = len(new)
+	}
+
+	var diff SheetDiff
+	for i := 0; i < rows; i++ {
+		var oldRow, newRow []interface{}
+		if i < len(old) {
+			oldRow = old[i]
+		}
+		if i < len(new) {
+			newRow = new[i]
+		}
+		if !sheetRowsEqual(oldRow, newRow) {
+			diff = append(diff, SheetRowDiff{Row: i + 1, Old: oldRow, New: newRow})
+		}
+	}
+	return diff
+}
+
+func sheetRowsEqual(a, b []interface{}) bool {
+	cells := len(a)
+	if len(b) > cells {
+		cells = len(b)
+	}
+	for i := 0; i < cells; i++ {
+		var aCell, bCell string
+		if i < len(a) {
+			aCell = cellString(a[i])
+		}
+		if i < len(b) {
+			bCell = cellString(b[i])
+		}
+		if aCell != bCell {
+			return false
 		}
-		destValue.Set(reflect.Append(destValue, data.Elem()))
 	}
-	reflect.ValueOf(dest).Elem().Set(destValue)
+	return true
 }
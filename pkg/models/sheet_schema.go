@@ -0,0 +1,277 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+const SheetHighlights = "Highlights"
+const SheetRoster = "Roster"
+
+var ErrUnknownColumn = errors.New("column is not in the schema")
+var ErrDuplicateKey = errors.New("duplicate key")
+var ErrMissingReference = errors.New("referenced row does not exist")
+var ErrNoSchema = errors.New("sheet has no schema")
+
+// SheetSchema describes a sheet in the website_data spreadsheet.
+type SheetSchema struct {
+	SheetName string
+	// Row is a struct used to check headers and cell types with UnmarshalSheet.
+	// A nil Row accepts any columns.
+	Row interface{}
+	// Key lists the columns whose values, taken together, must be unique.
+	Key []string
+	// References lists the columns whose values must exist in another sheet.
+	References []SheetReference
+}
+
+// SheetReference requires the values of Column to be in ToColumn of ToSheet.
+type SheetReference struct {
+	Column   string
+	ToSheet  string
+	ToColumn string
+}
+
+// Leader is a row in the Leaders sheet.
+type Leader struct {
+	Name         string `sheet:",required"`
+	Epithet      string
+	Affiliations string
+	Blurb        string
+	Icon         string
+}
+
+// Highlight is a row in the Highlights sheet.
+type Highlight struct {
+	Alt    string
+	Source string `sheet:",required"`
+}
+
+// RosterMember is a row in the Roster sheet.
+type RosterMember struct {
+	Name        string `sheet:",required"`
+	Instruments string
+	DiscordID   string
+}
+
+var sheetSchemas = make(map[string]SheetSchema)
+
+// RegisterSheetSchema adds or replaces the schema for a sheet.
+func RegisterSheetSchema(schema SheetSchema) { sheetSchemas[schema.SheetName] = schema }
+
+func init() {
+	RegisterSheetSchema(SheetSchema{SheetName: SheetProjects, Row: Project{}, Key: []string{"Name"}})
+	RegisterSheetSchema(SheetSchema{SheetName: SheetParts, Row: Part{}, Key: []string{"Project", "Part Name"},
+		References: []SheetReference{{Column: "Project", ToSheet: SheetProjects, ToColumn: "Name"}}})
+	RegisterSheetSchema(SheetSchema{SheetName: SheetCredits, Row: Credit{},
+		References: []SheetReference{{Column: "Project", ToSheet: SheetProjects, ToColumn: "Name"}}})
+	RegisterSheetSchema(SheetSchema{SheetName: SheetDirectors, Row: Leader{}, Key: []string{"Name"}})
+	RegisterSheetSchema(SheetSchema{SheetName: SheetHighlights, Row: Highlight{}})
+	RegisterSheetSchema(SheetSchema{SheetName: SheetRoster, Row: RosterMember{}})
+}
+
+// SheetValidation is the report for one sheet.
+// Sheets with errors should not be written. Warnings are informational.
+type SheetValidation struct {
+	SheetName string
+	Errors    SheetErrors `json:"Errors,omitempty"`
+	Warnings  SheetErrors `json:"Warnings,omitempty"`
+}
+
+type SheetValidations []SheetValidation
+
+// HasErrors is true if any sheet has errors.
+func (x SheetValidations) HasErrors() bool {
+	for _, validation := range x {
+		if len(validation.Errors) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSpreadsheet checks the sheets of a website_data upload against their schemas.
+// References to sheets that are not in the upload are checked against the sheets in redis.
+// Other spreadsheets are not validated.
+func ValidateSpreadsheet(ctx context.Context, spreadsheet Spreadsheet) (SheetValidations, error) {
+	if spreadsheet.SpreadsheetName != SpreadsheetWebsiteData {
+		return nil, nil
+	}
+
+	uploaded := make(map[string][][]interface{}, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		uploaded[sheet.Name] = sheet.Values
+	}
+	readSheet := func(name string) ([][]interface{}, error) {
+		if values, ok := uploaded[name]; ok {
+			return values, nil
+		}
+		return redis.ReadSheet(ctx, SpreadsheetWebsiteData, name)
+	}
+
+	validations := make(SheetValidations, 0, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		validation, err := ValidateSheet(sheet.Name, sheet.Values, readSheet)
+		if err != nil {
+			return nil, err
+		}
+		validations = append(validations, validation)
+	}
+	return validations, nil
+}
+
+// ValidateSheet checks a sheet against its schema.
+// readSheet is used to look up referenced sheets.
+func ValidateSheet(name string, values [][]interface{}, readSheet func(name string) ([][]interface{}, error)) (SheetValidation, error) {
+	validation := SheetValidation{SheetName: name}
+	schema, ok := sheetSchemas[name]
+	if !ok {
+		validation.Warnings = append(validation.Warnings, SheetCellError{Row: 1, Err: ErrNoSchema})
+		return validation, nil
+	}
+	if len(values) == 0 {
+		validation.Errors = append(validation.Errors, SheetCellError{Row: 1, Err: ErrMissingValue})
+		return validation, nil
+	}
+
+	if schema.Row != nil {
+		validation.Errors = append(validation.Errors, schema.checkRows(values)...)
+		validation.Warnings = append(validation.Warnings, schema.checkHeader(values[0])...)
+	}
+	if len(schema.Key) != 0 {
+		validation.Errors = append(validation.Errors, checkUniqueKey(values, schema.Key)...)
+	}
+	for _, ref := range schema.References {
+		refValues, err := readSheet(ref.ToSheet)
+		if err != nil {
+			return SheetValidation{}, fmt.Errorf("cannot read sheet `%s`: %w", ref.ToSheet, err)
+		}
+		validation.Errors = append(validation.Errors, checkReference(values, ref, refValues)...)
+	}
+	return validation, nil
+}
+
+func (x SheetSchema) checkRows(values [][]interface{}) SheetErrors {
+	dest := reflect.New(reflect.SliceOf(reflect.TypeOf(x.Row)))
+	err := UnmarshalSheet(values, dest.Interface())
+	var report SheetErrors
+	switch {
+	case err == nil:
+		return nil
+	case !errors.As(err, &report):
+		return SheetErrors{{Row: 1, Err: err}}
+	}
+
+	// Blank rows are common in hand edited sheets and are not reported.
+	want := report[:0]
+	for _, cellErr := range report {
+		if cellErr.Row > 1 && errors.Is(cellErr.Err, ErrMissingValue) && isBlankRow(values[cellErr.Row-1]) {
+			continue
+		}
+		want = append(want, cellErr)
+	}
+	if len(want) == 0 {
+		return nil
+	}
+	return want
+}
+
+func isBlankRow(row []interface{}) bool {
+	for _, cell := range row {
+		if cellString(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (x SheetSchema) checkHeader(header []interface{}) SheetErrors {
+	known := make(map[string]bool)
+	for _, column := range sheetColumns(reflect.TypeOf(x.Row)) {
+		known[normalizeColumnName(column.Name)] = true
+	}
+
+	var warnings SheetErrors
+	for _, cell := range header {
+		name := cellString(cell)
+		if name != "" && !known[normalizeColumnName(name)] {
+			warnings = append(warnings, SheetCellError{Row: 1, Column: name, Err: ErrUnknownColumn})
+		}
+	}
+	return warnings
+}
+
+// columnIndex returns the index of column in the header, or -1.
+func columnIndex(header []interface{}, column string) int {
+	for i, cell := range header {
+		if normalizeColumnName(cellString(cell)) == normalizeColumnName(column) {
+			return i
+		}
+	}
+	return -1
+}
+
+func cellAt(row []interface{}, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return cellString(row[index])
+}
+
+func checkUniqueKey(values [][]interface{}, key []string) SheetErrors {
+	indexes := make([]int, len(key))
+	for i, column := range key {
+		if indexes[i] = columnIndex(values[0], column); indexes[i] == -1 {
+			return nil // reported by checkRows if the column is required
+		}
+	}
+
+	var report SheetErrors
+	seen := make(map[string]int)
+	for i, row := range values[1:] {
+		keyValues := make([]string, len(indexes))
+		for j, index := range indexes {
+			keyValues[j] = cellAt(row, index)
+		}
+		keyString := strings.Join(keyValues, ", ")
+		if strings.Trim(keyString, ", ") == "" {
+			continue // blank rows are reported as missing values
+		}
+		if first, ok := seen[keyString]; ok {
+			report = append(report, SheetCellError{Row: i + 2, Column: strings.Join(key, ", "), Value: keyString,
+				Err: fmt.Errorf("%w, first seen in row %d", ErrDuplicateKey, first)})
+			continue
+		}
+		seen[keyString] = i + 2
+	}
+	return report
+}
+
+func checkReference(values [][]interface{}, ref SheetReference, refValues [][]interface{}) SheetErrors {
+	index := columnIndex(values[0], ref.Column)
+	if index == -1 {
+		return nil // reported by checkRows if the column is required
+	}
+
+	want := make(map[string]bool)
+	if len(refValues) != 0 {
+		refIndex := columnIndex(refValues[0], ref.ToColumn)
+		for _, row := range refValues[1:] {
+			want[cellAt(row, refIndex)] = true
+		}
+	}
+
+	var report SheetErrors
+	for i, row := range values[1:] {
+		value := cellAt(row, index)
+		if value != "" && !want[value] {
+			report = append(report, SheetCellError{Row: i + 2, Column: ref.Column, Value: value,
+				Err: fmt.Errorf("%w in %s.%s", ErrMissingReference, ref.ToSheet, ref.ToColumn)})
+		}
+	}
+	return report
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidateSheet(t *testing.T) {
+	projects := [][]interface{}{
+		{"Name", "Title", "Parts Released"},
+		{"01-snake-eater", "Snake Eater", "TRUE"},
+		{"02-proof-of-a-hero", "Proof of a Hero", "FALSE"},
+	}
+	readSheet := func(name string) ([][]interface{}, error) {
+		if name == SheetProjects {
+			return projects, nil
+		}
+		return nil, nil
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		got, err := ValidateSheet(SheetProjects, projects, readSheet)
+		require.NoError(t, err)
+		assert.Equal(t, SheetValidation{SheetName: SheetProjects}, got)
+	})
+
+	t.Run("no schema", func(t *testing.T) {
+		got, err := ValidateSheet("Cheese", [][]interface{}{{"Name"}}, readSheet)
+		require.NoError(t, err)
+		assert.Empty(t, got.Errors)
+		require.Len(t, got.Warnings, 1)
+		assert.True(t, errors.Is(got.Warnings[0], ErrNoSchema))
+	})
+
+	t.Run("renamed column", func(t *testing.T) {
+		got, err := ValidateSheet(SheetProjects, [][]interface{}{
+			{"Project Name", "Title"},
+			{"01-snake-eater", "Snake Eater"},
+		}, readSheet)
+		require.NoError(t, err)
+		assert.Equal(t, SheetErrors{{Row: 1, Column: "Name", Err: ErrMissingColumn}}, got.Errors)
+		assert.Equal(t, SheetErrors{{Row: 1, Column: "Project Name", Err: ErrUnknownColumn}}, got.Warnings)
+	})
+
+	t.Run("renamed title", func(t *testing.T) {
+		got, err := ValidateSheet(SheetProjects, [][]interface{}{
+			{"Name", "Project Title"},
+			{"01-snake-eater", "Snake Eater"},
+		}, readSheet)
+		require.NoError(t, err)
+		assert.Equal(t, SheetErrors{{Row: 1, Column: "Title", Err: ErrMissingColumn}}, got.Errors,
+			"projects without a title are dropped, so the sheet is rejected")
+	})
+
+	t.Run("bad type", func(t *testing.T) {
+		got, err := ValidateSheet(SheetProjects, [][]interface{}{
+			{"Name", "Title", "Parts Released"},
+			{"01-snake-eater", "Snake Eater", "sure"},
+			{},
+		}, readSheet)
+		require.NoError(t, err)
+		require.Len(t, got.Errors, 1)
+		assert.Equal(t, 2, got.Errors[0].Row)
+		assert.Equal(t, "Parts Released", got.Errors[0].Column)
+	})
+
+	t.Run("roster", func(t *testing.T) {
+		got, err := ValidateSheet(SheetRoster, [][]interface{}{
+			{"Name", "Instruments", "Favorite Cheese"},
+			{"Jackson", "Trumpet", "Gouda"},
+			{"", "Tuba"},
+		}, readSheet)
+		require.NoError(t, err)
+		assert.Equal(t, SheetErrors{{Row: 1, Column: "Favorite Cheese", Err: ErrUnknownColumn}}, got.Warnings)
+		require.Len(t, got.Errors, 1)
+		assert.Equal(t, 3, got.Errors[0].Row)
+		assert.True(t, errors.Is(got.Errors[0], ErrMissingValue))
+	})
+
+	t.Run("duplicate key", func(t *testing.T) {
+		got, err := ValidateSheet(SheetParts, [][]interface{}{
+			{"Project", "Part Name"},
+			{"01-snake-eater", "Trumpet 1"},
+			{"01-snake-eater", "Trumpet 2"},
+			{"01-snake-eater", "Trumpet 1"},
+		}, readSheet)
+		require.NoError(t, err)
+		require.Len(t, got.Errors, 1)
+		assert.Equal(t, 4, got.Errors[0].Row)
+		assert.Equal(t, "01-snake-eater, Trumpet 1", got.Errors[0].Value)
+		assert.True(t, errors.Is(got.Errors[0], ErrDuplicateKey))
+	})
+
+	t.Run("missing reference", func(t *testing.T) {
+		got, err := ValidateSheet(SheetParts, [][]interface{}{
+			{"Project", "Part Name"},
+			{"01-snake-eater", "Trumpet 1"},
+			{"03-the-end", "Trumpet 1"},
+		}, readSheet)
+		require.NoError(t, err)
+		require.Len(t, got.Errors, 1)
+		assert.Equal(t, 3, got.Errors[0].Row)
+		assert.Equal(t, "03-the-end", got.Errors[0].Value)
+		assert.True(t, errors.Is(got.Errors[0], ErrMissingReference))
+	})
+}
+
+func TestSheetValidations_HasErrors(t *testing.T) {
+	assert.False(t, SheetValidations{{SheetName: SheetProjects, Warnings: SheetErrors{{Row: 1}}}}.HasErrors())
+	assert.True(t, SheetValidations{{SheetName: SheetProjects}, {SheetName: SheetParts, Errors: SheetErrors{{Row: 1}}}}.HasErrors())
+}
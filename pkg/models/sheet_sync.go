@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"time"
+)
+
+const SheetSyncHistoryRedisKey = "sheets:sync_history"
+
+// MaxSheetSyncs is how many syncs are kept in the history.
+const MaxSheetSyncs = 1000
+
+type SheetSyncDirection string
+
+const (
+	// SheetSyncPull copies a google sheet into redis.
+	SheetSyncPull SheetSyncDirection = "pull"
+	// SheetSyncPush copies a sheet in redis to google.
+	SheetSyncPush SheetSyncDirection = "push"
+)
+
+// SheetSync records one sheet copied between google and redis.
+type SheetSync struct {
+	Direction       SheetSyncDirection
+	SpreadsheetName string
+	SheetName       string
+	StartedAt       time.Time
+	Duration        time.Duration
+	Diff            SheetDiff `json:"Diff,omitempty"`
+	Error           string    `json:"Error,omitempty"`
+}
+
+func SaveSheetSync(ctx context.Context, sync SheetSync) error {
+	syncJSON, err := json.Marshal(sync)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.RPush(ctx, SheetSyncHistoryRedisKey, string(syncJSON)); err != nil {
+		return errors.RedisFailure(err)
+	}
+	if err := redis.LTrim(ctx, SheetSyncHistoryRedisKey, -MaxSheetSyncs, -1); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// ListSheetSyncs returns up to limit of the most recent syncs, newest first.
+func ListSheetSyncs(ctx context.Context, limit int) ([]SheetSync, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	syncsJSON, err := redis.LRange(ctx, SheetSyncHistoryRedisKey, -limit, -1)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	syncs := make([]SheetSync, 0, len(syncsJSON))
+	for i := len(syncsJSON) - 1; i >= 0; i-- {
+		var sync SheetSync
+		if err := json.Unmarshal([]byte(syncsJSON[i]), &sync); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		syncs = append(syncs, sync)
+	}
+	return syncs, nil
+}
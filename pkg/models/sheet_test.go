@@ -0,0 +1,146 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type sheetTestRow struct {
+	Name       string `sheet:"Full Name,required"`
+	Count      int    `sheet:"How Many"`
+	Ratio      float64
+	Active     bool
+	StartDate  time.Time `sheet:"Start"`
+	Tags       []string
+	Ignored    string `sheet:"-"`
+	unexported string
+}
+
+func TestUnmarshalSheet(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var got []sheetTestRow
+		require.NoError(t, UnmarshalSheet([][]interface{}{
+			{"Full Name", "How Many", "Ratio", "active", "Start", "Tags", "Ignored"},
+			{"Jackson", "3", "0.5", true, "2021-03-04", "a, b,c", "cheese"},
+			{"Brandon", float64(7), "", "FALSE", "1/2/2006", ""},
+		}, &got))
+
+		assert.Equal(t, []sheetTestRow{
+			{Name: "Jackson", Count: 3, Ratio: 0.5, Active: true,
+				StartDate: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), Tags: []string{"a", "b", "c"}},
+			{Name: "Brandon", Count: 7,
+				StartDate: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		}, got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var got []sheetTestRow
+		assert.NoError(t, UnmarshalSheet(nil, &got))
+		assert.Empty(t, got)
+	})
+
+	t.Run("invalid dest", func(t *testing.T) {
+		var got sheetTestRow
+		assert.Error(t, UnmarshalSheet([][]interface{}{{"Full Name"}}, &got))
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		var got []sheetTestRow
+		err := UnmarshalSheet([][]interface{}{{"How Many"}, {"1"}}, &got)
+		assert.Equal(t, SheetErrors{{Row: 1, Column: "Full Name", Err: ErrMissingColumn}}, err)
+		assert.Equal(t, []sheetTestRow{{Count: 1}}, got)
+	})
+
+	t.Run("bad cells", func(t *testing.T) {
+		var got []sheetTestRow
+		err := UnmarshalSheet([][]interface{}{
+			{"Full Name", "How Many", "Active", "Start"},
+			{"Jackson", "three", "yes", "2021-03-04"},
+			{"", "4", "true", "tomorrow"},
+		}, &got)
+		require.Error(t, err)
+		report, ok := err.(SheetErrors)
+		require.True(t, ok, "err should be SheetErrors")
+		assert.Len(t, report, 4)
+		assert.Equal(t, 2, report[0].Row)
+		assert.Equal(t, "How Many", report[0].Column)
+		assert.Equal(t, "three", report[0].Value)
+		assert.Equal(t, 2, report[1].Row)
+		assert.Equal(t, "Active", report[1].Column)
+		assert.Equal(t, SheetCellError{Row: 3, Column: "Full Name", Err: ErrMissingValue}, report[2])
+		assert.Equal(t, 3, report[3].Row)
+		assert.Equal(t, "Start", report[3].Column)
+
+		assert.Equal(t, []sheetTestRow{
+			{Name: "Jackson", StartDate: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+			{Count: 4, Active: true},
+		}, got)
+	})
+}
+
+func TestMarshalSheet(t *testing.T) {
+	src := []sheetTestRow{
+		{Name: "Jackson", Count: 3, Ratio: 0.5, Active: true,
+			StartDate: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), Tags: []string{"a", "b"}, Ignored: "cheese"},
+		{Name: "Brandon"},
+	}
+
+	got, err := MarshalSheet(src)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{
+		{"Full Name", "How Many", "Ratio", "Active", "Start", "Tags"},
+		{"Jackson", int64(3), 0.5, true, "2021-03-04T00:00:00Z", "a, b"},
+		{"Brandon", int64(0), 0.0, false, "", ""},
+	}, got)
+
+	var roundTrip []sheetTestRow
+	require.NoError(t, UnmarshalSheet(got, &roundTrip))
+	src[0].Ignored = ""
+	assert.Equal(t, src[0], roundTrip[0])
+	assert.Equal(t, sheetTestRow{Name: "Brandon"}, roundTrip[1])
+}
+
+func TestProjects_ToValues(t *testing.T) {
+	projects := Projects{
+		{Name: "01-snake-eater", Title: "Snake Eater", PartsReleased: true, ClixBy: "Finny Jacob Zeleny"},
+		{Name: "02-proof-of-a-hero", Title: "Proof of a Hero", SubmissionDeadline: "never"},
+	}
+	values, err := projects.ToValues()
+	require.NoError(t, err)
+	assert.Contains(t, values[0], "Parts Released")
+	assert.Contains(t, values[0], "Clix By")
+	assert.Equal(t, projects, ValuesToProjects(values))
+}
+
+func Test_splitWords(t *testing.T) {
+	for _, tt := range []struct{ name, want string }{
+		{"Name", "Name"},
+		{"PartsReleased", "Parts Released"},
+		{"ChoirPronunciationGuide", "Choir Pronunciation Guide"},
+		{"DiscordID", "Discord ID"},
+		{"HTTPServer", "HTTP Server"},
+	} {
+		assert.Equal(t, tt.want, splitWords(tt.name), tt.name)
+	}
+}
+
+func TestDiffSheets(t *testing.T) {
+	old := [][]interface{}{
+		{"Name", "Title"},
+		{"01-snake-eater", "Snake Eater"},
+		{"02-proof-of-a-hero", "Proof of a Hero", ""},
+	}
+	new := [][]interface{}{
+		{"Name", "Title"},
+		{"01-snake-eater", "Snake Eater!"},
+		{"02-proof-of-a-hero", "Proof of a Hero"},
+		{"03-the-end", "The End"},
+	}
+	assert.Equal(t, SheetDiff{
+		{Row: 2, Old: old[1], New: new[1]},
+		{Row: 4, New: new[3]},
+	}, DiffSheets(old, new))
+	assert.Empty(t, DiffSheets(old, old))
+}
@@ -0,0 +1,135 @@
+package models
+
+import "strings"
+
+// OtherSection holds the parts that are not in the project's instrumentation template.
+const OtherSection = "Other"
+
+// SubmissionTracking counts a project's submissions by part and by section.
+type SubmissionTracking struct {
+	Project string
+	// Template is the instrumentation template the parts are grouped by.
+	// It is empty if the project has no template, and then every part is in the Other section.
+	Template    ArrangementScale `json:"Template,omitempty"`
+	Submissions int
+	// Performers is the number of members with at least one submission.
+	Performers int
+	// Parts are in score order, followed by unlisted parts.
+	Parts    []PartSubmissions
+	Sections []SectionSubmissions
+	// MissingParts are the parts with no submissions, in score order.
+	MissingParts []string
+}
+
+type PartSubmissions struct {
+	PartName    string
+	ScoreOrder  int
+	Section     string
+	Submissions int
+	Performers  int
+	// Unlisted is true for submissions to a part that is not in the Parts sheet, like a part that was renamed.
+	Unlisted bool `json:"Unlisted,omitempty"`
+}
+
+type SectionSubmissions struct {
+	Section      string
+	Parts        int
+	Submissions  int
+	Performers   int
+	MissingParts []string `json:"MissingParts,omitempty"`
+}
+
+// BuildSubmissionTracking joins the project's uploads to its parts.
+// Parts are grouped into the sections of the project's instrumentation template.
+func BuildSubmissionTracking(project Project, parts Parts, uploads SubmissionUploads) SubmissionTracking {
+	template, ok := GetInstrumentationTemplate(project.Instrumentation)
+	sectionOf := func(part Part) string {
+		if section, _, ok := template.Match(part); ok {
+			return section.Name
+		}
+		return OtherSection
+	}
+
+	tracking := SubmissionTracking{Project: project.Name}
+	if ok {
+		tracking.Template = template.Scale
+	}
+	partIndex := make(map[string]int)
+	for _, part := range parts.ForProject(project.Name).Sort() {
+		key := strings.ToLower(part.PartName)
+		if _, ok := partIndex[key]; ok {
+			continue
+		}
+		partIndex[key] = len(tracking.Parts)
+		tracking.Parts = append(tracking.Parts, PartSubmissions{
+			PartName:   part.PartName,
+			ScoreOrder: part.ScoreOrder,
+			Section:    sectionOf(part),
+		})
+	}
+
+	performers := make(map[string]bool)
+	partPerformers := make(map[int]map[string]bool)
+	for _, upload := range uploads {
+		if upload.Project != project.Name {
+			continue
+		}
+		key := strings.ToLower(upload.PartName)
+		i, ok := partIndex[key]
+		if !ok {
+			i = len(tracking.Parts)
+			partIndex[key] = i
+			tracking.Parts = append(tracking.Parts, PartSubmissions{
+				PartName: upload.PartName,
+				Section:  sectionOf(Part{PartName: upload.PartName}),
+				Unlisted: true,
+			})
+		}
+		if partPerformers[i] == nil {
+			partPerformers[i] = make(map[string]bool)
+		}
+		partPerformers[i][upload.DiscordID] = true
+		performers[upload.DiscordID] = true
+		tracking.Parts[i].Submissions++
+		tracking.Submissions++
+	}
+	tracking.Performers = len(performers)
+
+	sectionIndex := make(map[string]int)
+	for _, section := range template.Sections {
+		sectionIndex[section.Name] = len(sectionIndex)
+	}
+	sectionIndex[OtherSection] = len(sectionIndex)
+	sections := make([]SectionSubmissions, len(sectionIndex))
+	sectionPerformers := make([]map[string]bool, len(sectionIndex))
+
+	for i := range tracking.Parts {
+		part := &tracking.Parts[i]
+		part.Performers = len(partPerformers[i])
+
+		j := sectionIndex[part.Section]
+		sections[j].Section = part.Section
+		sections[j].Parts++
+		sections[j].Submissions += part.Submissions
+		if sectionPerformers[j] == nil {
+			sectionPerformers[j] = make(map[string]bool)
+		}
+		for discordID := range partPerformers[i] {
+			sectionPerformers[j][discordID] = true
+		}
+		if part.Submissions == 0 {
+			sections[j].MissingParts = append(sections[j].MissingParts, part.PartName)
+			tracking.MissingParts = append(tracking.MissingParts, part.PartName)
+		}
+	}
+
+	// Sections are in score order, and only sections with parts are listed.
+	for j := range sections {
+		if sections[j].Parts == 0 {
+			continue
+		}
+		sections[j].Performers = len(sectionPerformers[j])
+		tracking.Sections = append(tracking.Sections, sections[j])
+	}
+	return tracking
+}
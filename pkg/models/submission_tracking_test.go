@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBuildSubmissionTracking(t *testing.T) {
+	project := Project{Name: "14-aurene", Instrumentation: ArrangementScaleBigBand}
+	parts := Parts{
+		{Project: "14-aurene", PartName: "Trumpet 1", ScoreOrder: 2},
+		{Project: "14-aurene", PartName: "Alto Saxophone 1", ScoreOrder: 1},
+		{Project: "14-aurene", PartName: "Trumpet 2", ScoreOrder: 3},
+		{Project: "14-aurene", PartName: "Otamatone", ScoreOrder: 4},
+		{Project: "01-snake-eater", PartName: "Trumpet 1"},
+	}
+	uploads := SubmissionUploads{
+		{Project: "14-aurene", PartName: "Trumpet 1", DiscordID: "1"},
+		{Project: "14-aurene", PartName: "trumpet 1", DiscordID: "1"},
+		{Project: "14-aurene", PartName: "Trumpet 1", DiscordID: "2"},
+		{Project: "14-aurene", PartName: "Alto Saxophone 1", DiscordID: "2"},
+		{Project: "14-aurene", PartName: "Flugelhorn", DiscordID: "3"},
+	}
+
+	assert.Equal(t, SubmissionTracking{
+		Project:     "14-aurene",
+		Template:    ArrangementScaleBigBand,
+		Submissions: 5,
+		Performers:  3,
+		Parts: []PartSubmissions{
+			{PartName: "Alto Saxophone 1", ScoreOrder: 1, Section: "Saxophones", Submissions: 1, Performers: 1},
+			{PartName: "Trumpet 1", ScoreOrder: 2, Section: "Brass", Submissions: 3, Performers: 2},
+			{PartName: "Trumpet 2", ScoreOrder: 3, Section: "Brass"},
+			{PartName: "Otamatone", ScoreOrder: 4, Section: OtherSection},
+			{PartName: "Flugelhorn", Section: "Brass", Submissions: 1, Performers: 1, Unlisted: true},
+		},
+		Sections: []SectionSubmissions{
+			{Section: "Saxophones", Parts: 1, Submissions: 1, Performers: 1},
+			{Section: "Brass", Parts: 3, Submissions: 4, Performers: 3, MissingParts: []string{"Trumpet 2"}},
+			{Section: OtherSection, Parts: 1, MissingParts: []string{"Otamatone"}},
+		},
+		MissingParts: []string{"Trumpet 2", "Otamatone"},
+	}, BuildSubmissionTracking(project, parts, uploads))
+}
+
+func TestBuildSubmissionTracking_NoTemplate(t *testing.T) {
+	project := Project{Name: "14-aurene"}
+	parts := Parts{{Project: "14-aurene", PartName: "Trumpet 1", ScoreOrder: 1}}
+
+	tracking := BuildSubmissionTracking(project, parts, nil)
+	assert.Equal(t, ArrangementScale(""), tracking.Template)
+	assert.Equal(t, []SectionSubmissions{
+		{Section: OtherSection, Parts: 1, MissingParts: []string{"Trumpet 1"}},
+	}, tracking.Sections, "parts are not grouped by another template")
+}
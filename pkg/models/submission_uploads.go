@@ -0,0 +1,171 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SubmissionUpload is a recording uploaded by a member to the submissions bucket.
+type SubmissionUpload struct {
+	Project   string
+	PartName  string
+	DiscordID string
+	ObjectKey string
+	FileName  string
+	FileSize  int64
+	Checksum  string // hex encoded sha256 of the file, as reported by the uploader
+	CreatedAt time.Time
+}
+
+type SubmissionUploads []SubmissionUpload
+
+func submissionUploadsRedisKey(project string) string { return "submissions:uploads:" + project }
+
+// Uploads are pending until the uploaded object is checked against the size and checksum the uploader reported.
+func pendingSubmissionUploadsRedisKey(project string) string { return "submissions:pending:" + project }
+
+var ErrSubmissionUploadNotFound = errors.New("submission upload not found")
+
+var objectKeyUnsafeChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+func objectKeySafe(str string) string {
+	return strings.Trim(objectKeyUnsafeChars.ReplaceAllString(strings.ToLower(str), "-"), "-")
+}
+
+// NewSubmissionUpload returns a new upload with a unique object key.
+func NewSubmissionUpload(project, partName, discordID, fileName string, fileSize int64, checksum string) SubmissionUpload {
+	now := time.Now()
+	return SubmissionUpload{
+		Project:   project,
+		PartName:  partName,
+		DiscordID: discordID,
+		ObjectKey: fmt.Sprintf("%s/%s/%s-%d-%s",
+			objectKeySafe(project), objectKeySafe(partName), discordID, now.UnixNano(), objectKeySafe(fileName)),
+		FileName:  fileName,
+		FileSize:  fileSize,
+		Checksum:  strings.ToLower(checksum),
+		CreatedAt: now,
+	}
+}
+
+// ListSubmissionUploads returns the uploads for a project, oldest first.
+func ListSubmissionUploads(ctx context.Context, project string) (SubmissionUploads, error) {
+	uploadsMap, err := redis.HGetAll(ctx, submissionUploadsRedisKey(project))
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	uploads := make(SubmissionUploads, 0, len(uploadsMap))
+	for _, uploadJSON := range uploadsMap {
+		var upload SubmissionUpload
+		if err := json.Unmarshal([]byte(uploadJSON), &upload); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads.Sort(), nil
+}
+
+// SaveSubmissionUpload saves an accepted upload.
+func SaveSubmissionUpload(ctx context.Context, upload SubmissionUpload) error {
+	return saveSubmissionUpload(ctx, submissionUploadsRedisKey(upload.Project), upload)
+}
+
+// SavePendingSubmissionUpload saves an upload that has not been checked yet.
+func SavePendingSubmissionUpload(ctx context.Context, upload SubmissionUpload) error {
+	return saveSubmissionUpload(ctx, pendingSubmissionUploadsRedisKey(upload.Project), upload)
+}
+
+func saveSubmissionUpload(ctx context.Context, key string, upload SubmissionUpload) error {
+	uploadJSON, err := json.Marshal(upload)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.HSet(ctx, key, map[string]string{upload.ObjectKey: string(uploadJSON)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// GetPendingSubmissionUpload returns ErrSubmissionUploadNotFound if there is no pending upload for the object key.
+func GetPendingSubmissionUpload(ctx context.Context, project, objectKey string) (SubmissionUpload, error) {
+	uploadJSON, err := redis.HGet(ctx, pendingSubmissionUploadsRedisKey(project), objectKey)
+	if err != nil {
+		return SubmissionUpload{}, errors.RedisFailure(err)
+	}
+	if uploadJSON == "" {
+		return SubmissionUpload{}, ErrSubmissionUploadNotFound
+	}
+	var upload SubmissionUpload
+	if err := json.Unmarshal([]byte(uploadJSON), &upload); err != nil {
+		return SubmissionUpload{}, errors.JsonDecodeFailure(err)
+	}
+	return upload, nil
+}
+
+// AcceptSubmissionUpload moves a pending upload into the project's submissions.
+func AcceptSubmissionUpload(ctx context.Context, upload SubmissionUpload) error {
+	if err := SaveSubmissionUpload(ctx, upload); err != nil {
+		return err
+	}
+	return DeletePendingSubmissionUpload(ctx, upload)
+}
+
+func DeletePendingSubmissionUpload(ctx context.Context, upload SubmissionUpload) error {
+	if err := redis.HDel(ctx, pendingSubmissionUploadsRedisKey(upload.Project), upload.ObjectKey); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// VerifyObject reads the uploaded object and reports how it differs from the size and checksum the uploader reported.
+// It returns an empty string if the object matches.
+func (x SubmissionUpload) VerifyObject(size int64, object io.Reader) (string, error) {
+	return verifyObject(x.FileSize, x.Checksum, size, object)
+}
+
+func verifyObject(wantSize int64, wantChecksum string, size int64, object io.Reader) (string, error) {
+	if size != wantSize {
+		return fmt.Sprintf("uploaded file is %d bytes, not %d", size, wantSize), nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, object); err != nil {
+		return "", err
+	}
+	if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != wantChecksum {
+		return fmt.Sprintf("uploaded file has checksum %s, not %s", checksum, wantChecksum), nil
+	}
+	return "", nil
+}
+
+func (x SubmissionUploads) ForDiscordID(discordID string) SubmissionUploads {
+	var want SubmissionUploads
+	for _, upload := range x {
+		if upload.DiscordID == discordID {
+			want = append(want, upload)
+		}
+	}
+	return want
+}
+
+// Sorting
+
+func (x SubmissionUploads) Len() int      { return len(x) }
+func (x SubmissionUploads) Swap(i, j int) { x[i], x[j] = x[j], x[i] }
+func (x SubmissionUploads) Less(i, j int) bool {
+	if x[i].CreatedAt.Equal(x[j].CreatedAt) {
+		return x[i].ObjectKey < x[j].ObjectKey
+	}
+	return x[i].CreatedAt.Before(x[j].CreatedAt)
+}
+func (x SubmissionUploads) Sort() SubmissionUploads { sort.Sort(x); return x }
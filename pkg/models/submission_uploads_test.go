@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"strings"
+	"testing"
+)
+
+func TestSubmissionUpload_VerifyObject(t *testing.T) {
+	// sha256 of "cheese"
+	upload := NewSubmissionUpload("06-aurene", "Trumpet 1", "1", "take1.wav", 6,
+		"873AC9FFEA4DD04FA719E8920CD6938F0C23CD678AF330939CFF53C3D2855F34")
+
+	for _, tt := range []struct {
+		name   string
+		size   int64
+		object string
+		want   string
+	}{
+		{"ok", 6, "cheese", ""},
+		{"wrong size", 7, "cheeses", "uploaded file is 7 bytes, not 6"},
+		{"wrong checksum", 6, "cheesy", "uploaded file has checksum " +
+			"435d917993db623957c049ed4e9c5cbad0f7eae8a6bc07be7b83b326d99ff831, not " + upload.Checksum},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := upload.VerifyObject(tt.size, strings.NewReader(tt.object))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAcceptSubmissionUpload(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	upload := NewSubmissionUpload("06-aurene", "Trumpet 1", "1", "take1.wav", 6, "")
+	require.NoError(t, SavePendingSubmissionUpload(ctx, upload))
+
+	uploads, err := ListSubmissionUploads(ctx, "06-aurene")
+	require.NoError(t, err)
+	assert.Empty(t, uploads, "pending uploads are not submissions")
+
+	got, err := GetPendingSubmissionUpload(ctx, "06-aurene", upload.ObjectKey)
+	require.NoError(t, err)
+	assert.Equal(t, upload.ObjectKey, got.ObjectKey)
+
+	require.NoError(t, AcceptSubmissionUpload(ctx, got))
+	uploads, err = ListSubmissionUploads(ctx, "06-aurene")
+	require.NoError(t, err)
+	assert.Len(t, uploads, 1)
+	_, err = GetPendingSubmissionUpload(ctx, "06-aurene", upload.ObjectKey)
+	assert.True(t, errors.Is(err, ErrSubmissionUploadNotFound), "errors.Is(%v, ErrSubmissionUploadNotFound)", err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/virtual-vgo/vvgo/pkg/clients/sheets"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"strings"
 )
 
@@ -29,10 +30,15 @@ func valuesToSubmissionRecords(values [][]interface{}) Submissions {
 		return nil
 	}
 	submissionRecords := make([]Submission, 0, len(values)-1) // ignore the header row
-	UnmarshalSheet(values, &submissionRecords)
+	if err := UnmarshalSheet(values, &submissionRecords); err != nil {
+		logger.WithError(err).Warn("models.UnmarshalSheet() reported errors")
+	}
 	return submissionRecords
 }
 
+// ToCredits builds credits from a submissions spreadsheet.
+//
+// Deprecated: credits for submission uploads are generated with GenerateCreditsDraft, which merges members by discord id.
 func (x Submissions) ToCredits(projectName string) Credits {
 	creditsMap := make(map[string]*Credit)
 	for i, record := range x {
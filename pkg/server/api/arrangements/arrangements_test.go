@@ -0,0 +1,149 @@
+package arrangements
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers/test_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestArrangements(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+
+	director := models.Identity{Kind: models.KindDiscord, DiscordID: "1",
+		Roles: []models.Role{models.RoleVVGOVerifiedMember, models.RoleVVGOExecutiveDirector}}
+	repPrep := models.Identity{Kind: models.KindDiscord, DiscordID: "2",
+		Roles: []models.Role{models.RoleVVGOVerifiedMember, models.RoleVVGOProductionTeam}}
+	arranger := models.Identity{Kind: models.KindDiscord, DiscordID: "3", Roles: []models.Role{models.RoleVVGOVerifiedMember}}
+	newRequest := func(method string, target string, body string, identity models.Identity) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &identity))
+	}
+
+	snakeEater := models.NewArrangementSubmission("Snake Eater", "Norihiko Hibino", "Jackson", "3", models.ArrangementScaleFull, "orchestra")
+	snakeEater.AddFile(models.ArrangementFileScore, "Snake Eater.pdf", 1024, strings.Repeat("a", 64))
+	aurene := models.NewArrangementSubmission("Aurene", "Maclaine Diemer", "Brandon", "4", models.ArrangementScaleBigBand, "5444")
+	for _, submission := range []models.ArrangementSubmission{snakeEater, aurene} {
+		require.NoError(t, models.SaveArrangementSubmission(ctx, submission))
+	}
+
+	t.Run("submissions/post", func(t *testing.T) {
+		for _, tt := range []struct {
+			name string
+			body string
+			want string
+		}{
+			{"no title", `{}`, "title is required"},
+			{"bad scale", `{"Title":"a","Composer":"b","Arranger":"c","Scale":"cheese"}`,
+				"scale must be one of [full big-band wind-ensemble everything-we-have other]"},
+			{"no mockup", `{"Title":"a","Composer":"b","Arranger":"c","Scale":"full","Instrumentation":"d"}`,
+				"a mockup is required"},
+			{"bad checksum", `{"Title":"a","Composer":"b","Arranger":"c","Scale":"full","Instrumentation":"d",` +
+				`"Files":[{"Kind":"mockup","FileName":"a.mp3","FileSize":1,"Checksum":"cheese"}]}`,
+				"checksum must be a hex encoded sha256"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError(tt.want),
+					Submissions(newRequest(http.MethodPost, "/submissions", tt.body, arranger)))
+			})
+		}
+	})
+
+	t.Run("review", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("feasibility must be from 1 to 5"),
+			Review(newRequest(http.MethodPost, "/review", `{"Arrangement":"`+snakeEater.ID+`","Feasibility":6}`, repPrep)))
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("arrangement `cheese` not found"),
+			Review(newRequest(http.MethodPost, "/review", `{"Arrangement":"cheese","Feasibility":3}`, repPrep)))
+
+		resp := Review(newRequest(http.MethodPost, "/review",
+			`{"Arrangement":"`+snakeEater.ID+`","Notes":"tough horn parts","Feasibility":3}`, repPrep))
+		require.NotNil(t, resp.Arrangement, resp.Error)
+		require.Len(t, resp.Arrangement.Reviews, 1)
+		assert.Equal(t, "2", resp.Arrangement.Reviews[0].ReviewerDiscordID)
+		assert.Equal(t, 3.0, resp.Arrangement.Feasibility())
+	})
+
+	t.Run("submissions/get", func(t *testing.T) {
+		resp := Submissions(newRequest(http.MethodGet, "/submissions", "", repPrep))
+		require.Len(t, resp.Arrangements, 2)
+		assert.Len(t, resp.Arrangements[0].Reviews, 1)
+
+		resp = Submissions(newRequest(http.MethodGet, "/submissions", "", arranger))
+		require.Len(t, resp.Arrangements, 1)
+		assert.Equal(t, snakeEater.ID, resp.Arrangements[0].ID)
+		assert.Empty(t, resp.Arrangements[0].Reviews, "arrangers do not see reviews")
+	})
+
+	t.Run("file", func(t *testing.T) {
+		want := http_helpers.NewNotFoundError("file `" + snakeEater.Files[0].ObjectKey + "` not found")
+		test_helpers.AssertEqualApiResponses(t, want, File(newRequest(http.MethodGet,
+			"/file?arrangement="+snakeEater.ID+"&objectKey="+snakeEater.Files[0].ObjectKey, "",
+			models.Identity{Kind: models.KindDiscord, DiscordID: "4"})))
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("objectKey is required"),
+			CompleteUpload(newRequest(http.MethodPost, "/complete", `{"Arrangement":"`+snakeEater.ID+`"}`, arranger)))
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("arrangement `cheese` not found"),
+			CompleteUpload(newRequest(http.MethodPost, "/complete", `{"Arrangement":"cheese","ObjectKey":"a"}`, arranger)))
+
+		// Only the arranger completes their uploads, and files are not available until the upload is complete.
+		want := http_helpers.NewNotFoundError("file `" + snakeEater.Files[0].ObjectKey + "` not found")
+		test_helpers.AssertEqualApiResponses(t, want, CompleteUpload(newRequest(http.MethodPost, "/complete",
+			`{"Arrangement":"`+snakeEater.ID+`","ObjectKey":"`+snakeEater.Files[0].ObjectKey+`"}`, repPrep)))
+		test_helpers.AssertEqualApiResponses(t, want, File(newRequest(http.MethodGet,
+			"/file?arrangement="+snakeEater.ID+"&objectKey="+snakeEater.Files[0].ObjectKey, "", repPrep)))
+	})
+
+	t.Run("promote", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("season `season3` has no ballot"),
+			Ballot(newRequest(http.MethodGet, "/ballot?season=season3", "", director)))
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("season is required"),
+			Promote(newRequest(http.MethodPost, "/promote", `{"Arrangements":["cheese"]}`, director)))
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("arrangement not found: cheese"),
+			Promote(newRequest(http.MethodPost, "/promote", `{"Season":"season3","Arrangements":["cheese"]}`, director)))
+
+		resp := Promote(newRequest(http.MethodPost, "/promote",
+			`{"Season":"season3","Arrangements":["`+snakeEater.ID+`"]}`, director))
+		require.NotNil(t, resp.Election, resp.Error)
+		assert.Equal(t, "arrangements-season3", resp.Election.ID)
+		assert.Len(t, resp.Election.Choices, 1, "arrangements can be promoted one at a time")
+
+		resp = Promote(newRequest(http.MethodPost, "/promote",
+			`{"Season":"season3","Arrangements":["`+snakeEater.ID+`","`+aurene.ID+`"]}`, director))
+		require.NotNil(t, resp.Election, resp.Error)
+		assert.Len(t, resp.Election.Choices, 2)
+	})
+
+	t.Run("ballot", func(t *testing.T) {
+		resp := Ballot(newRequest(http.MethodGet, "/ballot?season=season3", "", director))
+		require.NotNil(t, resp.Election, resp.Error)
+		assert.Equal(t, models.ArrangementsBallot{aurene.ID, snakeEater.ID}, resp.Ballot, "sorted by label")
+		assert.Equal(t, "Snake Eater (arr. Jackson)", resp.Election.Label(snakeEater.ID))
+
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewOkResponse(),
+			Ballot(newRequest(http.MethodPost, "/ballot", `{"Season":"season3","Ballot":["`+snakeEater.ID+`"]}`, director)))
+		resp = Ballot(newRequest(http.MethodGet, "/ballot?season=season3", "", director))
+		assert.Equal(t, models.ArrangementsBallot{snakeEater.ID}, resp.Ballot)
+	})
+
+	t.Run("decline", func(t *testing.T) {
+		resp := Decline(newRequest(http.MethodPost, "/decline", `{"Arrangement":"`+aurene.ID+`"}`, director))
+		require.NotNil(t, resp.Arrangement, resp.Error)
+		assert.Equal(t, models.ArrangementStatusDeclined, resp.Arrangement.Status)
+
+		election, err := voting.GetElection(ctx, "arrangements-season3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{snakeEater.ID}, election.Choices, "declined arrangements leave the ballot")
+	})
+}
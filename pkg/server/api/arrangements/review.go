@@ -0,0 +1,124 @@
+package arrangements
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
+	"net/http"
+	"time"
+)
+
+type PostReviewRequest struct {
+	Arrangement string
+	Notes       string
+	Feasibility int
+}
+
+// Review adds the Rep-Prep member's notes and feasibility rating to an arrangement.
+// A member's earlier review of the arrangement is replaced.
+func Review(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data PostReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if data.Feasibility < models.MinFeasibility || data.Feasibility > models.MaxFeasibility {
+		return http_helpers.NewBadRequestError(fmt.Sprintf("feasibility must be from %d to %d",
+			models.MinFeasibility, models.MaxFeasibility))
+	}
+	if identity.DiscordID == "" {
+		return http_helpers.NewBadRequestError("a discord login is required to review")
+	}
+
+	if _, errResp := getSubmission(ctx, data.Arrangement); errResp != nil {
+		return *errResp
+	}
+	submission, err := models.UpdateArrangementSubmission(ctx, data.Arrangement, func(submission *models.ArrangementSubmission) error {
+		submission.AddReview(models.ArrangementReview{
+			ReviewerDiscordID: identity.DiscordID,
+			Notes:             data.Notes,
+			Feasibility:       data.Feasibility,
+			At:                time.Now().UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		logger.MethodFailure(ctx, "models.UpdateArrangementSubmission", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, Arrangement: &submission}
+}
+
+type PostPromoteRequest struct {
+	Season       string
+	Arrangements []string
+}
+
+// Promote puts arrangements on the ballot for a season.
+func Promote(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data PostPromoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	switch {
+	case data.Season == "":
+		return http_helpers.NewBadRequestError("season is required")
+	case len(data.Arrangements) == 0:
+		return http_helpers.NewBadRequestError("arrangements are required")
+	}
+
+	election, err := models.PromoteArrangements(ctx, data.Season, data.Arrangements...)
+	switch {
+	case errors.Is(err, models.ErrArrangementNotFound):
+		return http_helpers.NewNotFoundError(err.Error())
+	case errors.Is(err, models.ErrArrangementDeclined), errors.Is(err, voting.ErrInvalidElection):
+		return http_helpers.NewBadRequestError(err.Error())
+	case err != nil:
+		logger.MethodFailure(ctx, "models.PromoteArrangements", err)
+		return http_helpers.NewInternalServerError()
+	default:
+		return models.ApiResponse{Status: models.StatusOk, Election: &election}
+	}
+}
+
+type PostDeclineRequest struct {
+	Arrangement string
+}
+
+// Decline removes an arrangement from consideration.
+func Decline(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data PostDeclineRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if _, errResp := getSubmission(ctx, data.Arrangement); errResp != nil {
+		return *errResp
+	}
+
+	submission, err := models.DeclineArrangement(ctx, data.Arrangement)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.DeclineArrangement", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, Arrangement: &submission}
+}
@@ -0,0 +1,302 @@
+package arrangements
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/minio/minio-go/v6"
+	minio_wrapper "github.com/virtual-vgo/vvgo/pkg/clients/minio"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const UploadExpiry = 3600 * time.Second // 1 hour to start the upload
+const DownloadExpiry = 3600 * time.Second
+
+var validChecksum = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// Submissions lists arrangement submissions, and submits a new arrangement on POST.
+// Arrangers only see their own submissions.
+// A new submission comes with an upload url for each file; each upload is finished with CompleteUpload.
+func Submissions(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	switch r.Method {
+	case http.MethodGet:
+		return handleGetSubmissions(ctx, identity)
+	case http.MethodPost:
+		return handlePostSubmissions(r, ctx, identity)
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
+}
+
+// canReview is true for Rep-Prep, who are on the production team, and executive directors.
+func canReview(identity models.Identity) bool {
+	return identity.HasRole(models.RoleVVGOProductionTeam) || identity.HasRole(models.RoleVVGOExecutiveDirector)
+}
+
+func handleGetSubmissions(ctx context.Context, identity models.Identity) models.ApiResponse {
+	submissions, err := models.ListArrangementSubmissions(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListArrangementSubmissions", err)
+		return http_helpers.NewInternalServerError()
+	}
+	if !canReview(identity) {
+		submissions = submissions.ForDiscordID(identity.DiscordID)
+		for i := range submissions {
+			submissions[i].Reviews = nil
+		}
+	}
+	if submissions == nil {
+		submissions = models.ArrangementSubmissions{}
+	}
+	return models.ApiResponse{Status: models.StatusOk, Arrangements: submissions}
+}
+
+type PostSubmissionsRequest struct {
+	Title           string
+	Composer        string
+	Arranger        string
+	Scale           models.ArrangementScale
+	Instrumentation string
+	Notes           string
+	Files           []PostSubmissionsFile
+}
+
+type PostSubmissionsFile struct {
+	Kind     models.ArrangementFileKind
+	FileName string
+	FileSize int64
+	Checksum string
+}
+
+func (x PostSubmissionsRequest) validate() string {
+	switch {
+	case x.Title == "":
+		return "title is required"
+	case x.Composer == "":
+		return "composer is required"
+	case x.Arranger == "":
+		return "arranger is required"
+	case !x.Scale.IsValid():
+		return fmt.Sprintf("scale must be one of %v", models.ArrangementScales)
+	case x.Instrumentation == "":
+		return "instrumentation is required"
+	}
+
+	kinds := make(map[models.ArrangementFileKind]bool)
+	for _, file := range x.Files {
+		switch {
+		case !file.Kind.IsValid():
+			return fmt.Sprintf("file kind must be one of %v", models.ArrangementFileKinds)
+		case file.FileName == "":
+			return "fileName is required"
+		case file.FileSize <= 0:
+			return "fileSize must be positive"
+		case !validChecksum.MatchString(file.Checksum):
+			return "checksum must be a hex encoded sha256"
+		}
+		kinds[file.Kind] = true
+	}
+	switch {
+	case !kinds[models.ArrangementFileMockup]:
+		return "a mockup is required"
+	case !kinds[models.ArrangementFileScore]:
+		return "a score is required"
+	default:
+		return ""
+	}
+}
+
+func handlePostSubmissions(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
+	var data PostSubmissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if reason := data.validate(); reason != "" {
+		return http_helpers.NewBadRequestError(reason)
+	}
+	if identity.DiscordID == "" {
+		return http_helpers.NewBadRequestError("a discord login is required to submit")
+	}
+
+	submission := models.NewArrangementSubmission(data.Title, data.Composer, data.Arranger,
+		identity.DiscordID, data.Scale, data.Instrumentation)
+	submission.Notes = data.Notes
+	for _, file := range data.Files {
+		submission.AddFile(file.Kind, file.FileName, file.FileSize, file.Checksum)
+	}
+
+	minioClient, err := minio_wrapper.NewClient()
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.New", err)
+		return http_helpers.NewInternalServerError()
+	}
+	uploadUrls := make(map[string]string, len(submission.Files))
+	for _, file := range submission.Files {
+		uploadUrl, err := minioClient.PresignedPutObject(config.Config().VVGO.ArrangementsBucket, file.ObjectKey, UploadExpiry)
+		if err != nil {
+			logger.MethodFailure(ctx, "minio.PresignedPutObject", err)
+			return http_helpers.NewInternalServerError()
+		}
+		uploadUrls[file.ObjectKey] = uploadUrl.String()
+	}
+
+	if err := models.SaveArrangementSubmission(ctx, submission); err != nil {
+		logger.MethodFailure(ctx, "models.SaveArrangementSubmission", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, Arrangement: &submission, UploadUrls: uploadUrls}
+}
+
+type CompleteUploadRequest struct {
+	Arrangement string
+	ObjectKey   string
+}
+
+// CompleteUpload marks a file of an arrangement as uploaded once the upload is finished.
+// The uploaded object must match the size and checksum that were reported with the submission.
+// Otherwise the object is removed, and a new upload url is returned so the arranger can upload again.
+func CompleteUpload(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data CompleteUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if data.ObjectKey == "" {
+		return http_helpers.NewBadRequestError("objectKey is required")
+	}
+	submission, errResp := getSubmission(ctx, data.Arrangement)
+	if errResp != nil {
+		return *errResp
+	}
+	file, ok := submission.GetFile(data.ObjectKey)
+	switch {
+	case !ok, submission.ArrangerDiscordID != identity.DiscordID:
+		return http_helpers.NewNotFoundError(fmt.Sprintf("file `%s` not found", data.ObjectKey))
+	case file.Uploaded:
+		return models.ApiResponse{Status: models.StatusOk, Arrangement: &submission}
+	}
+
+	minioClient, err := minio_wrapper.NewClient()
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.New", err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	bucket := config.Config().VVGO.ArrangementsBucket
+	object, err := minioClient.GetObject(bucket, file.ObjectKey, minio.GetObjectOptions{})
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.GetObject", err)
+		return http_helpers.NewInternalServerError()
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.Object.Stat", err)
+		if resp, ok := err.(minio.ErrorResponse); ok && resp.StatusCode == http.StatusNotFound {
+			return http_helpers.NewBadRequestError("the upload is not finished")
+		}
+		return http_helpers.NewInternalServerError()
+	}
+
+	reason, err := file.VerifyObject(info.Size, object)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ArrangementFile.VerifyObject", err)
+		return http_helpers.NewInternalServerError()
+	}
+	if reason != "" {
+		if err := minioClient.RemoveObject(bucket, file.ObjectKey); err != nil {
+			logger.MethodFailure(ctx, "minio.RemoveObject", err)
+		}
+		uploadUrl, err := minioClient.PresignedPutObject(bucket, file.ObjectKey, UploadExpiry)
+		if err != nil {
+			logger.MethodFailure(ctx, "minio.PresignedPutObject", err)
+			return http_helpers.NewInternalServerError()
+		}
+		resp := http_helpers.NewBadRequestError(reason)
+		resp.UploadUrls = map[string]string{file.ObjectKey: uploadUrl.String()}
+		return resp
+	}
+
+	submission, err = models.UpdateArrangementSubmission(ctx, submission.ID, func(submission *models.ArrangementSubmission) error {
+		for i := range submission.Files {
+			if submission.Files[i].ObjectKey == file.ObjectKey {
+				submission.Files[i].Uploaded = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.MethodFailure(ctx, "models.UpdateArrangementSubmission", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, Arrangement: &submission}
+}
+
+// File redirects to a file of an arrangement.
+// Reviewers can download any file, and arrangers can download their own.
+// Files are only available once their upload is complete.
+func File(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	id, objectKey := r.URL.Query().Get("arrangement"), r.URL.Query().Get("objectKey")
+	submission, errResp := getSubmission(ctx, id)
+	if errResp != nil {
+		return *errResp
+	}
+	file, ok := submission.GetFile(objectKey)
+	if !ok || !file.Uploaded || !(canReview(identity) || submission.ArrangerDiscordID == identity.DiscordID) {
+		return http_helpers.NewNotFoundError(fmt.Sprintf("file `%s` not found", objectKey))
+	}
+
+	minioClient, err := minio_wrapper.NewClient()
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.New", err)
+		return http_helpers.NewInternalServerError()
+	}
+	downloadUrl, err := minioClient.PresignedGetObject(config.Config().VVGO.ArrangementsBucket, objectKey, DownloadExpiry, nil)
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.PresignedGetObject", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusFound, Location: downloadUrl.String()}
+}
+
+func getSubmission(ctx context.Context, id string) (models.ArrangementSubmission, *models.ApiResponse) {
+	if id == "" {
+		resp := http_helpers.NewBadRequestError("arrangement is required")
+		return models.ArrangementSubmission{}, &resp
+	}
+
+	submission, err := models.GetArrangementSubmission(ctx, id)
+	switch {
+	case errors.Is(err, models.ErrArrangementNotFound):
+		resp := http_helpers.NewNotFoundError(fmt.Sprintf("arrangement `%s` not found", id))
+		return models.ArrangementSubmission{}, &resp
+	case err != nil:
+		logger.MethodFailure(ctx, "models.GetArrangementSubmission", err)
+		resp := http_helpers.NewInternalServerError()
+		return models.ArrangementSubmission{}, &resp
+	default:
+		return submission, nil
+	}
+}
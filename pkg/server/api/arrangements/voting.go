@@ -3,25 +3,28 @@ package arrangements
 import (
 	"context"
 	"encoding/json"
-	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"github.com/virtual-vgo/vvgo/pkg/models"
 	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
 	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
 	"net/http"
 	"sort"
-	"strings"
+	"time"
 )
 
-const Season = "season2"
-
+// Ballot shows the director's ballot for a season's arrangements, and casts it on POST.
+// Arrangements are put on the ballot with Promote.
+// Ballots rank arrangement ids; the election's labels show their titles.
 func Ballot(r *http.Request) models.ApiResponse {
 	ctx := r.Context()
 	identity := login.IdentityFromContext(ctx)
 
 	switch r.Method {
 	case http.MethodGet:
-		return handleGetBallot(ctx, identity)
+		return handleGetBallot(ctx, identity, r.URL.Query().Get("season"))
 	case http.MethodPost:
 		return handlePostBallot(r, ctx, identity)
 	default:
@@ -29,74 +32,68 @@ func Ballot(r *http.Request) models.ApiResponse {
 	}
 }
 
-func handleGetBallot(ctx context.Context, identity models.Identity) models.ApiResponse {
-	var ballotJSON string
-	if err := redis.Do(ctx, redis.Cmd(&ballotJSON,
-		"HGET", "arrangements:"+Season+":ballots", identity.DiscordID)); err != nil {
-		logger.RedisFailure(ctx, err)
+func handleGetBallot(ctx context.Context, identity models.Identity, season string) models.ApiResponse {
+	election, errResp := seasonElection(ctx, season)
+	if errResp != nil {
+		return *errResp
 	}
 
-	var ballot models.ArrangementsBallot
-	if ballotJSON != "" {
-		if err := json.NewDecoder(strings.NewReader(ballotJSON)).Decode(&ballot); err != nil {
-			logger.JsonDecodeFailure(ctx, err)
-			// Don't return here, instead we'll make a new ballot
-		}
+	ballot, err := voting.GetBallot(ctx, election, identity.DiscordID)
+	if err != nil {
+		logger.MethodFailure(ctx, "voting.GetBallot", err)
+		return http_helpers.NewInternalServerError()
 	}
-
 	if len(ballot) == 0 {
-		if err := redis.Do(ctx, redis.Cmd(&ballot,
-			"LRANGE", "arrangements:"+Season+":submissions", "0", "-1")); err != nil {
-			logger.RedisFailure(ctx, err)
-			return http_helpers.NewInternalServerError()
-		}
-		sort.Strings(ballot)
+		ballot = append(voting.Ballot(nil), election.Choices...)
+		sort.Slice(ballot, func(i, j int) bool { return election.Label(ballot[i]) < election.Label(ballot[j]) })
 	}
-
-	return models.ApiResponse{Status: models.StatusOk, Ballot: ballot}
+	return models.ApiResponse{Status: models.StatusOk, Election: &election, Ballot: models.ArrangementsBallot(ballot)}
 }
 
-type PostBallotRequest []string
+type PostBallotRequest struct {
+	Season string
+	Ballot voting.Ballot
+}
 
 func handlePostBallot(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
-	var ballot PostBallotRequest
-	if err := json.NewDecoder(r.Body).Decode(&ballot); err != nil {
+	var data PostBallotRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
 		logger.JsonDecodeFailure(ctx, err)
 		return http_helpers.NewJsonDecodeError(err)
 	}
-	if validateBallot(ctx, ballot) == false {
-		return http_helpers.NewBadRequestError("invalid ballot")
+	election, errResp := seasonElection(ctx, data.Season)
+	if errResp != nil {
+		return *errResp
 	}
 
-	ballotJSON, _ := json.Marshal(ballot)
-	if err := redis.Do(ctx, redis.Cmd(nil,
-		"HSET", "arrangements:"+Season+":ballots", identity.DiscordID, string(ballotJSON))); err != nil {
-		logger.RedisFailure(ctx, err)
+	err := voting.CastBallot(ctx, election, identity.DiscordID, data.Ballot, time.Now())
+	switch {
+	case errors.Is(err, voting.ErrElectionNotOpen), errors.Is(err, voting.ErrInvalidBallot):
+		return http_helpers.NewBadRequestError(err.Error())
+	case err != nil:
+		logger.MethodFailure(ctx, "voting.CastBallot", err)
 		return http_helpers.NewInternalServerError()
+	default:
+		return http_helpers.NewOkResponse()
 	}
-	return http_helpers.NewOkResponse()
 }
 
-func validateBallot(ctx context.Context, ballot []string) bool {
-	var allowedChoices []string
-
-	if err := redis.Do(ctx, redis.Cmd(&allowedChoices,
-		"LRANGE", "arrangements:"+Season+":submissions", "0", "-1")); err != nil {
-		logger.RedisFailure(ctx, err)
-	}
-	if len(ballot) != len(allowedChoices) {
-		return false
+func seasonElection(ctx context.Context, season string) (voting.Election, *models.ApiResponse) {
+	if season == "" {
+		resp := http_helpers.NewBadRequestError("season is required")
+		return voting.Election{}, &resp
 	}
 
-	index := make(map[string]struct{}, len(allowedChoices))
-	for _, choice := range allowedChoices {
-		index[choice] = struct{}{}
-	}
-
-	for _, choice := range ballot {
-		if _, ok := index[choice]; !ok {
-			return false
-		}
+	election, err := voting.GetElection(ctx, models.ArrangementsElectionID(season))
+	switch {
+	case errors.Is(err, voting.ErrElectionNotFound):
+		resp := http_helpers.NewNotFoundError(fmt.Sprintf("season `%s` has no ballot", season))
+		return voting.Election{}, &resp
+	case err != nil:
+		logger.MethodFailure(ctx, "voting.GetElection", err)
+		resp := http_helpers.NewInternalServerError()
+		return voting.Election{}, &resp
+	default:
+		return election, nil
 	}
-	return true
 }
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"net/http"
+	"strconv"
+)
+
+const DefaultAuditLogLimit = 50
+
+// AuditLog lists the most recent changes made by the production team.
+func AuditLog(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = DefaultAuditLogLimit
+	}
+
+	entries, err := models.ListAuditEntries(ctx, limit)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListAuditEntries", err)
+		return http_helpers.NewInternalServerError()
+	}
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+	return models.ApiResponse{Status: models.StatusOk, AuditEntries: entries}
+}
@@ -29,7 +29,7 @@ func OAuthRedirect(r *http.Request) models.ApiResponse {
 	state := strconv.FormatUint(binary.BigEndian.Uint64(statusBytes[:16]), 16)
 	secret := strconv.FormatUint(binary.BigEndian.Uint64(statusBytes[16:]), 16)
 
-	if err := redis.Do(ctx, redis.Cmd(nil, "SETEX", "oauth_state:"+state, "300", secret)); err != nil {
+	if err := redis.SetEx(ctx, "oauth_state:"+state, 300*time.Second, secret); err != nil {
 		logger.RedisFailure(ctx, err)
 		return http_helpers.NewInternalServerError()
 	}
@@ -42,8 +42,7 @@ func OAuthRedirect(r *http.Request) models.ApiResponse {
 }
 
 func validateState(ctx context.Context, state, secret string) bool {
-	var wantSecret string
-	err := redis.Do(ctx, redis.Cmd(&wantSecret, "GET", "oauth_state:"+state))
+	wantSecret, err := redis.Get(ctx, "oauth_state:"+state)
 	switch {
 	case err != nil:
 		logger.RedisFailure(ctx, err)
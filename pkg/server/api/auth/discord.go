@@ -75,20 +75,7 @@ func Discord(r *http.Request) models.ApiResponse {
 	}
 
 	// check that they have the member role
-	var loginRoles []models.Role
-	for _, discordRole := range guildMember.Roles {
-		switch discordRole {
-		case "": // ignore empty strings
-			continue
-		case discord.VVGOExecutiveDirectorRoleID:
-			loginRoles = append(loginRoles, models.RoleVVGOExecutiveDirector)
-		case discord.VVGOProductionTeamRoleID:
-			loginRoles = append(loginRoles, models.RoleVVGOProductionTeam)
-		case discord.VVGOVerifiedMemberRoleID:
-			loginRoles = append(loginRoles, models.RoleVVGOVerifiedMember)
-		}
-	}
-
+	loginRoles := loginRolesForGuildMember(guildMember)
 	if len(loginRoles) == 0 {
 		logAuthFailure("not a member")
 		return http_helpers.NewUnauthorizedError()
@@ -108,3 +95,8 @@ func Discord(r *http.Request) models.ApiResponse {
 
 	return models.ApiResponse{Status: models.StatusOk, Identity: &identity}
 }
+
+// loginRolesForGuildMember maps the member's discord roles to vvgo roles using the configured role map.
+func loginRolesForGuildMember(guildMember *discord.GuildMember) []models.Role {
+	return models.RolesForGuildMember(*guildMember)
+}
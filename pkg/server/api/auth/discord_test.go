@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"testing"
+)
+
+func TestLoginRolesForGuildMember(t *testing.T) {
+	roleMap := config.Config().Discord.RoleMap
+	t.Cleanup(func() { config.Update(func(x *config.Configuration) { x.Discord.RoleMap = roleMap }) })
+	config.Update(func(x *config.Configuration) {
+		x.Discord.RoleMap = map[string]string{
+			"leader-role":  "vvgo-leader",
+			"teams-role":   "vvgo-teams",
+			"member-role":  "vvgo-member",
+			"alumni-role":  "vvgo-member",
+			"ignored-role": "",
+		}
+	})
+
+	for _, tt := range []struct {
+		name  string
+		roles []string
+		want  []models.Role
+	}{
+		{name: "no roles", roles: nil, want: nil},
+		{name: "unknown roles", roles: []string{"", "cheese", "ignored-role"}, want: nil},
+		{
+			name:  "mapped roles",
+			roles: []string{"member-role", "cheese", "teams-role"},
+			want:  []models.Role{models.RoleVVGOVerifiedMember, models.RoleVVGOProductionTeam},
+		},
+		{
+			name:  "duplicate roles",
+			roles: []string{"member-role", "alumni-role"},
+			want:  []models.Role{models.RoleVVGOVerifiedMember},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, loginRolesForGuildMember(&discord.GuildMember{Roles: tt.roles}))
+		})
+	}
+}
@@ -23,7 +23,7 @@ func Password(r *http.Request) models.ApiResponse {
 	}
 
 	passwords := make(map[string]string)
-	passwords["vvgo-member"] = config.Config.VVGO.MemberPasswordHash
+	passwords["vvgo-member"] = config.Config().VVGO.MemberPasswordHash
 
 	user := r.FormValue("user")
 	pass := r.FormValue("pass")
@@ -8,7 +8,7 @@ import (
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"github.com/virtual-vgo/vvgo/pkg/models"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 )
 
@@ -27,15 +27,15 @@ func Handle(expires time.Duration, handler func(*http.Request) models.ApiRespons
 }
 
 func readCache(ctx context.Context, key string) (models.ApiResponse, bool) {
-	var cacheRespJSON bytes.Buffer
-	if err := redis.Do(ctx, redis.Cmd(&cacheRespJSON, "GET", key)); err != nil {
+	cacheRespJSON, err := redis.Get(ctx, key)
+	if err != nil {
 		logger.RedisFailure(ctx, err)
 		return models.ApiResponse{}, false
 	}
 
-	if cacheRespJSON.Len() != 0 {
+	if cacheRespJSON != "" {
 		var cacheResp models.ApiResponse
-		if err := json.NewDecoder(&cacheRespJSON).Decode(&cacheResp); err != nil {
+		if err := json.NewDecoder(strings.NewReader(cacheRespJSON)).Decode(&cacheResp); err != nil {
 			logger.JsonDecodeFailure(ctx, err)
 		} else {
 			return cacheResp, true
@@ -55,8 +55,7 @@ func writeCache(ctx context.Context, key string, expires time.Duration, resp mod
 		return
 	}
 
-	ttl := int(expires.Seconds())
-	if err := redis.Do(ctx, redis.Cmd(nil, "SETEX", key, strconv.Itoa(ttl), cacheRespJSON.String())); err != nil {
+	if err := redis.SetEx(ctx, key, expires, cacheRespJSON.String()); err != nil {
 		logger.RedisFailure(ctx, err)
 		return
 	}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron/sheets_sync"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"time"
+)
+
+// CreditsDrafts shows a project's credits draft, and generates a draft from the project's submissions on POST.
+func CreditsDrafts(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		project := r.URL.Query().Get("project")
+		if project == "" {
+			return http_helpers.NewBadRequestError("project is required")
+		}
+		draft, errResp := getCreditsDraft(ctx, project)
+		if errResp != nil {
+			return *errResp
+		}
+		return models.ApiResponse{Status: models.StatusOk, CreditsDraft: &draft}
+	case http.MethodPost:
+		return handlePostCreditsDrafts(r, ctx, login.IdentityFromContext(ctx))
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
+}
+
+type PostCreditsDraftsRequest struct {
+	Project string
+	// Regenerate replaces an existing draft, and any edits made to it.
+	Regenerate bool
+}
+
+func handlePostCreditsDrafts(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
+	var data PostCreditsDraftsRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if data.Project == "" {
+		return http_helpers.NewBadRequestError("project is required")
+	}
+
+	projects, err := models.ListProjects(ctx, identity)
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+	project, ok := projects.Get(data.Project)
+	if !ok {
+		return http_helpers.NewNotFoundError(fmt.Sprintf("project %s does not exist", data.Project))
+	}
+
+	if !data.Regenerate {
+		_, err := models.GetCreditsDraft(ctx, project.Name)
+		switch {
+		case err == nil:
+			return http_helpers.NewBadRequestError(fmt.Sprintf("project %s already has a draft", project.Name))
+		case !errors.Is(err, models.ErrCreditsDraftNotFound):
+			logger.MethodFailure(ctx, "models.GetCreditsDraft", err)
+			return http_helpers.NewInternalServerError()
+		}
+	}
+
+	parts, err := models.ListParts(ctx, identity)
+	if err != nil {
+		logger.ListPartsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+	draft, err := models.GenerateCreditsDraft(ctx, project, parts, authorName(identity))
+	switch {
+	case errors.Is(err, models.ErrNoInstrumentationTemplate):
+		return http_helpers.NewBadRequestError(err.Error())
+	case err != nil:
+		logger.MethodFailure(ctx, "models.GenerateCreditsDraft", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, CreditsDraft: &draft}
+}
+
+type PostCreditsDraftEditRequest struct {
+	Project string
+	// UpdatedAt is when the draft being edited was last updated.
+	UpdatedAt time.Time
+	Credits   []models.DraftCredit
+}
+
+// CreditsDraftEdit replaces the credits of a draft.
+// An approved draft goes back to draft.
+func CreditsDraftEdit(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data PostCreditsDraftEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	switch {
+	case data.Project == "":
+		return http_helpers.NewBadRequestError("project is required")
+	case data.UpdatedAt.IsZero():
+		return http_helpers.NewBadRequestError("updatedAt is required")
+	}
+	for i, credit := range data.Credits {
+		switch {
+		case credit.MajorCategory == "":
+			return http_helpers.NewBadRequestError(fmt.Sprintf("credit %d needs a major category", i+1))
+		case credit.Name == "":
+			return http_helpers.NewBadRequestError(fmt.Sprintf("credit %d needs a name", i+1))
+		}
+	}
+
+	draft, err := models.EditCreditsDraft(ctx, data.Project, data.UpdatedAt, data.Credits, authorName(identity))
+	if errResp := creditsDraftError(ctx, data.Project, err); errResp != nil {
+		return *errResp
+	}
+	return models.ApiResponse{Status: models.StatusOk, CreditsDraft: &draft}
+}
+
+type PostCreditsDraftRequest struct {
+	Project string
+	// UpdatedAt is when the draft the reviewer saw was last updated.
+	// The request is refused if the draft changed since.
+	UpdatedAt time.Time
+}
+
+// CreditsDraftApprove approves a draft for publishing.
+func CreditsDraftApprove(r *http.Request) models.ApiResponse {
+	return handleCreditsDraftAction(r, "approved credits draft", models.ApproveCreditsDraft)
+}
+
+// CreditsDraftPublish publishes an approved draft into the Credits sheet.
+func CreditsDraftPublish(r *http.Request) models.ApiResponse {
+	return handleCreditsDraftAction(r, "published credits draft", publishCreditsDraft)
+}
+
+// publishCreditsDraft pulls the Credits sheet before publishing, so the draft is written over the latest edits in google.
+// The sheet is pushed after, so the next pull does not undo the publish.
+// The sheet's sync lock is held throughout, so a scheduled pull cannot come in between.
+// Without a google spreadsheet, only the redis copy is published.
+func publishCreditsDraft(ctx context.Context, project string, updatedAt time.Time, by string) (models.CreditsDraft, error) {
+	var draft models.CreditsDraft
+	err := sheets_sync.WithSheetLock(ctx, models.SpreadsheetWebsiteData, models.SheetCredits, func() error {
+		_, err := sheets_sync.Pull(ctx, models.SpreadsheetWebsiteData, models.SheetCredits)
+		if err != nil && !errors.Is(err, sheets_sync.ErrUnknownSpreadsheet) {
+			return err
+		}
+		draft, err = models.PublishCreditsDraft(ctx, project, updatedAt, by, func() error {
+			_, err := sheets_sync.Push(ctx, models.SpreadsheetWebsiteData, models.SheetCredits)
+			if err != nil && !errors.Is(err, sheets_sync.ErrUnknownSpreadsheet) {
+				return err
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return models.CreditsDraft{}, err
+	}
+	return draft, nil
+}
+
+func handleCreditsDraftAction(r *http.Request, action string,
+	do func(ctx context.Context, project string, updatedAt time.Time, by string) (models.CreditsDraft, error)) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data PostCreditsDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	switch {
+	case data.Project == "":
+		return http_helpers.NewBadRequestError("project is required")
+	case data.UpdatedAt.IsZero():
+		return http_helpers.NewBadRequestError("updatedAt is required")
+	}
+
+	draft, err := do(ctx, data.Project, data.UpdatedAt, authorName(identity))
+	if errResp := creditsDraftError(ctx, data.Project, err); errResp != nil {
+		return *errResp
+	}
+
+	if err := models.SaveAuditEntry(ctx, models.AuditEntry{
+		By:      authorName(identity),
+		Action:  action,
+		Project: draft.Project,
+		Details: fmt.Sprintf("%d credits", len(draft.Credits)),
+	}); err != nil {
+		logger.MethodFailure(ctx, "models.SaveAuditEntry", err)
+	}
+	return models.ApiResponse{Status: models.StatusOk, CreditsDraft: &draft}
+}
+
+// creditsDraftError returns the response for an error changing a project's draft, or nil if there is no error.
+func creditsDraftError(ctx context.Context, project string, err error) *models.ApiResponse {
+	var resp models.ApiResponse
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, models.ErrCreditsDraftNotFound):
+		resp = http_helpers.NewNotFoundError(fmt.Sprintf("project %s has no credits draft", project))
+	case errors.Is(err, models.ErrCreditsDraftNotApproved):
+		resp = http_helpers.NewBadRequestError(fmt.Sprintf("the credits draft for %s is not approved", project))
+	case errors.Is(err, models.ErrCreditsDraftChanged):
+		resp = http_helpers.NewConflictError(err.Error() + ", reload it and try again")
+	default:
+		logger.MethodFailure(ctx, "models.CreditsDraft", err)
+		resp = http_helpers.NewInternalServerError()
+	}
+	return &resp
+}
+
+func getCreditsDraft(ctx context.Context, project string) (models.CreditsDraft, *models.ApiResponse) {
+	draft, err := models.GetCreditsDraft(ctx, project)
+	switch {
+	case errors.Is(err, models.ErrCreditsDraftNotFound):
+		resp := http_helpers.NewNotFoundError(fmt.Sprintf("project %s has no credits draft", project))
+		return models.CreditsDraft{}, &resp
+	case err != nil:
+		logger.MethodFailure(ctx, "models.GetCreditsDraft", err)
+		resp := http_helpers.NewInternalServerError()
+		return models.CreditsDraft{}, &resp
+	default:
+		return draft, nil
+	}
+}
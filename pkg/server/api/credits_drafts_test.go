@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/clients/sheets"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers/test_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreditsDrafts(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "Parts Released"},
+		{"06-aurene", "Aurene", true},
+	}))
+	require.NoError(t, models.SaveSubmissionUpload(ctx, models.NewSubmissionUpload("06-aurene", "Trumpet 1", "1", "a.mp3", 1, "")))
+
+	google := sheets.NewFakeClient()
+	sheets.UseClient(google)
+	spreadsheetIDs := config.Config().Sheets.SpreadsheetIDs
+	config.Update(func(x *config.Configuration) {
+		x.Sheets.SpreadsheetIDs = map[string]string{models.SpreadsheetWebsiteData: "google-id"}
+	})
+	t.Cleanup(func() { config.Update(func(x *config.Configuration) { x.Sheets.SpreadsheetIDs = spreadsheetIDs }) })
+	require.NoError(t, google.WriteSheet(ctx, "google-id", models.SheetCredits, [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text", "Discord ID"},
+	}))
+
+	teams := models.Identity{Kind: models.KindDiscord, Roles: []models.Role{models.RoleVVGOProductionTeam}, DiscordID: "42069"}
+	newRequest := func(method string, target string, body string) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &teams))
+	}
+
+	test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("project 06-aurene has no credits draft"),
+		CreditsDrafts(newRequest(http.MethodGet, "/drafts?project=06-aurene", "")))
+	test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("project cheese does not exist"),
+		CreditsDrafts(newRequest(http.MethodPost, "/drafts", `{"Project":"cheese"}`)))
+
+	resp := CreditsDrafts(newRequest(http.MethodPost, "/drafts", `{"Project":"06-aurene"}`))
+	require.NotNil(t, resp.CreditsDraft, resp.Error)
+	assert.Equal(t, []models.DraftCredit{{MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "1", BottomText: "(1)", DiscordID: "1"}},
+		resp.CreditsDraft.Credits)
+	test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("project 06-aurene already has a draft"),
+		CreditsDrafts(newRequest(http.MethodPost, "/drafts", `{"Project":"06-aurene"}`)))
+
+	generatedAt := resp.CreditsDraft.UpdatedAt.Format(time.RFC3339Nano)
+	test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("updatedAt is required"),
+		CreditsDraftEdit(newRequest(http.MethodPost, "/drafts/edit", `{"Project":"06-aurene","Credits":[]}`)))
+	test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("credit 1 needs a name"),
+		CreditsDraftEdit(newRequest(http.MethodPost, "/drafts/edit",
+			`{"Project":"06-aurene","UpdatedAt":"`+generatedAt+`","Credits":[{"MajorCategory":"PERFORMERS"}]}`)))
+	resp = CreditsDraftEdit(newRequest(http.MethodPost, "/drafts/edit",
+		`{"Project":"06-aurene","UpdatedAt":"`+generatedAt+`","Credits":[{"MajorCategory":"PERFORMERS","MinorCategory":"TRUMPET","Name":"Jackson","DiscordID":"1"}]}`))
+	require.NotNil(t, resp.CreditsDraft, resp.Error)
+	editedAt := resp.CreditsDraft.UpdatedAt.Format(time.RFC3339Nano)
+
+	resp = CreditsPasta(newRequest(http.MethodGet, "/pasta?projectName=06-aurene", ""))
+	require.NotNil(t, resp.CreditsPasta, resp.Error)
+	assert.Equal(t, "— PERFORMERS —\n\nTRUMPET\nJackson\n", resp.CreditsPasta.YoutubePasta)
+
+	test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("the credits draft for 06-aurene is not approved"),
+		CreditsDraftPublish(newRequest(http.MethodPost, "/drafts/publish", `{"Project":"06-aurene","UpdatedAt":"`+editedAt+`"}`)))
+	resp = CreditsDraftApprove(newRequest(http.MethodPost, "/drafts/approve", `{"Project":"06-aurene","UpdatedAt":"`+generatedAt+`"}`))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, http.StatusConflict, resp.Error.Code, "the draft was edited since it was generated")
+	resp = CreditsDraftApprove(newRequest(http.MethodPost, "/drafts/approve", `{"Project":"06-aurene","UpdatedAt":"`+editedAt+`"}`))
+	require.NotNil(t, resp.CreditsDraft, resp.Error)
+	resp = CreditsDraftPublish(newRequest(http.MethodPost, "/drafts/publish", `{"Project":"06-aurene","UpdatedAt":"`+editedAt+`"}`))
+	require.NotNil(t, resp.CreditsDraft, resp.Error)
+	assert.Equal(t, models.CreditsDraftStatusPublished, resp.CreditsDraft.Status)
+
+	credits, err := models.ListCredits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.Credits{{Project: "06-aurene", MajorCategory: "PERFORMERS", MinorCategory: "TRUMPET", Name: "Jackson", DiscordID: "1", CreditsDraft: true}}, credits)
+
+	published, err := redis.ReadSheet(ctx, models.SpreadsheetWebsiteData, models.SheetCredits)
+	require.NoError(t, err)
+	pushed, err := google.ReadSheet(ctx, "google-id", models.SheetCredits)
+	require.NoError(t, err)
+	assert.Equal(t, published, pushed, "the published sheet is pushed to google")
+
+	entries, err := models.ListAuditEntries(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
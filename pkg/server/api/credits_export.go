@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CreditsExport downloads a project's credits in one of the registered credits formats.
+// Caption formats take the rollSpeed in lines per second, and the start of the credits in the video, like 3m20s.
+func CreditsExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodGet {
+		http_helpers.WriteErrorMethodNotAllowed(ctx, w)
+		return
+	}
+
+	renderer, project, options, errResp := parseCreditsExport(r)
+	if errResp != nil {
+		http_helpers.WriteAPIResponse(ctx, w, *errResp)
+		return
+	}
+
+	credits, err := models.ListCredits(ctx)
+	if err != nil {
+		logger.ListCreditsFailure(ctx, err)
+		http_helpers.WriteInternalServerError(ctx, w)
+		return
+	}
+	file, err := renderer.Render(project, credits.ForProject(project.Name), options)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.CreditsRenderer.Render", err)
+		http_helpers.WriteInternalServerError(ctx, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", renderer.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-credits%s"`, project.Name, renderer.Extension))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(file); err != nil {
+		logger.MethodFailure(ctx, "http.ResponseWriter.Write", err)
+	}
+}
+
+func parseCreditsExport(r *http.Request) (models.CreditsRenderer, models.Project, models.CreditsRenderOptions, *models.ApiResponse) {
+	ctx := r.Context()
+	fail := func(resp models.ApiResponse) (models.CreditsRenderer, models.Project, models.CreditsRenderOptions, *models.ApiResponse) {
+		return models.CreditsRenderer{}, models.Project{}, models.CreditsRenderOptions{}, &resp
+	}
+
+	projectName := r.FormValue("project")
+	if projectName == "" {
+		return fail(http_helpers.NewBadRequestError("project is required"))
+	}
+
+	renderer, ok := models.GetCreditsRenderer(r.FormValue("format"))
+	if !ok {
+		var formats []string
+		for _, renderer := range models.ListCreditsRenderers() {
+			formats = append(formats, renderer.Format)
+		}
+		return fail(http_helpers.NewBadRequestError(fmt.Sprintf("format must be one of %v", formats)))
+	}
+
+	var options models.CreditsRenderOptions
+	if rollSpeed := r.FormValue("rollSpeed"); rollSpeed != "" {
+		speed, err := strconv.ParseFloat(rollSpeed, 64)
+		if err != nil || math.IsNaN(speed) || math.IsInf(speed, 0) || speed <= 0 {
+			return fail(http_helpers.NewBadRequestError("rollSpeed must be a positive number of lines per second"))
+		}
+		options.RollSpeed = math.Min(speed, models.MaxRollSpeed)
+	}
+	if start := r.FormValue("start"); start != "" {
+		offset, err := time.ParseDuration(start)
+		if err != nil || offset < 0 {
+			return fail(http_helpers.NewBadRequestError("start must be a duration like 3m20s"))
+		}
+		options.Start = offset
+	}
+
+	projects, err := models.ListProjects(ctx, login.IdentityFromContext(ctx))
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return fail(http_helpers.NewInternalServerError())
+	}
+	project, ok := projects.Get(projectName)
+	if !ok {
+		return fail(http_helpers.NewNotFoundError(fmt.Sprintf("project %s does not exist", projectName)))
+	}
+	return renderer, project, options, nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreditsExport(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "Parts Released"},
+		{"06-aurene", "Aurene", true},
+	}))
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetCredits, "test", [][]interface{}{
+		{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text"},
+		{"06-aurene", 0, "PERFORMERS", "TRUMPET", "Jackson", ""},
+	}))
+
+	anonymous := models.Anonymous()
+	export := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &anonymous))
+		recorder := httptest.NewRecorder()
+		CreditsExport(recorder, req)
+		return recorder
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		recorder := export("/credits/export?project=06-aurene&format=markdown")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "text/markdown; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="06-aurene-credits.md"`, recorder.Header().Get("Content-Disposition"))
+		assert.Equal(t, "# Aurene\n\n## PERFORMERS\n\n### TRUMPET\n\n- Jackson\n", recorder.Body.String())
+	})
+
+	t.Run("srt", func(t *testing.T) {
+		recorder := export("/credits/export?project=06-aurene&format=srt&start=1m&rollSpeed=1")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "3\n00:01:03,000 --> 00:01:04,000\nJackson\n")
+	})
+
+	t.Run("srt/fast roll speed", func(t *testing.T) {
+		recorder := export("/credits/export?project=06-aurene&format=srt&rollSpeed=1e9")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "3\n00:00:00,030 --> 00:00:00,040\nJackson\n", "the speed is capped")
+	})
+
+	for _, tt := range []struct {
+		name   string
+		target string
+		code   int
+	}{
+		{"no project", "/credits/export?format=csv", http.StatusBadRequest},
+		{"unknown format", "/credits/export?project=06-aurene&format=pdf", http.StatusBadRequest},
+		{"bad roll speed", "/credits/export?project=06-aurene&format=srt&rollSpeed=-1", http.StatusBadRequest},
+		{"nan roll speed", "/credits/export?project=06-aurene&format=srt&rollSpeed=NaN", http.StatusBadRequest},
+		{"infinite roll speed", "/credits/export?project=06-aurene&format=srt&rollSpeed=Inf", http.StatusBadRequest},
+		{"bad start", "/credits/export?project=06-aurene&format=srt&start=soon", http.StatusBadRequest},
+		{"unknown project", "/credits/export?project=cheese&format=csv", http.StatusNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, export(tt.target).Code)
+		})
+	}
+}
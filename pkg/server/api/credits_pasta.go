@@ -13,6 +13,8 @@ type GetCreditsPastaRequest struct {
 	ProjectName   string
 }
 
+// CreditsPasta formats credits for the website, the video and youtube.
+// Without a spreadsheetID, the credits come from the project's credits draft.
 func CreditsPasta(r *http.Request) models.ApiResponse {
 	ctx := r.Context()
 
@@ -23,12 +25,16 @@ func CreditsPasta(r *http.Request) models.ApiResponse {
 	}
 
 	switch {
+	case inputData.ProjectName == "":
+		return http_helpers.NewBadRequestError("projectName is required")
 	case inputData.SpreadsheetID == "":
-		return http_helpers.NewBadRequestError("spreadsheetID is required")
+		draft, errResp := getCreditsDraft(ctx, inputData.ProjectName)
+		if errResp != nil {
+			return *errResp
+		}
+		return creditsPastaResponse(draft.ToCredits())
 	case inputData.ReadRange == "":
 		return http_helpers.NewBadRequestError("readRange is required")
-	case inputData.ProjectName == "":
-		return http_helpers.NewBadRequestError("projectName is required")
 	default:
 		break
 	}
@@ -38,8 +44,10 @@ func CreditsPasta(r *http.Request) models.ApiResponse {
 		logger.ListSubmissionsFailure(ctx, err)
 		return http_helpers.NewBadRequestError(err.Error())
 	}
+	return creditsPastaResponse(submissions.ToCredits(inputData.ProjectName))
+}
 
-	credits := submissions.ToCredits(inputData.ProjectName)
+func creditsPastaResponse(credits models.Credits) models.ApiResponse {
 	return models.ApiResponse{
 		Status: models.StatusOk,
 		CreditsPasta: &models.CreditsPasta{
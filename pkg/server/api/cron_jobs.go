@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"net/http"
+)
+
+const (
+	CronJobActionPause   = "pause"
+	CronJobActionResume  = "resume"
+	CronJobActionTrigger = "trigger"
+)
+
+type PostCronJobRequest struct {
+	Name   string
+	Action string
+}
+
+// CronJobs lists the background jobs, and pauses, resumes, or triggers a job on POST.
+func CronJobs(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var data PostCronJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			return http_helpers.NewJsonDecodeError(err)
+		}
+
+		var err error
+		switch data.Action {
+		case CronJobActionPause:
+			err = cron.Pause(ctx, data.Name, true)
+		case CronJobActionResume:
+			err = cron.Pause(ctx, data.Name, false)
+		case CronJobActionTrigger:
+			err = cron.Trigger(ctx, data.Name)
+		default:
+			return http_helpers.NewBadRequestError("action must be pause, resume, or trigger")
+		}
+
+		switch {
+		case errors.Is(err, cron.ErrUnknownJob):
+			return http_helpers.NewNotFoundError(err.Error())
+		case errors.Is(err, cron.ErrJobRunning):
+			return http_helpers.NewBadRequestError(err.Error())
+		case err != nil:
+			logger.MethodFailure(ctx, "cron."+data.Action, err)
+			return http_helpers.NewInternalServerError()
+		}
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	jobs, err := cron.ListJobs(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "cron.ListJobs", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, CronJobs: jobs}
+}
@@ -22,7 +22,7 @@ func FetchSpreadsheets(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, sheet := range spreadsheet.Sheets {
-		if err := redis.WriteSheet(ctx, spreadsheet.SpreadsheetName, sheet.Name, sheet.Values); err != nil {
+		if err := redis.WriteSheet(ctx, spreadsheet.SpreadsheetName, sheet.Name, "devel", sheet.Values); err != nil {
 			logger.RedisFailure(ctx, err)
 			http_helpers.WriteInternalServerError(ctx, w)
 			return
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"net/http"
+)
+
+// DiscordRateLimits returns the rate limit waits of the discord client since the server started.
+func DiscordRateLimits(r *http.Request) models.ApiResponse {
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, DiscordRateLimits: discord.RateLimitStats()}
+}
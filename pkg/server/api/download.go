@@ -35,7 +35,7 @@ func Download(r *http.Request) models.ApiResponse {
 		return http_helpers.NewInternalServerError()
 	}
 
-	distroBucket := config.Config.VVGO.DistroBucket
+	distroBucket := config.Config().VVGO.DistroBucket
 
 	_, err = minioClient.StatObject(distroBucket, fileName, minio.StatObjectOptions{})
 	if err != nil {
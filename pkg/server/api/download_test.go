@@ -22,7 +22,7 @@ func TestDownload(t *testing.T) {
 	require.NoError(t, err, "minioClient.MakeBucket() failed")
 	_, err = minioClient.PutObject(bucketName, "danish", strings.NewReader(""), -1, minio.PutObjectOptions{})
 	require.NoError(t, err, "minioClient.PutObject() failed")
-	config.Config.VVGO.DistroBucket = bucketName
+	config.Update(func(x *config.Configuration) { x.VVGO.DistroBucket = bucketName })
 
 	t.Run("invalid method", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/download?fileName=danish", nil)
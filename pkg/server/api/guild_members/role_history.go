@@ -0,0 +1,38 @@
+package guild_members
+
+import (
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"net/http"
+)
+
+// HandleRoleHistory returns the roles given to and taken from a member, oldest first.
+func HandleRoleHistory(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		return http_helpers.NewBadRequestError("user_id is required")
+	}
+
+	changes, err := models.ListGuildMemberRoleChanges(ctx, discord.Snowflake(userID))
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListGuildMemberRoleChanges", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, GuildMemberRoleChanges: changes}
+}
+
+// HandleSync returns the last sync of the directory.
+func HandleSync(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	sync, err := models.GetGuildMemberSync(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.GetGuildMemberSync", err)
+		return http_helpers.NewInternalServerError()
+	} else if sync == nil {
+		return http_helpers.NewNotFoundError("the guild has not been synced")
+	}
+	return models.ApiResponse{Status: models.StatusOk, GuildMemberSync: sync}
+}
@@ -1,22 +1,22 @@
 package guild_members
 
 import (
-	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"github.com/virtual-vgo/vvgo/pkg/models"
-	"github.com/virtual-vgo/vvgo/pkg/server/api/cache"
 	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
 	"net/http"
 	"strconv"
-	"time"
 )
 
+// DefaultSearchLimit is the number of results when the request has no limit.
+const DefaultSearchLimit = 10
+
 type SearchRequest struct {
 	Limit int
 	Query string
 }
 
-var HandleSearch = cache.Handle(60*time.Second, func(r *http.Request) models.ApiResponse {
+func HandleSearch(r *http.Request) models.ApiResponse {
 	ctx := r.Context()
 
 	queryParams := r.URL.Query()
@@ -29,13 +29,14 @@ var HandleSearch = cache.Handle(60*time.Second, func(r *http.Request) models.Api
 	if params.Query == "" {
 		return http_helpers.NewBadRequestError("query is required")
 	}
+	if params.Limit <= 0 {
+		params.Limit = DefaultSearchLimit
+	}
 
-	members, err := discord.SearchGuildMembers(ctx, params.Query, params.Limit)
+	members, err := models.SearchGuildMembers(ctx, params.Query, params.Limit)
 	if err != nil {
-		logger.MethodFailure(ctx, "discord.SearchGuildMembers", err)
+		logger.MethodFailure(ctx, "models.SearchGuildMembers", err)
 		return http_helpers.NewInternalServerError()
 	}
-
-	saveGuildMembers(ctx, members)
 	return models.ApiResponse{Status: models.StatusOk, GuildMembers: members}
-})
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+)
+
+// InstrumentationTemplates lists the score order templates.
+func InstrumentationTemplates(r *http.Request) models.ApiResponse {
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, InstrumentationTemplates: models.InstrumentationTemplates}
+}
+
+// InstrumentationReport shows which sections of a template a project's parts cover.
+// The template is the project's instrumentation, unless the template parameter picks another.
+func InstrumentationReport(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	projectName := r.FormValue("project")
+	if projectName == "" {
+		return http_helpers.NewBadRequestError("project is required")
+	}
+
+	projects, err := models.ListProjects(ctx, identity)
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+	project, ok := projects.Get(projectName)
+	if !ok {
+		return http_helpers.NewNotFoundError(fmt.Sprintf("project %s does not exist", projectName))
+	}
+
+	scale := models.ParseArrangementScale(r.FormValue("template"))
+	if scale == "" {
+		scale = project.Instrumentation
+	}
+	if scale == "" {
+		scale = models.ArrangementScaleFull
+	}
+	template, ok := models.GetInstrumentationTemplate(scale)
+	if !ok {
+		return http_helpers.NewBadRequestError(fmt.Sprintf("there is no template for instrumentation `%s`", scale))
+	}
+
+	parts, err := models.ListParts(ctx, identity)
+	if err != nil {
+		logger.ListPartsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	report := models.BuildInstrumentationReport(template, project, parts)
+	return models.ApiResponse{Status: models.StatusOk, InstrumentationReport: &report}
+}
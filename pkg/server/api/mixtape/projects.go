@@ -56,12 +56,12 @@ func HandleProjects(r *http.Request) models.ApiResponse {
 			return http_helpers.NewJsonDecodeError(err)
 		}
 
-		var id uint64
-		if err := redis.Do(r.Context(), redis.Cmd(&id, redis.INCR, mixtape.NextProjectIdRedisKey)); err != nil {
+		id, err := redis.Incr(ctx, mixtape.NextProjectIdRedisKey)
+		if err != nil {
 			logger.RedisFailure(ctx, err)
 			return http_helpers.NewInternalServerError()
 		}
-		return saveProject(id, data, ctx)
+		return saveProject(uint64(id), data, ctx)
 
 	case http.MethodPut:
 		id := idFromUrl(r.URL.Path)
@@ -81,7 +81,7 @@ func HandleProjects(r *http.Request) models.ApiResponse {
 			return http_helpers.NewBadRequestError("invalid id")
 		}
 
-		if err := redis.Do(ctx, redis.Cmd(nil, redis.HDEL, mixtape.ProjectsRedisKey, redis.ObjectId(id).String())); err != nil {
+		if err := redis.HDel(ctx, mixtape.ProjectsRedisKey, redis.ObjectId(id).String()); err != nil {
 			return http_helpers.NewRedisError(err)
 		}
 		return http_helpers.NewOkResponse()
@@ -106,9 +106,9 @@ func saveProject(id uint64, data CreateMixtapeProjectParams, ctx context.Context
 		logger.JsonEncodeFailure(ctx, err)
 	}
 
-	if err := redis.Do(ctx, redis.Cmd(nil, redis.HSET,
-		mixtape.ProjectsRedisKey, redis.ObjectId(id).String(), projectJSON.String()),
-	); err != nil {
+	if err := redis.HSet(ctx, mixtape.ProjectsRedisKey, map[string]string{
+		redis.ObjectId(id).String(): projectJSON.String(),
+	}); err != nil {
 		logger.RedisFailure(ctx, err)
 		return http_helpers.NewRedisError(err)
 	}
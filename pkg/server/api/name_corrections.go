@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+)
+
+// NameCorrections lists the credited name corrections requested by members, and reviews one on POST.
+// GET lists pending corrections, or the corrections with ?status=.
+func NameCorrections(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		return handleGetNameCorrections(ctx, models.NameCorrectionStatus(r.URL.Query().Get("status")))
+	case http.MethodPost:
+		return handlePostNameCorrections(r, ctx, login.IdentityFromContext(ctx))
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
+}
+
+func handleGetNameCorrections(ctx context.Context, status models.NameCorrectionStatus) models.ApiResponse {
+	if status == "" {
+		status = models.NameCorrectionStatusPending
+	}
+
+	corrections, err := models.ListNameCorrections(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListNameCorrections", err)
+		return http_helpers.NewInternalServerError()
+	}
+	corrections = corrections.WithStatus(status)
+	if corrections == nil {
+		corrections = models.NameCorrections{}
+	}
+	return models.ApiResponse{Status: models.StatusOk, NameCorrections: corrections}
+}
+
+type PostNameCorrectionsRequest struct {
+	ID      string
+	Approve bool
+}
+
+func handlePostNameCorrections(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
+	var data PostNameCorrectionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if data.ID == "" {
+		return http_helpers.NewBadRequestError("id is required")
+	}
+
+	correction, err := models.ReviewNameCorrection(ctx, data.ID, data.Approve, authorName(identity))
+	switch {
+	case errors.Is(err, models.ErrNameCorrectionNotFound):
+		return http_helpers.NewNotFoundError(fmt.Sprintf("name correction `%s` not found", data.ID))
+	case errors.Is(err, models.ErrNameCorrectionReviewed):
+		return http_helpers.NewBadRequestError(err.Error())
+	case err != nil:
+		logger.MethodFailure(ctx, "models.ReviewNameCorrection", err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	if err := models.SaveAuditEntry(ctx, models.AuditEntry{
+		By:      authorName(identity),
+		Action:  string(correction.Status) + " name correction",
+		Project: correction.Project,
+		Details: fmt.Sprintf("%s -> %s", correction.CurrentName, correction.RequestedName),
+	}); err != nil {
+		logger.MethodFailure(ctx, "models.SaveAuditEntry", err)
+	}
+	return models.ApiResponse{Status: models.StatusOk, NameCorrection: &correction}
+}
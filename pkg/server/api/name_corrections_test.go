@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers/test_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNameCorrections(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	credit := models.Credit{Project: "01-snake-eater", Name: "Jackson H.", DiscordID: "1234"}
+	correction, err := models.RequestNameCorrection(ctx, credit, "Jackson Hall")
+	require.NoError(t, err)
+
+	teams := models.Identity{Kind: models.KindDiscord, Roles: []models.Role{models.RoleVVGOProductionTeam}, DiscordID: "42069"}
+	newRequest := func(method string, target string, body string) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &teams))
+	}
+
+	t.Run("get/pending", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t,
+			models.ApiResponse{Status: models.StatusOk, NameCorrections: models.NameCorrections{correction}},
+			NameCorrections(newRequest(http.MethodGet, "/name_corrections", "")))
+	})
+
+	t.Run("post/not found", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("name correction `cheese` not found"),
+			NameCorrections(newRequest(http.MethodPost, "/name_corrections", `{"ID":"cheese","Approve":true}`)))
+	})
+
+	t.Run("post/approve", func(t *testing.T) {
+		resp := NameCorrections(newRequest(http.MethodPost, "/name_corrections", `{"ID":"`+correction.ID+`","Approve":true}`))
+		require.NotNil(t, resp.NameCorrection, resp.Error)
+		assert.Equal(t, models.NameCorrectionStatusApproved, resp.NameCorrection.Status)
+		assert.Equal(t, "discord:42069", resp.NameCorrection.ReviewedBy)
+
+		entries, err := models.ListAuditEntries(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "approved name correction", entries[0].Action)
+		assert.Equal(t, "Jackson H. -> Jackson Hall", entries[0].Details)
+
+		test_helpers.AssertEqualApiResponses(t,
+			models.ApiResponse{Status: models.StatusOk, NameCorrections: models.NameCorrections{}},
+			NameCorrections(newRequest(http.MethodGet, "/name_corrections", "")))
+	})
+}
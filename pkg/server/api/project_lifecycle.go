@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+)
+
+// ProjectLifecycle shows the lifecycle of a project, and moves it to a new state on POST.
+func ProjectLifecycle(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	switch r.Method {
+	case http.MethodGet:
+		return handleGetProjectLifecycle(ctx, r.URL.Query().Get("project"))
+	case http.MethodPost:
+		return handlePostProjectLifecycle(r, ctx, identity)
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
+}
+
+// lifecycleProject looks the project up in every project, drafts included.
+// The production team can see the lifecycle of any project; transitions check the identity themselves.
+func lifecycleProject(ctx context.Context, name string) (models.Project, *models.ApiResponse) {
+	if name == "" {
+		resp := http_helpers.NewBadRequestError("project is required")
+		return models.Project{}, &resp
+	}
+
+	project, err := models.GetProject(ctx, name)
+	switch {
+	case errors.Is(err, models.ErrProjectNotFound):
+		resp := http_helpers.NewNotFoundError(fmt.Sprintf("project `%s` not found", name))
+		return models.Project{}, &resp
+	case err != nil:
+		logger.ListProjectsFailure(ctx, err)
+		resp := http_helpers.NewInternalServerError()
+		return models.Project{}, &resp
+	}
+	return project, nil
+}
+
+func handleGetProjectLifecycle(ctx context.Context, name string) models.ApiResponse {
+	project, errResp := lifecycleProject(ctx, name)
+	if errResp != nil {
+		return *errResp
+	}
+
+	lifecycle, err := models.GetProjectLifecycle(ctx, project)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.GetProjectLifecycle", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, ProjectLifecycle: &lifecycle}
+}
+
+type PostProjectLifecycleRequest struct {
+	Project string
+	State   models.ProjectState
+}
+
+func handlePostProjectLifecycle(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
+	var data PostProjectLifecycleRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if !data.State.IsValid() {
+		return http_helpers.NewBadRequestError(fmt.Sprintf("state must be one of %v", models.ProjectStates))
+	}
+
+	project, errResp := lifecycleProject(ctx, data.Project)
+	if errResp != nil {
+		return *errResp
+	}
+	if !project.LifecycleState().TransitionAllowed(identity, data.State) {
+		return http_helpers.NewErrorResponse(models.ApiError{
+			Code:  http.StatusForbidden,
+			Error: fmt.Sprintf("you cannot move `%s` to %s", project.Name, data.State),
+		})
+	}
+
+	lifecycle, err := models.TransitionProject(ctx, project, data.State, authorName(identity))
+	switch {
+	case errors.Is(err, models.ErrInvalidTransition):
+		return http_helpers.NewBadRequestError(fmt.Sprintf("%s; %s can move to %v",
+			err, project.LifecycleState(), project.LifecycleState().Transitions()))
+	case err != nil:
+		logger.MethodFailure(ctx, "models.TransitionProject", err)
+		return http_helpers.NewInternalServerError()
+	default:
+		return models.ApiResponse{Status: models.StatusOk, ProjectLifecycle: &lifecycle}
+	}
+}
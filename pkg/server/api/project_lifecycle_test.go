@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers/test_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProjectLifecycle(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "Hidden", "Parts Released"},
+		{"06-aurene", "Aurene", true, true},
+	}))
+
+	teams := models.Identity{Kind: models.KindDiscord, Roles: []models.Role{models.RoleVVGOProductionTeam}, DiscordID: "42069"}
+	newRequest := func(method string, target string, body string) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &teams))
+	}
+
+	resp := ProjectLifecycle(newRequest(http.MethodGet, "/lifecycle?project=06-aurene", ""))
+	require.NotNil(t, resp.ProjectLifecycle, resp.Error)
+	assert.Equal(t, models.ProjectStateDraft, resp.ProjectLifecycle.State)
+
+	resp = ProjectLifecycle(newRequest(http.MethodPost, "/lifecycle", `{"Project":"06-aurene","State":"pre-production"}`))
+	require.NotNil(t, resp.ProjectLifecycle, resp.Error)
+	assert.Equal(t, models.ProjectStatePreProduction, resp.ProjectLifecycle.State)
+
+	test_helpers.AssertEqualApiResponses(t, http_helpers.NewErrorResponse(models.ApiError{
+		Code:  http.StatusForbidden,
+		Error: "you cannot move `06-aurene` to draft",
+	}), ProjectLifecycle(newRequest(http.MethodPost, "/lifecycle", `{"Project":"06-aurene","State":"draft"}`)))
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/virtual-vgo/vvgo/pkg/server/api/rbac"
 	"github.com/virtual-vgo/vvgo/pkg/server/api/slash_command"
 	"github.com/virtual-vgo/vvgo/pkg/server/api/traces"
+	"github.com/virtual-vgo/vvgo/pkg/server/api/voting"
 	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
 	"github.com/virtual-vgo/vvgo/pkg/server/login"
 	"net/http"
@@ -65,34 +66,64 @@ func Routes() http.Handler {
 
 	// api endpoints
 	rbacMux.HandleApiFunc("/api/v1/arrangements/ballot", arrangements.Ballot, models.RoleVVGOExecutiveDirector)
+	rbacMux.HandleApiFunc("/api/v1/arrangements/complete", arrangements.CompleteUpload, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/arrangements/decline", arrangements.Decline, models.RoleVVGOExecutiveDirector)
+	rbacMux.HandleApiFunc("/api/v1/arrangements/file", arrangements.File, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/arrangements/promote", arrangements.Promote, models.RoleVVGOExecutiveDirector)
+	rbacMux.HandleApiFunc("/api/v1/arrangements/review", arrangements.Review, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/arrangements/submissions", arrangements.Submissions, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/audit_log", api.AuditLog, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/auth/discord", auth.Discord, models.RoleAnonymous)
 	rbacMux.HandleApiFunc("/api/v1/auth/logout", auth.Logout, models.RoleAnonymous)
 	rbacMux.HandleApiFunc("/api/v1/auth/oauth_redirect", auth.OAuthRedirect, models.RoleAnonymous)
 	rbacMux.HandleApiFunc("/api/v1/channels/list", channels.HandleList, models.RoleVVGOVerifiedMember)
 	rbacMux.HandleApiFunc("/api/v1/credits", api.Credits, models.RoleAnonymous)
+	rbacMux.HandleFunc("/api/v1/credits/export", api.CreditsExport, models.RoleAnonymous)
+	rbacMux.HandleApiFunc("/api/v1/credits/drafts", api.CreditsDrafts, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/credits/drafts/approve", api.CreditsDraftApprove, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/credits/drafts/edit", api.CreditsDraftEdit, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/credits/drafts/publish", api.CreditsDraftPublish, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/credits/pasta", api.CreditsPasta, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/credits/table", api.CreditsTable, models.RoleAnonymous)
+	rbacMux.HandleApiFunc("/api/v1/cron/jobs", api.CronJobs, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/dataset", api.Dataset, models.RoleAnonymous)
+	rbacMux.HandleApiFunc("/api/v1/discord/rate_limits", api.DiscordRateLimits, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/download", api.Download, models.RoleDownload)
 	rbacMux.HandleApiFunc("/api/v1/guild_members/search", guild_members.HandleSearch, models.RoleVVGOVerifiedMember)
 	rbacMux.HandleApiFunc("/api/v1/guild_members/lookup", guild_members.HandleLookup, models.RoleVVGOVerifiedMember)
 	rbacMux.HandleApiFunc("/api/v1/guild_members/list", guild_members.HandleList, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/guild_members/role_history", guild_members.HandleRoleHistory, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/guild_members/sync", guild_members.HandleSync, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/auth/password", auth.Password, models.RoleAnonymous)
 	rbacMux.HandleApiFunc("/api/v1/traces/spans", traces.HandleSpans, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/traces/waterfall", traces.HandleWaterfall, models.RoleVVGOExecutiveDirector)
 	rbacMux.HandleApiFunc("/api/v1/me", api.Me, models.RoleAnonymous)
 	rbacMux.HandleApiFunc("/api/v1/mixtape/projects/", mixtape.HandleProjects, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/name_corrections", api.NameCorrections, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/instrumentation/report", api.InstrumentationReport, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/instrumentation/templates", api.InstrumentationTemplates, models.RoleVVGOVerifiedMember)
 	rbacMux.HandleApiFunc("/api/v1/parts", api.Parts, models.RoleVVGOVerifiedMember)
 	rbacMux.HandleApiFunc("/api/v1/projects", api.Projects, models.RoleAnonymous)
+	rbacMux.HandleApiFunc("/api/v1/projects/lifecycle", api.ProjectLifecycle, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/sessions", api.Sessions, models.RoleVVGOVerifiedMember)
 	rbacMux.HandleFunc("/api/v1/slash_commands", slash_command.Handle, models.RoleAnonymous)
 	rbacMux.HandleFunc("/api/v1/slack_commands/list", slash_command.List, models.RoleVVGOProductionTeam)
 	rbacMux.HandleFunc("/api/v1/slack_commands/update", slash_command.Update, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/spreadsheet", api.Spreadsheet, models.RoleWriteSpreadsheet)
+	rbacMux.HandleApiFunc("/api/v1/spreadsheet/sync", api.SpreadsheetSync, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/spreadsheet/versions", api.SpreadsheetVersions, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/spreadsheet/versions/diff", api.SpreadsheetVersionsDiff, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/spreadsheet/versions/rollback", api.SpreadsheetRollback, models.RoleVVGOProductionTeam)
+	rbacMux.HandleApiFunc("/api/v1/submissions", api.Submissions, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/submissions/complete", api.CompleteSubmission, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/submissions/tracking", api.SubmissionTracking, models.RoleVVGOProductionTeam)
 	rbacMux.HandleApiFunc("/api/v1/version", api.Version, models.RoleAnonymous)
+	rbacMux.HandleApiFunc("/api/v1/voting/ballot", voting.Ballot, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/voting/elections", voting.Elections, models.RoleVVGOVerifiedMember)
+	rbacMux.HandleApiFunc("/api/v1/voting/results", voting.Results, models.RoleVVGOExecutiveDirector)
 	rbacMux.HandleApiFunc("/download", api.Download, models.RoleDownload)
 
-	if config.Config.Development {
+	if config.Config().Development {
 		rbacMux.HandleFunc("/api/v1/devel/fetch_spreadsheets", devel.FetchSpreadsheets, models.RoleVVGOProductionTeam)
 	}
 	rbacMux.Handle("/", ServeUI, models.RoleAnonymous)
@@ -56,7 +56,7 @@ func handleDeleteSessions(r *http.Request, ctx context.Context) models.ApiRespon
 	for _, session := range data.Sessions {
 		sessionIds = append(sessionIds, "sessions:"+session)
 	}
-	if err := redis.Do(ctx, redis.Cmd(nil, "DEL", sessionIds...)); err != nil {
+	if err := redis.Del(ctx, sessionIds...); err != nil {
 		logger.RedisFailure(ctx, err)
 		return http_helpers.NewInternalServerError()
 	}
@@ -5,6 +5,31 @@ import (
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
 )
 
+const BeepAgainComponent = "again"
+
 func BeepInteractionHandler(context.Context, discord.Interaction) discord.InteractionResponse {
-	return InteractionResponseMessage("boop", false)
+	response := InteractionResponseMessage("boop", false)
+	response.Data.Components = beepComponents()
+	return response
+}
+
+// BeepAgainInteractionHandler adds a boop to the message.
+func BeepAgainInteractionHandler(_ context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	content := "boop"
+	if interaction.Message != nil && interaction.Message.Content != "" {
+		content = interaction.Message.Content + " boop"
+	}
+	return InteractionResponseUpdate(discord.InteractionApplicationCommandCallbackData{
+		Content:    content,
+		Components: beepComponents(),
+	})
+}
+
+func beepComponents() []discord.Component {
+	return []discord.Component{ActionRow(discord.Component{
+		Type:     discord.ComponentTypeButton,
+		Style:    discord.ButtonStylePrimary,
+		Label:    "Beep",
+		CustomID: ComponentID("beep", BeepAgainComponent),
+	})}
 }
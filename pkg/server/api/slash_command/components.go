@@ -0,0 +1,43 @@
+package slash_command
+
+import (
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"strings"
+)
+
+// ComponentID returns the custom id of a component of a command.
+// The data is sent back with the interaction, and is read with ComponentData.
+func ComponentID(command string, component string, data ...string) string {
+	return strings.Join(append([]string{command, component}, data...), "/")
+}
+
+// ComponentData returns the data in the custom id of a component interaction.
+func ComponentData(interaction discord.Interaction) []string {
+	if interaction.Data == nil {
+		return nil
+	}
+	_, _, data := parseComponentID(interaction.Data.CustomID)
+	return data
+}
+
+func parseComponentID(customID string) (command string, component string, data []string) {
+	parts := strings.Split(customID, "/")
+	switch len(parts) {
+	case 0:
+		return "", "", nil
+	case 1:
+		return parts[0], "", nil
+	default:
+		return parts[0], parts[1], parts[2:]
+	}
+}
+
+// ActionRow puts components in a row under a message.
+func ActionRow(components ...discord.Component) discord.Component {
+	return discord.Component{Type: discord.ComponentTypeActionRow, Components: components}
+}
+
+// InteractionResponseUpdate replaces the message with the component.
+func InteractionResponseUpdate(data discord.InteractionApplicationCommandCallbackData) discord.InteractionResponse {
+	return discord.InteractionResponse{Type: discord.InteractionCallbackTypeUpdateMessage, Data: &data}
+}
@@ -0,0 +1,41 @@
+package slash_command
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"testing"
+)
+
+func TestComponentID(t *testing.T) {
+	customID := ComponentID("cheese", "pick", "brie", "cheddar")
+	assert.Equal(t, "cheese/pick/brie/cheddar", customID)
+	assert.Equal(t, []string{"brie", "cheddar"}, ComponentData(NewComponentInteraction(discord.GuildMember{}, customID)))
+
+	command, component, data := parseComponentID("cheese")
+	assert.Equal(t, "cheese", command)
+	assert.Equal(t, "", component)
+	assert.Empty(t, data)
+}
+
+func TestHandleComponentInteraction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("beep again", func(t *testing.T) {
+		interaction := NewComponentInteraction(discord.GuildMember{}, ComponentID("beep", BeepAgainComponent))
+		interaction.Message.Content = "boop"
+		response, err := Simulate(ctx, interaction)
+		require.NoError(t, err)
+		assert.Equal(t, discord.InteractionCallbackTypeUpdateMessage, response.Type)
+		require.NotNil(t, response.Data)
+		assert.Equal(t, "boop boop", response.Data.Content)
+		assert.Equal(t, beepComponents(), response.Data.Components)
+	})
+
+	t.Run("unknown component", func(t *testing.T) {
+		response, err := Simulate(ctx, NewComponentInteraction(discord.GuildMember{}, ComponentID("beep", "cheese")))
+		require.NoError(t, err)
+		assertEqualInteractionResponse(t, InteractionResponseGalaxyBrain, response)
+	})
+}
@@ -0,0 +1,160 @@
+package slash_command
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxCreditedNameLength is the longest name a member can ask to be credited as.
+const MaxCreditedNameLength = 64
+
+// CreditsCommand lets members check how they are credited, and ask for a correction.
+var CreditsCommand = SlashCommand{
+	Name:        "credits",
+	Description: "Your credits for a project.",
+	Subcommands: []SlashCommand{
+		{
+			Name:         "show",
+			Description:  "Show how you are credited for a project.",
+			Options:      OptionsOf(ProjectOptions{}),
+			Handler:      CreditsShowInteractionHandler,
+			Autocomplete: map[string]AutocompleteHandler{"project": CreditsProjectAutocomplete},
+		},
+		{
+			Name:         "correct",
+			Description:  "Ask the production team to change your credited name.",
+			Options:      OptionsOf(CreditsCorrectOptions{}),
+			Handler:      CreditsCorrectInteractionHandler,
+			Autocomplete: map[string]AutocompleteHandler{"project": CreditsProjectAutocomplete},
+		},
+	},
+}
+
+type CreditsCorrectOptions struct {
+	Project string `option:"project,required" description:"Name of the project"`
+	Name    string `option:"name,required" description:"Your name as it should appear in the credits"`
+}
+
+func CreditsShowInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	var options ProjectOptions
+	if err := DecodeOptions(interaction, &options); err != nil {
+		return InteractionResponseOof
+	}
+	project, credits, errResponse := memberCredits(ctx, interaction, options.Project)
+	if errResponse != nil {
+		return *errResponse
+	}
+
+	corrections, err := models.ListNameCorrections(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListNameCorrections", err)
+		return InteractionResponseOof
+	}
+
+	lines := []string{fmt.Sprintf("For **%s** you are credited as:", project.Title)}
+	for _, credit := range credits {
+		line := fmt.Sprintf("• **%s**, %s", credit.Name, credit.MinorCategory)
+		if credit.BottomText != "" {
+			line += " " + credit.BottomText
+		}
+		lines = append(lines, line)
+	}
+	for _, correction := range corrections.Pending().ForDiscordID(interaction.Member.User.ID.String()) {
+		if correction.Project == project.Name {
+			lines = append(lines, fmt.Sprintf("⏳ Your request to be credited as **%s** is waiting for review.", correction.RequestedName))
+		}
+	}
+	lines = append(lines, "If your name is wrong, use `/credits correct` to ask for a change.")
+	return InteractionResponseMessage(strings.Join(lines, "\n"), true)
+}
+
+func CreditsCorrectInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	var options CreditsCorrectOptions
+	if err := DecodeOptions(interaction, &options); err != nil {
+		return InteractionResponseOof
+	}
+	name := strings.TrimSpace(options.Name)
+	switch {
+	case name == "":
+		return InteractionResponseMessage("Your name can't be blank 😅", true)
+	case utf8.RuneCountInString(name) > MaxCreditedNameLength:
+		return InteractionResponseMessage(fmt.Sprintf("Names can be at most %d characters 😅", MaxCreditedNameLength), true)
+	}
+
+	project, credits, errResponse := memberCredits(ctx, interaction, options.Project)
+	if errResponse != nil {
+		return *errResponse
+	}
+
+	var current []string
+	requested := make(map[string]bool)
+	for _, credit := range credits {
+		if credit.Name == name || requested[credit.Name] {
+			continue
+		}
+		if _, err := models.RequestNameCorrection(ctx, credit, name); err != nil {
+			logger.MethodFailure(ctx, "models.RequestNameCorrection", err)
+			return InteractionResponseOof
+		}
+		requested[credit.Name] = true
+		current = append(current, "**"+credit.Name+"**")
+	}
+
+	if len(current) == 0 {
+		return InteractionResponseMessage(fmt.Sprintf("You are already credited as **%s** for **%s** 🙂", name, project.Title), true)
+	}
+	return InteractionResponseMessage(fmt.Sprintf("Thanks! The production team will review changing %s to **%s** for **%s** 📝",
+		strings.Join(current, ", "), name, project.Title), true)
+}
+
+// CreditsProjectAutocomplete suggests the projects that credit the member.
+func CreditsProjectAutocomplete(ctx context.Context, interaction discord.Interaction, value string) []discord.ApplicationCommandOptionChoice {
+	projects, err := models.ListProjects(ctx, models.IdentityForGuildMember(interaction.Member))
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return nil
+	}
+	credits, err := models.ListCredits(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListCredits", err)
+		return nil
+	}
+
+	credited := make(map[string]bool)
+	for _, credit := range credits.ForDiscordID(interaction.Member.User.ID.String()) {
+		credited[credit.Project] = true
+	}
+	var want models.Projects
+	for _, project := range projects {
+		if credited[project.Name] {
+			want = append(want, project)
+		}
+	}
+	return projectChoices(want, value)
+}
+
+// memberCredits returns the project and the member's credits for it, or a response for the member if there are none.
+func memberCredits(ctx context.Context, interaction discord.Interaction, name string) (models.Project, models.Credits, *discord.InteractionResponse) {
+	project, errResponse := commandProject(ctx, models.IdentityForGuildMember(interaction.Member), name)
+	if errResponse != nil {
+		return models.Project{}, nil, errResponse
+	}
+
+	credits, err := models.ListCredits(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListCredits", err)
+		resp := InteractionResponseOof
+		return models.Project{}, nil, &resp
+	}
+	credits = credits.ForProject(project.Name).ForDiscordID(interaction.Member.User.ID.String())
+	if len(credits) == 0 {
+		resp := InteractionResponseMessage(fmt.Sprintf("I don't see you in the credits for **%s** 🤔", project.Title), true)
+		return models.Project{}, nil, &resp
+	}
+	return project, credits, nil
+}
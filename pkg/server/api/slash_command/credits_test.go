@@ -0,0 +1,122 @@
+package slash_command
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"testing"
+	"time"
+)
+
+func TestCreditsCommand(t *testing.T) {
+	ctx := context.Background()
+	member := discord.GuildMember{User: discord.User{ID: "1234"}, Roles: []string{"690636730281230396"}}
+	stranger := discord.GuildMember{User: discord.User{ID: "5678"}, Roles: []string{"690636730281230396"}}
+
+	setup := func(t *testing.T) {
+		redis.UseStore(redis.NewMemoryStore())
+		require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+			{"Name", "Title", "State"},
+			{"01-snake-eater", "Snake Eater", "released"},
+			{"02-proof-of-a-hero", "Proof of a Hero", "released"},
+		}))
+		require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetCredits, "test", [][]interface{}{
+			{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text", "Discord ID"},
+			{"01-snake-eater", 0, "PERFORMERS", "TRUMPET", "Jackson H.", "(1, 2)", "1234"},
+			{"01-snake-eater", 1, "PERFORMERS", "TROMBONE", "Jackson H.", "", "1234"},
+			{"02-proof-of-a-hero", 2, "PERFORMERS", "TRUMPET", "Brandon", "", "5678"},
+		}))
+	}
+
+	simulate := func(t *testing.T, member discord.GuildMember, command string, options ...discord.ApplicationCommandInteractionDataOption) discord.InteractionResponse {
+		response, err := Simulate(ctx, NewCommandInteraction(member, command, options...))
+		require.NoError(t, err)
+		return response
+	}
+
+	t.Run("show", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, member, "credits show", Option("project", "01-snake-eater"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("For **Snake Eater** you are credited as:\n"+
+			"• **Jackson H.**, TRUMPET (1, 2)\n"+
+			"• **Jackson H.**, TROMBONE\n"+
+			"If your name is wrong, use `/credits correct` to ask for a change.", true), response)
+
+		response = simulate(t, member, "credits show", Option("project", "02-proof-of-a-hero"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("I don't see you in the credits for **Proof of a Hero** 🤔", true), response)
+	})
+
+	t.Run("show without discord id", func(t *testing.T) {
+		setup(t)
+		require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetCredits, "test", [][]interface{}{
+			{"Project", "Order", "Major Category", "Minor Category", "Name", "Bottom Text"},
+			{"01-snake-eater", 0, "PERFORMERS", "TRUMPET", "Jackson H.", ""},
+		}))
+		require.NoError(t, models.SaveGuildMembers(ctx, time.Now(),
+			discord.GuildMember{User: discord.User{ID: "1234", Username: "jackson"}, Nick: "Jackson H."}))
+
+		response := simulate(t, member, "credits show", Option("project", "01-snake-eater"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("I don't see you in the credits for **Snake Eater** 🤔", true), response)
+	})
+
+	t.Run("correct", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, member, "credits correct", Option("project", "01-snake-eater"), Option("name", " Jackson Hall "))
+		assertEqualInteractionResponse(t, InteractionResponseMessage(
+			"Thanks! The production team will review changing **Jackson H.** to **Jackson Hall** for **Snake Eater** 📝", true), response)
+
+		corrections, err := models.ListNameCorrections(ctx)
+		require.NoError(t, err)
+		require.Len(t, corrections, 1, "one pending correction per project")
+		assert.Equal(t, "1234", corrections[0].DiscordID)
+		assert.Equal(t, "Jackson H.", corrections[0].CurrentName)
+		assert.Equal(t, "Jackson Hall", corrections[0].RequestedName)
+
+		response = simulate(t, member, "credits show", Option("project", "01-snake-eater"))
+		assert.Contains(t, response.Data.Content, "⏳ Your request to be credited as **Jackson Hall** is waiting for review.")
+
+		response = simulate(t, member, "credits correct", Option("project", "01-snake-eater"), Option("name", "Jackson H."))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("You are already credited as **Jackson H.** for **Snake Eater** 🙂", true), response)
+
+		response = simulate(t, member, "credits correct", Option("project", "01-snake-eater"), Option("name", "  "))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("Your name can't be blank 😅", true), response)
+	})
+
+	t.Run("autocomplete", func(t *testing.T) {
+		setup(t)
+		response, err := Simulate(ctx, NewAutocompleteInteraction(stranger, "credits show", Option("project", "")))
+		require.NoError(t, err)
+		assert.Equal(t, []discord.ApplicationCommandOptionChoice{{Name: "Proof of a Hero", Value: "02-proof-of-a-hero"}}, response.Data.Choices)
+	})
+}
+
+func TestMySubmissionsInteractionHandler(t *testing.T) {
+	ctx := context.Background()
+	member := discord.GuildMember{User: discord.User{ID: "1234"}, Roles: []string{"690636730281230396"}}
+	redis.UseStore(redis.NewMemoryStore())
+
+	response, err := Simulate(ctx, NewCommandInteraction(member, "mysubmissions"))
+	require.NoError(t, err)
+	assertEqualInteractionResponse(t, InteractionResponseMessage("I don't see any submissions from you for current projects 🤔", true), response)
+
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "State"},
+		{"01-snake-eater", "Snake Eater", "parts-released"},
+		{"02-proof-of-a-hero", "Proof of a Hero", "released"},
+	}))
+	createdAt := time.Unix(1622592000, 0).UTC()
+	for _, upload := range []models.SubmissionUpload{
+		{Project: "01-snake-eater", PartName: "Trumpet 1", DiscordID: "1234", ObjectKey: "a", FileName: "take1.wav", CreatedAt: createdAt},
+		{Project: "01-snake-eater", PartName: "Trumpet 2", DiscordID: "5678", ObjectKey: "b", FileName: "take1.wav", CreatedAt: createdAt},
+		{Project: "02-proof-of-a-hero", PartName: "Trumpet 1", DiscordID: "1234", ObjectKey: "c", FileName: "take1.wav", CreatedAt: createdAt},
+	} {
+		require.NoError(t, models.SaveSubmissionUpload(ctx, upload))
+	}
+
+	response, err = Simulate(ctx, NewCommandInteraction(member, "mysubmissions"))
+	require.NoError(t, err)
+	assertEqualInteractionResponse(t, InteractionResponseMessage("**Snake Eater**\n• Trumpet 1: `take1.wav` <t:1622592000:R>", true), response)
+}
@@ -0,0 +1,126 @@
+package slash_command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"sync"
+	"time"
+)
+
+// DeferredTimeout is how long deferred work may run before the user is told it timed out.
+// Interaction tokens expire after 15 minutes, so this must be shorter.
+var DeferredTimeout = 2 * time.Minute
+
+const deferredTimeoutMessage = "this is taking too long, please try again later ⏳"
+
+// UserError is an error that is shown to the user as written.
+// Other errors are logged and the user sees an oof.
+type UserError string
+
+func (x UserError) Error() string { return string(x) }
+
+// DeferredWork runs after the interaction is acknowledged.
+// The returned data replaces the "thinking..." response.
+type DeferredWork func(ctx context.Context) (discord.InteractionApplicationCommandCallbackData, error)
+
+var deferred sync.WaitGroup
+
+// Defer acknowledges the interaction and runs work in the background.
+// Discord shows the user that we are thinking until the work finishes and the response is edited.
+// Ephemeral responses are only shown to the user who ran the command.
+func Defer(interaction discord.Interaction, ephemeral bool, work DeferredWork) discord.InteractionResponse {
+	var flags int
+	if ephemeral {
+		flags = discord.InteractionApplicationCommandCallbackDataFlagEphemeral
+	}
+
+	deferred.Add(1)
+	go func() {
+		defer deferred.Done()
+		// The request context ends when the acknowledgement is sent.
+		workCtx, cancel := context.WithTimeout(context.Background(), DeferredTimeout)
+		defer cancel()
+		data := runDeferredWork(workCtx, work)
+		data.Flags = flags
+
+		editCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := discord.EditOriginalInteractionResponse(editCtx, interaction.Token, data); err != nil {
+			logger.MethodFailure(editCtx, "discord.EditOriginalInteractionResponse", err)
+		}
+	}()
+
+	return discord.InteractionResponse{
+		Type: discord.InteractionCallbackTypeAcknowledgeWithSource,
+		Data: &discord.InteractionApplicationCommandCallbackData{Flags: flags},
+	}
+}
+
+// runDeferredWork runs work until ctx is done.
+// Work that is still running then has its context cancelled, so it can stop instead of running on in the background.
+func runDeferredWork(ctx context.Context, work DeferredWork) (data discord.InteractionApplicationCommandCallbackData) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		data discord.InteractionApplicationCommandCallbackData
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- result{err: fmt.Errorf("panic: %v", recovered)}
+			}
+		}()
+		data, err := work(ctx)
+		done <- result{data, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res.err = ctx.Err()
+	}
+
+	var userErr UserError
+	switch {
+	case res.err == nil:
+		return res.data
+	case errors.Is(res.err, context.DeadlineExceeded):
+		logger.WithError(res.err).Error("slash command: deferred work timed out")
+		return discord.InteractionApplicationCommandCallbackData{Content: deferredTimeoutMessage}
+	case errors.As(res.err, &userErr):
+		return discord.InteractionApplicationCommandCallbackData{Content: userErr.Error()}
+	default:
+		logger.WithError(res.err).Error("slash command: deferred work failed")
+		return *InteractionResponseOof.Data
+	}
+}
+
+// FollowUp sends another message in response to the interaction.
+func FollowUp(ctx context.Context, interaction discord.Interaction, data discord.InteractionApplicationCommandCallbackData) error {
+	if _, err := discord.CreateFollowupMessage(ctx, interaction.Token, data); err != nil {
+		return fmt.Errorf("discord.CreateFollowupMessage() failed: %w", err)
+	}
+	return nil
+}
+
+// WaitDeferred blocks until deferred work finishes and responses are sent, or the context ends.
+func WaitDeferred(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		deferred.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
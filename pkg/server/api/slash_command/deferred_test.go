@@ -0,0 +1,163 @@
+package slash_command
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type webhookRequest struct {
+	Method string
+	Path   string
+	Data   discord.InteractionApplicationCommandCallbackData
+}
+
+// fakeWebhooks records the interaction webhook requests sent to discord.
+type fakeWebhooks struct {
+	lock     sync.Mutex
+	requests []webhookRequest
+}
+
+func newFakeWebhooks(t *testing.T) *fakeWebhooks {
+	webhooks := new(fakeWebhooks)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := webhookRequest{Method: r.Method, Path: r.URL.Path}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request.Data))
+		webhooks.lock.Lock()
+		webhooks.requests = append(webhooks.requests, request)
+		webhooks.lock.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(ts.Close)
+	endpoint := config.Config().Discord.Endpoint
+	t.Cleanup(func() { config.Update(func(x *config.Configuration) { x.Discord.Endpoint = endpoint }) })
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
+	return webhooks
+}
+
+func (x *fakeWebhooks) Requests(t *testing.T) []webhookRequest {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, WaitDeferred(ctx))
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	return x.requests
+}
+
+func TestDefer(t *testing.T) {
+	ctx := context.Background()
+	interaction := NewCommandInteraction(discord.GuildMember{}, "cheese")
+	interaction.Token = "test-token"
+	originalPath := "/webhooks/" + discord.ApplicationID + "/test-token/messages/@original"
+
+	t.Run("ok", func(t *testing.T) {
+		webhooks := newFakeWebhooks(t)
+		response := Defer(interaction, true, func(ctx context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+			return discord.InteractionApplicationCommandCallbackData{Content: "brie"}, nil
+		})
+		assert.Equal(t, discord.InteractionResponse{
+			Type: discord.InteractionCallbackTypeAcknowledgeWithSource,
+			Data: &discord.InteractionApplicationCommandCallbackData{Flags: discord.InteractionApplicationCommandCallbackDataFlagEphemeral},
+		}, response)
+		assert.Equal(t, []webhookRequest{{
+			Method: http.MethodPatch,
+			Path:   originalPath,
+			Data: discord.InteractionApplicationCommandCallbackData{
+				Content: "brie",
+				Flags:   discord.InteractionApplicationCommandCallbackDataFlagEphemeral,
+			},
+		}}, webhooks.Requests(t))
+	})
+
+	for _, tt := range []struct {
+		name string
+		work DeferredWork
+		want string
+	}{
+		{
+			name: "user error",
+			work: func(context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+				return discord.InteractionApplicationCommandCallbackData{}, UserError("no cheese here")
+			},
+			want: "no cheese here",
+		},
+		{
+			name: "error",
+			work: func(context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+				return discord.InteractionApplicationCommandCallbackData{}, errors.New("the cheese is on fire")
+			},
+			want: InteractionResponseOof.Data.Content,
+		},
+		{
+			name: "panic",
+			work: func(context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+				panic("the cheese is on fire")
+			},
+			want: InteractionResponseOof.Data.Content,
+		},
+		{
+			name: "timeout",
+			work: func(ctx context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+				<-ctx.Done()
+				return discord.InteractionApplicationCommandCallbackData{}, ctx.Err()
+			},
+			want: deferredTimeoutMessage,
+		},
+		{
+			name: "timeout ignoring context",
+			work: func(ctx context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+				time.Sleep(time.Second)
+				return discord.InteractionApplicationCommandCallbackData{Content: "brie"}, nil
+			},
+			want: deferredTimeoutMessage,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(timeout time.Duration) { DeferredTimeout = timeout }(DeferredTimeout)
+			DeferredTimeout = 10 * time.Millisecond
+			webhooks := newFakeWebhooks(t)
+			Defer(interaction, false, tt.work)
+			requests := webhooks.Requests(t)
+			require.Len(t, requests, 1)
+			assert.Equal(t, originalPath, requests[0].Path)
+			assert.Equal(t, tt.want, requests[0].Data.Content)
+		})
+	}
+
+	t.Run("timed out work is cancelled", func(t *testing.T) {
+		defer func(timeout time.Duration) { DeferredTimeout = timeout }(DeferredTimeout)
+		DeferredTimeout = 10 * time.Millisecond
+		webhooks := newFakeWebhooks(t)
+		stopped := make(chan struct{})
+		Defer(interaction, false, func(ctx context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+			defer close(stopped)
+			<-ctx.Done()
+			return discord.InteractionApplicationCommandCallbackData{}, ctx.Err()
+		})
+		require.Len(t, webhooks.Requests(t), 1)
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("the work is still running")
+		}
+	})
+
+	t.Run("follow up", func(t *testing.T) {
+		webhooks := newFakeWebhooks(t)
+		require.NoError(t, FollowUp(ctx, interaction, discord.InteractionApplicationCommandCallbackData{Content: "cheddar"}))
+		assert.Equal(t, []webhookRequest{{
+			Method: http.MethodPost,
+			Path:   "/webhooks/" + discord.ApplicationID + "/test-token",
+			Data:   discord.InteractionApplicationCommandCallbackData{Content: "cheddar"},
+		}}, webhooks.Requests(t))
+	})
+}
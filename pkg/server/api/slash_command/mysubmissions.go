@@ -0,0 +1,45 @@
+package slash_command
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"strings"
+)
+
+// MySubmissionsInteractionHandler lists the member's submissions for current projects.
+func MySubmissionsInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	identity := models.IdentityForGuildMember(interaction.Member)
+	projects, err := models.ListProjects(ctx, identity)
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return InteractionResponseOof
+	}
+
+	var sections []string
+	for _, project := range projects.Current() {
+		uploads, err := models.ListSubmissionUploads(ctx, project.Name)
+		if err != nil {
+			logger.ListSubmissionUploadsFailure(ctx, err)
+			return InteractionResponseOof
+		}
+		uploads = uploads.ForDiscordID(interaction.Member.User.ID.String())
+		if len(uploads) == 0 {
+			continue
+		}
+
+		lines := []string{fmt.Sprintf("**%s**", project.Title)}
+		for _, upload := range uploads {
+			lines = append(lines, fmt.Sprintf("• %s: `%s` %s", upload.PartName, upload.FileName,
+				discord.FormatTimestamp(upload.CreatedAt, discord.TimestampStyleRelative)))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if len(sections) == 0 {
+		return InteractionResponseMessage("I don't see any submissions from you for current projects 🤔", true)
+	}
+	return InteractionResponseMessage(strings.Join(sections, "\n\n"), true)
+}
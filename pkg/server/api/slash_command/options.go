@@ -0,0 +1,204 @@
+package slash_command
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrMissingOption = errors.New("missing required option")
+var ErrInvalidOption = errors.New("invalid option")
+
+// OptionsOf returns the options of a command from the fields of an options struct.
+// Fields are tagged with the option name, and with required if the option is required:
+//
+//	type partsOptions struct {
+//		Project string `option:"project,required" description:"Name of the project"`
+//		Verbose bool   `option:"verbose" description:"Show everything"`
+//	}
+//
+// The option type follows the field type. A `type` tag of user, channel, role,
+// or mentionable picks those option types for string fields.
+func OptionsOf(v interface{}) func(context.Context) ([]discord.ApplicationCommandOption, error) {
+	return func(context.Context) ([]discord.ApplicationCommandOption, error) {
+		fields, err := optionFields(reflect.TypeOf(v))
+		if err != nil {
+			return nil, err
+		}
+		options := make([]discord.ApplicationCommandOption, 0, len(fields))
+		for _, field := range fields {
+			options = append(options, discord.ApplicationCommandOption{
+				Type:        field.optionType,
+				Name:        field.name,
+				Description: field.description,
+				Required:    field.required,
+			})
+		}
+		return options, nil
+	}
+}
+
+// DecodeOptions copies the options of an interaction into the fields of an options struct.
+// See OptionsOf for the struct tags.
+func DecodeOptions(interaction discord.Interaction, dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: dest must be a pointer to a struct", ErrInvalidOption)
+	}
+	fields, err := optionFields(value.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	var options []discord.ApplicationCommandInteractionDataOption
+	if interaction.Data != nil {
+		options = interaction.Data.Options
+	}
+	for _, field := range fields {
+		option, ok := findOption(options, field.name)
+		if !ok {
+			if field.required {
+				return fmt.Errorf("%w: %s", ErrMissingOption, field.name)
+			}
+			continue
+		}
+		if err := setOptionField(value.Elem().Field(field.index), option.Value.String()); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidOption, field.name, err)
+		}
+	}
+	return nil
+}
+
+// FocusedOption returns the option the user is typing in an autocomplete interaction.
+func FocusedOption(interaction discord.Interaction) (discord.ApplicationCommandInteractionDataOption, bool) {
+	if interaction.Data == nil {
+		return discord.ApplicationCommandInteractionDataOption{}, false
+	}
+	for _, option := range interaction.Data.Options {
+		if option.Focused {
+			return option, true
+		}
+	}
+	return discord.ApplicationCommandInteractionDataOption{}, false
+}
+
+// OptionValue returns the value of an option of the interaction.
+func OptionValue(interaction discord.Interaction, name string) string {
+	if interaction.Data == nil {
+		return ""
+	}
+	option, _ := findOption(interaction.Data.Options, name)
+	return option.Value.String()
+}
+
+func findOption(options []discord.ApplicationCommandInteractionDataOption, name string) (discord.ApplicationCommandInteractionDataOption, bool) {
+	for _, option := range options {
+		if option.Name == name {
+			return option, true
+		}
+	}
+	return discord.ApplicationCommandInteractionDataOption{}, false
+}
+
+type optionField struct {
+	index       int
+	name        string
+	description string
+	required    bool
+	optionType  discord.ApplicationCommandOptionType
+}
+
+func optionFields(structType reflect.Type) ([]optionField, error) {
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: options must be a struct", ErrInvalidOption)
+	}
+
+	var fields []optionField
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		tag, ok := structField.Tag.Lookup("option")
+		if !ok {
+			continue
+		}
+		name, flags := splitOptionTag(tag)
+		if name == "" {
+			return nil, fmt.Errorf("%w: field %s has no option name", ErrInvalidOption, structField.Name)
+		}
+
+		field := optionField{
+			index:       i,
+			name:        name,
+			description: structField.Tag.Get("description"),
+			required:    flags["required"],
+		}
+		switch structField.Type.Kind() {
+		case reflect.String:
+			field.optionType = discord.ApplicationCommandOptionTypeString
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			field.optionType = discord.ApplicationCommandOptionTypeInteger
+		case reflect.Bool:
+			field.optionType = discord.ApplicationCommandOptionTypeBoolean
+		case reflect.Float32, reflect.Float64:
+			field.optionType = discord.ApplicationCommandOptionTypeNumber
+		default:
+			return nil, fmt.Errorf("%w: field %s has unsupported type %s", ErrInvalidOption, structField.Name, structField.Type)
+		}
+
+		switch optionType := structField.Tag.Get("type"); optionType {
+		case "":
+		case "user", "channel", "role", "mentionable":
+			if field.optionType != discord.ApplicationCommandOptionTypeString {
+				return nil, fmt.Errorf("%w: field %s must be a string to be a %s", ErrInvalidOption, structField.Name, optionType)
+			}
+			field.optionType = map[string]discord.ApplicationCommandOptionType{
+				"user":        discord.ApplicationCommandOptionTypeUser,
+				"channel":     discord.ApplicationCommandOptionTypeChannel,
+				"role":        discord.ApplicationCommandOptionTypeRole,
+				"mentionable": discord.ApplicationCommandOptionTypeMentionable,
+			}[optionType]
+		default:
+			return nil, fmt.Errorf("%w: field %s has unknown type %s", ErrInvalidOption, structField.Name, optionType)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func splitOptionTag(tag string) (string, map[string]bool) {
+	parts := strings.Split(tag, ",")
+	flags := make(map[string]bool, len(parts)-1)
+	for _, flag := range parts[1:] {
+		flags[strings.TrimSpace(flag)] = true
+	}
+	return strings.TrimSpace(parts[0]), flags
+}
+
+func setOptionField(field reflect.Value, text string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	}
+	return nil
+}
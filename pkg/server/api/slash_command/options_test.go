@@ -0,0 +1,56 @@
+package slash_command
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"testing"
+)
+
+type testOptions struct {
+	Cheese  string  `option:"cheese,required" description:"A cheese"`
+	Wheels  int     `option:"wheels" description:"How many wheels"`
+	Aged    bool    `option:"aged" description:"Is it aged"`
+	Weight  float64 `option:"weight" description:"Weight in kg"`
+	Maker   string  `option:"maker" description:"Who made it" type:"user"`
+	ignored string
+}
+
+func TestOptionsOf(t *testing.T) {
+	got, err := OptionsOf(testOptions{})(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []discord.ApplicationCommandOption{
+		{Type: discord.ApplicationCommandOptionTypeString, Name: "cheese", Description: "A cheese", Required: true},
+		{Type: discord.ApplicationCommandOptionTypeInteger, Name: "wheels", Description: "How many wheels"},
+		{Type: discord.ApplicationCommandOptionTypeBoolean, Name: "aged", Description: "Is it aged"},
+		{Type: discord.ApplicationCommandOptionTypeNumber, Name: "weight", Description: "Weight in kg"},
+		{Type: discord.ApplicationCommandOptionTypeUser, Name: "maker", Description: "Who made it"},
+	}, got)
+
+	_, err = OptionsOf(struct {
+		Cheese []string `option:"cheese"`
+	}{})(context.Background())
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestDecodeOptions(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		interaction := NewCommandInteraction(discord.GuildMember{}, "cheese",
+			Option("cheese", "brie"), Option("wheels", 3), Option("aged", true), Option("weight", 1.5), Option("maker", "42069"))
+		var got testOptions
+		require.NoError(t, DecodeOptions(interaction, &got))
+		assert.Equal(t, testOptions{Cheese: "brie", Wheels: 3, Aged: true, Weight: 1.5, Maker: "42069"}, got)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		var got testOptions
+		assert.ErrorIs(t, DecodeOptions(NewCommandInteraction(discord.GuildMember{}, "cheese"), &got), ErrMissingOption)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		interaction := NewCommandInteraction(discord.GuildMember{}, "cheese", Option("cheese", "brie"), Option("wheels", "many"))
+		var got testOptions
+		assert.ErrorIs(t, DecodeOptions(interaction, &got), ErrInvalidOption)
+	})
+}
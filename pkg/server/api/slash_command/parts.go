@@ -4,26 +4,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
-	"github.com/virtual-vgo/vvgo/pkg/errors"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"github.com/virtual-vgo/vvgo/pkg/models"
 )
 
-func PartsCommandOptions(ctx context.Context) ([]discord.ApplicationCommandOption, error) {
-	identity := models.Anonymous()
-	projects, err := models.ListProjects(ctx, identity)
-	if err != nil {
-		return nil, errors.ListProjectsFailure(err)
-	}
-	return []discord.ApplicationCommandOption{ProjectCommandOption(projects.Current())}, nil
+// ProjectOptions are the options of commands about one project.
+type ProjectOptions struct {
+	Project string `option:"project,required" description:"Name of the project"`
 }
 
 func PartsInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
-	var projectName string
-	for _, option := range interaction.Data.Options {
-		if option.Name == "project" {
-			projectName = option.Value
-		}
+	var options ProjectOptions
+	if err := DecodeOptions(interaction, &options); err != nil {
+		return InteractionResponseOof
 	}
 
 	identity := models.Anonymous()
@@ -33,17 +26,27 @@ func PartsInteractionHandler(ctx context.Context, interaction discord.Interactio
 		return InteractionResponseOof
 	}
 
-	project, ok := projects.Get(projectName)
+	project, ok := projects.Get(options.Project)
 	if !ok {
 		return InteractionResponseOof
 	}
 
+	return discord.InteractionResponse{
+		Type: discord.InteractionCallbackTypeChannelMessageWithSource,
+		Data: &discord.InteractionApplicationCommandCallbackData{
+			Embeds: []discord.Embed{ProjectEmbed(project)},
+		},
+	}
+}
+
+// ProjectEmbed links to the parts and submissions of the project.
+func ProjectEmbed(project models.Project) discord.Embed {
 	description := fmt.Sprintf(`· Parts are [here!](https://vvgo.org%s)
 · Submit files [here!](%s)
 · Submission Deadline: %s.`,
-		project.PartsPage(), project.SubmissionLink, project.SubmissionDeadline)
+		project.PartsPage(), project.SubmissionLink, DeadlineText(project))
 
-	embed := discord.Embed{
+	return discord.Embed{
 		Title:       project.Title,
 		Type:        discord.EmbedTypeRich,
 		Description: description,
@@ -51,10 +54,4 @@ func PartsInteractionHandler(ctx context.Context, interaction discord.Interactio
 		Color:       0x8C17D9,
 		Footer:      &discord.EmbedFooter{Text: "Bottom text."},
 	}
-	return discord.InteractionResponse{
-		Type: discord.InteractionCallbackTypeChannelMessageWithSource,
-		Data: &discord.InteractionApplicationCommandCallbackData{
-			Embeds: []discord.Embed{embed},
-		},
-	}
 }
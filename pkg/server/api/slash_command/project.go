@@ -1,22 +1,66 @@
 package slash_command
 
 import (
+	"context"
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"github.com/virtual-vgo/vvgo/pkg/models"
+	"strings"
 )
 
-func ProjectCommandOption(projects models.Projects) discord.ApplicationCommandOption {
+// ProjectAutocomplete suggests current projects whose name or title contains the value.
+func ProjectAutocomplete(ctx context.Context, _ discord.Interaction, value string) []discord.ApplicationCommandOptionChoice {
+	projects, err := models.ListProjects(ctx, models.Anonymous())
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return nil
+	}
+
+	return projectChoices(projects.Current(), value)
+}
+
+// projectChoices are the projects whose name or title contains the value.
+func projectChoices(projects models.Projects, value string) []discord.ApplicationCommandOptionChoice {
+	value = strings.ToLower(value)
 	var choices []discord.ApplicationCommandOptionChoice
 	for _, project := range projects {
-		choices = append(choices, discord.ApplicationCommandOptionChoice{
-			Name: project.Title, Value: project.Name,
-		})
+		if strings.Contains(strings.ToLower(project.Name), value) || strings.Contains(strings.ToLower(project.Title), value) {
+			choices = append(choices, discord.ApplicationCommandOptionChoice{Name: project.Title, Value: project.Name})
+		}
+	}
+	return choices
+}
+
+// PartAutocomplete suggests parts of the project in the interaction's project option.
+// Parts are listed with the roles of the member using the command.
+func PartAutocomplete(ctx context.Context, interaction discord.Interaction, value string) []discord.ApplicationCommandOptionChoice {
+	parts, err := models.ListParts(ctx, models.IdentityForGuildMember(interaction.Member))
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListParts", err)
+		return nil
+	}
+
+	value = strings.ToLower(value)
+	var choices []discord.ApplicationCommandOptionChoice
+	for _, part := range parts.ForProject(OptionValue(interaction, "project")).Sort() {
+		if strings.Contains(strings.ToLower(part.PartName), value) {
+			choices = append(choices, discord.ApplicationCommandOptionChoice{Name: part.PartName, Value: part.PartName})
+		}
 	}
-	return discord.ApplicationCommandOption{
-		Type:        discord.ApplicationCommandOptionTypeString,
-		Name:        "project",
-		Description: "Name of the project",
-		Required:    true,
-		Choices:     choices,
+	return choices
+}
+
+// DeadlineText shows the submission deadline in the reader's timezone, followed by a countdown.
+// Deadlines that cannot be parsed are shown as written in the sheet.
+func DeadlineText(project models.Project) string {
+	deadline, ok := project.Deadline()
+	switch {
+	case ok:
+		return discord.FormatTimestamp(deadline, discord.TimestampStyleLongDateTime) +
+			" (" + discord.FormatTimestamp(deadline, discord.TimestampStyleRelative) + ")"
+	case project.SubmissionDeadline != "":
+		return project.SubmissionDeadline
+	default:
+		return "TBD"
 	}
 }
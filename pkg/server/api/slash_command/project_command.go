@@ -0,0 +1,280 @@
+package slash_command
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"strings"
+	"time"
+)
+
+// ProjectCommand is the production team's commands for running projects.
+var ProjectCommand = SlashCommand{
+	Name:        "project",
+	Description: "Run a project.",
+	Roles:       []models.Role{models.RoleVVGOProductionTeam, models.RoleVVGOExecutiveDirector},
+	Subcommands: []SlashCommand{
+		{
+			Name:        "release",
+			Description: "Release the parts of a project.",
+			Options:     OptionsOf(ProjectOptions{}),
+			Handler:     ProjectReleaseInteractionHandler,
+			Autocomplete: map[string]AutocompleteHandler{"project": ProjectStateAutocomplete(
+				models.ProjectStatePreProduction, models.ProjectStateSubmissionsClosed)},
+		},
+		{
+			Name:        "extend",
+			Description: "Extend the submission deadline of a project.",
+			Options:     OptionsOf(ProjectExtendOptions{}),
+			Handler:     ProjectExtendInteractionHandler,
+			Autocomplete: map[string]AutocompleteHandler{"project": ProjectStateAutocomplete(
+				ProjectExtendStates...)},
+		},
+		{
+			Name:        "close",
+			Description: "Close submissions for a project.",
+			Options:     OptionsOf(ProjectOptions{}),
+			Handler:     ProjectCloseInteractionHandler,
+			Autocomplete: map[string]AutocompleteHandler{"project": ProjectStateAutocomplete(
+				models.ProjectStatePartsReleased)},
+		},
+		{
+			Name:        "announce",
+			Description: "Post a project announcement in a channel.",
+			Options:     OptionsOf(ProjectAnnounceOptions{}),
+			Handler:     ProjectAnnounceInteractionHandler,
+			Autocomplete: map[string]AutocompleteHandler{"project": ProjectStateAutocomplete(
+				ProjectAnnounceStates...)},
+		},
+	},
+}
+
+// ProjectExtendStates are the states of projects whose deadline can be extended.
+var ProjectExtendStates = []models.ProjectState{
+	models.ProjectStatePreProduction, models.ProjectStatePartsReleased, models.ProjectStateSubmissionsClosed,
+}
+
+// ProjectAnnounceStates are the states of projects that can be announced.
+var ProjectAnnounceStates = []models.ProjectState{
+	models.ProjectStatePartsReleased, models.ProjectStateSubmissionsClosed,
+	models.ProjectStateMixing, models.ProjectStateReleased,
+}
+
+type ProjectExtendOptions struct {
+	Project  string `option:"project,required" description:"Name of the project"`
+	Deadline string `option:"deadline,required" description:"New deadline (ex 2021-06-08 23:59 America/New_York)"`
+}
+
+type ProjectAnnounceOptions struct {
+	Project string `option:"project,required" description:"Name of the project"`
+	Channel string `option:"channel,required" description:"Channel to post in" type:"channel"`
+	Message string `option:"message" description:"Text above the announcement"`
+}
+
+func ProjectReleaseInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	return transitionProject(ctx, interaction, models.ProjectStatePartsReleased, "release parts",
+		"Parts for **%s** are released 🎉")
+}
+
+func ProjectCloseInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	return transitionProject(ctx, interaction, models.ProjectStateSubmissionsClosed, "close submissions",
+		"Submissions for **%s** are closed 🔒")
+}
+
+func transitionProject(ctx context.Context, interaction discord.Interaction, to models.ProjectState, action string, format string) discord.InteractionResponse {
+	var options ProjectOptions
+	if err := DecodeOptions(interaction, &options); err != nil {
+		return InteractionResponseOof
+	}
+	identity := models.IdentityForGuildMember(interaction.Member)
+	project, errResponse := lifecycleProject(ctx, options.Project)
+	if errResponse != nil {
+		return *errResponse
+	}
+	if !project.LifecycleState().TransitionAllowed(identity, to) {
+		return InteractionResponseMessage(fmt.Sprintf("You cannot move **%s** to %s 🔒", project.Title, to), true)
+	}
+
+	lifecycle, err := models.TransitionProject(ctx, project, to, memberAuthor(identity))
+	switch {
+	case errors.Is(err, models.ErrInvalidTransition):
+		state := project.LifecycleState()
+		return InteractionResponseMessage(fmt.Sprintf("**%s** is %s and can move to %s 🤔",
+			project.Title, state, statesText(state.Transitions())), true)
+	case err != nil:
+		logger.MethodFailure(ctx, "models.TransitionProject", err)
+		return InteractionResponseOof
+	}
+
+	saveAuditEntry(ctx, models.AuditEntry{
+		By:      memberAuthor(identity),
+		Action:  action,
+		Project: project.Name,
+		Details: fmt.Sprintf("%s -> %s", project.LifecycleState(), lifecycle.State),
+	})
+	return InteractionResponseMessage(fmt.Sprintf(format, project.Title), true)
+}
+
+func ProjectExtendInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	var options ProjectExtendOptions
+	if err := DecodeOptions(interaction, &options); err != nil {
+		return InteractionResponseOof
+	}
+	identity := models.IdentityForGuildMember(interaction.Member)
+	project, errResponse := lifecycleProject(ctx, options.Project)
+	if errResponse != nil {
+		return *errResponse
+	}
+	if state := project.LifecycleState(); !inStates(state, ProjectExtendStates) {
+		return InteractionResponseMessage(fmt.Sprintf("**%s** is %s, so its deadline cannot be extended 🔒", project.Title, state), true)
+	}
+
+	deadline, err := models.ParseDeadline(options.Deadline, models.DeadlineLocation())
+	if err != nil || deadline.IsZero() {
+		return InteractionResponseMessage(fmt.Sprintf("I can't read `%s` as a deadline 😅", options.Deadline), true)
+	}
+
+	extension, err := models.ExtendDeadline(ctx, project, deadline, memberAuthor(identity))
+	switch {
+	case errors.Is(err, models.ErrDeadlineNotExtended):
+		return InteractionResponseMessage(fmt.Sprintf("The deadline for **%s** is already %s 🤔",
+			project.Title, DeadlineText(project)), true)
+	case err != nil:
+		logger.MethodFailure(ctx, "models.ExtendDeadline", err)
+		return InteractionResponseOof
+	}
+
+	saveAuditEntry(ctx, models.AuditEntry{
+		By:      memberAuthor(identity),
+		Action:  "extend deadline",
+		Project: project.Name,
+		Details: fmt.Sprintf("%s -> %s", formatAuditTime(extension.Previous), formatAuditTime(extension.Deadline)),
+	})
+	project.SubmissionDeadlineAt = extension.Deadline
+	return InteractionResponseMessage(fmt.Sprintf("The deadline for **%s** is now %s ⏰",
+		project.Title, DeadlineText(project)), true)
+}
+
+func ProjectAnnounceInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	var options ProjectAnnounceOptions
+	if err := DecodeOptions(interaction, &options); err != nil {
+		return InteractionResponseOof
+	}
+	identity := models.IdentityForGuildMember(interaction.Member)
+	project, errResponse := lifecycleProject(ctx, options.Project)
+	if errResponse != nil {
+		return *errResponse
+	}
+	if state := project.LifecycleState(); !inStates(state, ProjectAnnounceStates) {
+		return InteractionResponseMessage(fmt.Sprintf("**%s** is %s, so it cannot be announced 🔒", project.Title, state), true)
+	}
+
+	return Defer(interaction, true, func(ctx context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+		embed := ProjectEmbed(project)
+		params := discord.CreateMessageParams{Content: options.Message, Embed: &embed}
+		if _, err := discord.CreateMessage(ctx, discord.Snowflake(options.Channel), params); err != nil {
+			return discord.InteractionApplicationCommandCallbackData{}, fmt.Errorf("discord.CreateMessage() failed: %w", err)
+		}
+		saveAuditEntry(ctx, models.AuditEntry{
+			By:      memberAuthor(identity),
+			Action:  "announce",
+			Project: project.Name,
+			Details: "channel " + options.Channel,
+		})
+		return discord.InteractionApplicationCommandCallbackData{
+			Content: fmt.Sprintf("Announced **%s** in <#%s> 📣", project.Title, options.Channel),
+		}, nil
+	})
+}
+
+// ProjectStateAutocomplete suggests projects in the given states that the member can see.
+func ProjectStateAutocomplete(states ...models.ProjectState) AutocompleteHandler {
+	return func(ctx context.Context, interaction discord.Interaction, value string) []discord.ApplicationCommandOptionChoice {
+		projects, err := models.ListProjects(ctx, models.IdentityForGuildMember(interaction.Member))
+		if err != nil {
+			logger.ListProjectsFailure(ctx, err)
+			return nil
+		}
+
+		var want models.Projects
+		for _, project := range projects {
+			if inStates(project.LifecycleState(), states) {
+				want = append(want, project)
+			}
+		}
+		return projectChoices(want, value)
+	}
+}
+
+func inStates(state models.ProjectState, states []models.ProjectState) bool {
+	for _, want := range states {
+		if state == want {
+			return true
+		}
+	}
+	return false
+}
+
+// commandProject returns the project if the member can see it, or a response for the member if there is no such project.
+func commandProject(ctx context.Context, identity models.Identity, name string) (models.Project, *discord.InteractionResponse) {
+	projects, err := models.ListProjects(ctx, identity)
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		resp := InteractionResponseOof
+		return models.Project{}, &resp
+	}
+	project, ok := projects.Get(name)
+	if !ok {
+		resp := InteractionResponseMessage(fmt.Sprintf("I can't find a project called `%s` 🤔", name), true)
+		return models.Project{}, &resp
+	}
+	return project, nil
+}
+
+// lifecycleProject returns the project, drafts included, or a response for the member if there is no such project.
+// The production team runs projects before anyone else can see them, so the commands check permissions themselves.
+func lifecycleProject(ctx context.Context, name string) (models.Project, *discord.InteractionResponse) {
+	project, err := models.GetProject(ctx, name)
+	switch {
+	case errors.Is(err, models.ErrProjectNotFound):
+		resp := InteractionResponseMessage(fmt.Sprintf("I can't find a project called `%s` 🤔", name), true)
+		return models.Project{}, &resp
+	case err != nil:
+		logger.ListProjectsFailure(ctx, err)
+		resp := InteractionResponseOof
+		return models.Project{}, &resp
+	}
+	return project, nil
+}
+
+// memberAuthor identifies the member in lifecycle histories and audit entries.
+func memberAuthor(identity models.Identity) string {
+	return identity.Kind.String() + ":" + identity.DiscordID
+}
+
+func saveAuditEntry(ctx context.Context, entry models.AuditEntry) {
+	if err := models.SaveAuditEntry(ctx, entry); err != nil {
+		logger.MethodFailure(ctx, "models.SaveAuditEntry", err)
+	}
+}
+
+func formatAuditTime(t time.Time) string {
+	if t.IsZero() {
+		return "none"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func statesText(states []models.ProjectState) string {
+	if len(states) == 0 {
+		return "nothing"
+	}
+	names := make([]string, len(states))
+	for i, state := range states {
+		names[i] = state.String()
+	}
+	return strings.Join(names, " or ")
+}
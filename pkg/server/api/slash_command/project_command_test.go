@@ -0,0 +1,155 @@
+package slash_command
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestProjectCommand(t *testing.T) {
+	ctx := context.Background()
+	teams := discord.GuildMember{User: discord.User{ID: "42069"}, Roles: []string{"746434659252174971"}}
+	member := discord.GuildMember{User: discord.User{ID: "1234"}, Roles: []string{"690636730281230396"}}
+
+	setup := func(t *testing.T) {
+		redis.UseStore(redis.NewMemoryStore())
+		require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+			{"Name", "Title", "State", "Submission Deadline", "Submission Link"},
+			{"01-snake-eater", "Snake Eater", "pre-production", "2021-06-01 23:59 UTC", "https://bit.ly/vvgo01submit"},
+			{"02-proof-of-a-hero", "Proof of a Hero", "parts-released", "2021-06-01 23:59 UTC", ""},
+			{"03-draft", "Draft", "draft", "2021-06-01 23:59 UTC", ""},
+		}))
+	}
+
+	simulate := func(t *testing.T, member discord.GuildMember, command string, options ...discord.ApplicationCommandInteractionDataOption) discord.InteractionResponse {
+		response, err := Simulate(ctx, NewCommandInteraction(member, command, options...))
+		require.NoError(t, err)
+		return response
+	}
+
+	projectState := func(t *testing.T, name string) models.ProjectState {
+		projects, err := models.ListProjects(ctx, models.IdentityForGuildMember(teams))
+		require.NoError(t, err)
+		project, ok := projects.Get(name)
+		require.True(t, ok)
+		return project.LifecycleState()
+	}
+
+	auditActions := func(t *testing.T) []string {
+		entries, err := models.ListAuditEntries(ctx, 10)
+		require.NoError(t, err)
+		var actions []string
+		for _, entry := range entries {
+			assert.Equal(t, "discord:42069", entry.By)
+			actions = append(actions, entry.Action+" "+entry.Project)
+		}
+		return actions
+	}
+
+	t.Run("forbidden", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, member, "project release", Option("project", "01-snake-eater"))
+		assertEqualInteractionResponse(t, InteractionResponseForbidden, response)
+		assert.Equal(t, models.ProjectStatePreProduction, projectState(t, "01-snake-eater"))
+	})
+
+	t.Run("release", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, teams, "project release", Option("project", "01-snake-eater"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("Parts for **Snake Eater** are released 🎉", true), response)
+		assert.Equal(t, models.ProjectStatePartsReleased, projectState(t, "01-snake-eater"))
+		assert.Equal(t, []string{"release parts 01-snake-eater"}, auditActions(t))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, teams, "project close", Option("project", "02-proof-of-a-hero"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("Submissions for **Proof of a Hero** are closed 🔒", true), response)
+		assert.Equal(t, models.ProjectStateSubmissionsClosed, projectState(t, "02-proof-of-a-hero"))
+
+		response = simulate(t, teams, "project close", Option("project", "01-snake-eater"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage(
+			"**Snake Eater** is pre-production and can move to draft or parts-released 🤔", true), response)
+		assert.Equal(t, []string{"close submissions 02-proof-of-a-hero"}, auditActions(t))
+	})
+
+	t.Run("draft project", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, teams, "project release", Option("project", "03-draft"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage(
+			"**Draft** is draft and can move to pre-production 🤔", true), response)
+
+		response = simulate(t, teams, "project extend", Option("project", "03-draft"), Option("deadline", "2021-06-08 23:59 UTC"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage(
+			"**Draft** is draft, so its deadline cannot be extended 🔒", true), response)
+
+		response = simulate(t, teams, "project announce", Option("project", "01-snake-eater"), Option("channel", "5678"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage(
+			"**Snake Eater** is pre-production, so it cannot be announced 🔒", true), response)
+		assert.Empty(t, auditActions(t))
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, teams, "project close", Option("project", "cheese"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("I can't find a project called `cheese` 🤔", true), response)
+	})
+
+	t.Run("extend", func(t *testing.T) {
+		setup(t)
+		response := simulate(t, teams, "project extend", Option("project", "02-proof-of-a-hero"), Option("deadline", "2021-06-08 23:59 UTC"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage(
+			"The deadline for **Proof of a Hero** is now <t:1623196740:F> (<t:1623196740:R>) ⏰", true), response)
+
+		projects, err := models.ListProjects(ctx, models.Anonymous())
+		require.NoError(t, err)
+		deadline, _ := projects.First().Deadline()
+		assert.True(t, time.Date(2021, 6, 8, 23, 59, 0, 0, time.UTC).Equal(deadline))
+
+		response = simulate(t, teams, "project extend", Option("project", "02-proof-of-a-hero"), Option("deadline", "2021-06-02"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage(
+			"The deadline for **Proof of a Hero** is already <t:1623196740:F> (<t:1623196740:R>) 🤔", true), response)
+
+		response = simulate(t, teams, "project extend", Option("project", "02-proof-of-a-hero"), Option("deadline", "soon"))
+		assertEqualInteractionResponse(t, InteractionResponseMessage("I can't read `soon` as a deadline 😅", true), response)
+		assert.Equal(t, []string{"extend deadline 02-proof-of-a-hero"}, auditActions(t))
+	})
+
+	t.Run("announce", func(t *testing.T) {
+		setup(t)
+		webhooks := newFakeWebhooks(t)
+		response := simulate(t, teams, "project announce",
+			Option("project", "02-proof-of-a-hero"), Option("channel", "5678"), Option("message", "Parts are out!"))
+		assert.Equal(t, discord.InteractionCallbackTypeAcknowledgeWithSource, response.Type)
+
+		requests := webhooks.Requests(t)
+		require.Len(t, requests, 2)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		assert.Equal(t, "/channels/5678/messages", requests[0].Path)
+		assert.Equal(t, "Parts are out!", requests[0].Data.Content)
+		assert.Equal(t, "Announced **Proof of a Hero** in <#5678> 📣", requests[1].Data.Content)
+		assert.Equal(t, []string{"announce 02-proof-of-a-hero"}, auditActions(t))
+	})
+
+	t.Run("autocomplete", func(t *testing.T) {
+		setup(t)
+		for command, want := range map[string][]discord.ApplicationCommandOptionChoice{
+			"project release": {{Name: "Snake Eater", Value: "01-snake-eater"}},
+			"project close":   {{Name: "Proof of a Hero", Value: "02-proof-of-a-hero"}},
+		} {
+			response, err := Simulate(ctx, NewAutocompleteInteraction(teams, command, Option("project", "")))
+			require.NoError(t, err)
+			assert.Equal(t, want, response.Data.Choices, command)
+		}
+
+		response, err := Simulate(ctx, NewAutocompleteInteraction(member, "project release", Option("project", "")))
+		require.NoError(t, err)
+		assert.Empty(t, response.Data.Choices, "members without the roles get no suggestions")
+	})
+}
@@ -0,0 +1,115 @@
+package slash_command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"strings"
+)
+
+// Option is an option of a simulated interaction.
+func Option(name string, value interface{}) discord.ApplicationCommandInteractionDataOption {
+	return discord.ApplicationCommandInteractionDataOption{Name: name, Value: discord.OptionValue(fmt.Sprint(value))}
+}
+
+// NewCommandInteraction builds the interaction discord sends when the member runs a command.
+// Subcommands are named after the command, as they are typed: `project parts`.
+func NewCommandInteraction(member discord.GuildMember, command string, options ...discord.ApplicationCommandInteractionDataOption) discord.Interaction {
+	names := strings.Fields(command)
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	for i := len(names) - 1; i > 0; i-- {
+		options = []discord.ApplicationCommandInteractionDataOption{{
+			Name:    names[i],
+			Type:    subcommandOptionType(names[:i+1]),
+			Options: options,
+		}}
+	}
+	return discord.Interaction{
+		Type:   discord.InteractionTypeApplicationCommand,
+		Member: member,
+		Data: &discord.ApplicationCommandInteractionData{
+			Name:    names[0],
+			Options: options,
+		},
+	}
+}
+
+// NewAutocompleteInteraction builds the interaction discord sends while the member types the focused option.
+func NewAutocompleteInteraction(member discord.GuildMember, command string, focused discord.ApplicationCommandInteractionDataOption, options ...discord.ApplicationCommandInteractionDataOption) discord.Interaction {
+	focused.Focused = true
+	interaction := NewCommandInteraction(member, command, append(options, focused)...)
+	interaction.Type = discord.InteractionTypeApplicationCommandAutocomplete
+	return interaction
+}
+
+// NewComponentInteraction builds the interaction discord sends when the member clicks a button or picks from a select menu.
+func NewComponentInteraction(member discord.GuildMember, customID string, values ...string) discord.Interaction {
+	componentType := discord.ComponentTypeButton
+	if len(values) != 0 {
+		componentType = discord.ComponentTypeSelectMenu
+	}
+	return discord.Interaction{
+		Type:    discord.InteractionTypeMessageComponent,
+		Member:  member,
+		Message: &discord.Message{},
+		Data: &discord.ApplicationCommandInteractionData{
+			CustomID:      customID,
+			ComponentType: componentType,
+			Values:        values,
+		},
+	}
+}
+
+// Simulate handles the interaction as if it were sent by discord.
+// The interaction and the response are sent through json, so that they match what discord sends and receives.
+func Simulate(ctx context.Context, interaction discord.Interaction) (discord.InteractionResponse, error) {
+	var decoded discord.Interaction
+	if err := roundTripJson(interaction, &decoded); err != nil {
+		return discord.InteractionResponse{}, fmt.Errorf("interaction: %w", err)
+	}
+	response, ok := HandleInteraction(ctx, decoded)
+	if !ok {
+		return discord.InteractionResponse{}, fmt.Errorf("unsupported interaction type %d", interaction.Type)
+	}
+	var decodedResponse discord.InteractionResponse
+	if err := roundTripJson(response, &decodedResponse); err != nil {
+		return discord.InteractionResponse{}, fmt.Errorf("response: %w", err)
+	}
+	return decodedResponse, nil
+}
+
+func roundTripJson(src interface{}, dest interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// subcommandOptionType is the type of the last subcommand in names.
+func subcommandOptionType(names []string) discord.ApplicationCommandOptionType {
+	for _, command := range SlashCommands {
+		if command.Name != names[0] {
+			continue
+		}
+		for _, name := range names[1:] {
+			var found bool
+			for _, subcommand := range command.Subcommands {
+				if subcommand.Name == name {
+					command, found = subcommand, true
+					break
+				}
+			}
+			if !found {
+				break
+			}
+		}
+		if len(command.Subcommands) != 0 {
+			return discord.ApplicationCommandOptionTypeSubCommandGroup
+		}
+	}
+	return discord.ApplicationCommandOptionTypeSubCommand
+}
@@ -10,9 +10,10 @@ import (
 	"encoding/json"
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
 	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
 	"net/http"
-	"time"
+	"strconv"
 )
 
 var SlashCommands = []SlashCommand{
@@ -20,45 +21,56 @@ var SlashCommands = []SlashCommand{
 		Name:        "beep",
 		Description: "Send a beep.",
 		Handler:     BeepInteractionHandler,
+		Components:  map[string]InteractionHandler{BeepAgainComponent: BeepAgainInteractionHandler},
 	},
 	{
-		Name:        "parts",
-		Description: "Parts link for a project.",
-		Options:     PartsCommandOptions,
-		Handler:     PartsInteractionHandler,
+		Name:         "parts",
+		Description:  "Parts link for a project.",
+		Options:      OptionsOf(ProjectOptions{}),
+		Handler:      PartsInteractionHandler,
+		Autocomplete: map[string]AutocompleteHandler{"project": ProjectAutocomplete},
 	},
 	{
-		Name:        "submit",
-		Description: "Submission link for a project.",
-		Options:     SubmitCommandOptions,
-		Handler:     SubmitInteractionHandler,
+		Name:         "submit",
+		Description:  "Submission link for a project.",
+		Options:      OptionsOf(ProjectOptions{}),
+		Handler:      SubmitInteractionHandler,
+		Autocomplete: map[string]AutocompleteHandler{"project": ProjectAutocomplete},
 	},
 	{
 		Name:        "when2meet",
 		Description: "Make a when2meet link.",
-		Options:     When2meetCommandOptions,
+		Options:     OptionsOf(When2meetOptions{}),
 		Handler:     when2meetInteractionHandler,
 	},
+	{
+		Name:        "mysubmissions",
+		Description: "Your submissions for current projects.",
+		Handler:     MySubmissionsInteractionHandler,
+	},
+	CreditsCommand,
+	ProjectCommand,
 }
 
 var InteractionResponseOof = InteractionResponseMessage("oof please try again 😅", true)
 var InteractionResponseGalaxyBrain = InteractionResponseMessage("this interaction is too galaxy brain for me 😥", true)
+var InteractionResponseForbidden = InteractionResponseMessage("you don't have permission to do that 🙅", true)
 
+// Update syncs the commands with discord, and writes the sync report.
+// Changes are only reported with ?dry_run=true.
 func Update(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	timer := time.NewTimer(1 * time.Second)
-	defer timer.Stop()
-	for _, command := range SlashCommands {
-		<-timer.C
-		if err := command.Create(ctx); err != nil {
-			logger.MethodFailure(ctx, "SlashCommand.Create", err)
-			http_helpers.WriteInternalServerError(ctx, w)
-			return
-		} else {
-			logger.Info(command.Name, "command created")
-		}
+	dryRun, _ := strconv.ParseBool(r.FormValue("dry_run"))
+	report, err := Sync(ctx, dryRun)
+	if err != nil {
+		logger.MethodFailure(ctx, "slash_command.Sync", err)
+		http_helpers.WriteInternalServerError(ctx, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.JsonEncodeFailure(ctx, err)
 	}
-	http.Redirect(w, r, "/slash_commands", http.StatusFound)
 }
 
 func List(w http.ResponseWriter, r *http.Request) {
@@ -126,42 +138,175 @@ func HandleInteraction(ctx context.Context, interaction discord.Interaction) (di
 	switch interaction.Type {
 	case discord.InteractionTypePing:
 		return discord.InteractionResponse{Type: discord.InteractionCallbackTypePong}, true
+
 	case discord.InteractionTypeApplicationCommand:
+		command, ok := resolveCommand(&interaction)
+		switch {
+		case !ok || command.Handler == nil:
+			return InteractionResponseGalaxyBrain, true
+		case !command.Allows(interaction.Member):
+			return InteractionResponseForbidden, true
+		}
+		return command.Handler(ctx, interaction), true
+
+	case discord.InteractionTypeApplicationCommandAutocomplete:
+		var choices []discord.ApplicationCommandOptionChoice
+		command, ok := resolveCommand(&interaction)
+		if focused, isFocused := FocusedOption(interaction); ok && isFocused && command.Allows(interaction.Member) {
+			if autocomplete := command.Autocomplete[focused.Name]; autocomplete != nil {
+				choices = autocomplete(ctx, interaction, focused.Value.String())
+			}
+		}
+		if len(choices) > MaxChoices {
+			choices = choices[:MaxChoices]
+		}
+		return discord.InteractionResponse{
+			Type: discord.InteractionCallbackTypeApplicationCommandAutocompleteResult,
+			Data: &discord.InteractionApplicationCommandCallbackData{Choices: choices},
+		}, true
+
+	case discord.InteractionTypeMessageComponent:
+		if interaction.Data == nil {
+			return InteractionResponseGalaxyBrain, true
+		}
+		commandName, componentName, _ := parseComponentID(interaction.Data.CustomID)
 		for _, command := range SlashCommands {
-			if interaction.Data.Name == command.Name {
-				return command.Handler(ctx, interaction), true
+			if command.Name != commandName {
+				continue
+			}
+			if handler := command.Components[componentName]; handler != nil {
+				if !command.Allows(interaction.Member) {
+					return InteractionResponseForbidden, true
+				}
+				return handler(ctx, interaction), true
 			}
 		}
 		return InteractionResponseGalaxyBrain, true
+
 	default:
 		return discord.InteractionResponse{}, false
 	}
 }
 
+// resolveCommand finds the command or subcommand of the interaction.
+// The interaction's options are replaced with the options of the subcommand.
+func resolveCommand(interaction *discord.Interaction) (SlashCommand, bool) {
+	if interaction.Data == nil {
+		return SlashCommand{}, false
+	}
+	for _, command := range SlashCommands {
+		if command.Name != interaction.Data.Name {
+			continue
+		}
+		resolved, options, ok := command.resolve(interaction.Data.Options)
+		data := *interaction.Data
+		data.Options = options
+		interaction.Data = &data
+		return resolved, ok
+	}
+	return SlashCommand{}, false
+}
+
+// MaxChoices is the most choices discord accepts in an autocomplete result.
+const MaxChoices = 25
+
+// SlashCommand is a command, or a subcommand of another command.
 type SlashCommand struct {
 	Name        string
 	Description string
-	Options     func(context.Context) ([]discord.ApplicationCommandOption, error)
-	Handler     InteractionHandler
+	// Options returns the options of the command. Commands with subcommands do not have options.
+	Options func(context.Context) ([]discord.ApplicationCommandOption, error)
+	// Subcommands are run as `/command subcommand`.
+	// A subcommand with its own subcommands is a subcommand group.
+	Subcommands []SlashCommand
+	// Handler runs the command. Commands with subcommands do not have handlers.
+	Handler InteractionHandler
+	// Autocomplete suggests values for the options of the command, by option name.
+	Autocomplete map[string]AutocompleteHandler
+	// Components handle buttons and select menus on the command's messages, by component name.
+	// Only top level commands have components. Use ComponentID to build the custom id of a component.
+	Components map[string]InteractionHandler
+	// Roles restricts the command to members with these roles. Commands without roles can be used by everyone.
+	// Discord only hides top level commands, so subcommands inherit the roles of their command, and members
+	// without the roles are also turned away when they use the command.
+	Roles []models.Role
 }
 
+// InteractionHandler responds to an interaction.
+// For commands, the interaction options are the options of the subcommand that was run.
 type InteractionHandler func(context.Context, discord.Interaction) discord.InteractionResponse
 
-func (x SlashCommand) Create(ctx context.Context) (err error) {
-	var options []discord.ApplicationCommandOption
-	if x.Options != nil {
-		options, err = x.Options(ctx)
-		if err != nil {
-			return err
+// AutocompleteHandler suggests values for the option the user is typing.
+type AutocompleteHandler func(ctx context.Context, interaction discord.Interaction, value string) []discord.ApplicationCommandOptionChoice
+
+// resolve walks the subcommands named in the options, and returns the subcommand and its options.
+func (x SlashCommand) resolve(options []discord.ApplicationCommandInteractionDataOption) (SlashCommand, []discord.ApplicationCommandInteractionDataOption, bool) {
+	if len(x.Subcommands) == 0 {
+		return x, options, true
+	}
+	if len(options) != 1 {
+		return SlashCommand{}, nil, false
+	}
+	for _, subcommand := range x.Subcommands {
+		if subcommand.Name == options[0].Name {
+			if len(subcommand.Roles) == 0 {
+				subcommand.Roles = x.Roles
+			}
+			return subcommand.resolve(options[0].Options)
+		}
+	}
+	return SlashCommand{}, nil, false
+}
+
+// Allows is true if the member has one of the roles of the command.
+func (x SlashCommand) Allows(member discord.GuildMember) bool {
+	if len(x.Roles) == 0 {
+		return true
+	}
+	identity := models.IdentityForGuildMember(member)
+	for _, role := range x.Roles {
+		if identity.HasRole(role) {
+			return true
 		}
 	}
-	params := discord.CreateApplicationCommandParams{
-		Name:        x.Name,
-		Description: x.Description,
-		Options:     options,
+	return false
+}
+
+func (x SlashCommand) applicationCommandOptions(ctx context.Context) ([]discord.ApplicationCommandOption, error) {
+	if len(x.Subcommands) != 0 {
+		options := make([]discord.ApplicationCommandOption, 0, len(x.Subcommands))
+		for _, subcommand := range x.Subcommands {
+			subOptions, err := subcommand.applicationCommandOptions(ctx)
+			if err != nil {
+				return nil, err
+			}
+			optionType := discord.ApplicationCommandOptionTypeSubCommand
+			if len(subcommand.Subcommands) != 0 {
+				optionType = discord.ApplicationCommandOptionTypeSubCommandGroup
+			}
+			options = append(options, discord.ApplicationCommandOption{
+				Type:        optionType,
+				Name:        subcommand.Name,
+				Description: subcommand.Description,
+				Options:     subOptions,
+			})
+		}
+		return options, nil
+	}
+
+	if x.Options == nil {
+		return nil, nil
+	}
+	options, err := x.Options(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range options {
+		if x.Autocomplete[options[i].Name] != nil {
+			options[i].Autocomplete = true
+		}
 	}
-	_, err = discord.CreateApplicationCommand(ctx, params)
-	return err
+	return options, nil
 }
 
 func InteractionResponseMessage(text string, ephemeral bool) discord.InteractionResponse {
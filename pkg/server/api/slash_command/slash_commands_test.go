@@ -42,9 +42,9 @@ func TestHandleBeepInteraction(t *testing.T) {
 
 func TestHandlePartsInteraction(t *testing.T) {
 	ctx := context.Background()
-	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, [][]interface{}{
-		{"Name", "Title", "Parts Released"},
-		{"10-hildas-healing", "Hilda's Healing", true},
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "Parts Released", "Submission Deadline"},
+		{"10-hildas-healing", "Hilda's Healing", true, "2021-06-01 23:59 UTC"},
 	}))
 
 	interaction := discord.Interaction{
@@ -66,7 +66,7 @@ func TestHandlePartsInteraction(t *testing.T) {
 			Embeds: []discord.Embed{{
 				Title:       "Hilda's Healing",
 				Type:        "rich",
-				Description: "· Parts are [here!](https://vvgo.org/parts?project=10-hildas-healing)\n· Submit files [here!]()\n· Submission Deadline: .",
+				Description: "· Parts are [here!](https://vvgo.org/parts?project=10-hildas-healing)\n· Submit files [here!]()\n· Submission Deadline: <t:1622591940:F> (<t:1622591940:R>).",
 				Url:         "https://vvgo.org/parts?project=10-hildas-healing",
 				Color:       9181145,
 				Footer:      &discord.EmbedFooter{Text: "Bottom text."},
@@ -77,7 +77,7 @@ func TestHandlePartsInteraction(t *testing.T) {
 
 func TestHandleSubmissionInteraction(t *testing.T) {
 	ctx := context.Background()
-	redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, [][]interface{}{
+	redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
 		{"Name", "Title", "Parts Released", "Submission Link"},
 		{"10-hildas-healing", "Hilda's Healing", true, "https://bit.ly/vvgo10submit"},
 	})
@@ -98,7 +98,7 @@ func TestHandleSubmissionInteraction(t *testing.T) {
 	assertEqualInteractionResponse(t, discord.InteractionResponse{
 		Type: discord.InteractionCallbackTypeChannelMessageWithSource,
 		Data: &discord.InteractionApplicationCommandCallbackData{
-			Content: "[Submit here](https://bit.ly/vvgo10submit) for Hilda's Healing. Submission Deadline is ",
+			Content: "[Submit here](https://bit.ly/vvgo10submit) for Hilda's Healing. Submission Deadline is TBD.",
 		},
 	}, response)
 }
@@ -111,24 +111,33 @@ func TestHandleWhen2MeetInteraction(t *testing.T) {
 	when2meet.Endpoint = ts.URL
 
 	ctx := context.Background()
-	interaction := discord.Interaction{
-		Type:   discord.InteractionTypeApplicationCommand,
-		Member: discord.GuildMember{User: discord.User{ID: "42069"}},
-		Data: &discord.ApplicationCommandInteractionData{
-			Name: "when2meet",
-			Options: []discord.ApplicationCommandInteractionDataOption{
-				{Name: "start_date", Value: "2030-02-01"},
-				{Name: "end_date", Value: "2030-02-02"},
-				{Name: "event_name", Value: "holy cheesus"},
-			},
-		},
-	}
+	member := discord.GuildMember{User: discord.User{ID: "42069"}}
+
+	t.Run("ok", func(t *testing.T) {
+		webhooks := newFakeWebhooks(t)
+		interaction := NewCommandInteraction(member, "when2meet",
+			Option("start_date", "2030-02-01"), Option("end_date", "2030-02-02"), Option("event_name", "holy cheesus"))
+		response, err := Simulate(ctx, interaction)
+		require.NoError(t, err)
+		assert.Equal(t, discord.InteractionCallbackTypeAcknowledgeWithSource, response.Type)
+
+		requests := webhooks.Requests(t)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "<@42069> created a [when2meet](https://when2meet.com/?10947260-c2u6i).", requests[0].Data.Content)
+		assert.Equal(t, discord.InteractionApplicationCommandCallbackDataFlagEphemeral, requests[0].Data.Flags)
+	})
 
-	response, ok := HandleInteraction(ctx, interaction)
-	assert.True(t, ok)
+	t.Run("invalid date", func(t *testing.T) {
+		webhooks := newFakeWebhooks(t)
+		interaction := NewCommandInteraction(member, "when2meet",
+			Option("start_date", "tomorrow"), Option("end_date", "2030-02-02"), Option("event_name", "holy cheesus"))
+		_, err := Simulate(ctx, interaction)
+		require.NoError(t, err)
 
-	want := InteractionResponseMessage("<@42069> created a [when2meet](https://when2meet.com/?10947260-c2u6i).", true)
-	assertEqualInteractionResponse(t, want, response)
+		requests := webhooks.Requests(t)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "dates must look like 2021-02-04 😅", requests[0].Data.Content)
+	})
 }
 
 func assertEqualInteractionResponse(t *testing.T, want, got discord.InteractionResponse) {
@@ -141,3 +150,86 @@ func assertEqualInteractionApplicationCommandCallbackData(t *testing.T, want, go
 	assert.Equal(t, want.TTS, got.TTS, "interaction.Data.TTS")
 	assert.Equal(t, want.Embeds, got.Embeds, "interaction.Data.Embeds")
 }
+
+func TestHandleSubcommandInteraction(t *testing.T) {
+	defer func(commands []SlashCommand) { SlashCommands = commands }(SlashCommands)
+	handler := func(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
+		var options struct {
+			Name string `option:"name,required"`
+		}
+		if err := DecodeOptions(interaction, &options); err != nil {
+			return InteractionResponseOof
+		}
+		return InteractionResponseMessage("hello "+options.Name, false)
+	}
+	SlashCommands = []SlashCommand{{
+		Name: "cheese",
+		Subcommands: []SlashCommand{
+			{Name: "wheel", Subcommands: []SlashCommand{{Name: "roll", Handler: handler}}},
+			{Name: "melt", Handler: handler},
+		},
+	}}
+
+	ctx := context.Background()
+	for _, command := range []string{"cheese melt", "cheese wheel roll"} {
+		t.Run(command, func(t *testing.T) {
+			response, err := Simulate(ctx, NewCommandInteraction(discord.GuildMember{}, command, Option("name", "brie")))
+			require.NoError(t, err)
+			assertEqualInteractionResponse(t, InteractionResponseMessage("hello brie", false), response)
+		})
+	}
+
+	t.Run("unknown subcommand", func(t *testing.T) {
+		response, err := Simulate(ctx, NewCommandInteraction(discord.GuildMember{}, "cheese wheel eat"))
+		require.NoError(t, err)
+		assertEqualInteractionResponse(t, InteractionResponseGalaxyBrain, response)
+	})
+
+	t.Run("application command options", func(t *testing.T) {
+		got, err := SlashCommands[0].applicationCommandOptions(ctx)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, discord.ApplicationCommandOptionTypeSubCommandGroup, got[0].Type)
+		assert.Equal(t, discord.ApplicationCommandOptionTypeSubCommand, got[0].Options[0].Type)
+		assert.Equal(t, discord.ApplicationCommandOptionTypeSubCommand, got[1].Type)
+	})
+}
+
+func TestHandleAutocompleteInteraction(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "Parts Released"},
+		{"10-hildas-healing", "Hilda's Healing", true},
+		{"11-cheese-dreams", "Cheese Dreams", true},
+	}))
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetParts, "test", [][]interface{}{
+		{"Project", "Part Name"},
+		{"10-hildas-healing", "Trumpet 1"},
+		{"10-hildas-healing", "Trombone"},
+		{"11-cheese-dreams", "Trumpet 2"},
+	}))
+
+	t.Run("project", func(t *testing.T) {
+		response, err := Simulate(ctx, NewAutocompleteInteraction(discord.GuildMember{}, "parts", Option("project", "hilda")))
+		require.NoError(t, err)
+		assert.Equal(t, discord.InteractionCallbackTypeApplicationCommandAutocompleteResult, response.Type)
+		require.NotNil(t, response.Data)
+		assert.Equal(t, []discord.ApplicationCommandOptionChoice{
+			{Name: "Hilda's Healing", Value: "10-hildas-healing"},
+		}, response.Data.Choices)
+	})
+
+	t.Run("part", func(t *testing.T) {
+		member := discord.GuildMember{Roles: []string{"690636730281230396"}}
+		interaction := NewAutocompleteInteraction(member, "parts", Option("part", "trum"), Option("project", "10-hildas-healing"))
+		choices := PartAutocomplete(ctx, interaction, "trum")
+		assert.Equal(t, []discord.ApplicationCommandOptionChoice{
+			{Name: "Trumpet 1", Value: "Trumpet 1"},
+		}, choices)
+	})
+
+	t.Run("part anonymous", func(t *testing.T) {
+		interaction := NewAutocompleteInteraction(discord.GuildMember{}, "parts", Option("part", "trum"), Option("project", "10-hildas-healing"))
+		assert.Empty(t, PartAutocomplete(ctx, interaction, "trum"))
+	})
+}
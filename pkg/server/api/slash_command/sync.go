@@ -0,0 +1,235 @@
+package slash_command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"sort"
+	"strings"
+)
+
+type SyncAction string
+
+const (
+	SyncActionCreate SyncAction = "create"
+	SyncActionUpdate SyncAction = "update"
+	SyncActionDelete SyncAction = "delete"
+)
+
+// SyncChange is a change to one registered command.
+type SyncChange struct {
+	Action  SyncAction
+	Command string
+	ID      string `json:",omitempty"`
+	// Fields are the fields that changed in an update.
+	Fields []string `json:",omitempty"`
+}
+
+// SyncReport lists the changes made by Sync, or the changes it would make in a dry run.
+type SyncReport struct {
+	DryRun    bool
+	Changes   []SyncChange
+	Unchanged []string
+}
+
+func (x SyncReport) String() string {
+	var lines []string
+	if x.DryRun {
+		lines = append(lines, "dry run: no changes were made")
+	}
+	for _, change := range x.Changes {
+		line := string(change.Action) + " /" + change.Command
+		if len(change.Fields) != 0 {
+			line += " (" + strings.Join(change.Fields, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(x.Changes) == 0 {
+		lines = append(lines, "commands are up to date")
+	}
+	if len(x.Unchanged) != 0 {
+		lines = append(lines, "unchanged: /"+strings.Join(x.Unchanged, ", /"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// desiredCommand is a command as it should be registered with discord.
+type desiredCommand struct {
+	params      discord.CreateApplicationCommandParams
+	permissions []discord.ApplicationCommandPermission
+}
+
+// Sync registers SlashCommands with discord.
+// Commands are only created, updated, or deleted if they differ from the registered commands.
+// A dry run reports the changes without making them.
+func Sync(ctx context.Context, dryRun bool) (SyncReport, error) {
+	report := SyncReport{DryRun: dryRun}
+
+	registered, err := discord.GetApplicationCommands(ctx)
+	if err != nil {
+		return report, fmt.Errorf("discord.GetApplicationCommands() failed: %w", err)
+	}
+	permissions, err := discord.GetApplicationCommandPermissions(ctx)
+	if err != nil {
+		return report, fmt.Errorf("discord.GetApplicationCommandPermissions() failed: %w", err)
+	}
+	registeredPermissions := make(map[string][]discord.ApplicationCommandPermission, len(permissions))
+	for _, permission := range permissions {
+		registeredPermissions[permission.ID] = permission.Permissions
+	}
+
+	registeredByName := make(map[string]discord.ApplicationCommand, len(registered))
+	for _, command := range registered {
+		registeredByName[command.Name] = command
+	}
+
+	declared := make(map[string]bool, len(SlashCommands))
+	for _, command := range SlashCommands {
+		want, err := command.desired(ctx)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", command.Name, err)
+		}
+		declared[command.Name] = true
+
+		current, ok := registeredByName[command.Name]
+		if !ok {
+			change := SyncChange{Action: SyncActionCreate, Command: command.Name}
+			if !dryRun {
+				created, err := discord.CreateApplicationCommand(ctx, want.params)
+				if err != nil {
+					return report, fmt.Errorf("discord.CreateApplicationCommand() failed: %w", err)
+				}
+				change.ID = created.ID
+				if len(want.permissions) != 0 {
+					if err := editPermissions(ctx, created.ID, want.permissions); err != nil {
+						return report, err
+					}
+				}
+			}
+			report.Changes = append(report.Changes, change)
+			continue
+		}
+
+		fields := diffCommand(want, current, registeredPermissions[current.ID])
+		if len(fields) == 0 {
+			report.Unchanged = append(report.Unchanged, command.Name)
+			continue
+		}
+		if !dryRun {
+			permissionsChanged := fields[len(fields)-1] == "permissions"
+			if len(fields) > 1 || !permissionsChanged {
+				params := discord.EditApplicationCommandParams(want.params)
+				if _, err := discord.EditApplicationCommand(ctx, discord.Snowflake(current.ID), params); err != nil {
+					return report, fmt.Errorf("discord.EditApplicationCommand() failed: %w", err)
+				}
+			}
+			if permissionsChanged {
+				if err := editPermissions(ctx, current.ID, want.permissions); err != nil {
+					return report, err
+				}
+			}
+		}
+		report.Changes = append(report.Changes, SyncChange{Action: SyncActionUpdate, Command: command.Name, ID: current.ID, Fields: fields})
+	}
+
+	for _, command := range registered {
+		if declared[command.Name] {
+			continue
+		}
+		if !dryRun {
+			if err := discord.DeleteApplicationCommand(ctx, discord.Snowflake(command.ID)); err != nil {
+				return report, fmt.Errorf("discord.DeleteApplicationCommand() failed: %w", err)
+			}
+		}
+		report.Changes = append(report.Changes, SyncChange{Action: SyncActionDelete, Command: command.Name, ID: command.ID})
+	}
+	return report, nil
+}
+
+func editPermissions(ctx context.Context, id string, permissions []discord.ApplicationCommandPermission) error {
+	params := discord.EditApplicationCommandPermissionsParams{Permissions: permissions}
+	if params.Permissions == nil {
+		params.Permissions = []discord.ApplicationCommandPermission{}
+	}
+	if err := discord.EditApplicationCommandPermissions(ctx, discord.Snowflake(id), params); err != nil {
+		return fmt.Errorf("discord.EditApplicationCommandPermissions() failed: %w", err)
+	}
+	return nil
+}
+
+// diffCommand returns the fields of the registered command that differ from the desired command.
+// Permissions are always last.
+func diffCommand(want desiredCommand, current discord.ApplicationCommand, permissions []discord.ApplicationCommandPermission) []string {
+	var fields []string
+	if want.params.Description != current.Description {
+		fields = append(fields, "description")
+	}
+	if !equalJson(want.params.Options, current.Options) {
+		fields = append(fields, "options")
+	}
+	if want.params.DefaultPermission != (current.DefaultPermission == nil || *current.DefaultPermission) {
+		fields = append(fields, "default_permission")
+	}
+	if !equalJson(sortPermissions(want.permissions), sortPermissions(permissions)) {
+		fields = append(fields, "permissions")
+	}
+	return fields
+}
+
+// equalJson compares values as they are sent to discord, so that missing and empty fields are equal.
+func equalJson(a, b interface{}) bool {
+	aJson, _ := json.Marshal(a)
+	bJson, _ := json.Marshal(b)
+	return string(aJson) == string(bJson)
+}
+
+func sortPermissions(permissions []discord.ApplicationCommandPermission) []discord.ApplicationCommandPermission {
+	if len(permissions) == 0 {
+		return nil
+	}
+	sorted := append([]discord.ApplicationCommandPermission(nil), permissions...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+func (x SlashCommand) desired(ctx context.Context) (desiredCommand, error) {
+	options, err := x.applicationCommandOptions(ctx)
+	if err != nil {
+		return desiredCommand{}, err
+	}
+	return desiredCommand{
+		params: discord.CreateApplicationCommandParams{
+			Name:              x.Name,
+			Description:       x.Description,
+			Options:           options,
+			DefaultPermission: len(x.Roles) == 0,
+		},
+		permissions: rolePermissions(x.Roles),
+	}, nil
+}
+
+// rolePermissions allows the discord roles that map to the vvgo roles.
+func rolePermissions(roles []models.Role) []discord.ApplicationCommandPermission {
+	var permissions []discord.ApplicationCommandPermission
+	for discordRole, vvgoRole := range config.Config().Discord.RoleMap {
+		for _, role := range roles {
+			if models.Role(vvgoRole) == role {
+				permissions = append(permissions, discord.ApplicationCommandPermission{
+					ID:         discordRole,
+					Type:       discord.ApplicationCommandPermissionTypeRole,
+					Permission: true,
+				})
+				break
+			}
+		}
+	}
+	return sortPermissions(permissions)
+}
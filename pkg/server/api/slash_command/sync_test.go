@@ -0,0 +1,141 @@
+package slash_command
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCommands is the discord application commands api.
+type fakeCommands struct {
+	lock        sync.Mutex
+	commands    []discord.ApplicationCommand
+	permissions []discord.GuildApplicationCommandPermissions
+	calls       []string
+}
+
+func newFakeCommands(t *testing.T, commands []discord.ApplicationCommand, permissions []discord.GuildApplicationCommandPermissions) *fakeCommands {
+	fake := &fakeCommands{commands: commands, permissions: permissions}
+	prefix := "/applications/" + discord.ApplicationID + "/guilds/" + discord.GuildID().String() + "/commands"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.lock.Lock()
+		defer fake.lock.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodGet && path == "":
+			_ = json.NewEncoder(w).Encode(fake.commands)
+			return
+		case r.Method == http.MethodGet && path == "/permissions":
+			_ = json.NewEncoder(w).Encode(fake.permissions)
+			return
+		case r.Method == http.MethodPost && path == "":
+			var command discord.ApplicationCommand
+			require.NoError(t, json.Unmarshal(body, &command))
+			command.ID = "new-" + command.Name
+			_ = json.NewEncoder(w).Encode(command)
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+		fake.calls = append(fake.calls, r.Method+" "+path+" "+strings.TrimSpace(string(body)))
+	}))
+	t.Cleanup(ts.Close)
+	endpoint := config.Config().Discord.Endpoint
+	t.Cleanup(func() { config.Update(func(x *config.Configuration) { x.Discord.Endpoint = endpoint }) })
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
+	return fake
+}
+
+func (x *fakeCommands) Calls() []string {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	return x.calls
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	defer func(commands []SlashCommand) { SlashCommands = commands }(SlashCommands)
+	defer func(roleMap map[string]string) {
+		config.Update(func(x *config.Configuration) { x.Discord.RoleMap = roleMap })
+	}(config.Config().Discord.RoleMap)
+	config.Update(func(x *config.Configuration) {
+		x.Discord.RoleMap = map[string]string{"111": "vvgo-teams", "222": "vvgo-member"}
+	})
+
+	type cheeseOptions struct {
+		Cheese string `option:"cheese,required" description:"A cheese"`
+	}
+	SlashCommands = []SlashCommand{
+		{Name: "beep", Description: "Send a beep."},
+		{Name: "cheese", Description: "Cheese.", Options: OptionsOf(cheeseOptions{})},
+		{Name: "secret", Description: "Secret cheese.", Roles: []models.Role{models.RoleVVGOProductionTeam}},
+		{Name: "new", Description: "New cheese.", Roles: []models.Role{models.RoleVVGOProductionTeam}},
+	}
+	falseValue := false
+	registered := []discord.ApplicationCommand{
+		{ID: "1", Name: "beep", Description: "Send a beep."},
+		{ID: "2", Name: "cheese", Description: "Old cheese."},
+		{ID: "3", Name: "secret", Description: "Secret cheese.", DefaultPermission: &falseValue},
+		{ID: "4", Name: "stale", Description: "Stale cheese."},
+	}
+	permissions := []discord.GuildApplicationCommandPermissions{{ID: "3", Permissions: []discord.ApplicationCommandPermission{
+		{ID: "222", Type: discord.ApplicationCommandPermissionTypeRole, Permission: true},
+	}}}
+
+	wantChanges := []SyncChange{
+		{Action: SyncActionUpdate, Command: "cheese", ID: "2", Fields: []string{"description", "options"}},
+		{Action: SyncActionUpdate, Command: "secret", ID: "3", Fields: []string{"permissions"}},
+		{Action: SyncActionCreate, Command: "new"},
+		{Action: SyncActionDelete, Command: "stale", ID: "4"},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		fake := newFakeCommands(t, registered, permissions)
+		report, err := Sync(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, SyncReport{DryRun: true, Changes: wantChanges, Unchanged: []string{"beep"}}, report)
+		assert.Empty(t, fake.Calls())
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		fake := newFakeCommands(t, registered, permissions)
+		report, err := Sync(ctx, false)
+		require.NoError(t, err)
+		wantChanges[2].ID = "new-new"
+		assert.Equal(t, SyncReport{Changes: wantChanges, Unchanged: []string{"beep"}}, report)
+		assert.Equal(t, []string{
+			`PATCH /2 {"name":"cheese","description":"Cheese.","options":[{"type":3,"name":"cheese","description":"A cheese","required":true}],"default_permission":true}`,
+			`PUT /3/permissions {"permissions":[{"id":"111","type":1,"permission":true}]}`,
+			`POST  {"name":"new","description":"New cheese.","default_permission":false}`,
+			`PUT /new-new/permissions {"permissions":[{"id":"111","type":1,"permission":true}]}`,
+			`DELETE /4 `,
+		}, fake.Calls())
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		SlashCommands = SlashCommands[:1]
+		fake := newFakeCommands(t, registered[:1], nil)
+		report, err := Sync(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, "commands are up to date\nunchanged: /beep", report.String())
+		assert.Empty(t, fake.Calls())
+	})
+}
+
+func TestSyncReport_String(t *testing.T) {
+	report := SyncReport{DryRun: true, Changes: []SyncChange{
+		{Action: SyncActionUpdate, Command: "cheese", Fields: []string{"description", "options"}},
+		{Action: SyncActionDelete, Command: "stale"},
+	}, Unchanged: []string{"beep", "parts"}}
+	assert.Equal(t, "dry run: no changes were made\nupdate /cheese (description, options)\ndelete /stale\nunchanged: /beep, /parts", report.String())
+}
@@ -5,53 +5,31 @@ import (
 	"fmt"
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
 	"github.com/virtual-vgo/vvgo/pkg/clients/when2meet"
-	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
 )
 
-func When2meetCommandOptions(context.Context) ([]discord.ApplicationCommandOption, error) {
-	return []discord.ApplicationCommandOption{
-		{
-			Type:        discord.ApplicationCommandOptionTypeString,
-			Name:        "event_name",
-			Description: "A name for the event.",
-			Required:    true,
-		},
-		{
-			Type:        discord.ApplicationCommandOptionTypeString,
-			Name:        "start_date",
-			Description: "Start Date (ex 2021-02-04)",
-			Required:    true,
-		},
-		{
-			Type:        discord.ApplicationCommandOptionTypeString,
-			Name:        "end_date",
-			Description: "End Date (ex 2021-02-05)",
-			Required:    true,
-		},
-	}, nil
+type When2meetOptions struct {
+	EventName string `option:"event_name,required" description:"A name for the event."`
+	StartDate string `option:"start_date,required" description:"Start Date (ex 2021-02-04)"`
+	EndDate   string `option:"end_date,required" description:"End Date (ex 2021-02-05)"`
 }
 
-func when2meetInteractionHandler(ctx context.Context, interaction discord.Interaction) discord.InteractionResponse {
-	var eventName, startDate, endDate string
-	for _, option := range interaction.Data.Options {
-		switch option.Name {
-		case "start_date":
-			startDate = option.Value
-		case "end_date":
-			endDate = option.Value
-		case "event_name":
-			eventName = option.Value
-		}
-	}
-	if eventName == "" || startDate == "" || endDate == "" {
+func when2meetInteractionHandler(_ context.Context, interaction discord.Interaction) discord.InteractionResponse {
+	var options When2meetOptions
+	if err := DecodeOptions(interaction, &options); err != nil {
 		return InteractionResponseOof
 	}
 
-	url, err := when2meet.CreateEvent(eventName, startDate, endDate)
-	if err != nil {
-		logger.MethodFailure(ctx, "when2meet.CreateEvent", err)
-		return InteractionResponseOof
-	}
-	return InteractionResponseMessage(
-		fmt.Sprintf("<@%s> created a [when2meet](%s).", interaction.Member.User.ID, url), true)
+	return Defer(interaction, true, func(ctx context.Context) (discord.InteractionApplicationCommandCallbackData, error) {
+		url, err := when2meet.CreateEvent(ctx, options.EventName, options.StartDate, options.EndDate)
+		switch {
+		case errors.Is(err, when2meet.ErrInvalidDate):
+			return discord.InteractionApplicationCommandCallbackData{}, UserError("dates must look like 2021-02-04 😅")
+		case err != nil:
+			return discord.InteractionApplicationCommandCallbackData{}, fmt.Errorf("when2meet.CreateEvent() failed: %w", err)
+		}
+		return discord.InteractionApplicationCommandCallbackData{
+			Content: fmt.Sprintf("<@%s> created a [when2meet](%s).", interaction.Member.User.ID, url),
+		}, nil
+	})
 }
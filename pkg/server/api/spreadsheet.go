@@ -7,6 +7,7 @@ import (
 	"github.com/virtual-vgo/vvgo/pkg/logger"
 	"github.com/virtual-vgo/vvgo/pkg/models"
 	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
 	"io"
 	"net/http"
 	"net/url"
@@ -19,7 +20,8 @@ func Spreadsheet(r *http.Request) models.ApiResponse {
 	case http.MethodGet:
 		return handleGetSpreadsheet(ctx, r.URL.Query())
 	case http.MethodPost:
-		return handlePostSpreadsheet(ctx, r.Body)
+		dryRun := r.URL.Query().Get("dryRun") == "true"
+		return handlePostSpreadsheet(ctx, r.Body, authorName(login.IdentityFromContext(ctx)), dryRun)
 	default:
 		return http_helpers.NewMethodNotAllowedError()
 	}
@@ -51,17 +53,49 @@ func handleGetSpreadsheet(ctx context.Context, params url.Values) models.ApiResp
 	}}
 }
 
-func handlePostSpreadsheet(ctx context.Context, body io.Reader) models.ApiResponse {
+// authorName identifies who made a change in version histories and audit records.
+func authorName(identity models.Identity) string {
+	if identity.DiscordID != "" {
+		return identity.Kind.String() + ":" + identity.DiscordID
+	}
+	return identity.Kind.String()
+}
+
+// handlePostSpreadsheet validates the sheets against their schemas before writing them.
+// Uploads with errors are rejected with the validation report in the error data.
+// A dry run returns the same response without writing anything.
+func handlePostSpreadsheet(ctx context.Context, body io.Reader, author string, dryRun bool) models.ApiResponse {
 	var data models.Spreadsheet
 	if err := json.NewDecoder(body).Decode(&data); err != nil {
 		return http_helpers.NewJsonDecodeError(err)
 	}
 
+	validations, err := models.ValidateSpreadsheet(ctx, data)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ValidateSpreadsheet", err)
+		return http_helpers.NewInternalServerError()
+	}
+	if validations.HasErrors() {
+		validationsJSON, err := json.Marshal(validations)
+		if err != nil {
+			logger.JsonEncodeFailure(ctx, err)
+			return http_helpers.NewInternalServerError()
+		}
+		return http_helpers.NewErrorResponse(models.ApiError{
+			Code:  http.StatusBadRequest,
+			Error: "spreadsheet failed validation",
+			Data:  validationsJSON,
+		})
+	}
+	if dryRun {
+		return models.ApiResponse{Status: models.StatusOk, SheetValidations: validations}
+	}
+
 	for _, sheet := range data.Sheets {
-		if err := redis.WriteSheet(ctx, data.SpreadsheetName, sheet.Name, sheet.Values); err != nil {
+		if err := redis.WriteSheet(ctx, data.SpreadsheetName, sheet.Name, author, sheet.Values); err != nil {
 			logger.RedisFailure(ctx, err)
 			return http_helpers.NewInternalServerError()
 		}
 	}
-	return http_helpers.NewOkResponse()
+	return models.ApiResponse{Status: models.StatusOk, SheetValidations: validations}
 }
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/cron/sheets_sync"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const DefaultSheetSyncLimit = 50
+
+func SpreadsheetSync(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		return handleGetSpreadsheetSync(ctx, r.URL.Query())
+	case http.MethodPost:
+		return handlePostSpreadsheetSync(ctx, r.Body)
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
+}
+
+func handleGetSpreadsheetSync(ctx context.Context, params url.Values) models.ApiResponse {
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	if limit <= 0 {
+		limit = DefaultSheetSyncLimit
+	}
+
+	syncs, err := models.ListSheetSyncs(ctx, limit)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.ListSheetSyncs", err)
+		return http_helpers.NewInternalServerError()
+	}
+	if syncs == nil {
+		syncs = []models.SheetSync{}
+	}
+	return models.ApiResponse{Status: models.StatusOk, SheetSyncs: syncs}
+}
+
+type PostSpreadsheetSyncRequest struct {
+	Direction       models.SheetSyncDirection
+	SpreadsheetName string
+	SheetNames      []string
+}
+
+func handlePostSpreadsheetSync(ctx context.Context, body io.Reader) models.ApiResponse {
+	var data PostSpreadsheetSyncRequest
+	if err := json.NewDecoder(body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+
+	switch {
+	case data.Direction != models.SheetSyncPull && data.Direction != models.SheetSyncPush:
+		return http_helpers.NewBadRequestError("direction must be pull or push")
+	case data.SpreadsheetName == "":
+		return http_helpers.NewBadRequestError("spreadsheetName is required")
+	case len(data.SheetNames) == 0:
+		return http_helpers.NewBadRequestError("sheetNames is required")
+	}
+
+	syncs := make([]models.SheetSync, 0, len(data.SheetNames))
+	for _, sheetName := range data.SheetNames {
+		syncs = append(syncs, sheets_sync.LockedSync(ctx, data.Direction, data.SpreadsheetName, sheetName))
+	}
+	return models.ApiResponse{Status: models.StatusOk, SheetSyncs: syncs}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHandlePostSpreadsheet(t *testing.T) {
+	ctx := context.Background()
+	spreadsheetName := "spreadsheet-test-" + models.SpreadsheetWebsiteData
+
+	upload := func(sheets ...models.Sheet) string {
+		body, err := json.Marshal(models.Spreadsheet{SpreadsheetName: models.SpreadsheetWebsiteData, Sheets: sheets})
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("other spreadsheets are not validated", func(t *testing.T) {
+		body := `{"spreadsheet_name":"` + spreadsheetName + `","sheets":[{"Name":"Cheese","Values":[["Holes"],["yes"]]}]}`
+		got := handlePostSpreadsheet(ctx, strings.NewReader(body), "test", false)
+		assert.Equal(t, models.StatusOk, got.Status)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		body := upload(models.Sheet{Name: models.SheetProjects, Values: [][]interface{}{{"Project Name", "Title"}, {"01-snake-eater", "Snake Eater"}}})
+		got := handlePostSpreadsheet(ctx, strings.NewReader(body), "test", false)
+		require.Equal(t, models.StatusError, got.Status)
+		assert.Equal(t, http.StatusBadRequest, got.Error.Code)
+
+		var report models.SheetValidations
+		require.NoError(t, json.Unmarshal(got.Error.Data, &report))
+		require.Len(t, report, 1)
+		assert.Len(t, report[0].Errors, 1)
+		assert.Len(t, report[0].Warnings, 1)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		values := [][]interface{}{{"Name", "Title"}, {"dry-run-test", "Dry Run"}}
+		body := upload(models.Sheet{Name: models.SheetProjects, Values: values})
+		got := handlePostSpreadsheet(ctx, strings.NewReader(body), "test", true)
+		assert.Equal(t, models.ApiResponse{
+			Status:           models.StatusOk,
+			SheetValidations: models.SheetValidations{{SheetName: models.SheetProjects}},
+		}, got)
+
+		stored, err := redis.ReadSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects)
+		require.NoError(t, err)
+		assert.NotEqual(t, values, stored)
+	})
+}
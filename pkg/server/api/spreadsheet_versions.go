@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"strconv"
+)
+
+// SpreadsheetVersions lists the saved versions of a sheet, or returns one version if the version param is set.
+func SpreadsheetVersions(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	params := r.URL.Query()
+	spreadsheetName, sheetName := params.Get("spreadsheetName"), params.Get("sheetName")
+	switch {
+	case spreadsheetName == "":
+		return http_helpers.NewBadRequestError("spreadsheetName is required")
+	case sheetName == "":
+		return http_helpers.NewBadRequestError("sheetName is required")
+	}
+
+	if params.Get("version") != "" {
+		version, err := strconv.ParseInt(params.Get("version"), 10, 64)
+		if err != nil {
+			return http_helpers.NewBadRequestError("version must be an integer")
+		}
+		return getSheetVersion(r, spreadsheetName, sheetName, version)
+	}
+
+	versions, err := redis.ListSheetVersions(ctx, spreadsheetName, sheetName)
+	if err != nil {
+		logger.MethodFailure(ctx, "redis.ListSheetVersions", err)
+		return http_helpers.NewInternalServerError()
+	}
+	if len(versions) == 0 {
+		versions = []redis.SheetVersion{}
+	}
+	return models.ApiResponse{Status: models.StatusOk, SheetVersions: versions}
+}
+
+func getSheetVersion(r *http.Request, spreadsheetName, sheetName string, version int64) models.ApiResponse {
+	ctx := r.Context()
+	sheetVersion, err := redis.GetSheetVersion(ctx, spreadsheetName, sheetName, version)
+	switch {
+	case errors.Is(err, redis.ErrSheetVersionNotFound):
+		return http_helpers.NewNotFoundError(fmt.Sprintf("version %d not found", version))
+	case err != nil:
+		logger.MethodFailure(ctx, "redis.GetSheetVersion", err)
+		return http_helpers.NewInternalServerError()
+	default:
+		return models.ApiResponse{Status: models.StatusOk, SheetVersion: &sheetVersion}
+	}
+}
+
+// SpreadsheetVersionsDiff compares two versions of a sheet row by row.
+func SpreadsheetVersionsDiff(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	params := r.URL.Query()
+	spreadsheetName, sheetName := params.Get("spreadsheetName"), params.Get("sheetName")
+	from, fromErr := strconv.ParseInt(params.Get("from"), 10, 64)
+	to, toErr := strconv.ParseInt(params.Get("to"), 10, 64)
+	switch {
+	case spreadsheetName == "":
+		return http_helpers.NewBadRequestError("spreadsheetName is required")
+	case sheetName == "":
+		return http_helpers.NewBadRequestError("sheetName is required")
+	case fromErr != nil:
+		return http_helpers.NewBadRequestError("from must be an integer")
+	case toErr != nil:
+		return http_helpers.NewBadRequestError("to must be an integer")
+	}
+
+	var values [2][][]interface{}
+	for i, version := range []int64{from, to} {
+		sheetVersion, err := redis.GetSheetVersion(ctx, spreadsheetName, sheetName, version)
+		switch {
+		case errors.Is(err, redis.ErrSheetVersionNotFound):
+			return http_helpers.NewNotFoundError(fmt.Sprintf("version %d not found", version))
+		case err != nil:
+			logger.MethodFailure(ctx, "redis.GetSheetVersion", err)
+			return http_helpers.NewInternalServerError()
+		}
+		values[i] = sheetVersion.Values
+	}
+
+	diff := models.DiffSheets(values[0], values[1])
+	if diff == nil {
+		diff = models.SheetDiff{}
+	}
+	return models.ApiResponse{Status: models.StatusOk, SheetDiff: diff}
+}
+
+type PostSpreadsheetRollbackRequest struct {
+	SpreadsheetName string
+	SheetName       string
+	Version         int64
+}
+
+// SpreadsheetRollback restores a previous version of a sheet.
+func SpreadsheetRollback(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data PostSpreadsheetRollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	switch {
+	case data.SpreadsheetName == "":
+		return http_helpers.NewBadRequestError("spreadsheetName is required")
+	case data.SheetName == "":
+		return http_helpers.NewBadRequestError("sheetName is required")
+	case data.Version <= 0:
+		return http_helpers.NewBadRequestError("version must be positive")
+	}
+
+	author := authorName(login.IdentityFromContext(ctx))
+	version, err := redis.RollbackSheet(ctx, data.SpreadsheetName, data.SheetName, author, data.Version)
+	switch {
+	case errors.Is(err, redis.ErrSheetVersionNotFound):
+		return http_helpers.NewNotFoundError(fmt.Sprintf("version %d not found", data.Version))
+	case err != nil:
+		logger.MethodFailure(ctx, "redis.RollbackSheet", err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	version.Values = nil
+	return models.ApiResponse{Status: models.StatusOk, SheetVersion: &version}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+)
+
+// SubmissionTracking counts a project's submissions by part and by section.
+func SubmissionTracking(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	projectName := r.FormValue("project")
+	if projectName == "" {
+		return http_helpers.NewBadRequestError("project is required")
+	}
+
+	projects, err := models.ListProjects(ctx, identity)
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+	project, ok := projects.Get(projectName)
+	if !ok {
+		return http_helpers.NewNotFoundError(fmt.Sprintf("project %s does not exist", projectName))
+	}
+
+	parts, err := models.ListParts(ctx, identity)
+	if err != nil {
+		logger.ListPartsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+	uploads, err := models.ListSubmissionUploads(ctx, project.Name)
+	if err != nil {
+		logger.ListSubmissionUploadsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	tracking := models.BuildSubmissionTracking(project, parts, uploads)
+	return models.ApiResponse{Status: models.StatusOk, SubmissionTracking: &tracking}
+}
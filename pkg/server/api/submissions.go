@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/minio/minio-go/v6"
+	minio_wrapper "github.com/virtual-vgo/vvgo/pkg/clients/minio"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const SubmissionUploadExpiry = 3600 * time.Second // 1 hour to start the upload
+
+var validChecksum = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+func Submissions(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	switch r.Method {
+	case http.MethodGet:
+		return handleGetSubmissions(r, ctx, identity)
+	case http.MethodPost:
+		return handlePostSubmissions(r, ctx, identity)
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
+}
+
+func handleGetSubmissions(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
+	projectName := r.URL.Query().Get("project")
+	if projectName == "" {
+		return http_helpers.NewBadRequestError("project is required")
+	}
+
+	uploads, err := models.ListSubmissionUploads(ctx, projectName)
+	if err != nil {
+		logger.ListSubmissionUploadsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	// Members can only see their own submissions.
+	if !identity.HasRole(models.RoleVVGOProductionTeam) {
+		uploads = uploads.ForDiscordID(identity.DiscordID)
+	}
+
+	if uploads == nil {
+		uploads = []models.SubmissionUpload{}
+	}
+	return models.ApiResponse{Status: models.StatusOk, Submissions: uploads}
+}
+
+type PostSubmissionsRequest struct {
+	Project  string `json:"project"`
+	Part     string `json:"part"`
+	FileName string `json:"fileName"`
+	FileSize int64  `json:"fileSize"`
+	Checksum string `json:"checksum"`
+}
+
+func (x PostSubmissionsRequest) validate() string {
+	switch {
+	case x.Project == "":
+		return "project is required"
+	case x.Part == "":
+		return "part is required"
+	case x.FileName == "":
+		return "fileName is required"
+	case x.FileSize <= 0:
+		return "fileSize must be positive"
+	case validChecksum.MatchString(x.Checksum) == false:
+		return "checksum must be a hex encoded sha256"
+	default:
+		return ""
+	}
+}
+
+func handlePostSubmissions(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
+	var data PostSubmissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if reason := data.validate(); reason != "" {
+		return http_helpers.NewBadRequestError(reason)
+	}
+	if identity.DiscordID == "" {
+		return http_helpers.NewBadRequestError("a discord login is required to submit")
+	}
+
+	projects, err := models.ListProjects(ctx, identity)
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+	project, ok := projects.Get(data.Project)
+	switch {
+	case !ok:
+		return http_helpers.NewNotFoundError(fmt.Sprintf("project `%s` not found", data.Project))
+	case !project.AcceptingSubmissions():
+		return http_helpers.NewBadRequestError(fmt.Sprintf("project `%s` is not accepting submissions", data.Project))
+	}
+
+	parts, err := models.ListParts(ctx, identity)
+	if err != nil {
+		logger.ListPartsFailure(ctx, err)
+		return http_helpers.NewInternalServerError()
+	}
+	var partFound bool
+	for _, part := range parts.ForProject(project.Name) {
+		if part.PartName == data.Part {
+			partFound = true
+			break
+		}
+	}
+	if !partFound {
+		return http_helpers.NewNotFoundError(fmt.Sprintf("part `%s` not found", data.Part))
+	}
+
+	upload := models.NewSubmissionUpload(project.Name, data.Part, identity.DiscordID, data.FileName, data.FileSize, data.Checksum)
+
+	minioClient, err := minio_wrapper.NewClient()
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.New", err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	uploadUrl, err := minioClient.PresignedPutObject(config.Config().VVGO.SubmissionsBucket, upload.ObjectKey, SubmissionUploadExpiry)
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.PresignedPutObject", err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	if err := models.SavePendingSubmissionUpload(ctx, upload); err != nil {
+		logger.MethodFailure(ctx, "models.SavePendingSubmissionUpload", err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	return models.ApiResponse{Status: models.StatusOk, Submission: &upload, UploadUrl: uploadUrl.String()}
+}
+
+type CompleteSubmissionRequest struct {
+	Project   string `json:"project"`
+	ObjectKey string `json:"objectKey"`
+}
+
+// CompleteSubmission accepts a submission once the upload is finished.
+// The uploaded object must match the size and checksum that were reported when the upload was started,
+// otherwise the object is removed and the member has to upload again.
+func CompleteSubmission(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	if r.Method != http.MethodPost {
+		return http_helpers.NewMethodNotAllowedError()
+	}
+
+	var data CompleteSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	switch {
+	case data.Project == "":
+		return http_helpers.NewBadRequestError("project is required")
+	case data.ObjectKey == "":
+		return http_helpers.NewBadRequestError("objectKey is required")
+	}
+
+	upload, err := models.GetPendingSubmissionUpload(ctx, data.Project, data.ObjectKey)
+	switch {
+	case errors.Is(err, models.ErrSubmissionUploadNotFound):
+		return http_helpers.NewNotFoundError(fmt.Sprintf("upload `%s` not found", data.ObjectKey))
+	case err != nil:
+		logger.MethodFailure(ctx, "models.GetPendingSubmissionUpload", err)
+		return http_helpers.NewInternalServerError()
+	case upload.DiscordID != identity.DiscordID:
+		return http_helpers.NewNotFoundError(fmt.Sprintf("upload `%s` not found", data.ObjectKey))
+	}
+
+	minioClient, err := minio_wrapper.NewClient()
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.New", err)
+		return http_helpers.NewInternalServerError()
+	}
+
+	bucket := config.Config().VVGO.SubmissionsBucket
+	object, err := minioClient.GetObject(bucket, upload.ObjectKey, minio.GetObjectOptions{})
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.GetObject", err)
+		return http_helpers.NewInternalServerError()
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		logger.MethodFailure(ctx, "minio.Object.Stat", err)
+		if resp, ok := err.(minio.ErrorResponse); ok && resp.StatusCode == http.StatusNotFound {
+			return http_helpers.NewBadRequestError("the upload is not finished")
+		}
+		return http_helpers.NewInternalServerError()
+	}
+
+	reason, err := upload.VerifyObject(info.Size, object)
+	if err != nil {
+		logger.MethodFailure(ctx, "models.SubmissionUpload.VerifyObject", err)
+		return http_helpers.NewInternalServerError()
+	}
+	if reason != "" {
+		if err := minioClient.RemoveObject(bucket, upload.ObjectKey); err != nil {
+			logger.MethodFailure(ctx, "minio.RemoveObject", err)
+		}
+		if err := models.DeletePendingSubmissionUpload(ctx, upload); err != nil {
+			logger.MethodFailure(ctx, "models.DeletePendingSubmissionUpload", err)
+		}
+		return http_helpers.NewBadRequestError(reason)
+	}
+
+	if err := models.AcceptSubmissionUpload(ctx, upload); err != nil {
+		logger.MethodFailure(ctx, "models.AcceptSubmissionUpload", err)
+		return http_helpers.NewInternalServerError()
+	}
+	return models.ApiResponse{Status: models.StatusOk, Submission: &upload}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers/test_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubmissions(t *testing.T) {
+	ctx := context.Background()
+	project := "submissions-test-" + login.NewSessionKey()
+	uploads := []models.SubmissionUpload{
+		{Project: project, PartName: "Trumpet 1", DiscordID: "1", ObjectKey: "a", CreatedAt: time.Unix(1, 0).UTC()},
+		{Project: project, PartName: "Trumpet 2", DiscordID: "2", ObjectKey: "b", CreatedAt: time.Unix(2, 0).UTC()},
+	}
+	for _, upload := range uploads {
+		require.NoError(t, models.SaveSubmissionUpload(ctx, upload))
+	}
+
+	newRequest := func(method string, target string, body string, identity models.Identity) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &identity))
+	}
+
+	member := models.Identity{Kind: models.KindDiscord, Roles: []models.Role{models.RoleVVGOVerifiedMember}, DiscordID: "2"}
+	teams := models.Identity{Kind: models.KindDiscord, Roles: []models.Role{models.RoleVVGOProductionTeam}, DiscordID: "3"}
+
+	t.Run("invalid method", func(t *testing.T) {
+		req := newRequest(http.MethodDelete, "/submissions", "", member)
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewMethodNotAllowedError(), Submissions(req))
+	})
+
+	t.Run("get/no project", func(t *testing.T) {
+		req := newRequest(http.MethodGet, "/submissions", "", member)
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("project is required"), Submissions(req))
+	})
+
+	t.Run("get/production team", func(t *testing.T) {
+		req := newRequest(http.MethodGet, "/submissions?project="+project, "", teams)
+		test_helpers.AssertEqualApiResponses(t, models.ApiResponse{Status: models.StatusOk, Submissions: uploads}, Submissions(req))
+	})
+
+	t.Run("get/member", func(t *testing.T) {
+		req := newRequest(http.MethodGet, "/submissions?project="+project, "", member)
+		test_helpers.AssertEqualApiResponses(t, models.ApiResponse{Status: models.StatusOk, Submissions: uploads[1:]}, Submissions(req))
+	})
+
+	t.Run("post/invalid checksum", func(t *testing.T) {
+		body := `{"project":"` + project + `","part":"Trumpet 1","fileName":"take1.wav","fileSize":1024,"checksum":"cheese"}`
+		req := newRequest(http.MethodPost, "/submissions", body, member)
+		test_helpers.AssertEqualApiResponses(t,
+			http_helpers.NewBadRequestError("checksum must be a hex encoded sha256"), Submissions(req))
+	})
+
+	t.Run("post/no discord id", func(t *testing.T) {
+		body := `{"project":"` + project + `","part":"Trumpet 1","fileName":"take1.wav","fileSize":1024,"checksum":"` + strings.Repeat("a", 64) + `"}`
+		req := newRequest(http.MethodPost, "/submissions", body, models.Identity{Kind: models.KindPassword, Roles: []models.Role{models.RoleVVGOVerifiedMember}})
+		test_helpers.AssertEqualApiResponses(t,
+			http_helpers.NewBadRequestError("a discord login is required to submit"), Submissions(req))
+	})
+}
+
+func TestCompleteSubmission(t *testing.T) {
+	ctx := context.Background()
+	project := "submissions-test-" + login.NewSessionKey()
+	upload := models.NewSubmissionUpload(project, "Trumpet 1", "1", "take1.wav", 1024, strings.Repeat("a", 64))
+	require.NoError(t, models.SavePendingSubmissionUpload(ctx, upload))
+
+	newRequest := func(body string, identity models.Identity) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/submissions/complete", strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &identity))
+	}
+	owner := models.Identity{Kind: models.KindDiscord, Roles: []models.Role{models.RoleVVGOVerifiedMember}, DiscordID: "1"}
+	other := models.Identity{Kind: models.KindDiscord, Roles: []models.Role{models.RoleVVGOVerifiedMember}, DiscordID: "2"}
+
+	t.Run("no object key", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("objectKey is required"),
+			CompleteSubmission(newRequest(`{"project":"`+project+`"}`, owner)))
+	})
+
+	t.Run("unknown upload", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("upload `cheese` not found"),
+			CompleteSubmission(newRequest(`{"project":"`+project+`","objectKey":"cheese"}`, owner)))
+	})
+
+	t.Run("someone else's upload", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("upload `"+upload.ObjectKey+"` not found"),
+			CompleteSubmission(newRequest(`{"project":"`+project+`","objectKey":"`+upload.ObjectKey+`"}`, other)))
+	})
+}
@@ -3,170 +3,217 @@ package voting
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
-	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
 	"github.com/virtual-vgo/vvgo/pkg/logger"
-	"github.com/virtual-vgo/vvgo/pkg/server/api/arrangements"
+	"github.com/virtual-vgo/vvgo/pkg/models"
 	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
 	"net/http"
 	"sort"
-	"strings"
+	"time"
 )
 
-func Results(w http.ResponseWriter, r *http.Request) {
+// Elections lists the elections the member can vote in, and creates or replaces an election on POST.
+// Executive directors see every election, and are the only ones who can change them.
+func Elections(r *http.Request) models.ApiResponse {
 	ctx := r.Context()
-	data := make(map[string]string)
-	if err := redis.Do(ctx, redis.Cmd(&data,
-		"HGETALL", "arrangements:"+arrangements.Season+":ballots")); err != nil {
-		logger.RedisFailure(ctx, err)
-		http_helpers.WriteInternalServerError(ctx, w)
-		return
-	}
-
-	ballots := make([][]string, 0, len(data))
-	for _, ballotJSON := range data {
-		var ballot []string
-		_ = json.Unmarshal([]byte(ballotJSON), &ballot)
-		ballots = append(ballots, ballot)
-	}
-	results := rankResultsForView(determineWinners(ballots))
-	page := struct {
-		Results []voteRanking
-		Ballots []namedBallot
-	}{
-		Results: results,
-		Ballots: nameBallots(ctx, data),
-	}
-	_ = page
+	identity := login.IdentityFromContext(ctx)
+	switch r.Method {
+	case http.MethodGet:
+		return handleGetElections(ctx, identity)
+	case http.MethodPost:
+		if !identity.HasRole(models.RoleVVGOExecutiveDirector) {
+			return http_helpers.NewUnauthorizedError()
+		}
+		return handlePostElections(r, ctx)
+	default:
+		return http_helpers.NewMethodNotAllowedError()
+	}
 }
 
-type namedBallot struct {
-	Nick  string
-	Votes []string
-}
+func handleGetElections(ctx context.Context, identity models.Identity) models.ApiResponse {
+	elections, err := voting.ListElections(ctx)
+	if err != nil {
+		logger.MethodFailure(ctx, "voting.ListElections", err)
+		return http_helpers.NewInternalServerError()
+	}
 
-func nameBallots(ctx context.Context, data map[string]string) []namedBallot {
-	ballots := make([]namedBallot, 0, len(data))
-	for userID, ballotJSON := range data {
-		var nick string
-		guildMember, err := discord.GetGuildMember(ctx, discord.Snowflake(userID))
-		if err != nil {
-			logger.MethodFailure(ctx, "discord.GetGuildMember", err)
-		} else {
-			nick = guildMember.Nick
+	want := make([]voting.Election, 0, len(elections))
+	for _, election := range elections {
+		if canVote(identity, election) || identity.HasRole(models.RoleVVGOExecutiveDirector) {
+			want = append(want, election)
 		}
-
-		var votes []string
-		_ = json.Unmarshal([]byte(ballotJSON), &votes)
-		ballots = append(ballots, namedBallot{Votes: votes, Nick: nick})
 	}
-	return ballots
+	return models.ApiResponse{Status: models.StatusOk, Elections: want}
 }
 
-func rankResults(results [][]string) map[int][]string {
-	rank := make(map[int][]string)
-	count := 1
-	for _, row := range results {
-		rank[count] = row
-		count += len(row)
+func handlePostElections(r *http.Request, ctx context.Context) models.ApiResponse {
+	var election voting.Election
+	if err := json.NewDecoder(r.Body).Decode(&election); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
 	}
-	return rank
-}
 
-type voteRanking struct {
-	Rank  int
-	Names string
+	err := voting.SaveElection(ctx, election)
+	switch {
+	case errors.Is(err, voting.ErrInvalidElection):
+		return http_helpers.NewBadRequestError(err.Error())
+	case err != nil:
+		logger.MethodFailure(ctx, "voting.SaveElection", err)
+		return http_helpers.NewInternalServerError()
+	default:
+		return models.ApiResponse{Status: models.StatusOk, Election: &election}
+	}
 }
 
-func rankResultsForView(results [][]string) []voteRanking {
-	ranking := make([]voteRanking, len(results))
-	count := 1
-	for i := range results {
-		ranking[i].Names = strings.Join(results[i], ", ")
-		ranking[i].Rank = count
-		count += len(results[i])
+// Ballot shows the member's ballot for an election, and casts it on POST.
+func Ballot(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	identity := login.IdentityFromContext(ctx)
+	switch r.Method {
+	case http.MethodGet:
+		return handleGetBallot(ctx, identity, r.URL.Query().Get("election"))
+	case http.MethodPost:
+		return handlePostBallot(r, ctx, identity)
+	default:
+		return http_helpers.NewMethodNotAllowedError()
 	}
-	return ranking
 }
 
-func determineWinners(ballots [][]string) [][]string {
-	if len(ballots) < 1 {
-		return [][]string{}
+func handleGetBallot(ctx context.Context, identity models.Identity, id string) models.ApiResponse {
+	election, errResp := voterElection(ctx, identity, id)
+	if errResp != nil {
+		return *errResp
 	}
 
-	results := make([][]string, 0, len(ballots[0]))
-	for len(ballots[0]) > 0 {
-		winner := pickNextWinner(ballots)
-		results = append(results, winner)
-		ballots = removeChoiceFromBallots(ballots, winner...)
+	ballot, err := voting.GetBallot(ctx, election, identity.DiscordID)
+	if err != nil {
+		logger.MethodFailure(ctx, "voting.GetBallot", err)
+		return http_helpers.NewInternalServerError()
 	}
-	return results
+	if len(ballot) == 0 {
+		ballot = append(voting.Ballot(nil), election.Choices...)
+		sort.Strings(ballot)
+	}
+	return models.ApiResponse{Status: models.StatusOk, Election: &election, Ballot: models.ArrangementsBallot(ballot)}
 }
 
-func pickNextWinner(ballots [][]string) []string {
-	// score first choices
-	firstChoice := make(map[string]int)
+type PostBallotRequest struct {
+	Election string
+	Ballot   voting.Ballot
+}
 
-	for _, ballot := range ballots {
-		firstChoice[ballot[0]] += 1
+func handlePostBallot(r *http.Request, ctx context.Context, identity models.Identity) models.ApiResponse {
+	var data PostBallotRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		return http_helpers.NewJsonDecodeError(err)
+	}
+	if identity.DiscordID == "" {
+		return http_helpers.NewBadRequestError("a discord login is required to vote")
+	}
+	election, errResp := voterElection(ctx, identity, data.Election)
+	if errResp != nil {
+		return *errResp
 	}
 
-	// find the max and min votes
-	maxVotes := 0
-	minVotes := len(ballots)
-	for _, votes := range firstChoice {
-		if votes > maxVotes {
-			maxVotes = votes
-		}
-		if votes < minVotes {
-			minVotes = votes
-		}
+	err := voting.CastBallot(ctx, election, identity.DiscordID, data.Ballot, time.Now())
+	switch {
+	case errors.Is(err, voting.ErrElectionNotOpen), errors.Is(err, voting.ErrInvalidBallot):
+		return http_helpers.NewBadRequestError(err.Error())
+	case err != nil:
+		logger.MethodFailure(ctx, "voting.CastBallot", err)
+		return http_helpers.NewInternalServerError()
+	default:
+		return http_helpers.NewOkResponse()
 	}
+}
 
-	// is max votes the majority?
-	if float64(maxVotes) > float64(len(ballots))*0.5 {
-		// find choice w/ max votes and return it
-		for choice, votes := range firstChoice {
-			if votes == maxVotes {
-				return []string{choice}
-			}
-		}
+// Results counts the ballots of an election, and lists the ballots with the nicks of the voters.
+func Results(r *http.Request) models.ApiResponse {
+	ctx := r.Context()
+	if r.Method != http.MethodGet {
+		return http_helpers.NewMethodNotAllowedError()
 	}
 
-	// remove losers from the ballot
-	var losers []string
-	for choice, votes := range firstChoice {
-		if votes == minVotes {
-			losers = append(losers, choice)
-		}
+	election, errResp := getElection(ctx, r.URL.Query().Get("election"))
+	if errResp != nil {
+		return *errResp
 	}
 
-	// dead tie
-	if minVotes == maxVotes {
-		sort.Strings(losers)
-		return losers
+	ballots, err := voting.ListBallots(ctx, election)
+	if err != nil {
+		logger.MethodFailure(ctx, "voting.ListBallots", err)
+		return http_helpers.NewInternalServerError()
+	}
+	result, err := voting.Results(ctx, election)
+	if err != nil {
+		logger.MethodFailure(ctx, "voting.Results", err)
+		return http_helpers.NewInternalServerError()
 	}
 
-	return pickNextWinner(removeChoiceFromBallots(ballots, losers...))
+	return models.ApiResponse{
+		Status:          models.StatusOk,
+		Election:        &election,
+		ElectionResult:  &result,
+		ElectionBallots: nameBallots(ctx, ballots),
+	}
 }
 
-func removeChoiceFromBallots(ballots [][]string, choices ...string) [][]string {
-	newBallots := make([][]string, len(ballots))
-	for i := range ballots {
-		var newChoices []string
-		for _, ballotChoice := range ballots[i] {
-			// should we keep this choice?
-			keep := true
-			for _, badChoice := range choices {
-				if ballotChoice == badChoice {
-					keep = false
-				}
-			}
-			if keep {
-				newChoices = append(newChoices, ballotChoice)
+// nameBallots looks up voters in the guild member directory.
+func nameBallots(ctx context.Context, ballots map[string]voting.Ballot) []models.ElectionBallot {
+	named := make([]models.ElectionBallot, 0, len(ballots))
+	for voterID, ballot := range ballots {
+		var nick string
+		member, err := models.GetGuildMember(ctx, discord.Snowflake(voterID))
+		if err != nil {
+			logger.MethodFailure(ctx, "models.GetGuildMember", err)
+		} else if member != nil {
+			nick = member.Nick
+			if nick == "" {
+				nick = member.User.Username
 			}
 		}
-		newBallots[i] = newChoices
+		named = append(named, models.ElectionBallot{VoterID: voterID, Nick: nick, Ballot: ballot})
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].VoterID < named[j].VoterID })
+	return named
+}
+
+func canVote(identity models.Identity, election voting.Election) bool {
+	return identity.HasRole(models.Role(election.VoterRole))
+}
+
+// voterElection returns the election if the member can vote in it.
+func voterElection(ctx context.Context, identity models.Identity, id string) (voting.Election, *models.ApiResponse) {
+	election, errResp := getElection(ctx, id)
+	if errResp != nil {
+		return voting.Election{}, errResp
+	}
+	if !canVote(identity, election) {
+		resp := http_helpers.NewNotFoundError(fmt.Sprintf("election `%s` not found", id))
+		return voting.Election{}, &resp
+	}
+	return election, nil
+}
+
+func getElection(ctx context.Context, id string) (voting.Election, *models.ApiResponse) {
+	if id == "" {
+		resp := http_helpers.NewBadRequestError("election is required")
+		return voting.Election{}, &resp
+	}
+
+	election, err := voting.GetElection(ctx, id)
+	switch {
+	case errors.Is(err, voting.ErrElectionNotFound):
+		resp := http_helpers.NewNotFoundError(fmt.Sprintf("election `%s` not found", id))
+		return voting.Election{}, &resp
+	case err != nil:
+		logger.MethodFailure(ctx, "voting.GetElection", err)
+		resp := http_helpers.NewInternalServerError()
+		return voting.Election{}, &resp
+	default:
+		return election, nil
 	}
-	return newBallots
 }
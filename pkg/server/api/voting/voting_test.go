@@ -1,56 +1,91 @@
 package voting
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/http_helpers/test_helpers"
+	"github.com/virtual-vgo/vvgo/pkg/server/login"
+	"github.com/virtual-vgo/vvgo/pkg/voting"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
-func Test_rankResults(t *testing.T) {
-	results := [][]string{{"Choice A", "Choice B"}, {"Choice C"}, {"Choice D"}, {"Choice E"}, {"Choice F"}}
-	assert.Equal(t, map[int][]string{
-		1: {"Choice A", "Choice B"},
-		3: {"Choice C"},
-		4: {"Choice D"},
-		5: {"Choice E"},
-		6: {"Choice F"},
-	}, rankResults(results))
-}
+func TestVoting(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	require.NoError(t, models.SaveGuildMembers(ctx, time.Now(), discord.GuildMember{User: discord.User{ID: "1", Username: "jackson"}, Nick: "Jackson"}))
+
+	director := models.Identity{Kind: models.KindDiscord, DiscordID: "1",
+		Roles: []models.Role{models.RoleVVGOVerifiedMember, models.RoleVVGOExecutiveDirector}}
+	member := models.Identity{Kind: models.KindDiscord, DiscordID: "2", Roles: []models.Role{models.RoleVVGOVerifiedMember}}
+	newRequest := func(method string, target string, body string, identity models.Identity) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		return req.WithContext(context.WithValue(req.Context(), login.CtxKeyVVGOIdentity, &identity))
+	}
+
+	election := voting.Election{
+		ID:        "season3-arrangements",
+		Title:     "Season 3 Arrangements",
+		Method:    voting.MethodInstantRunoff,
+		Choices:   []string{"Snake Eater", "Aurene"},
+		VoterRole: models.RoleVVGOExecutiveDirector.String(),
+	}
+	body := `{"ID":"season3-arrangements","Title":"Season 3 Arrangements","Method":"irv",` +
+		`"Choices":["Snake Eater","Aurene"],"VoterRole":"vvgo-leader"}`
+
+	t.Run("elections/post", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewUnauthorizedError(),
+			Elections(newRequest(http.MethodPost, "/elections", body, member)))
+		test_helpers.AssertEqualApiResponses(t, models.ApiResponse{Status: models.StatusOk, Election: &election},
+			Elections(newRequest(http.MethodPost, "/elections", body, director)))
+
+		resp := Elections(newRequest(http.MethodPost, "/elections", `{"ID":"Cheese!"}`, director))
+		require.NotNil(t, resp.Error)
+		assert.Equal(t, http.StatusBadRequest, resp.Error.Code)
+	})
 
-func Test_determineWinners(t *testing.T) {
-	t.Run("no ties", func(t *testing.T) {
-		ballots := [][]string{
-			{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
-			{"Choice D", "Choice B", "Choice A", "Choice C", "Choice E", "Choice F"},
-			{"Choice C", "Choice B", "Choice E", "Choice A", "Choice D", "Choice F"},
-			{"Choice C", "Choice B", "Choice D", "Choice E", "Choice A", "Choice F"},
-			{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
-		}
-		assert.Equal(t, [][]string{
-			{"Choice A"}, {"Choice B"}, {"Choice C"}, {"Choice D"}, {"Choice E"}, {"Choice F"},
-		}, determineWinners(ballots))
+	t.Run("elections/get", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, models.ApiResponse{Status: models.StatusOk, Elections: []voting.Election{election}},
+			Elections(newRequest(http.MethodGet, "/elections", "", director)))
+		test_helpers.AssertEqualApiResponses(t, models.ApiResponse{Status: models.StatusOk},
+			Elections(newRequest(http.MethodGet, "/elections", "", member)))
 	})
 
-	t.Run("tie", func(t *testing.T) {
-		ballots := [][]string{
-			{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
-			{"Choice C", "Choice B", "Choice E", "Choice A", "Choice D", "Choice F"},
-			{"Choice C", "Choice B", "Choice D", "Choice E", "Choice A", "Choice F"},
-			{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
-		}
-		assert.Equal(t, [][]string{
-			{"Choice A", "Choice C"}, {"Choice B"}, {"Choice D"}, {"Choice E"}, {"Choice F"},
-		}, determineWinners(ballots))
+	t.Run("ballot", func(t *testing.T) {
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewNotFoundError("election `season3-arrangements` not found"),
+			Ballot(newRequest(http.MethodGet, "/ballot?election=season3-arrangements", "", member)))
+
+		test_helpers.AssertEqualApiResponses(t, models.ApiResponse{Status: models.StatusOk, Election: &election,
+			Ballot: models.ArrangementsBallot{"Aurene", "Snake Eater"}},
+			Ballot(newRequest(http.MethodGet, "/ballot?election=season3-arrangements", "", director)))
+
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("invalid ballot: cheese is not a choice"),
+			Ballot(newRequest(http.MethodPost, "/ballot", `{"Election":"season3-arrangements","Ballot":["cheese"]}`, director)))
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewOkResponse(),
+			Ballot(newRequest(http.MethodPost, "/ballot", `{"Election":"season3-arrangements","Ballot":["Snake Eater"]}`, director)))
+
+		test_helpers.AssertEqualApiResponses(t, models.ApiResponse{Status: models.StatusOk, Election: &election,
+			Ballot: models.ArrangementsBallot{"Snake Eater"}},
+			Ballot(newRequest(http.MethodGet, "/ballot?election=season3-arrangements", "", director)))
 	})
 
-	t.Run("tie with lifting", func(t *testing.T) {
-		ballots := [][]string{
-			{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
-			{"Choice D", "Choice B", "Choice A", "Choice C", "Choice E", "Choice F"},
-			{"Choice C", "Choice B", "Choice D", "Choice E", "Choice A", "Choice F"},
-			{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
-		}
-		assert.Equal(t, [][]string{
-			{"Choice A", "Choice B"}, {"Choice C"}, {"Choice D"}, {"Choice E"}, {"Choice F"},
-		}, determineWinners(ballots))
+	t.Run("results", func(t *testing.T) {
+		resp := Results(newRequest(http.MethodGet, "/results?election=season3-arrangements", "", director))
+		require.NotNil(t, resp.ElectionResult, resp.Error)
+		assert.Equal(t, []voting.Rank{{Rank: 1, Choices: []string{"Snake Eater"}}, {Rank: 2, Choices: []string{"Aurene"}}},
+			resp.ElectionResult.Ranking)
+		assert.Equal(t, []models.ElectionBallot{{VoterID: "1", Nick: "Jackson", Ballot: voting.Ballot{"Snake Eater"}}},
+			resp.ElectionBallots)
+
+		test_helpers.AssertEqualApiResponses(t, http_helpers.NewBadRequestError("election is required"),
+			Results(newRequest(http.MethodGet, "/results", "", director)))
 	})
 }
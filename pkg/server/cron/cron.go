@@ -0,0 +1,385 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JobsRedisKey is the hash of job states by job name.
+const JobsRedisKey = "cron:jobs"
+
+// PausedRedisKey is the hash of paused job names.
+const PausedRedisKey = "cron:jobs:paused"
+
+// DefaultTimeout is the timeout of jobs that do not set one.
+const DefaultTimeout = 10 * time.Minute
+
+func lockKey(name string) string { return "cron:locks:" + name }
+
+var ErrUnknownJob = errors.New("unknown job")
+var ErrDuplicateJob = errors.New("job is already registered")
+var ErrJobRunning = errors.New("job is already running")
+
+// Job is a named background task.
+type Job struct {
+	Name        string
+	Description string
+	// Schedule is parsed with ParseSchedule.
+	Schedule string
+	// Timeout limits each run. It is also how long the lock is held if a replica dies during a run.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+func (x Job) timeout() time.Duration {
+	if x.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return x.Timeout
+}
+
+type registeredJob struct {
+	Job
+	schedule Schedule
+	// nextRun is when this replica next checks the job, or zero if it has not loaded the job state.
+	nextRun time.Time
+}
+
+var jobs = make(map[string]*registeredJob)
+var jobsLock sync.Mutex
+
+// Register adds a job to the scheduler.
+func Register(job Job) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return err
+	}
+
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	if _, ok := jobs[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+	}
+	jobs[job.Name] = &registeredJob{Job: job, schedule: schedule}
+	return nil
+}
+
+func getJob(name string) (*registeredJob, bool) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	job, ok := jobs[name]
+	return job, ok
+}
+
+// Run starts scheduled jobs until ctx is done.
+// Every replica can run the scheduler; a lock in redis makes sure each run happens on only one of them.
+func Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			startDueJobs(ctx, now)
+		}
+	}
+}
+
+// startDueJobs starts the jobs that are due and returns a WaitGroup for them.
+func startDueJobs(ctx context.Context, now time.Time) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for _, job := range dueJobs(ctx, now) {
+		wg.Add(1)
+		go func(job Job, schedule Schedule) {
+			defer wg.Done()
+			runScheduled(ctx, job, schedule, now)
+		}(job.Job, job.schedule)
+	}
+	return &wg
+}
+
+func dueJobs(ctx context.Context, now time.Time) []*registeredJob {
+	// Job states are read from redis without holding jobsLock, so a slow redis does not stall the rest of the scheduler.
+	loaded := make(map[string]time.Time)
+	for _, name := range unloadedJobs() {
+		state, err := getJobState(ctx, name)
+		if err != nil {
+			logger.MethodFailure(ctx, "cron.getJobState", err)
+			continue
+		}
+		loaded[name] = state.NextRun
+	}
+
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	var due []*registeredJob
+	for _, job := range jobs {
+		if job.nextRun.IsZero() {
+			// Jobs missed while the server was down run once at startup.
+			nextRun, ok := loaded[job.Name]
+			if !ok {
+				continue
+			}
+			if job.nextRun = nextRun; job.nextRun.IsZero() {
+				job.nextRun = job.schedule.Next(now)
+			}
+		}
+		if job.nextRun.IsZero() || job.nextRun.After(now) {
+			continue
+		}
+		job.nextRun = job.schedule.Next(now)
+		due = append(due, job)
+	}
+	return due
+}
+
+// unloadedJobs returns the names of jobs whose state has not been read from redis.
+func unloadedJobs() []string {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	var names []string
+	for name, job := range jobs {
+		if job.nextRun.IsZero() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func runScheduled(ctx context.Context, job Job, schedule Schedule, now time.Time) {
+	token, ok, err := acquireLock(ctx, job)
+	switch {
+	case err != nil:
+		logger.MethodFailure(ctx, "cron.acquireLock", err)
+		return
+	case !ok:
+		return // another replica is running the job
+	}
+
+	state, err := getJobState(ctx, job.Name)
+	if err != nil {
+		logger.MethodFailure(ctx, "cron.getJobState", err)
+		releaseLock(ctx, job, token)
+		return
+	}
+	if state.NextRun.After(now) {
+		// Another replica already ran the job.
+		setNextRun(job.Name, state.NextRun)
+		releaseLock(ctx, job, token)
+		return
+	}
+
+	nextRun := schedule.Next(now)
+	paused, err := isPaused(ctx, job.Name)
+	switch {
+	case err != nil:
+		logger.MethodFailure(ctx, "cron.isPaused", err)
+		releaseLock(ctx, job, token)
+	case paused:
+		state.NextRun = nextRun
+		if err := saveJobState(ctx, state); err != nil {
+			logger.MethodFailure(ctx, "cron.saveJobState", err)
+		}
+		releaseLock(ctx, job, token)
+	default:
+		execute(ctx, job, token, nextRun)
+	}
+}
+
+func setNextRun(name string, nextRun time.Time) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	if job, ok := jobs[name]; ok {
+		job.nextRun = nextRun
+	}
+}
+
+// Trigger starts a job now, outside its schedule.
+// The job keeps running after ctx is done.
+func Trigger(ctx context.Context, name string) error {
+	job, ok := getJob(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+
+	token, ok, err := acquireLock(ctx, job.Job)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return fmt.Errorf("%w: %s", ErrJobRunning, name)
+	}
+
+	state, err := getJobState(ctx, name)
+	if err != nil {
+		releaseLock(ctx, job.Job, token)
+		return err
+	}
+	go execute(context.Background(), job.Job, token, state.NextRun)
+	return nil
+}
+
+// execute runs the job, records the result, and releases the lock.
+func execute(ctx context.Context, job Job, token string, nextRun time.Time) {
+	defer releaseLock(ctx, job, token)
+
+	startedAt := time.Now()
+	err := runJob(ctx, job)
+	state := models.CronJob{
+		Name:         job.Name,
+		LastRun:      startedAt.UTC(),
+		LastDuration: time.Since(startedAt),
+		NextRun:      nextRun,
+	}
+
+	jobLogger := logger.WithField("job", job.Name).WithField("duration", state.LastDuration)
+	if err != nil {
+		state.LastError = err.Error()
+		jobLogger.WithError(err).Error("cron: job failed")
+	} else {
+		jobLogger.Info("cron: job completed")
+	}
+
+	if err := saveJobState(ctx, state); err != nil {
+		logger.MethodFailure(ctx, "cron.saveJobState", err)
+	}
+}
+
+func runJob(ctx context.Context, job Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, job.timeout())
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func acquireLock(ctx context.Context, job Job) (string, bool, error) {
+	hostname, _ := os.Hostname()
+	token := fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), time.Now().UnixNano())
+	ok, err := redis.SetNX(ctx, lockKey(job.Name), job.timeout(), token)
+	if err != nil {
+		return "", false, errors.RedisFailure(err)
+	}
+	return token, ok, nil
+}
+
+func releaseLock(ctx context.Context, job Job, token string) {
+	if _, err := redis.DelIfEqual(ctx, lockKey(job.Name), token); err != nil {
+		logger.RedisFailure(ctx, err)
+	}
+}
+
+func getJobState(ctx context.Context, name string) (models.CronJob, error) {
+	stateJSON, err := redis.HGet(ctx, JobsRedisKey, name)
+	if err != nil {
+		return models.CronJob{}, errors.RedisFailure(err)
+	}
+	state := models.CronJob{Name: name}
+	if stateJSON == "" {
+		return state, nil
+	}
+	if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+		return models.CronJob{}, errors.JsonDecodeFailure(err)
+	}
+	return state, nil
+}
+
+func saveJobState(ctx context.Context, state models.CronJob) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.HSet(ctx, JobsRedisKey, map[string]string{state.Name: string(stateJSON)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+func isPaused(ctx context.Context, name string) (bool, error) {
+	paused, err := redis.HGet(ctx, PausedRedisKey, name)
+	if err != nil {
+		return false, errors.RedisFailure(err)
+	}
+	return paused != "", nil
+}
+
+// Pause stops or resumes the scheduled runs of a job.
+// Paused jobs can still be triggered.
+func Pause(ctx context.Context, name string, paused bool) error {
+	if _, ok := getJob(name); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+
+	var err error
+	if paused {
+		err = redis.HSet(ctx, PausedRedisKey, map[string]string{name: time.Now().UTC().Format(time.RFC3339)})
+	} else {
+		err = redis.HDel(ctx, PausedRedisKey, name)
+	}
+	if err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// ListJobs returns the status of the registered jobs, sorted by name.
+func ListJobs(ctx context.Context) ([]models.CronJob, error) {
+	jobsLock.Lock()
+	registered := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		registered = append(registered, job.Job)
+	}
+	jobsLock.Unlock()
+	sort.Slice(registered, func(i, j int) bool { return registered[i].Name < registered[j].Name })
+	if len(registered) == 0 {
+		return []models.CronJob{}, nil
+	}
+
+	statesMap, err := redis.HGetAll(ctx, JobsRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+	pausedMap, err := redis.HGetAll(ctx, PausedRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+	lockKeys := make([]string, len(registered))
+	for i, job := range registered {
+		lockKeys[i] = lockKey(job.Name)
+	}
+	locks, err := redis.MGet(ctx, lockKeys...)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	want := make([]models.CronJob, len(registered))
+	for i, job := range registered {
+		var state models.CronJob
+		if stateJSON := statesMap[job.Name]; stateJSON != "" {
+			if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+				return nil, errors.JsonDecodeFailure(err)
+			}
+		}
+		state.Name = job.Name
+		state.Description = job.Description
+		state.Schedule = job.Schedule
+		state.Paused = pausedMap[job.Name] != ""
+		state.Running = i < len(locks) && locks[i] != ""
+		want[i] = state
+	}
+	return want, nil
+}
@@ -0,0 +1,116 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func resetJobs() {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+	jobs = make(map[string]*registeredJob)
+}
+
+func TestScheduler(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	resetJobs()
+
+	var runs int32
+	require.NoError(t, Register(Job{
+		Name:     "cheese",
+		Schedule: "0 * * * *",
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return errors.New("out of cheese")
+		},
+	}))
+	assert.True(t, errors.Is(Register(Job{Name: "cheese"}), ErrDuplicateJob), "errors.Is(err, ErrDuplicateJob)")
+
+	start := time.Date(2021, 6, 2, 10, 30, 0, 0, time.UTC)
+	startDueJobs(ctx, start).Wait()
+	assert.Equal(t, int32(0), runs, "before the schedule")
+
+	startDueJobs(ctx, start.Add(30*time.Minute)).Wait()
+	assert.Equal(t, int32(1), runs, "on schedule")
+
+	list, err := ListJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "cheese", list[0].Name)
+	assert.Equal(t, "out of cheese", list[0].LastError)
+	assert.Equal(t, time.Date(2021, 6, 2, 12, 0, 0, 0, time.UTC), list[0].NextRun)
+	assert.False(t, list[0].Running)
+
+	t.Run("other replica ran the job", func(t *testing.T) {
+		setNextRun("cheese", start.Add(30*time.Minute))
+		startDueJobs(ctx, start.Add(31*time.Minute)).Wait()
+		assert.Equal(t, int32(1), runs)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		require.NoError(t, redis.Set(ctx, lockKey("cheese"), "other-replica"))
+		startDueJobs(ctx, start.Add(90*time.Minute)).Wait()
+		assert.Equal(t, int32(1), runs)
+		assert.True(t, errors.Is(Trigger(ctx, "cheese"), ErrJobRunning), "errors.Is(err, ErrJobRunning)")
+		require.NoError(t, redis.Del(ctx, lockKey("cheese")))
+	})
+
+	t.Run("paused", func(t *testing.T) {
+		require.NoError(t, Pause(ctx, "cheese", true))
+		startDueJobs(ctx, start.Add(150*time.Minute)).Wait()
+		assert.Equal(t, int32(1), runs)
+
+		list, err := ListJobs(ctx)
+		require.NoError(t, err)
+		assert.True(t, list[0].Paused)
+		assert.Equal(t, time.Date(2021, 6, 2, 14, 0, 0, 0, time.UTC), list[0].NextRun)
+		require.NoError(t, Pause(ctx, "cheese", false))
+	})
+
+	t.Run("trigger", func(t *testing.T) {
+		require.NoError(t, Trigger(ctx, "cheese"))
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 2 }, time.Second, time.Millisecond)
+		assert.True(t, errors.Is(Trigger(ctx, "moon"), ErrUnknownJob), "errors.Is(err, ErrUnknownJob)")
+	})
+}
+
+// blockingStore holds HGet calls until release is closed.
+type blockingStore struct {
+	redis.Store
+	started chan struct{}
+	release chan struct{}
+}
+
+func (x blockingStore) HGet(ctx context.Context, key string, field string) (string, error) {
+	x.started <- struct{}{}
+	<-x.release
+	return x.Store.HGet(ctx, key, field)
+}
+
+func TestDueJobs_SlowRedis(t *testing.T) {
+	ctx := context.Background()
+	store := blockingStore{Store: redis.NewMemoryStore(), started: make(chan struct{}), release: make(chan struct{})}
+	redis.UseStore(store)
+	defer redis.UseStore(redis.NewMemoryStore())
+	resetJobs()
+	require.NoError(t, Register(Job{Name: "cheese", Schedule: "0 * * * *", Run: func(ctx context.Context) error { return nil }}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dueJobs(ctx, time.Date(2021, 6, 2, 10, 30, 0, 0, time.UTC))
+	}()
+	<-store.started
+
+	_, ok := getJob("cheese")
+	assert.True(t, ok, "the jobs can be read while the job state is loaded")
+	close(store.release)
+	<-done
+}
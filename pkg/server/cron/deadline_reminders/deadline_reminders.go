@@ -0,0 +1,168 @@
+package deadline_reminders
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedisKey is the hash of reminders that were posted.
+// Fields are `channel:project:deadline:reminder`, so changing a deadline schedules new reminders.
+// Fields are removed once their deadline has passed.
+const RedisKey = "cron:deadline_reminders"
+
+// ChannelIDs returns the channels that get reminders.
+func ChannelIDs() []string {
+	if config.Config().Development {
+		return []string{config.Config().Discord.SandboxChannelID}
+	}
+	return config.Config().Deadlines.ReminderChannelIDs
+}
+
+// SendReminders posts reminders for projects with a deadline coming up.
+// Only the closest reminder that is due is posted, so reminders missed while the server was down are not all posted at once.
+func SendReminders(ctx context.Context, now time.Time) {
+	pruneReminders(ctx, now)
+	channelIDs := ChannelIDs()
+	if len(channelIDs) == 0 {
+		return
+	}
+
+	projects, err := models.ListProjects(ctx, models.Anonymous())
+	if err != nil {
+		logger.ListProjectsFailure(ctx, err)
+		return
+	}
+
+	for _, project := range projects.Current() {
+		if !project.AcceptingSubmissions() {
+			continue
+		}
+		deadline, ok := project.Deadline()
+		if !ok {
+			continue
+		}
+		reminder, ok := DueReminder(deadline, config.Config().Deadlines.Reminders, now)
+		if !ok {
+			continue
+		}
+		for _, channelID := range channelIDs {
+			sendReminder(ctx, channelID, project, deadline, reminder, now)
+		}
+	}
+}
+
+// pruneReminders removes the reminders of deadlines that have passed, since no more reminders are due for them.
+func pruneReminders(ctx context.Context, now time.Time) {
+	sent, err := redis.HGetAll(ctx, RedisKey)
+	if err != nil {
+		logger.RedisFailure(ctx, err)
+		return
+	}
+	var passed []string
+	for field := range sent {
+		fields := strings.Split(field, ":")
+		if len(fields) != 4 {
+			continue
+		}
+		deadline, err := strconv.ParseInt(fields[2], 10, 64)
+		if err == nil && !now.Before(time.Unix(deadline, 0)) {
+			passed = append(passed, field)
+		}
+	}
+	if len(passed) == 0 {
+		return
+	}
+	if err := redis.HDel(ctx, RedisKey, passed...); err != nil {
+		logger.RedisFailure(ctx, err)
+	}
+}
+
+// DueReminder returns the smallest reminder that has passed before the deadline.
+func DueReminder(deadline time.Time, reminders []time.Duration, now time.Time) (time.Duration, bool) {
+	if !now.Before(deadline) {
+		return 0, false
+	}
+	sorted := make([]time.Duration, len(reminders))
+	copy(sorted, reminders)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, reminder := range sorted {
+		if !now.Before(deadline.Add(-reminder)) {
+			return reminder, true
+		}
+	}
+	return 0, false
+}
+
+func sendReminder(ctx context.Context, channelID string, project models.Project, deadline time.Time, reminder time.Duration, now time.Time) {
+	field := fmt.Sprintf("%s:%s:%d:%s", channelID, project.Name, deadline.Unix(), reminder)
+	sentAt, err := redis.HGet(ctx, RedisKey, field)
+	if err != nil {
+		logger.RedisFailure(ctx, err)
+		return
+	}
+	if sentAt != "" {
+		return
+	}
+
+	_, err = discord.CreateMessage(ctx, discord.Snowflake(channelID), discord.CreateMessageParams{
+		Embed: makeEmbed(project, deadline, now),
+	})
+	if err != nil {
+		logger.HttpDoFailure(ctx, err)
+		return
+	}
+
+	if err := redis.HSet(ctx, RedisKey, map[string]string{field: time.Now().UTC().Format(time.RFC3339)}); err != nil {
+		logger.RedisFailure(ctx, err)
+	}
+}
+
+// makeEmbed gives the time left until the deadline, which is less than the reminder if the reminder was posted late.
+func makeEmbed(project models.Project, deadline time.Time, now time.Time) *discord.Embed {
+	return &discord.Embed{
+		Title: fmt.Sprintf("⏰ %s submissions are due in %s!", project.Title, formatTimeLeft(deadline.Sub(now))),
+		Type:  discord.EmbedTypeRich,
+		Description: fmt.Sprintf("· Parts are [here!](%s%s)\n· Submit files [here!](%s)\n· Submission Deadline: %s (%s).",
+			config.Config().VVGO.ServerUrl, project.PartsPage(), project.SubmissionLink,
+			discord.FormatTimestamp(deadline, discord.TimestampStyleLongDateTime),
+			discord.FormatTimestamp(deadline, discord.TimestampStyleRelative)),
+		Url:   config.Config().VVGO.ServerUrl + project.PartsPage(),
+		Color: 0x8C17D9,
+	}
+}
+
+// formatTimeLeft rounds the time down to the two largest units, like `1 day and 6 hours`.
+func formatTimeLeft(left time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	pair := func(n int64, unit string, m int64, smallUnit string) string {
+		if m == 0 {
+			return plural(n, unit)
+		}
+		return plural(n, unit) + " and " + plural(m, smallUnit)
+	}
+
+	switch {
+	case left >= 24*time.Hour:
+		return pair(int64(left/(24*time.Hour)), "day", int64(left%(24*time.Hour)/time.Hour), "hour")
+	case left >= time.Hour:
+		return pair(int64(left/time.Hour), "hour", int64(left%time.Hour/time.Minute), "minute")
+	case left >= time.Minute:
+		return plural(int64(left/time.Minute), "minute")
+	default:
+		return "less than a minute"
+	}
+}
@@ -0,0 +1,121 @@
+package deadline_reminders
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDueReminder(t *testing.T) {
+	deadline := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	reminders := []time.Duration{time.Hour, 168 * time.Hour, 24 * time.Hour}
+
+	for _, tt := range []struct {
+		name   string
+		now    time.Time
+		want   time.Duration
+		wantOk bool
+	}{
+		{name: "too early", now: deadline.Add(-200 * time.Hour)},
+		{name: "one week", now: deadline.Add(-100 * time.Hour), want: 168 * time.Hour, wantOk: true},
+		{name: "one day", now: deadline.Add(-24 * time.Hour), want: 24 * time.Hour, wantOk: true},
+		{name: "one hour", now: deadline.Add(-time.Minute), want: time.Hour, wantOk: true},
+		{name: "past deadline", now: deadline},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DueReminder(deadline, reminders, tt.now)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSendReminders(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+
+	var messages []discord.CreateMessageParams
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/channels/channel-id/messages", r.URL.Path)
+		var params discord.CreateMessageParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		messages = append(messages, params)
+		json.NewEncoder(w).Encode(discord.Message{Id: "message-id"})
+	}))
+	defer ts.Close()
+	config.Update(func(x *config.Configuration) {
+		x.Discord.Endpoint = ts.URL
+		x.Development = false
+		x.Deadlines.ReminderChannelIDs = []string{"channel-id"}
+		x.Deadlines.Reminders = []time.Duration{168 * time.Hour, 24 * time.Hour, time.Hour}
+	})
+
+	writeDeadline := func(deadline string) {
+		require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+			{"Name", "Title", "Parts Released", "Submission Deadline"},
+			{"10-hildas-healing", "Hilda's Healing", true, deadline},
+			{"11-the-end", "The End", false, deadline},
+		}))
+	}
+
+	deadline := time.Date(2021, 6, 1, 23, 59, 0, 0, time.UTC)
+	writeDeadline("2021-06-01 23:59 UTC")
+
+	SendReminders(ctx, deadline.Add(-200*time.Hour))
+	assert.Empty(t, messages, "before the first reminder")
+
+	SendReminders(ctx, deadline.Add(-30*time.Hour))
+	SendReminders(ctx, deadline.Add(-29*time.Hour))
+	require.Len(t, messages, 1, "one week reminder")
+	assert.Equal(t, "⏰ Hilda's Healing submissions are due in 1 day and 6 hours!", messages[0].Embed.Title,
+		"the one week reminder was posted late")
+	assert.Contains(t, messages[0].Embed.Description, "<t:1622591940:R>")
+
+	SendReminders(ctx, deadline.Add(-30*time.Minute))
+	require.Len(t, messages, 2, "one hour reminder")
+	assert.Equal(t, "⏰ Hilda's Healing submissions are due in 30 minutes!", messages[1].Embed.Title)
+
+	writeDeadline("2021-06-07 23:59 UTC")
+	SendReminders(ctx, deadline.Add(-30*time.Minute))
+	require.Len(t, messages, 3, "extended deadline")
+	assert.Equal(t, "⏰ Hilda's Healing submissions are due in 6 days!", messages[2].Embed.Title)
+
+	SendReminders(ctx, deadline)
+	sent, err := redis.HGetAll(ctx, RedisKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"channel-id:10-hildas-healing:1623110340:168h0m0s"}, keys(sent),
+		"reminders of the old deadline are removed once it passes")
+}
+
+func keys(m map[string]string) []string {
+	var want []string
+	for key := range m {
+		want = append(want, key)
+	}
+	return want
+}
+
+func TestFormatTimeLeft(t *testing.T) {
+	for _, tt := range []struct {
+		left time.Duration
+		want string
+	}{
+		{168 * time.Hour, "7 days"},
+		{25*time.Hour + 30*time.Minute, "1 day and 1 hour"},
+		{time.Hour, "1 hour"},
+		{2*time.Hour + 5*time.Minute, "2 hours and 5 minutes"},
+		{59*time.Minute + 59*time.Second, "59 minutes"},
+		{30 * time.Second, "less than a minute"},
+	} {
+		assert.Equal(t, tt.want, formatTimeLeft(tt.left), tt.left.String())
+	}
+}
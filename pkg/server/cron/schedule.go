@@ -0,0 +1,213 @@
+package cron
+
+import (
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule returns the next time a job runs.
+type Schedule interface {
+	// Next returns the first run after t, or the zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// scheduleDescriptors are shorthands for common cron expressions.
+var scheduleDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule reads a schedule.
+// A schedule is a five field cron expression (minute hour day-of-month month day-of-week) evaluated in UTC,
+// one of the descriptors like @daily, or `@every <duration>`.
+// The empty schedule never runs; those jobs only run when they are triggered.
+func ParseSchedule(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "":
+		return neverSchedule{}, nil
+	case strings.HasPrefix(expr, "@every "):
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("%w `%s`: %v", ErrInvalidSchedule, expr, err)
+		}
+		if interval < time.Second {
+			return nil, fmt.Errorf("%w `%s`: interval must be at least one second", ErrInvalidSchedule, expr)
+		}
+		return everySchedule{interval: interval}, nil
+	case strings.HasPrefix(expr, "@"):
+		cronExpr, ok := scheduleDescriptors[expr]
+		if !ok {
+			return nil, fmt.Errorf("%w `%s`: unknown descriptor", ErrInvalidSchedule, expr)
+		}
+		expr = cronExpr
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w `%s`: want 5 fields, got %d", ErrInvalidSchedule, expr, len(fields))
+	}
+
+	var schedule cronSchedule
+	var err error
+	for _, parse := range []struct {
+		dest *cronField
+		text string
+		spec fieldSpec
+	}{
+		{&schedule.minute, fields[0], minuteSpec},
+		{&schedule.hour, fields[1], hourSpec},
+		{&schedule.dayOfMonth, fields[2], dayOfMonthSpec},
+		{&schedule.month, fields[3], monthSpec},
+		{&schedule.dayOfWeek, fields[4], dayOfWeekSpec},
+	} {
+		if *parse.dest, err = parse.spec.parse(parse.text); err != nil {
+			return nil, fmt.Errorf("%w `%s`: %v", ErrInvalidSchedule, expr, err)
+		}
+	}
+	return schedule, nil
+}
+
+type neverSchedule struct{}
+
+func (neverSchedule) Next(time.Time) time.Time { return time.Time{} }
+
+type everySchedule struct{ interval time.Duration }
+
+func (x everySchedule) Next(t time.Time) time.Time { return t.Truncate(time.Second).Add(x.interval) }
+
+// cronField is a set of allowed values as a bitmask.
+type cronField struct {
+	bits uint64
+	// star is true if the field was `*`, which matters for the day fields.
+	star bool
+}
+
+func (x cronField) has(value int) bool { return x.bits&(1<<uint(value)) != 0 }
+
+type fieldSpec struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteSpec     = fieldSpec{name: "minute", min: 0, max: 59}
+	hourSpec       = fieldSpec{name: "hour", min: 0, max: 23}
+	dayOfMonthSpec = fieldSpec{name: "day of month", min: 1, max: 31}
+	monthSpec      = fieldSpec{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is 0 or 7.
+	dayOfWeekSpec = fieldSpec{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// parse reads a comma separated list of `*`, values, and ranges, each with an optional /step.
+func (x fieldSpec) parse(text string) (cronField, error) {
+	var field cronField
+	for _, part := range strings.Split(text, ",") {
+		rangeText, step := part, 1
+		if i := strings.Index(part, "/"); i != -1 {
+			var err error
+			rangeText = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return cronField{}, fmt.Errorf("invalid %s step `%s`", x.name, part[i+1:])
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangeText == "*":
+			low, high = x.min, x.max
+			field.star = field.star || step == 1
+		case strings.Contains(rangeText, "-"):
+			bounds := strings.SplitN(rangeText, "-", 2)
+			var err error
+			if low, err = x.value(bounds[0]); err != nil {
+				return cronField{}, err
+			}
+			if high, err = x.value(bounds[1]); err != nil {
+				return cronField{}, err
+			}
+			if low > high {
+				return cronField{}, fmt.Errorf("invalid %s range `%s`", x.name, rangeText)
+			}
+		default:
+			var err error
+			if low, err = x.value(rangeText); err != nil {
+				return cronField{}, err
+			}
+			high = low
+			if step != 1 {
+				high = x.max
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			field.bits |= 1 << uint(value)
+		}
+	}
+
+	if x.name == dayOfWeekSpec.name && field.has(7) {
+		field.bits = field.bits&^(1<<7) | 1
+	}
+	return field, nil
+}
+
+func (x fieldSpec) value(text string) (int, error) {
+	if value, ok := x.names[strings.ToLower(text)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil || value < x.min || value > x.max {
+		return 0, fmt.Errorf("invalid %s `%s`", x.name, text)
+	}
+	return value, nil
+}
+
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek cronField
+}
+
+// Next returns the first matching minute after t.
+func (x cronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	// Schedules like `0 0 30 2 *` never match, so give up after a few years.
+	for limit := t.Year() + 5; t.Year() <= limit; {
+		switch {
+		case !x.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !x.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !x.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case !x.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: if both day fields are restricted, either one can match.
+func (x cronSchedule) dayMatches(t time.Time) bool {
+	dayOfMonth, dayOfWeek := x.dayOfMonth.has(t.Day()), x.dayOfWeek.has(int(t.Weekday()))
+	if x.dayOfMonth.star || x.dayOfWeek.star {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
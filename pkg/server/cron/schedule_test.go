@@ -0,0 +1,46 @@
+package cron
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Wednesday
+	now := time.Date(2021, 6, 2, 10, 30, 15, 0, time.UTC)
+
+	for _, tt := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"@every 30s", time.Date(2021, 6, 2, 10, 30, 45, 0, time.UTC)},
+		{"@hourly", time.Date(2021, 6, 2, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2021, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2021, 6, 6, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2021, 6, 2, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, 6, 2, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2021, 6, 2, 13, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2021, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2021, 6, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * sat", time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		schedule, err := ParseSchedule(tt.expr)
+		if assert.NoError(t, err, tt.expr) {
+			assert.Equal(t, tt.want, schedule.Next(now), tt.expr)
+		}
+	}
+
+	for _, expr := range []string{"@sometimes", "@every 1ms", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "* * * cheese *"} {
+		_, err := ParseSchedule(expr)
+		require.Error(t, err, expr)
+		assert.True(t, errors.Is(err, ErrInvalidSchedule), expr)
+	}
+}
@@ -0,0 +1,223 @@
+package sheets_sync
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/clients/sheets"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"strings"
+	"time"
+)
+
+// SyncAuthor is the author of sheet versions written by a pull.
+const SyncAuthor = "sheets_sync"
+
+var ErrUnknownSpreadsheet = errors.New("spreadsheet has no google spreadsheet id")
+var ErrInvalidSheet = errors.New("sheet does not match its schema")
+
+// SyncSheet is a sheet copied between google and redis.
+type SyncSheet struct {
+	SpreadsheetName string
+	SheetName       string
+}
+
+// ConfiguredSheets returns the sheets listed in the config.
+func ConfiguredSheets() []SyncSheet {
+	var want []SyncSheet
+	for _, entry := range config.Config().Sheets.SyncSheets {
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 || fields[0] == "" || fields[1] == "" {
+			logger.WithField("entry", entry).Warn("sheets sync: invalid sheet entry")
+			continue
+		}
+		want = append(want, SyncSheet{SpreadsheetName: fields[0], SheetName: fields[1]})
+	}
+	return want
+}
+
+func spreadsheetId(spreadsheetName string) (string, error) {
+	id := config.Config().Sheets.SpreadsheetIDs[spreadsheetName]
+	if id == "" {
+		return "", fmt.Errorf("%s: %w", spreadsheetName, ErrUnknownSpreadsheet)
+	}
+	return id, nil
+}
+
+// sheetLockKey serializes syncs of a sheet with changes that pull the sheet, write it, and push it.
+func sheetLockKey(spreadsheetName, sheetName string) string {
+	return "sheets_sync:lock:" + spreadsheetName + ":" + sheetName
+}
+
+// WithSheetLock runs f while holding the sheet's sync lock.
+// Changes that pull a sheet, write it in redis, and push it hold the lock throughout,
+// so that a scheduled pull in between does not undo the change.
+func WithSheetLock(ctx context.Context, spreadsheetName, sheetName string, f func() error) error {
+	return redis.WithLock(ctx, sheetLockKey(spreadsheetName, sheetName), f)
+}
+
+// PullAll pulls every configured sheet, holding each sheet's sync lock.
+func PullAll(ctx context.Context) []models.SheetSync {
+	var syncs []models.SheetSync
+	for _, sheet := range ConfiguredSheets() {
+		syncs = append(syncs, LockedSync(ctx, models.SheetSyncPull, sheet.SpreadsheetName, sheet.SheetName))
+	}
+	return syncs
+}
+
+// LockedSync pulls or pushes a sheet while holding the sheet's sync lock.
+// A sync that cannot take the lock is reported as failed.
+func LockedSync(ctx context.Context, direction models.SheetSyncDirection, spreadsheetName, sheetName string) models.SheetSync {
+	sync := Pull
+	if direction == models.SheetSyncPush {
+		sync = Push
+	}
+	var result models.SheetSync
+	err := WithSheetLock(ctx, spreadsheetName, sheetName, func() error {
+		result, _ = sync(ctx, spreadsheetName, sheetName)
+		return nil
+	})
+	if err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"direction":   direction,
+			"spreadsheet": spreadsheetName,
+			"sheet":       sheetName,
+		}).Error("sheets sync: sync failed")
+		return models.SheetSync{Direction: direction, SpreadsheetName: spreadsheetName, SheetName: sheetName,
+			StartedAt: time.Now(), Error: err.Error()}
+	}
+	return result
+}
+
+// Pull copies a sheet from google into redis.
+// Sheets in website_data are checked against their schemas first, and a sheet with errors is not copied.
+// Syncs that change the sheet or fail are saved to the sync history.
+// Callers hold the sheet's sync lock, see WithSheetLock and LockedSync.
+func Pull(ctx context.Context, spreadsheetName, sheetName string) (models.SheetSync, error) {
+	return doSync(ctx, models.SheetSyncPull, spreadsheetName, sheetName, func(id string) (models.SheetDiff, error) {
+		values, err := sheets.ReadSheet(ctx, id, sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("sheets.ReadSheet() failed: %w", err)
+		}
+		current, err := redis.ReadSheet(ctx, spreadsheetName, sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("redis.ReadSheet() failed: %w", err)
+		}
+
+		diff := models.DiffSheets(current, values)
+		if len(diff) == 0 {
+			return nil, nil
+		}
+		if err := validateSheet(ctx, spreadsheetName, sheetName, values); err != nil {
+			return nil, err
+		}
+		if err := redis.WriteSheet(ctx, spreadsheetName, sheetName, SyncAuthor, values); err != nil {
+			return nil, fmt.Errorf("redis.WriteSheet() failed: %w", err)
+		}
+		return diff, nil
+	})
+}
+
+// Push copies a sheet from redis to google, replacing the edits made in google.
+// Syncs that change the sheet or fail are saved to the sync history.
+// Callers hold the sheet's sync lock, see WithSheetLock and LockedSync.
+func Push(ctx context.Context, spreadsheetName, sheetName string) (models.SheetSync, error) {
+	return doSync(ctx, models.SheetSyncPush, spreadsheetName, sheetName, func(id string) (models.SheetDiff, error) {
+		values, err := redis.ReadSheet(ctx, spreadsheetName, sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("redis.ReadSheet() failed: %w", err)
+		}
+		current, err := sheets.ReadSheet(ctx, id, sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("sheets.ReadSheet() failed: %w", err)
+		}
+
+		diff := models.DiffSheets(current, values)
+		if len(diff) == 0 {
+			return nil, nil
+		}
+		// Cells past the end of values are blanked in the same write, so they do not stay behind in google,
+		// and a failed write leaves the sheet as it was instead of empty.
+		if err := sheets.WriteSheet(ctx, id, sheetName, blankPast(values, current)); err != nil {
+			return nil, fmt.Errorf("sheets.WriteSheet() failed: %w", err)
+		}
+		return diff, nil
+	})
+}
+
+// blankPast pads values with blank cells to cover every cell of current.
+func blankPast(values, current [][]interface{}) [][]interface{} {
+	height := len(values)
+	if len(current) > height {
+		height = len(current)
+	}
+	padded := make([][]interface{}, height)
+	for i := range padded {
+		var row, currentRow []interface{}
+		if i < len(values) {
+			row = values[i]
+		}
+		if i < len(current) {
+			currentRow = current[i]
+		}
+		padded[i] = append([]interface{}(nil), row...)
+		for len(padded[i]) < len(currentRow) {
+			padded[i] = append(padded[i], "")
+		}
+	}
+	return padded
+}
+
+func validateSheet(ctx context.Context, spreadsheetName, sheetName string, values [][]interface{}) error {
+	if spreadsheetName != models.SpreadsheetWebsiteData {
+		return nil
+	}
+	validation, err := models.ValidateSheet(sheetName, values, func(name string) ([][]interface{}, error) {
+		return redis.ReadSheet(ctx, spreadsheetName, name)
+	})
+	if err != nil {
+		return fmt.Errorf("models.ValidateSheet() failed: %w", err)
+	}
+	if len(validation.Errors) != 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidSheet, validation.Errors.Error())
+	}
+	return nil
+}
+
+func doSync(ctx context.Context, direction models.SheetSyncDirection, spreadsheetName, sheetName string,
+	copySheet func(id string) (models.SheetDiff, error)) (models.SheetSync, error) {
+	sync := models.SheetSync{
+		Direction:       direction,
+		SpreadsheetName: spreadsheetName,
+		SheetName:       sheetName,
+		StartedAt:       time.Now(),
+	}
+
+	id, err := spreadsheetId(spreadsheetName)
+	if err == nil {
+		sync.Diff, err = copySheet(id)
+	}
+	sync.Duration = time.Since(sync.StartedAt)
+
+	entry := logger.WithFields(map[string]interface{}{
+		"direction":   direction,
+		"spreadsheet": spreadsheetName,
+		"sheet":       sheetName,
+	})
+	if err != nil {
+		sync.Error = err.Error()
+		entry.WithError(err).Error("sheets sync: sync failed")
+	} else {
+		entry.WithField("changed_rows", len(sync.Diff)).Info("sheets sync: sync completed")
+	}
+
+	if err != nil || len(sync.Diff) != 0 {
+		if saveErr := models.SaveSheetSync(ctx, sync); saveErr != nil {
+			logger.MethodFailure(ctx, "models.SaveSheetSync", saveErr)
+		}
+	}
+	return sync, err
+}
@@ -0,0 +1,169 @@
+package sheets_sync
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/clients/sheets"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"testing"
+	"time"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	fake := sheets.NewFakeClient()
+	sheets.UseClient(fake)
+	config.Update(func(x *config.Configuration) {
+		x.Sheets.SpreadsheetIDs = map[string]string{"website_data": "google-id"}
+	})
+
+	googleValues := [][]interface{}{{"Name", "Title"}, {"01-snake-eater", "Snake Eater"}}
+	require.NoError(t, fake.WriteSheet(ctx, "google-id", "Projects", googleValues))
+
+	t.Run("pull", func(t *testing.T) {
+		sync, err := Pull(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, models.SheetDiff{
+			{Row: 1, New: googleValues[0]},
+			{Row: 2, New: googleValues[1]},
+		}, sync.Diff)
+
+		got, err := redis.ReadSheet(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, googleValues, got)
+	})
+
+	t.Run("pull/no changes", func(t *testing.T) {
+		sync, err := Pull(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		assert.Empty(t, sync.Diff)
+	})
+
+	t.Run("push", func(t *testing.T) {
+		edited := [][]interface{}{{"Name", "Title"}, {"01-snake-eater", "Snake Eater!"}}
+		require.NoError(t, redis.WriteSheet(ctx, "website_data", "Projects", "test", edited))
+
+		sync, err := Push(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, models.SheetDiff{{Row: 2, Old: googleValues[1], New: edited[1]}}, sync.Diff)
+
+		got, err := fake.ReadSheet(ctx, "google-id", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, edited, got)
+	})
+
+	t.Run("push/fewer rows", func(t *testing.T) {
+		require.NoError(t, fake.WriteSheet(ctx, "google-id", "Projects", [][]interface{}{
+			{"Name", "Title"}, {"01-snake-eater", "Snake Eater"}, {"02-proof-of-a-hero", "Proof of a Hero"},
+		}))
+		edited := [][]interface{}{{"Name", "Title"}, {"01-snake-eater", "Snake Eater!"}}
+
+		_, err := Push(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		got, err := fake.ReadSheet(ctx, "google-id", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, edited, got)
+	})
+
+	t.Run("push/fewer columns", func(t *testing.T) {
+		require.NoError(t, fake.WriteSheet(ctx, "google-id", "Projects", [][]interface{}{
+			{"Name", "Title", "Notes"}, {"01-snake-eater", "Snake Eater", "old"},
+		}))
+
+		_, err := Push(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		got, err := fake.ReadSheet(ctx, "google-id", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{"Name", "Title"}, {"01-snake-eater", "Snake Eater!"}}, got)
+	})
+
+	t.Run("push/failed write", func(t *testing.T) {
+		before := [][]interface{}{{"Name", "Title"}, {"01-snake-eater", "Snake Eater"}}
+		require.NoError(t, fake.WriteSheet(ctx, "google-id", "Projects", before))
+		sheets.UseClient(failingWrites{fake})
+		defer sheets.UseClient(fake)
+
+		_, err := Push(ctx, "website_data", "Projects")
+		assert.Error(t, err)
+		got, err := fake.ReadSheet(ctx, "google-id", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, before, got, "the google sheet is kept")
+
+		require.NoError(t, fake.WriteSheet(ctx, "google-id", "Projects", [][]interface{}{{"Name", "Title"}, {"01-snake-eater", "Snake Eater!"}}))
+	})
+
+	t.Run("pull/invalid sheet", func(t *testing.T) {
+		require.NoError(t, fake.WriteSheet(ctx, "google-id", "Projects", [][]interface{}{
+			{"Name", "Title"}, {"01-snake-eater", "Snake Eater"}, {"01-snake-eater", "Snake Eater"},
+		}))
+		_, err := Pull(ctx, "website_data", "Projects")
+		assert.True(t, errors.Is(err, ErrInvalidSheet), "errors.Is(err, ErrInvalidSheet)")
+
+		got, err := redis.ReadSheet(ctx, "website_data", "Projects")
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{"Name", "Title"}, {"01-snake-eater", "Snake Eater!"}}, got)
+	})
+
+	t.Run("unknown spreadsheet", func(t *testing.T) {
+		_, err := Pull(ctx, "cheese", "Projects")
+		assert.True(t, errors.Is(err, ErrUnknownSpreadsheet), "errors.Is(err, ErrUnknownSpreadsheet)")
+	})
+
+	t.Run("history", func(t *testing.T) {
+		history, err := models.ListSheetSyncs(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, history, 7)
+		assert.Equal(t, "cheese", history[0].SpreadsheetName)
+		assert.NotEmpty(t, history[0].Error)
+		assert.Equal(t, models.SheetSyncPull, history[1].Direction)
+		assert.NotEmpty(t, history[1].Error)
+		assert.Equal(t, models.SheetSyncPush, history[2].Direction)
+		assert.NotEmpty(t, history[2].Error)
+		assert.Equal(t, models.SheetSyncPush, history[3].Direction)
+		assert.Equal(t, models.SheetSyncPush, history[4].Direction)
+		assert.Equal(t, models.SheetSyncPush, history[5].Direction)
+		assert.Equal(t, models.SheetSyncPull, history[6].Direction)
+	})
+}
+
+// failingWrites is a client whose writes fail.
+type failingWrites struct{ *sheets.FakeClient }
+
+func (failingWrites) WriteSheet(context.Context, string, string, [][]interface{}) error {
+	return errors.New("write failed")
+}
+
+func TestConfiguredSheets(t *testing.T) {
+	config.Update(func(x *config.Configuration) {
+		x.Sheets.SyncSheets = []string{"website_data:Projects", "invalid", "website_data:Parts"}
+	})
+	assert.Equal(t, []SyncSheet{
+		{SpreadsheetName: "website_data", SheetName: "Projects"},
+		{SpreadsheetName: "website_data", SheetName: "Parts"},
+	}, ConfiguredSheets())
+}
+
+func TestLockedSync(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	sheets.UseClient(sheets.NewFakeClient())
+	config.Update(func(x *config.Configuration) {
+		x.Sheets.SpreadsheetIDs = map[string]string{"website_data": "google-id"}
+	})
+
+	// Someone else is publishing to the sheet.
+	ok, err := redis.SetNX(ctx, sheetLockKey("website_data", "Credits"), time.Minute, "publish")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	sync := LockedSync(waitCtx, models.SheetSyncPull, "website_data", "Credits")
+	assert.Equal(t, models.SheetSyncPull, sync.Direction)
+	assert.Equal(t, context.DeadlineExceeded.Error(), sync.Error, "the pull waits for the lock")
+}
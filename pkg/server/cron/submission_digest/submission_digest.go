@@ -0,0 +1,181 @@
+package submission_digest
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"strings"
+	"time"
+)
+
+// RedisKey is the hash of digests that were posted.
+// Fields are `channel:project:date`, so each project gets one digest a day.
+// Fields of earlier days are removed when the digests run.
+const RedisKey = "cron:submission_digest"
+
+// MaxFieldLength is the longest value discord accepts for an embed field.
+const MaxFieldLength = 1024
+
+// ChannelID returns the channel that gets the digest.
+func ChannelID() string {
+	if config.Config().Development {
+		return config.Config().Discord.SandboxChannelID
+	}
+	return config.Config().Discord.ProductionChannelID
+}
+
+// SendDigests posts the submission counts of each project that is open for submissions.
+// A project that fails does not stop the digests of the other projects, and is reported in the error.
+func SendDigests(ctx context.Context, now time.Time) error {
+	pruneDigests(ctx, now)
+	channelID := ChannelID()
+	if channelID == "" {
+		return nil
+	}
+
+	// The digest is for the production team, so it includes hidden projects and unreleased parts.
+	identity := models.Identity{Roles: []models.Role{models.RoleVVGOProductionTeam}}
+	projects, err := models.ListProjects(ctx, identity)
+	if err != nil {
+		return errors.ListProjectsFailure(err)
+	}
+	parts, err := models.ListParts(ctx, identity)
+	if err != nil {
+		return errors.ListPartsFailure(err)
+	}
+
+	var failed []string
+	for _, project := range projects.Current() {
+		if !IsOpen(project, now) {
+			continue
+		}
+		uploads, err := models.ListSubmissionUploads(ctx, project.Name)
+		if err != nil {
+			logger.ListSubmissionUploadsFailure(ctx, err)
+			failed = append(failed, project.Name)
+			continue
+		}
+		if err := sendDigest(ctx, channelID, project, models.BuildSubmissionTracking(project, parts, uploads), now); err != nil {
+			logger.WithField("project", project.Name).WithError(err).Error("submission digest: send failed")
+			failed = append(failed, project.Name)
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("digests failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// pruneDigests removes the digests of earlier days, which no longer stop a digest from being posted.
+func pruneDigests(ctx context.Context, now time.Time) {
+	sent, err := redis.HGetAll(ctx, RedisKey)
+	if err != nil {
+		logger.RedisFailure(ctx, err)
+		return
+	}
+	today := digestDate(now)
+	var old []string
+	for field := range sent {
+		if fields := strings.Split(field, ":"); len(fields) == 3 && fields[2] < today {
+			old = append(old, field)
+		}
+	}
+	if len(old) == 0 {
+		return
+	}
+	if err := redis.HDel(ctx, RedisKey, old...); err != nil {
+		logger.RedisFailure(ctx, err)
+	}
+}
+
+func digestDate(now time.Time) string { return now.In(models.DeadlineLocation()).Format("2006-01-02") }
+
+// IsOpen is true if the project is accepting submissions and its deadline has not passed.
+func IsOpen(project models.Project, now time.Time) bool {
+	if !project.AcceptingSubmissions() {
+		return false
+	}
+	deadline, ok := project.Deadline()
+	return !ok || now.Before(deadline)
+}
+
+func sendDigest(ctx context.Context, channelID string, project models.Project, tracking models.SubmissionTracking, now time.Time) error {
+	field := fmt.Sprintf("%s:%s:%s", channelID, project.Name, digestDate(now))
+	sentAt, err := redis.HGet(ctx, RedisKey, field)
+	if err != nil {
+		return errors.RedisFailure(err)
+	}
+	if sentAt != "" {
+		return nil
+	}
+
+	_, err = discord.CreateMessage(ctx, discord.Snowflake(channelID), discord.CreateMessageParams{
+		Embed: MakeEmbed(project, tracking),
+	})
+	if err != nil {
+		return fmt.Errorf("discord.CreateMessage() failed: %w", err)
+	}
+
+	if err := redis.HSet(ctx, RedisKey, map[string]string{field: time.Now().UTC().Format(time.RFC3339)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// MakeEmbed shows the totals, a field for each section, and the parts with no submissions.
+func MakeEmbed(project models.Project, tracking models.SubmissionTracking) *discord.Embed {
+	description := fmt.Sprintf("**%d** submissions from **%d** performers.",
+		tracking.Submissions, tracking.Performers)
+	if deadline, ok := project.Deadline(); ok {
+		description += fmt.Sprintf("\nSubmissions are due %s.", discord.FormatTimestamp(deadline, discord.TimestampStyleRelative))
+	}
+	if tracking.Template == "" {
+		description += "\nThe project has no instrumentation template, so parts are not grouped into sections."
+	}
+
+	var fields []discord.EmbedField
+	for _, section := range tracking.Sections {
+		fields = append(fields, discord.EmbedField{
+			Name: section.Section,
+			Value: fmt.Sprintf("%d submissions · %d performers · %d/%d parts",
+				section.Submissions, section.Performers, section.Parts-len(section.MissingParts), section.Parts),
+			Inline: true,
+		})
+	}
+	if len(tracking.MissingParts) != 0 {
+		fields = append(fields, discord.EmbedField{
+			Name:  fmt.Sprintf("Parts without submissions (%d)", len(tracking.MissingParts)),
+			Value: listParts(tracking.MissingParts),
+		})
+	}
+
+	return &discord.Embed{
+		Title:       fmt.Sprintf("📊 %s submissions", project.Title),
+		Type:        discord.EmbedTypeRich,
+		Description: description,
+		Url:         config.Config().VVGO.ServerUrl + project.PartsPage(),
+		Color:       0x8C17D9,
+		Fields:      fields,
+	}
+}
+
+// listParts joins the part names, and ends the list early if it does not fit in a field.
+func listParts(partNames []string) string {
+	var list string
+	for i, partName := range partNames {
+		if i != 0 {
+			partName = ", " + partName
+		}
+		rest := fmt.Sprintf(", and %d more", len(partNames)-i-1)
+		if len(list)+len(partName)+len(rest) > MaxFieldLength {
+			return strings.TrimPrefix(list+fmt.Sprintf(", and %d more", len(partNames)-i), ", ")
+		}
+		list += partName
+	}
+	return list
+}
@@ -0,0 +1,104 @@
+package submission_digest
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendDigests(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+
+	var messages []discord.CreateMessageParams
+	var discordDown bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if discordDown {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		assert.Equal(t, "/channels/channel-id/messages", r.URL.Path)
+		var params discord.CreateMessageParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		messages = append(messages, params)
+		json.NewEncoder(w).Encode(discord.Message{Id: "message-id"})
+	}))
+	defer ts.Close()
+	config.Update(func(x *config.Configuration) {
+		x.Discord.Endpoint = ts.URL
+		x.Development = false
+		x.Discord.ProductionChannelID = "channel-id"
+	})
+
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetProjects, "test", [][]interface{}{
+		{"Name", "Title", "Parts Released", "Submission Deadline", "Instrumentation"},
+		{"10-hildas-healing", "Hilda's Healing", true, "2021-06-01 23:59 UTC", "full"},
+		{"11-the-end", "The End", false, "2021-06-01 23:59 UTC", "full"},
+	}))
+	require.NoError(t, redis.WriteSheet(ctx, models.SpreadsheetWebsiteData, models.SheetParts, "test", [][]interface{}{
+		{"Project", "Part Name", "Score Order"},
+		{"10-hildas-healing", "Trumpet 1", 1},
+		{"10-hildas-healing", "Trumpet 2", 2},
+		{"11-the-end", "Trumpet 1", 1},
+	}))
+	require.NoError(t, models.SaveSubmissionUpload(ctx, models.NewSubmissionUpload("10-hildas-healing", "Trumpet 1", "1", "trumpet.mp3", 1, "")))
+
+	deadline := time.Date(2021, 6, 1, 23, 59, 0, 0, time.UTC)
+	require.NoError(t, SendDigests(ctx, deadline.Add(-48*time.Hour)))
+	require.NoError(t, SendDigests(ctx, deadline.Add(-47*time.Hour)))
+	require.Len(t, messages, 1, "one digest a day")
+	assert.Equal(t, "📊 Hilda's Healing submissions", messages[0].Embed.Title)
+	assert.Equal(t, "**1** submissions from **1** performers.\nSubmissions are due <t:1622591940:R>.", messages[0].Embed.Description)
+	assert.Equal(t, []discord.EmbedField{
+		{Name: "Brass", Value: "1 submissions · 1 performers · 1/2 parts", Inline: true},
+		{Name: "Parts without submissions (1)", Value: "Trumpet 2"},
+	}, messages[0].Embed.Fields)
+
+	require.NoError(t, SendDigests(ctx, deadline.Add(-24*time.Hour)))
+	require.Len(t, messages, 2, "next day")
+	sent, err := redis.HGetAll(ctx, RedisKey)
+	require.NoError(t, err)
+	assert.Len(t, sent, 1, "the digest of the day before is removed")
+
+	require.NoError(t, SendDigests(ctx, deadline.Add(time.Hour)))
+	require.Len(t, messages, 2, "submissions are closed")
+
+	t.Run("discord failure", func(t *testing.T) {
+		discordDown = true
+		defer func() { discordDown = false }()
+		err := SendDigests(ctx, deadline.Add(-12*time.Hour))
+		assert.EqualError(t, err, "digests failed for 10-hildas-healing")
+	})
+}
+
+func TestMakeEmbed_NoTemplate(t *testing.T) {
+	project := models.Project{Name: "10-hildas-healing", Title: "Hilda's Healing"}
+	tracking := models.BuildSubmissionTracking(project, models.Parts{{Project: "10-hildas-healing", PartName: "Trumpet 1"}}, nil)
+
+	embed := MakeEmbed(project, tracking)
+	assert.Equal(t, "**0** submissions from **0** performers.\n"+
+		"The project has no instrumentation template, so parts are not grouped into sections.", embed.Description)
+	assert.Equal(t, models.OtherSection, embed.Fields[0].Name)
+}
+
+func TestListParts(t *testing.T) {
+	assert.Equal(t, "a, b", listParts([]string{"a", "b"}))
+
+	var partNames []string
+	for i := 0; i < 200; i++ {
+		partNames = append(partNames, "Trumpet")
+	}
+	got := listParts(partNames)
+	assert.LessOrEqual(t, len(got), MaxFieldLength)
+	assert.True(t, strings.HasSuffix(got, ", and 88 more"), got)
+}
@@ -39,8 +39,8 @@ func init() {
 
 func WhichTime(ctx context.Context, channelId string) {
 
-	var dataJson string
-	if err := redis.Do(ctx, redis.Cmd(&dataJson, redis.HGET, RedisKey, channelId)); err != nil {
+	dataJson, err := redis.HGet(ctx, RedisKey, channelId)
+	if err != nil {
 		logger.RedisFailure(ctx, err)
 		return
 	}
@@ -76,7 +76,7 @@ func createMessage(ctx context.Context, channelId string) {
 		return
 	}
 
-	if err := redis.Do(ctx, redis.Cmd(nil, redis.HSET, RedisKey, channelId, dataJSON.String())); err != nil {
+	if err := redis.HSet(ctx, RedisKey, map[string]string{channelId: dataJSON.String()}); err != nil {
 		logger.RedisFailure(ctx, err)
 		return
 	}
@@ -91,7 +91,7 @@ func editMessage(ctx context.Context, channelId string, messageId string) {
 
 	if err != nil {
 		logger.HttpDoFailure(ctx, err)
-		if err := redis.Do(ctx, redis.Cmd(nil, redis.HDEL, RedisKey, channelId)); err != nil {
+		if err := redis.HDel(ctx, RedisKey, channelId); err != nil {
 			logger.RedisFailure(ctx, err)
 		}
 	}
@@ -44,6 +44,13 @@ func NewNotFoundError(reason string) models.ApiResponse {
 	})
 }
 
+func NewConflictError(reason string) models.ApiResponse {
+	return NewErrorResponse(models.ApiError{
+		Code:  http.StatusConflict,
+		Error: reason,
+	})
+}
+
 func NewInternalServerError() models.ApiResponse {
 	return NewErrorResponse(models.ApiError{
 		Code:  http.StatusInternalServerError,
@@ -1,7 +1,6 @@
 package login
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/binary"
@@ -68,9 +67,8 @@ func NewSession(ctx context.Context, identity *models.Identity, expires time.Dur
 	identity.ExpiresAt = expiresAt
 	identity.CreatedAt = time.Now()
 	key := "sessions:" + identity.Key
-	stringExpires := strconv.Itoa(int(expires.Seconds()))
 	srcBytes, _ := json.Marshal(identity)
-	if err := redis.Do(ctx, redis.Cmd(nil, "SETEX", key, stringExpires, string(srcBytes))); err != nil {
+	if err := redis.SetEx(ctx, key, expires, string(srcBytes)); err != nil {
 		return "", err
 	}
 	return identity.Key, nil
@@ -78,19 +76,18 @@ func NewSession(ctx context.Context, identity *models.Identity, expires time.Dur
 
 // GetSession reads the login identity for the given session ID.
 func GetSession(ctx context.Context, id string, dest *models.Identity) error {
-	var gotBytes []byte
-	err := redis.Do(ctx, redis.Cmd(&gotBytes, "GET", "sessions:"+id))
+	gotJSON, err := redis.Get(ctx, "sessions:"+id)
 	switch {
 	case err != nil:
 		return err
-	case len(gotBytes) == 0:
+	case gotJSON == "":
 		return ErrSessionNotFound
 	default:
-		return json.NewDecoder(bytes.NewReader(gotBytes)).Decode(dest)
+		return json.NewDecoder(strings.NewReader(gotJSON)).Decode(dest)
 	}
 }
 
 // DeleteSession deletes the sessionID key from redis.
 func DeleteSession(ctx context.Context, id string) error {
-	return redis.Do(ctx, redis.Cmd(nil, "DEL", "sessions:"+id))
+	return redis.Del(ctx, "sessions:"+id)
 }
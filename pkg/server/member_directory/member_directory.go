@@ -0,0 +1,136 @@
+package member_directory
+
+import (
+	"context"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/logger"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"reflect"
+	"time"
+)
+
+// PageSize is how many members are requested from discord at a time. This is the most discord allows.
+const PageSize = 1000
+
+// Sync pages through every member of the guild and updates the directory.
+// Only new and changed members are written, and members that left the guild are removed.
+func Sync(ctx context.Context) (models.GuildMemberSync, error) {
+	sync := models.GuildMemberSync{StartedAt: time.Now()}
+	err := doSync(ctx, &sync)
+	sync.Duration = time.Since(sync.StartedAt)
+	if err != nil {
+		sync.Error = err.Error()
+	}
+	if err := models.SaveGuildMemberSync(ctx, sync); err != nil {
+		logger.MethodFailure(ctx, "models.SaveGuildMemberSync", err)
+	}
+	return sync, err
+}
+
+func doSync(ctx context.Context, sync *models.GuildMemberSync) error {
+	stored, err := models.ListGuildMembers(ctx)
+	if err != nil {
+		return fmt.Errorf("models.ListGuildMembers() failed: %w", err)
+	}
+	storedByID := make(map[discord.Snowflake]discord.GuildMember, len(stored))
+	for _, member := range stored {
+		storedByID[member.User.ID] = member
+	}
+
+	seen := make(map[discord.Snowflake]bool, len(stored))
+	var after discord.Snowflake
+	for {
+		page, err := discord.ListGuildMembers(ctx, PageSize, after)
+		if err != nil {
+			return fmt.Errorf("discord.ListGuildMembers() failed: %w", err)
+		}
+
+		var changed []discord.GuildMember
+		for _, member := range page {
+			seen[member.User.ID] = true
+			previous, ok := storedByID[member.User.ID]
+			switch {
+			case !ok:
+				sync.Added++
+				changed = append(changed, member)
+			case !reflect.DeepEqual(previous, member):
+				sync.Updated++
+				changed = append(changed, member)
+			}
+			if after.Less(member.User.ID) {
+				after = member.User.ID
+			}
+		}
+		if err := models.UpdateGuildMembers(ctx, sync.StartedAt, storedByID, changed...); err != nil {
+			return fmt.Errorf("models.UpdateGuildMembers() failed: %w", err)
+		}
+		sync.Members += len(page)
+
+		if len(page) < PageSize {
+			break
+		}
+	}
+
+	var removed []discord.Snowflake
+	for id := range storedByID {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+	if err := models.RemoveGuildMembers(ctx, sync.StartedAt, removed...); err != nil {
+		return fmt.Errorf("models.RemoveGuildMembers() failed: %w", err)
+	}
+	sync.Removed = len(removed)
+	return nil
+}
+
+// Lookup returns the members with the given ids.
+// Members missing from the directory are queried from discord and saved.
+func Lookup(ctx context.Context, ids ...discord.Snowflake) ([]discord.GuildMember, error) {
+	stored, err := models.GetGuildMembers(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("models.GetGuildMembers() failed: %w", err)
+	}
+	members := make([]discord.GuildMember, 0, len(ids))
+	var fetched []discord.GuildMember
+	for _, id := range ids {
+		if member, ok := stored[id]; ok {
+			members = append(members, member)
+			continue
+		}
+		member, err := discord.GetGuildMember(ctx, id)
+		if err != nil {
+			logger.MethodFailure(ctx, "discord.GetGuildMember", err)
+			continue
+		}
+		fetched = append(fetched, *member)
+		members = append(members, *member)
+	}
+	if err := models.SaveGuildMembers(ctx, time.Now(), fetched...); err != nil {
+		logger.MethodFailure(ctx, "models.SaveGuildMembers", err)
+	}
+	return members, nil
+}
+
+// HandleGatewayEvent keeps the directory up to date between syncs.
+func HandleGatewayEvent(ctx context.Context, event discord.GatewayEvent) {
+	var err error
+	switch event := event.(type) {
+	case discord.GuildMemberAddEvent:
+		if event.GuildID == discord.GuildID() {
+			err = models.SaveGuildMembers(ctx, time.Now(), event.GuildMember)
+		}
+	case discord.GuildMemberUpdateEvent:
+		if event.GuildID == discord.GuildID() {
+			err = models.SaveGuildMembers(ctx, time.Now(), event.GuildMember)
+		}
+	case discord.GuildMemberRemoveEvent:
+		if event.GuildID == discord.GuildID() {
+			err = models.RemoveGuildMembers(ctx, time.Now(), event.User.ID)
+		}
+	}
+	if err != nil {
+		logger.WithField("event", event.EventName()).WithError(err).Error("member directory: update failed")
+	}
+}
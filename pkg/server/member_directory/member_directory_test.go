@@ -0,0 +1,116 @@
+package member_directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/discord"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/config"
+	"github.com/virtual-vgo/vvgo/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	config.Update(func(x *config.Configuration) { x.Discord.GuildID = "guild" })
+
+	var guild []discord.GuildMember
+	for i := 1; i <= PageSize+5; i++ {
+		guild = append(guild, discord.GuildMember{User: discord.User{ID: discord.Snowflake(strconv.Itoa(i)), Username: fmt.Sprintf("member-%d", i)}})
+	}
+	var pages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/guilds/guild/members", r.URL.Path)
+		pages = append(pages, r.URL.Query().Get("after"))
+		after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page := make([]discord.GuildMember, 0)
+		for _, member := range guild {
+			if id, _ := strconv.Atoi(member.User.ID.String()); id > after && len(page) < limit {
+				page = append(page, member)
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(page))
+	}))
+	defer ts.Close()
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
+
+	sync, err := Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", strconv.Itoa(PageSize)}, pages)
+	assert.Equal(t, PageSize+5, sync.Members)
+	assert.Equal(t, PageSize+5, sync.Added)
+
+	t.Run("incremental", func(t *testing.T) {
+		guild[0].Nick = "first"
+		guild = guild[:len(guild)-1]
+		sync, err := Sync(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, PageSize+4, sync.Members)
+		assert.Equal(t, 0, sync.Added)
+		assert.Equal(t, 1, sync.Updated)
+		assert.Equal(t, 1, sync.Removed)
+
+		last, err := models.GetGuildMemberSync(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, sync.Updated, last.Updated)
+	})
+
+	t.Run("gateway events", func(t *testing.T) {
+		member := discord.GuildMember{User: discord.User{ID: "9000", Username: "chester"}, Roles: []string{"teams"}}
+		HandleGatewayEvent(ctx, discord.GuildMemberAddEvent{GuildMember: member, GuildID: "guild"})
+		HandleGatewayEvent(ctx, discord.GuildMemberAddEvent{GuildMember: discord.GuildMember{User: discord.User{ID: "9001"}}, GuildID: "other"})
+		HandleGatewayEvent(ctx, discord.GuildMemberRemoveEvent{User: discord.User{ID: "1"}, GuildID: "guild"})
+
+		got, err := models.GetGuildMember(ctx, "9000")
+		require.NoError(t, err)
+		assert.Equal(t, &member, got)
+		got, err = models.GetGuildMember(ctx, "9001")
+		require.NoError(t, err)
+		assert.Nil(t, got, "other guilds are ignored")
+		got, err = models.GetGuildMember(ctx, "1")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestLookup(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	config.Update(func(x *config.Configuration) { x.Discord.GuildID = "guild" })
+
+	stored := discord.GuildMember{User: discord.User{ID: "1", Username: "chester"}}
+	require.NoError(t, models.SaveGuildMembers(ctx, time.Now(), stored))
+
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path != "/guilds/guild/members/2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(discord.GuildMember{User: discord.User{ID: "2", Username: "brie"}}))
+	}))
+	defer ts.Close()
+	config.Update(func(x *config.Configuration) { x.Discord.Endpoint = ts.URL })
+
+	got, err := Lookup(ctx, "2", "1", "3")
+	require.NoError(t, err)
+	assert.Equal(t, []discord.GuildMember{
+		{User: discord.User{ID: "2", Username: "brie"}},
+		stored,
+	}, got)
+	assert.Equal(t, []string{"/guilds/guild/members/2", "/guilds/guild/members/3"}, requested, "only misses are queried")
+
+	fetched, err := models.GetGuildMember(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, &got[0], fetched, "saves fetched members")
+}
@@ -0,0 +1,268 @@
+package voting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const ElectionsRedisKey = "voting:elections"
+
+var ErrElectionNotFound = errors.New("election not found")
+var ErrElectionNotOpen = errors.New("election is not open")
+var ErrInvalidElection = errors.New("invalid election")
+var ErrInvalidBallot = errors.New("invalid ballot")
+
+var validElectionID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Election is a ranked-choice vote between choices.
+// Several elections can be open at the same time.
+type Election struct {
+	ID      string
+	Title   string
+	Method  Method
+	Choices []string
+	// Labels are how choices are shown to voters, by choice. Choices without a label are shown as they are.
+	Labels map[string]string `json:"Labels,omitempty"`
+	// VoterRole is the vvgo role that may vote, ex vvgo-leader.
+	VoterRole string
+	// OpensAt and ClosesAt bound when ballots can be cast.
+	// A zero OpensAt is open from when the election is created, and a zero ClosesAt never closes.
+	OpensAt  time.Time `json:"OpensAt,omitempty"`
+	ClosesAt time.Time `json:"ClosesAt,omitempty"`
+}
+
+type Elections []Election
+
+func ballotsRedisKey(id string) string { return "voting:elections:" + id + ":ballots" }
+
+func (x Election) Validate() error {
+	switch {
+	case !validElectionID.MatchString(x.ID):
+		return fmt.Errorf("%w: id must be lowercase letters, numbers, and dashes", ErrInvalidElection)
+	case x.Title == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidElection)
+	case !x.Method.IsValid():
+		return fmt.Errorf("%w: method must be one of %v", ErrInvalidElection, Methods)
+	case x.VoterRole == "":
+		return fmt.Errorf("%w: voter role is required", ErrInvalidElection)
+	case !x.OpensAt.IsZero() && !x.ClosesAt.IsZero() && !x.ClosesAt.After(x.OpensAt):
+		return fmt.Errorf("%w: closes at must be after opens at", ErrInvalidElection)
+	}
+
+	seen := make(map[string]bool, len(x.Choices))
+	for _, choice := range x.Choices {
+		switch {
+		case choice == "":
+			return fmt.Errorf("%w: choices cannot be blank", ErrInvalidElection)
+		case seen[choice]:
+			return fmt.Errorf("%w: %s is a choice more than once", ErrInvalidElection, choice)
+		}
+		seen[choice] = true
+	}
+	return nil
+}
+
+// Label is how the choice is shown to voters.
+func (x Election) Label(choice string) string {
+	if label := x.Labels[choice]; label != "" {
+		return label
+	}
+	return choice
+}
+
+// MinChoices is how many choices an election needs before ballots can be cast.
+// Elections can be saved with fewer, so choices can be added one at a time.
+const MinChoices = 2
+
+// IsOpen is true if ballots can be cast at the time.
+func (x Election) IsOpen(at time.Time) bool {
+	return len(x.Choices) >= MinChoices &&
+		(x.OpensAt.IsZero() || !at.Before(x.OpensAt)) && (x.ClosesAt.IsZero() || at.Before(x.ClosesAt))
+}
+
+// ValidateBallot checks that the ballot ranks at least one choice, and only ranks choices of the election once.
+func (x Election) ValidateBallot(ballot Ballot) error {
+	if len(ballot) == 0 {
+		return fmt.Errorf("%w: rank at least one choice", ErrInvalidBallot)
+	}
+	valid := make(map[string]bool, len(x.Choices))
+	for _, choice := range x.Choices {
+		valid[choice] = true
+	}
+	seen := make(map[string]bool, len(ballot))
+	for _, choice := range ballot {
+		switch {
+		case !valid[choice]:
+			return fmt.Errorf("%w: %s is not a choice", ErrInvalidBallot, choice)
+		case seen[choice]:
+			return fmt.Errorf("%w: %s is ranked more than once", ErrInvalidBallot, choice)
+		}
+		seen[choice] = true
+	}
+	return nil
+}
+
+// ListElections returns every election, by when they open.
+func ListElections(ctx context.Context) (Elections, error) {
+	electionsMap, err := redis.HGetAll(ctx, ElectionsRedisKey)
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	elections := make(Elections, 0, len(electionsMap))
+	for _, electionJSON := range electionsMap {
+		var election Election
+		if err := json.Unmarshal([]byte(electionJSON), &election); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		elections = append(elections, election)
+	}
+	sort.Slice(elections, func(i, j int) bool {
+		if !elections[i].OpensAt.Equal(elections[j].OpensAt) {
+			return elections[i].OpensAt.Before(elections[j].OpensAt)
+		}
+		return elections[i].ID < elections[j].ID
+	})
+	return elections, nil
+}
+
+func GetElection(ctx context.Context, id string) (Election, error) {
+	electionJSON, err := redis.HGet(ctx, ElectionsRedisKey, id)
+	switch {
+	case err != nil:
+		return Election{}, errors.RedisFailure(err)
+	case electionJSON == "":
+		return Election{}, fmt.Errorf("%w: %s", ErrElectionNotFound, id)
+	}
+
+	var election Election
+	if err := json.Unmarshal([]byte(electionJSON), &election); err != nil {
+		return Election{}, errors.JsonDecodeFailure(err)
+	}
+	return election, nil
+}
+
+// SaveElection creates or replaces an election.
+// Ballots that were cast are kept, and choices that were removed are ignored when they are counted.
+func SaveElection(ctx context.Context, election Election) error {
+	if err := election.Validate(); err != nil {
+		return err
+	}
+	electionJSON, err := json.Marshal(election)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.HSet(ctx, ElectionsRedisKey, map[string]string{election.ID: string(electionJSON)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// CastBallot records the voter's ballot, replacing any ballot they cast before.
+func CastBallot(ctx context.Context, election Election, voterID string, ballot Ballot, at time.Time) error {
+	switch {
+	case len(election.Choices) < MinChoices:
+		return fmt.Errorf("%w: %s needs at least %d choices", ErrElectionNotOpen, election.ID, MinChoices)
+	case !election.IsOpen(at):
+		return fmt.Errorf("%w: %s", ErrElectionNotOpen, election.ID)
+	}
+	if err := election.ValidateBallot(ballot); err != nil {
+		return err
+	}
+	ballotJSON, err := json.Marshal(ballot)
+	if err != nil {
+		return errors.JsonEncodeFailure(err)
+	}
+	if err := redis.HSet(ctx, ballotsRedisKey(election.ID), map[string]string{voterID: string(ballotJSON)}); err != nil {
+		return errors.RedisFailure(err)
+	}
+	return nil
+}
+
+// ImportBallots copies ballots cast outside the election store into the election, by voter id.
+// Voters who already cast a ballot in the election keep it. The ballots are checked against the choices,
+// but not against when the election is open. Invalid ballots are skipped and returned by voter id with the reason,
+// so one bad ballot does not stop the rest from being copied. It returns how many ballots were copied.
+func ImportBallots(ctx context.Context, election Election, ballots map[string]Ballot) (int, map[string]error, error) {
+	existing, err := ListBallots(ctx, election)
+	if err != nil {
+		return 0, nil, err
+	}
+	fields := make(map[string]string, len(ballots))
+	skipped := make(map[string]error)
+	for voterID, ballot := range ballots {
+		if _, ok := existing[voterID]; ok {
+			continue
+		}
+		if err := election.ValidateBallot(ballot); err != nil {
+			skipped[voterID] = err
+			continue
+		}
+		ballotJSON, err := json.Marshal(ballot)
+		if err != nil {
+			return 0, nil, errors.JsonEncodeFailure(err)
+		}
+		fields[voterID] = string(ballotJSON)
+	}
+	if len(fields) == 0 {
+		return 0, skipped, nil
+	}
+	if err := redis.HSet(ctx, ballotsRedisKey(election.ID), fields); err != nil {
+		return 0, nil, errors.RedisFailure(err)
+	}
+	return len(fields), skipped, nil
+}
+
+// GetBallot returns the voter's ballot, or nil if they have not voted.
+func GetBallot(ctx context.Context, election Election, voterID string) (Ballot, error) {
+	ballotJSON, err := redis.HGet(ctx, ballotsRedisKey(election.ID), voterID)
+	switch {
+	case err != nil:
+		return nil, errors.RedisFailure(err)
+	case ballotJSON == "":
+		return nil, nil
+	}
+
+	var ballot Ballot
+	if err := json.Unmarshal([]byte(ballotJSON), &ballot); err != nil {
+		return nil, errors.JsonDecodeFailure(err)
+	}
+	return ballot, nil
+}
+
+// ListBallots returns the ballots cast in the election by voter id.
+func ListBallots(ctx context.Context, election Election) (map[string]Ballot, error) {
+	ballotsMap, err := redis.HGetAll(ctx, ballotsRedisKey(election.ID))
+	if err != nil {
+		return nil, errors.RedisFailure(err)
+	}
+
+	ballots := make(map[string]Ballot, len(ballotsMap))
+	for voterID, ballotJSON := range ballotsMap {
+		var ballot Ballot
+		if err := json.Unmarshal([]byte(ballotJSON), &ballot); err != nil {
+			return nil, errors.JsonDecodeFailure(err)
+		}
+		ballots[voterID] = ballot
+	}
+	return ballots, nil
+}
+
+// Results counts the ballots cast in the election.
+func Results(ctx context.Context, election Election) (Result, error) {
+	ballotsMap, err := ListBallots(ctx, election)
+	if err != nil {
+		return Result{}, err
+	}
+	ballots := make([]Ballot, 0, len(ballotsMap))
+	for _, ballot := range ballotsMap {
+		ballots = append(ballots, ballot)
+	}
+	return Count(election.Method, election.Choices, ballots)
+}
@@ -0,0 +1,116 @@
+package voting
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/virtual-vgo/vvgo/pkg/clients/redis"
+	"testing"
+	"time"
+)
+
+func TestElections(t *testing.T) {
+	ctx := context.Background()
+	redis.UseStore(redis.NewMemoryStore())
+	opensAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	election := Election{
+		ID:        "season3-arrangements",
+		Title:     "Season 3 Arrangements",
+		Method:    MethodInstantRunoff,
+		Choices:   []string{"Snake Eater", "Proof of a Hero", "Aurene"},
+		VoterRole: "vvgo-leader",
+		OpensAt:   opensAt,
+		ClosesAt:  opensAt.Add(7 * 24 * time.Hour),
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		invalid := election
+		invalid.Choices = []string{"Aurene", "Aurene"}
+		assert.ErrorIs(t, SaveElection(ctx, invalid), ErrInvalidElection)
+
+		invalid = election
+		invalid.ClosesAt = opensAt
+		assert.ErrorIs(t, SaveElection(ctx, invalid), ErrInvalidElection)
+	})
+
+	t.Run("too few choices", func(t *testing.T) {
+		single := election
+		single.ID = "season3-single"
+		single.Choices = []string{"Aurene"}
+		require.NoError(t, SaveElection(ctx, single), "choices can be added later")
+		assert.False(t, single.IsOpen(opensAt))
+		assert.ErrorIs(t, CastBallot(ctx, single, "1", Ballot{"Aurene"}, opensAt), ErrElectionNotOpen)
+		require.NoError(t, redis.HDel(ctx, ElectionsRedisKey, single.ID))
+	})
+
+	require.NoError(t, SaveElection(ctx, election))
+	require.NoError(t, SaveElection(ctx, Election{
+		ID: "season3-schedule", Title: "Season 3 Schedule", Method: MethodSchulze,
+		Choices: []string{"Fall", "Winter"}, VoterRole: "vvgo-member",
+	}))
+
+	t.Run("list", func(t *testing.T) {
+		elections, err := ListElections(ctx)
+		require.NoError(t, err)
+		require.Len(t, elections, 2)
+		assert.Equal(t, "season3-schedule", elections[0].ID, "elections without an open time are first")
+		assert.Equal(t, election, elections[1])
+
+		_, err = GetElection(ctx, "cheese")
+		assert.ErrorIs(t, err, ErrElectionNotFound)
+	})
+
+	t.Run("cast", func(t *testing.T) {
+		assert.ErrorIs(t, CastBallot(ctx, election, "1", Ballot{"Aurene"}, opensAt.Add(-time.Second)), ErrElectionNotOpen)
+		assert.ErrorIs(t, CastBallot(ctx, election, "1", Ballot{"Aurene"}, election.ClosesAt), ErrElectionNotOpen)
+		assert.ErrorIs(t, CastBallot(ctx, election, "1", Ballot{"cheese"}, opensAt), ErrInvalidBallot)
+		assert.ErrorIs(t, CastBallot(ctx, election, "1", Ballot{"Aurene", "Aurene"}, opensAt), ErrInvalidBallot)
+		assert.ErrorIs(t, CastBallot(ctx, election, "1", Ballot{}, opensAt), ErrInvalidBallot)
+
+		require.NoError(t, CastBallot(ctx, election, "1", Ballot{"Snake Eater"}, opensAt))
+		require.NoError(t, CastBallot(ctx, election, "1", Ballot{"Aurene", "Snake Eater"}, opensAt))
+		require.NoError(t, CastBallot(ctx, election, "2", Ballot{"Aurene"}, opensAt))
+
+		ballot, err := GetBallot(ctx, election, "1")
+		require.NoError(t, err)
+		assert.Equal(t, Ballot{"Aurene", "Snake Eater"}, ballot, "ballots are replaced")
+
+		ballot, err = GetBallot(ctx, election, "3")
+		require.NoError(t, err)
+		assert.Nil(t, ballot)
+	})
+
+	t.Run("results", func(t *testing.T) {
+		result, err := Results(ctx, election)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Ballots)
+		assert.Equal(t, []Rank{
+			{1, []string{"Aurene"}}, {2, []string{"Snake Eater"}}, {3, []string{"Proof of a Hero"}},
+		}, result.Ranking)
+	})
+	t.Run("import", func(t *testing.T) {
+		imported, skipped, err := ImportBallots(ctx, election, map[string]Ballot{
+			"1": {"Snake Eater"},
+			"3": {"Proof of a Hero", "Aurene"},
+			"4": {"cheese"},
+			"5": {},
+			"6": {"Aurene", "Aurene"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, imported)
+		require.Len(t, skipped, 3)
+		for voterID, err := range skipped {
+			assert.ErrorIs(t, err, ErrInvalidBallot, voterID)
+		}
+
+		ballot, err := GetBallot(ctx, election, "1")
+		require.NoError(t, err)
+		assert.Equal(t, Ballot{"Aurene", "Snake Eater"}, ballot, "cast ballots are kept")
+		ballot, err = GetBallot(ctx, election, "3")
+		require.NoError(t, err)
+		assert.Equal(t, Ballot{"Proof of a Hero", "Aurene"}, ballot)
+		ballot, err = GetBallot(ctx, election, "4")
+		require.NoError(t, err)
+		assert.Nil(t, ballot, "invalid ballots are skipped")
+	})
+}
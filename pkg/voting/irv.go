@@ -0,0 +1,105 @@
+package voting
+
+import "sort"
+
+func countInstantRunoff(choices []string, ballots []Ballot) Result {
+	var result Result
+	var groups [][]string
+	for hasChoices(ballots) {
+		winners, rounds := instantRunoffWinners(rankedCount(groups)+1, ballots)
+		result.Rounds = append(result.Rounds, rounds...)
+		groups = append(groups, winners)
+		ballots = removeChoices(ballots, winners...)
+	}
+	// Choices that are on no ballot tie for last.
+	groups = append(groups, unranked(choices, groups))
+	result.Ranking = newRanking(groups)
+	return result
+}
+
+// instantRunoffWinners runs rounds until a choice has a majority of the ballots that are not exhausted.
+// Choices with no first-choice votes are never eliminated, so that a choice that is everyone's second choice can win.
+// All the choices with the fewest votes are eliminated together.
+// If every remaining choice has the same number of votes, they all win.
+func instantRunoffWinners(rank int, ballots []Ballot) ([]string, []Round) {
+	var rounds []Round
+	for {
+		round := Round{Rank: rank}
+		votes := make(map[string]int)
+		var active int
+		for _, ballot := range ballots {
+			if len(ballot) == 0 {
+				round.Exhausted++
+				continue
+			}
+			votes[ballot[0]]++
+			active++
+		}
+		for choice, count := range votes {
+			round.Tallies = append(round.Tallies, Tally{Choice: choice, Votes: count})
+		}
+		sort.Slice(round.Tallies, func(i, j int) bool {
+			if round.Tallies[i].Votes != round.Tallies[j].Votes {
+				return round.Tallies[i].Votes > round.Tallies[j].Votes
+			}
+			return round.Tallies[i].Choice < round.Tallies[j].Choice
+		})
+
+		maxVotes := round.Tallies[0].Votes
+		minVotes := round.Tallies[len(round.Tallies)-1].Votes
+		if maxVotes*2 > active {
+			round.Elected = []string{round.Tallies[0].Choice}
+			return round.Elected, append(rounds, round)
+		}
+
+		var losers []string
+		for _, tally := range round.Tallies {
+			if tally.Votes == minVotes {
+				losers = append(losers, tally.Choice)
+			}
+		}
+		sort.Strings(losers)
+		if minVotes == maxVotes {
+			round.Elected = losers
+			return round.Elected, append(rounds, round)
+		}
+
+		round.Eliminated = losers
+		rounds = append(rounds, round)
+		ballots = removeChoices(ballots, losers...)
+	}
+}
+
+func hasChoices(ballots []Ballot) bool {
+	for _, ballot := range ballots {
+		if len(ballot) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func rankedCount(groups [][]string) int {
+	var count int
+	for _, group := range groups {
+		count += len(group)
+	}
+	return count
+}
+
+func removeChoices(ballots []Ballot, choices ...string) []Ballot {
+	remove := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		remove[choice] = true
+	}
+
+	want := make([]Ballot, len(ballots))
+	for i, ballot := range ballots {
+		for _, choice := range ballot {
+			if !remove[choice] {
+				want[i] = append(want[i], choice)
+			}
+		}
+	}
+	return want
+}
@@ -0,0 +1,102 @@
+package voting
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCount_InstantRunoff(t *testing.T) {
+	choices := []string{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"}
+	count := func(t *testing.T, ballots ...Ballot) Result {
+		result, err := Count(MethodInstantRunoff, choices, ballots)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("no ties", func(t *testing.T) {
+		result := count(t,
+			Ballot{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
+			Ballot{"Choice D", "Choice B", "Choice A", "Choice C", "Choice E", "Choice F"},
+			Ballot{"Choice C", "Choice B", "Choice E", "Choice A", "Choice D", "Choice F"},
+			Ballot{"Choice C", "Choice B", "Choice D", "Choice E", "Choice A", "Choice F"},
+			Ballot{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
+		)
+		assert.Equal(t, []Rank{
+			{1, []string{"Choice A"}}, {2, []string{"Choice B"}}, {3, []string{"Choice C"}},
+			{4, []string{"Choice D"}}, {5, []string{"Choice E"}}, {6, []string{"Choice F"}},
+		}, result.Ranking)
+		assert.Equal(t, 5, result.Ballots)
+
+		// The rounds deciding first place.
+		assert.Equal(t, []Round{
+			{Rank: 1, Tallies: []Tally{{"Choice A", 2}, {"Choice C", 2}, {"Choice D", 1}}, Eliminated: []string{"Choice D"}},
+			{Rank: 1, Tallies: []Tally{{"Choice A", 2}, {"Choice C", 2}, {"Choice B", 1}}, Eliminated: []string{"Choice B"}},
+			{Rank: 1, Tallies: []Tally{{"Choice A", 3}, {"Choice C", 2}}, Elected: []string{"Choice A"}},
+		}, result.Rounds[:3])
+	})
+
+	t.Run("tie", func(t *testing.T) {
+		result := count(t,
+			Ballot{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
+			Ballot{"Choice C", "Choice B", "Choice E", "Choice A", "Choice D", "Choice F"},
+			Ballot{"Choice C", "Choice B", "Choice D", "Choice E", "Choice A", "Choice F"},
+			Ballot{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
+		)
+		assert.Equal(t, []Rank{
+			{1, []string{"Choice A", "Choice C"}}, {3, []string{"Choice B"}},
+			{4, []string{"Choice D"}}, {5, []string{"Choice E"}}, {6, []string{"Choice F"}},
+		}, result.Ranking)
+	})
+
+	t.Run("tie with lifting", func(t *testing.T) {
+		result := count(t,
+			Ballot{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
+			Ballot{"Choice D", "Choice B", "Choice A", "Choice C", "Choice E", "Choice F"},
+			Ballot{"Choice C", "Choice B", "Choice D", "Choice E", "Choice A", "Choice F"},
+			Ballot{"Choice A", "Choice B", "Choice C", "Choice D", "Choice E", "Choice F"},
+		)
+		assert.Equal(t, []Rank{
+			{1, []string{"Choice A", "Choice B"}}, {3, []string{"Choice C"}},
+			{4, []string{"Choice D"}}, {5, []string{"Choice E"}}, {6, []string{"Choice F"}},
+		}, result.Ranking)
+	})
+
+	t.Run("partial ballots", func(t *testing.T) {
+		result := count(t,
+			Ballot{"Choice A"},
+			Ballot{"Choice B", "Choice A"},
+			Ballot{"Choice C"},
+			Ballot{"Choice C", "cheese", "Choice C"},
+		)
+		assert.Equal(t, []Rank{
+			{1, []string{"Choice C"}}, {2, []string{"Choice A", "Choice B"}},
+			{4, []string{"Choice D", "Choice E", "Choice F"}},
+		}, result.Ranking)
+	})
+
+	t.Run("is deterministic", func(t *testing.T) {
+		ballots := []Ballot{
+			{"Choice A", "Choice B"}, {"Choice B", "Choice A"}, {"Choice C", "Choice D"}, {"Choice D", "Choice C"},
+		}
+		want := count(t, ballots...)
+		for i := 0; i < 20; i++ {
+			assert.Equal(t, want, count(t, ballots...))
+		}
+	})
+}
+
+func TestCount_UnknownMethod(t *testing.T) {
+	_, err := Count("cheese", []string{"Choice A"}, nil)
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+}
+
+func TestNewRanking(t *testing.T) {
+	assert.Equal(t, []Rank{
+		{1, []string{"Choice A", "Choice B"}},
+		{3, []string{"Choice C"}},
+		{4, []string{"Choice D"}},
+		{5, []string{"Choice E"}},
+		{6, []string{"Choice F"}},
+	}, newRanking([][]string{{"Choice B", "Choice A"}, {"Choice C"}, {"Choice D"}, {"Choice E"}, {"Choice F"}}))
+}
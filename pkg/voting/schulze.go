@@ -0,0 +1,103 @@
+package voting
+
+// https://en.wikipedia.org/wiki/Schulze_method
+
+func countSchulze(choices []string, ballots []Ballot) Result {
+	preferences := make(map[string]map[string]int, len(choices))
+	paths := make(map[string]map[string]int, len(choices))
+	for _, a := range choices {
+		preferences[a] = make(map[string]int, len(choices))
+		paths[a] = make(map[string]int, len(choices))
+		for _, b := range choices {
+			if a != b {
+				preferences[a][b] = 0
+			}
+		}
+	}
+
+	for _, ballot := range ballots {
+		position := make(map[string]int, len(ballot))
+		for i, choice := range ballot {
+			position[choice] = i
+		}
+		for _, a := range choices {
+			aPosition, aRanked := position[a]
+			if !aRanked {
+				continue
+			}
+			for _, b := range choices {
+				if bPosition, bRanked := position[b]; a != b && (!bRanked || aPosition < bPosition) {
+					preferences[a][b]++
+				}
+			}
+		}
+	}
+
+	// Widest paths, with the Floyd–Warshall algorithm.
+	for _, a := range choices {
+		for _, b := range choices {
+			switch {
+			case a == b:
+			case preferences[a][b] > preferences[b][a]:
+				paths[a][b] = preferences[a][b]
+			default:
+				paths[a][b] = 0
+			}
+		}
+	}
+	for _, via := range choices {
+		for _, a := range choices {
+			if a == via {
+				continue
+			}
+			for _, b := range choices {
+				if b == via || b == a {
+					continue
+				}
+				paths[a][b] = maxInt(paths[a][b], minInt(paths[a][via], paths[via][b]))
+			}
+		}
+	}
+
+	// The choices that no remaining choice beats take the next rank.
+	var groups [][]string
+	remaining := append([]string(nil), choices...)
+	for len(remaining) != 0 {
+		var winners, losers []string
+		for _, a := range remaining {
+			beaten := false
+			for _, b := range remaining {
+				if a != b && paths[b][a] > paths[a][b] {
+					beaten = true
+					break
+				}
+			}
+			if beaten {
+				losers = append(losers, a)
+			} else {
+				winners = append(winners, a)
+			}
+		}
+		if len(winners) == 0 { // not possible, the schulze relation is transitive
+			winners, losers = losers, nil
+		}
+		groups = append(groups, winners)
+		remaining = losers
+	}
+
+	return Result{Ranking: newRanking(groups), Preferences: preferences, StrongestPaths: paths}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,51 @@
+package voting
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCount_Schulze(t *testing.T) {
+	t.Run("wikipedia example", func(t *testing.T) {
+		var ballots []Ballot
+		for _, group := range []struct {
+			voters int
+			ballot Ballot
+		}{
+			{5, Ballot{"A", "C", "B", "E", "D"}},
+			{5, Ballot{"A", "D", "E", "C", "B"}},
+			{8, Ballot{"B", "E", "D", "A", "C"}},
+			{3, Ballot{"C", "A", "B", "E", "D"}},
+			{7, Ballot{"C", "A", "E", "B", "D"}},
+			{2, Ballot{"C", "B", "A", "D", "E"}},
+			{7, Ballot{"D", "C", "E", "B", "A"}},
+			{8, Ballot{"E", "B", "A", "D", "C"}},
+		} {
+			for i := 0; i < group.voters; i++ {
+				ballots = append(ballots, group.ballot)
+			}
+		}
+
+		result, err := Count(MethodSchulze, []string{"A", "B", "C", "D", "E"}, ballots)
+		require.NoError(t, err)
+		assert.Equal(t, []Rank{{1, []string{"E"}}, {2, []string{"A"}}, {3, []string{"C"}}, {4, []string{"B"}}, {5, []string{"D"}}}, result.Ranking)
+		assert.Equal(t, 20, result.Preferences["A"]["B"])
+		assert.Equal(t, 25, result.Preferences["B"]["A"])
+		assert.Equal(t, 28, result.StrongestPaths["A"]["B"])
+		assert.Equal(t, 25, result.StrongestPaths["B"]["A"])
+		assert.Empty(t, result.Rounds)
+	})
+
+	t.Run("unranked choices lose", func(t *testing.T) {
+		result, err := Count(MethodSchulze, []string{"A", "B", "C"}, []Ballot{{"B"}, {"B", "A"}, {"A"}})
+		require.NoError(t, err)
+		assert.Equal(t, []Rank{{1, []string{"B"}}, {2, []string{"A"}}, {3, []string{"C"}}}, result.Ranking)
+	})
+
+	t.Run("tie", func(t *testing.T) {
+		result, err := Count(MethodSchulze, []string{"A", "B", "C"}, []Ballot{{"B", "A", "C"}, {"A", "B", "C"}})
+		require.NoError(t, err)
+		assert.Equal(t, []Rank{{1, []string{"A", "B"}}, {3, []string{"C"}}}, result.Ranking)
+	})
+}
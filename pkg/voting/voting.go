@@ -0,0 +1,151 @@
+// Package voting counts ranked-choice elections.
+//
+// Ties are never broken at random.
+// Choices that cannot be separated share a rank, and are listed in alphabetical order.
+package voting
+
+import (
+	"fmt"
+	"github.com/virtual-vgo/vvgo/pkg/errors"
+	"sort"
+)
+
+var ErrUnknownMethod = errors.New("unknown voting method")
+
+// Method is how ballots are counted.
+type Method string
+
+const (
+	// MethodInstantRunoff repeatedly eliminates the choices with the fewest first-choice votes until one has a majority.
+	// The winner is removed from the ballots and the count is run again for each following rank.
+	MethodInstantRunoff Method = "irv"
+	// MethodSchulze ranks choices by the strength of their pairwise wins (the Schulze method).
+	// A choice that beats every other choice head to head (the Condorcet winner) always ranks first.
+	MethodSchulze Method = "schulze"
+)
+
+var Methods = []Method{MethodInstantRunoff, MethodSchulze}
+
+func (x Method) IsValid() bool {
+	for _, method := range Methods {
+		if x == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Ballot lists choices from most to least preferred.
+// Choices that are left off are ranked below every listed choice.
+type Ballot []string
+
+// Result is the outcome of counting an election.
+type Result struct {
+	Method  Method
+	Ballots int
+	Ranking []Rank
+	// Rounds are the instant-runoff tallies, in the order they were counted.
+	Rounds []Round `json:"Rounds,omitempty"`
+	// Preferences counts the ballots that prefer one choice to another, for the Schulze method.
+	// Preferences[a][b] is the number of ballots that rank a above b.
+	Preferences map[string]map[string]int `json:"Preferences,omitempty"`
+	// StrongestPaths are the strengths of the strongest paths between choices, for the Schulze method.
+	StrongestPaths map[string]map[string]int `json:"StrongestPaths,omitempty"`
+}
+
+// Rank is a place in the results.
+// Tied choices share a rank, and the next rank skips the places they took.
+type Rank struct {
+	Rank    int
+	Choices []string
+}
+
+// Round is one instant-runoff tally.
+type Round struct {
+	// Rank is the place being decided.
+	Rank    int
+	Tallies []Tally
+	// Exhausted is the number of ballots that have no remaining choices.
+	Exhausted  int      `json:"Exhausted,omitempty"`
+	Elected    []string `json:"Elected,omitempty"`
+	Eliminated []string `json:"Eliminated,omitempty"`
+}
+
+// Tally is the number of first-choice votes for a choice.
+type Tally struct {
+	Choice string
+	Votes  int
+}
+
+// Count ranks the choices.
+// Choices on the ballots that are not in choices are ignored, as are repeated choices.
+func Count(method Method, choices []string, ballots []Ballot) (Result, error) {
+	ballots = cleanBallots(choices, ballots)
+	var result Result
+	switch method {
+	case MethodInstantRunoff:
+		result = countInstantRunoff(choices, ballots)
+	case MethodSchulze:
+		result = countSchulze(choices, ballots)
+	default:
+		return Result{}, fmt.Errorf("%w: %s", ErrUnknownMethod, method)
+	}
+	result.Method = method
+	result.Ballots = len(ballots)
+	return result, nil
+}
+
+func cleanBallots(choices []string, ballots []Ballot) []Ballot {
+	valid := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		valid[choice] = true
+	}
+
+	cleaned := make([]Ballot, 0, len(ballots))
+	for _, ballot := range ballots {
+		seen := make(map[string]bool, len(ballot))
+		var want Ballot
+		for _, choice := range ballot {
+			if valid[choice] && !seen[choice] {
+				want = append(want, choice)
+				seen[choice] = true
+			}
+		}
+		if len(want) != 0 {
+			cleaned = append(cleaned, want)
+		}
+	}
+	return cleaned
+}
+
+// newRanking numbers the groups of tied choices.
+func newRanking(groups [][]string) []Rank {
+	ranking := make([]Rank, 0, len(groups))
+	place := 1
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		sort.Strings(group)
+		ranking = append(ranking, Rank{Rank: place, Choices: group})
+		place += len(group)
+	}
+	return ranking
+}
+
+// unranked are the choices that are not in the ranking.
+func unranked(choices []string, groups [][]string) []string {
+	ranked := make(map[string]bool, len(choices))
+	for _, group := range groups {
+		for _, choice := range group {
+			ranked[choice] = true
+		}
+	}
+	var want []string
+	for _, choice := range choices {
+		if !ranked[choice] {
+			want = append(want, choice)
+		}
+	}
+	return want
+}
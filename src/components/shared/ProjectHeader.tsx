@@ -4,6 +4,17 @@ import Row from "react-bootstrap/Row";
 import { Project } from "../../datasets";
 import { ProjectBanner } from "./ProjectBanner";
 
+// Deadlines are shown in the reader's timezone when the server could parse them.
+const deadlineText = (project: Project): string => {
+  const deadline = new Date(project.SubmissionDeadlineAt);
+  if (isNaN(deadline.getTime()) || deadline.getUTCFullYear() <= 1)
+    return `${project.SubmissionDeadline} (Hawaii Time)`;
+  return deadline.toLocaleString(undefined, {
+    dateStyle: "full",
+    timeStyle: "short",
+  });
+};
+
 export const ProjectHeader = (props: { project: Project }) => (
   <Row className="row-cols-1">
     <Col className="text-center">
@@ -18,7 +29,7 @@ export const ProjectHeader = (props: { project: Project }) => (
         <div className="m-2">
           <h4>
             <strong>Submission Deadline:</strong>{" "}
-            <em>{props.project.SubmissionDeadline} (Hawaii Time)</em>
+            <em>{deadlineText(props.project)}</em>
           </h4>
         </div>
       )}
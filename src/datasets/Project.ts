@@ -17,6 +17,7 @@ export class Project {
     YoutubeLink = "";
     YoutubeEmbed = "";
     SubmissionDeadline = "";
+    SubmissionDeadlineAt = "";
     SubmissionLink = "";
     BandcampAlbum = "";
 